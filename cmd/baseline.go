@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/codegen"
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/datacopy"
+	"github.com/xiezhihuan/db-migrator/internal/seed"
+)
+
+var (
+	baselineVersion      string
+	baselineSnapshot     string
+	baselineIgnoreTables []string
+	snapshotDatabase     string
+	snapshotOutput       string
+)
+
+// baselineCmd 记录基线命令
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "将已有数据库的结构记录为迁移基线",
+	Long: `把版本不大于 --version 的迁移记录为已执行，但不执行迁移本身，用于在已有结构的数据库上启用迁移。
+需要时会先创建迁移记录表和锁表；--version 不是已注册的迁移版本时写入一条基线标记，
+之后 up 会跳过所有不大于该版本的迁移。
+
+指定 --snapshot 时先将每个数据库与快照比较，结构不一致的数据库不会记录基线。
+快照可以用 baseline snapshot 从参照数据库生成。
+
+支持多种数据库选择方式：
+• --database=name        指定单个数据库
+• --databases=db1,db2    指定多个数据库
+• --patterns=shop*       使用通配符匹配数据库
+• --all                  操作所有配置的数据库
+
+示例：
+  db-migrator baseline --version 20240101120000 -d main
+  db-migrator baseline snapshot -d shop_template -o schema.json
+  db-migrator baseline --version 20240101120000 --patterns=shop_* --snapshot schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if baselineVersion == "" {
+			log.Fatalf("参数错误: 必须指定基线版本 --version")
+		}
+		if err := validateDatabaseFlags(); err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+
+		databases, err := resolveDatabases()
+		if err != nil {
+			log.Fatalf("解析数据库失败: %v", err)
+		}
+		printDatabaseInfo(databases)
+		fmt.Printf("📌 基线版本: %s\n", baselineVersion)
+
+		var mismatched []string
+		if baselineSnapshot != "" {
+			databases, mismatched, err = verifySnapshot(databases)
+			if err != nil {
+				log.Fatalf("校验结构快照失败: %v", err)
+			}
+		}
+
+		if len(databases) > 0 {
+			multiMigrator, err := createMultiMigrator()
+			if err != nil {
+				log.Fatalf("创建迁移器失败: %v", err)
+			}
+			defer multiMigrator.Close()
+
+			if err := multiMigrator.Baseline(context.Background(), databases, baselineVersion); err != nil {
+				log.Fatalf("记录基线失败: %v", err)
+			}
+		}
+
+		if len(mismatched) > 0 {
+			log.Fatalf("以下数据库与快照不一致，未记录基线: %s", strings.Join(mismatched, ", "))
+		}
+		fmt.Println("\n🎉 基线记录完成")
+	},
+}
+
+// baselineSnapshotCmd 生成结构快照命令
+var baselineSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "生成用于校验基线的结构快照",
+	Long: `读取参照数据库的表、列、索引、外键和 CHECK 约束，写入 JSON 快照文件。
+
+示例：
+  db-migrator baseline snapshot -d shop_template -o schema.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if snapshotOutput == "" {
+			log.Fatalf("参数错误: 必须指定输出文件 --output")
+		}
+
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		dbName := snapshotDatabase
+		if dbName == "" {
+			_, name, err := dbManager.GetDefaultDatabase()
+			if err != nil {
+				log.Fatalf("连接数据库失败: %v", err)
+			}
+			dbName = name
+		}
+		db, err := dbManager.GetDatabase(dbName)
+		if err != nil {
+			log.Fatalf("连接数据库失败: %v", err)
+		}
+
+		tables, schema, err := loadDatabaseSchema(db, nil)
+		if err != nil {
+			log.Fatalf("读取表结构失败: %v", err)
+		}
+		tables = filterSystemTables(tables)
+
+		if err := codegen.SaveSnapshot(snapshotOutput, schema, tables); err != nil {
+			log.Fatalf("保存快照失败: %v", err)
+		}
+		fmt.Printf("✅ 已保存数据库 %s 的结构快照（%d 张表）: %s\n", schema, len(tables), snapshotOutput)
+	},
+}
+
+// verifySnapshot 将每个数据库与快照比较，返回结构一致和不一致的数据库
+func verifySnapshot(databases []string) ([]string, []string, error) {
+	snapshot, err := codegen.LoadSnapshot(baselineSnapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	expected := filterSystemTables(snapshot.Tables)
+
+	dbManager := database.NewManager(config)
+	defer dbManager.CloseAll()
+
+	if len(databases) == 0 {
+		_, name, err := dbManager.GetDefaultDatabase()
+		if err != nil {
+			return nil, nil, err
+		}
+		databases = []string{name}
+	}
+
+	fmt.Printf("🔍 与快照 %s（来自 %s）比较结构\n", baselineSnapshot, snapshot.Database)
+	var matched, mismatched []string
+	for _, dbName := range databases {
+		db, err := dbManager.GetDatabase(dbName)
+		if err != nil {
+			return nil, nil, err
+		}
+		tables, _, err := loadDatabaseSchema(db, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取数据库 %s 的表结构失败: %v", dbName, err)
+		}
+
+		diffs := codegen.CompareSchema(expected, filterSystemTables(tables))
+		if len(diffs) == 0 {
+			fmt.Printf("  ✅ %s 与快照一致\n", dbName)
+			matched = append(matched, dbName)
+			continue
+		}
+
+		fmt.Printf("  ❌ %s 与快照有 %d 处差异:\n", dbName, len(diffs))
+		for _, diff := range diffs {
+			fmt.Printf("     • %s\n", diff)
+		}
+		mismatched = append(mismatched, dbName)
+	}
+	return matched, mismatched, nil
+}
+
+// filterSystemTables 去掉迁移记录表、锁表、种子记录表、数据同步状态表和 --ignore-tables 指定的表
+func filterSystemTables(tables []*codegen.Table) []*codegen.Table {
+	ignored := map[string]bool{
+		"schema_migrations":             true,
+		"schema_migrations_lock":        true,
+		seed.DefaultHistoryTable:        true,
+		datacopy.DefaultSyncStateTable:  true,
+		datacopy.DefaultCheckpointTable: true,
+	}
+	for _, table := range []string{config.Migrator.MigrationsTable, config.Migrator.LockTable, config.Migrator.SeedTable} {
+		if table != "" {
+			ignored[strings.ToLower(table)] = true
+		}
+	}
+	for _, table := range baselineIgnoreTables {
+		ignored[strings.ToLower(table)] = true
+	}
+
+	var result []*codegen.Table
+	for _, table := range tables {
+		if !ignored[strings.ToLower(table.Name)] {
+			result = append(result, table)
+		}
+	}
+	return result
+}
+
+func init() {
+	baselineCmd.Flags().StringVar(&baselineVersion, "version", "", "基线版本，不大于该版本的迁移记录为已执行")
+	baselineCmd.Flags().StringVar(&baselineSnapshot, "snapshot", "", "结构快照文件，记录基线前校验数据库结构")
+	baselineCmd.Flags().StringSliceVar(&baselineIgnoreTables, "ignore-tables", []string{}, "比较结构时忽略的表")
+	addDatabaseFlags(baselineCmd)
+
+	baselineSnapshotCmd.Flags().StringVarP(&snapshotDatabase, "database", "d", "", "参照数据库（默认为配置中的数据库）")
+	baselineSnapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "快照输出文件")
+	baselineSnapshotCmd.Flags().StringSliceVar(&baselineIgnoreTables, "ignore-tables", []string{}, "快照中忽略的表")
+
+	baselineCmd.AddCommand(baselineSnapshotCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
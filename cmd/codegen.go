@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/codegen"
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+var (
+	codegenDatabase    string
+	codegenTables      []string
+	codegenPackage     string
+	codegenOutput      string
+	codegenVersion     string
+	codegenName        string
+	codegenDescription string
+)
+
+// codegenCmd 代码生成命令组
+var codegenCmd = &cobra.Command{
+	Use:   "codegen",
+	Short: "从已有数据库生成代码",
+	Long:  `读取 information_schema 中的表结构，生成 Go 结构体或重建结构的基线迁移。`,
+}
+
+// codegenStructsCmd 生成结构体命令
+var codegenStructsCmd = &cobra.Command{
+	Use:   "structs",
+	Short: "生成带 db 标签的 Go 结构体",
+	Long: `为数据库中的表生成 Go 结构体，db 标签可被 builder.CreateTableFromStruct 和 SyncFromStruct 解析。
+默认输出到标准输出，--output 指定文件。
+
+示例：
+  db-migrator codegen structs --database shop
+  db-migrator codegen structs --database shop --tables users,orders --package models -o models/tables.go`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		tables, schema, err := loadCodegenSchema(dbManager)
+		if err != nil {
+			log.Fatalf("读取表结构失败: %v", err)
+		}
+
+		packageName := codegenPackage
+		if packageName == "" {
+			packageName = "models"
+		}
+		source, err := codegen.GenerateStructs(packageName, tables)
+		if err != nil {
+			log.Fatalf("生成结构体失败: %v", err)
+		}
+
+		if codegenOutput == "" {
+			fmt.Print(string(source))
+			return
+		}
+		if err := writeGeneratedFile(codegenOutput, source); err != nil {
+			log.Fatalf("写入文件失败: %v", err)
+		}
+		fmt.Printf("✅ 已为数据库 %s 的 %d 张表生成结构体: %s\n", schema, len(tables), codegenOutput)
+	},
+}
+
+// codegenMigrationCmd 生成基线迁移命令
+var codegenMigrationCmd = &cobra.Command{
+	Use:   "migration",
+	Short: "生成重建当前结构的基线迁移",
+	Long: `根据数据库当前结构生成 Go 迁移文件，Up 用 builder.AdvancedBuilder 的链式调用按外键依赖顺序建表，
+Down 按相反顺序删表。无法用构建器表达的定义（如表达式默认值）会以注释标出。
+默认写入 <迁移目录>/<版本>_baseline.go，版本为当前时间 YYYYMMDDHHMMSS；
+指定 --database 时写入 <迁移目录>/<数据库>/，迁移只应用到该数据库。
+
+示例：
+  db-migrator codegen migration --database shop
+  db-migrator codegen migration --database shop --tables users,orders -o migrations/shop/20240101000000_baseline.go`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		tables, schema, err := loadCodegenSchema(dbManager)
+		if err != nil {
+			log.Fatalf("读取表结构失败: %v", err)
+		}
+
+		version := codegenVersion
+		if version == "" {
+			version = time.Now().Format(migrationVersionFormat)
+		}
+		output := codegenOutput
+		if output == "" {
+			dir := migrationsBaseDir()
+			if codegenDatabase != "" {
+				dir = filepath.Join(dir, codegenDatabase)
+			}
+			name := strings.ToLower(codegenName)
+			if err := checkMigrationConflict(dir, version, name); err != nil {
+				log.Fatalf("创建迁移文件失败: %v", err)
+			}
+			output = filepath.Join(dir, fmt.Sprintf("%s_%s.go", version, name))
+		}
+		if _, err := os.Stat(output); err == nil {
+			log.Fatalf("文件已存在: %s", output)
+		}
+
+		packageName := codegenPackage
+		if packageName == "" {
+			packageName = migrationPackageName(codegenDatabase)
+		}
+		source, err := codegen.GenerateMigration(codegen.MigrationOptions{
+			Package:     packageName,
+			Database:    schema,
+			Target:      codegenDatabase,
+			Version:     version,
+			Name:        codegenName,
+			Description: codegenDescription,
+		}, tables)
+		if err != nil {
+			log.Fatalf("生成迁移失败: %v", err)
+		}
+
+		if err := writeGeneratedFile(output, source); err != nil {
+			log.Fatalf("写入文件失败: %v", err)
+		}
+		fmt.Printf("✅ 已为数据库 %s 的 %d 张表生成基线迁移: %s\n", schema, len(tables), output)
+		fmt.Println("🚀 请检查文件中标注的注意事项，并在迁移器中注册该迁移")
+	},
+}
+
+// loadCodegenSchema 连接 --database 指定的数据库（默认为配置中的数据库）并读取表结构，
+// 未指定 --tables 时跳过迁移记录表、种子记录表等工具自身的表
+func loadCodegenSchema(dbManager *database.Manager) ([]*codegen.Table, string, error) {
+	var db types.DB
+	var err error
+	if codegenDatabase != "" {
+		db, err = dbManager.GetDatabase(codegenDatabase)
+	} else {
+		db, _, err = dbManager.GetDefaultDatabase()
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	tables, schema, err := loadDatabaseSchema(db, codegenTables)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(codegenTables) == 0 {
+		tables = filterSystemTables(tables)
+	}
+	if len(tables) == 0 {
+		return nil, "", fmt.Errorf("数据库 %s 中没有找到表", schema)
+	}
+	return tables, schema, nil
+}
+
+// loadDatabaseSchema 读取连接当前库的表结构，配置中的名称可能与实际库名不同，以当前库为准
+func loadDatabaseSchema(db types.DB, tables []string) ([]*codegen.Table, string, error) {
+	var schema string
+	if err := db.QueryRow("SELECT DATABASE()").Scan(&schema); err != nil {
+		return nil, "", fmt.Errorf("获取当前数据库失败: %v", err)
+	}
+
+	result, err := codegen.LoadSchema(db, schema, tables)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, schema, nil
+}
+
+func writeGeneratedFile(filename string, source []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, source, 0644)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{codegenStructsCmd, codegenMigrationCmd} {
+		c.Flags().StringVarP(&codegenDatabase, "database", "d", "", "数据库名称（默认为配置中的数据库）")
+		c.Flags().StringSliceVar(&codegenTables, "tables", []string{}, "只生成指定的表")
+		c.Flags().StringVar(&codegenPackage, "package", "", "生成代码的包名（migration 默认为 migrations 或由数据库名转换的包名）")
+		c.Flags().StringVarP(&codegenOutput, "output", "o", "", "输出文件")
+	}
+	codegenMigrationCmd.Flags().StringVar(&codegenVersion, "version", "", "迁移版本（默认为当前时间 YYYYMMDDHHMMSS）")
+	codegenMigrationCmd.Flags().StringVar(&codegenName, "name", "Baseline", "迁移结构体名前缀")
+	codegenMigrationCmd.Flags().StringVar(&codegenDescription, "description", "", "迁移描述")
+
+	codegenCmd.AddCommand(codegenStructsCmd)
+	codegenCmd.AddCommand(codegenMigrationCmd)
+	rootCmd.AddCommand(codegenCmd)
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/codegen"
+	"github.com/xiezhihuan/db-migrator/internal/migrator"
+)
+
+// migrationVersionFormat 迁移版本格式 YYYYMMDDHHMMSS，按字符串排序即按时间排序
+const migrationVersionFormat = "20060102150405"
+
+var migrationType string
+
+// migrationNamePattern 迁移名称，同时用于文件名和 Go 类型名
+var migrationNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// createCmd 创建迁移命令
+var createCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "创建新的迁移文件",
+	Long: `在迁移目录下创建 <版本>_<名称>.go 或 .sql 迁移文件，版本为当前时间 YYYYMMDDHHMMSS。
+指定 --database 时文件写入 <迁移目录>/<数据库>/，只应用到该数据库；否则应用到默认数据库。
+同一目录中名称或版本重复时拒绝创建。
+
+Go 迁移需要在代码中注册到迁移器；SQL 迁移由 up/down/status 自动从迁移目录加载，
+文件中 "-- +migrate Up" 之后是向上迁移的语句，"-- +migrate Down" 之后是回滚语句。
+
+示例：
+  db-migrator create add_users_phone
+  db-migrator create add_users_phone -d shop
+  db-migrator create add_orders_index -d shop --type sql`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := createMigrationFile(name); err != nil {
+			log.Fatalf("创建迁移文件失败: %v", err)
+		}
+	},
+}
+
+func createMigrationFile(name string) error {
+	if !migrationNamePattern.MatchString(name) {
+		return fmt.Errorf("迁移名称 %s 无效，只能包含字母、数字和下划线，且以字母开头", name)
+	}
+	if migrationType != "go" && migrationType != "sql" {
+		return fmt.Errorf("不支持的迁移类型 %s，可选 go 或 sql", migrationType)
+	}
+
+	dir := migrationsBaseDir()
+	if targetDatabase != "" {
+		dir = filepath.Join(dir, targetDatabase)
+	}
+	version := time.Now().Format(migrationVersionFormat)
+	if err := checkMigrationConflict(dir, version, name); err != nil {
+		return err
+	}
+	filename := filepath.Join(dir, fmt.Sprintf("%s_%s.%s", version, name, migrationType))
+
+	// 确保目录存在
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var content string
+	if migrationType == "sql" {
+		content = sqlMigrationContent(version, name)
+	} else {
+		content = goMigrationContent(version, name)
+	}
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 创建迁移文件: %s\n", filename)
+	if migrationType == "sql" {
+		fmt.Println("🚀 请在文件中编写 Up 和 Down 部分的 SQL")
+	} else {
+		fmt.Printf("🚀 请编辑文件实现 UpWithContext() 和 DownWithContext() 方法，并在迁移器中注册 &%s.%sMigration{}\n",
+			migrationPackageName(targetDatabase), codegen.GoName(name))
+	}
+	return nil
+}
+
+// migrationsBaseDir 配置的迁移目录，默认为 migrations
+func migrationsBaseDir() string {
+	if config.Migrator.MigrationsDir != "" {
+		return config.Migrator.MigrationsDir
+	}
+	return "migrations"
+}
+
+// migrationPackageName 迁移文件的包名，数据库子目录使用由库名转换的包名
+func migrationPackageName(database string) string {
+	if database == "" {
+		return "migrations"
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, database)
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "db_" + name
+	}
+	return name
+}
+
+// checkMigrationConflict 检查目录中是否已有同名或同版本的迁移，
+// 名称转换成 Go 类型名后相同也视为重复
+func checkMigrationConflict(dir, version, name string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取迁移目录失败: %v", err)
+	}
+
+	typeName := codegen.GoName(name)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		existingVersion, existingName, ok := migrator.ParseMigrationFilename(entry.Name())
+		if !ok {
+			continue
+		}
+		if codegen.GoName(existingName) == typeName {
+			return fmt.Errorf("迁移 %s 已存在: %s", name, filepath.Join(dir, entry.Name()))
+		}
+		if existingVersion == version {
+			return fmt.Errorf("版本 %s 已被 %s 使用，请稍后重试", version, entry.Name())
+		}
+	}
+	return nil
+}
+
+// goMigrationContent Go 迁移模板
+func goMigrationContent(version, name string) string {
+	typeName := codegen.GoName(name) + "Migration"
+
+	databaseMethods := ""
+	if targetDatabase != "" {
+		databaseMethods = fmt.Sprintf(`
+// Database 返回目标数据库
+func (m *%[1]s) Database() string {
+	return %[2]q
+}
+
+// Databases 返回目标数据库列表
+func (m *%[1]s) Databases() []string {
+	return nil
+}
+`, typeName, targetDatabase)
+	}
+
+	return fmt.Sprintf(`package %[1]s
+
+import (
+	"github.com/xiezhihuan/db-migrator/internal/migrator"
+)
+
+// %[2]s %[3]s迁移
+type %[2]s struct {
+	migrator.BaseContextMigration
+}
+
+// Version 返回迁移版本
+func (m *%[2]s) Version() string {
+	return %[4]q
+}
+
+// Description 返回迁移描述
+func (m *%[2]s) Description() string {
+	return %[3]q
+}
+%[5]s
+// UpWithContext 执行向上迁移，mc 中的检查器和构建器已指向目标数据库
+func (m *%[2]s) UpWithContext(mc *migrator.MigrationContext) error {
+	// 示例：创建表（表已存在时跳过）
+	// err := mc.Advanced.Table("users").
+	// 	ID().
+	// 	String("username", 50).NotNull().Unique().End().
+	// 	String("email", 100).NotNull().Unique().End().
+	// 	Timestamps().
+	// 	Create(mc)
+	// if err != nil {
+	// 	return err
+	// }
+
+	// 示例：添加列（列已存在时跳过）
+	// err = mc.Builder.AddColumnIfNotExists(mc, "users", "phone", "VARCHAR(20)")
+	// if err != nil {
+	// 	return err
+	// }
+
+	// 示例：创建索引（索引已存在时跳过）
+	// err = mc.Builder.CreateIndexIfNotExists(mc, "users", "idx_phone",
+	// 	"CREATE INDEX idx_phone ON users(phone)")
+	// if err != nil {
+	// 	return err
+	// }
+
+	// 示例：从其他数据库只读查询（干运行时 mc.DryRun 为 true，写操作只打印）
+	// if mc.DBManager != nil {
+	// 	shared, err := mc.DBManager.GetDatabase("shared")
+	// 	...
+	// }
+
+	return nil
+}
+
+// DownWithContext 执行向下迁移（回滚）
+func (m *%[2]s) DownWithContext(mc *migrator.MigrationContext) error {
+	// 示例：删除索引
+	// err := mc.Builder.DropIndexIfExists(mc, "users", "idx_phone")
+	// if err != nil {
+	// 	return err
+	// }
+
+	// 示例：删除列
+	// err = mc.Builder.DropColumnIfExists(mc, "users", "phone")
+	// if err != nil {
+	// 	return err
+	// }
+
+	// 示例：删除表
+	// _, err = mc.DB.Exec("DROP TABLE IF EXISTS users")
+	// if err != nil {
+	// 	return err
+	// }
+
+	return nil
+}
+`, migrationPackageName(targetDatabase), typeName, name, version, databaseMethods)
+}
+
+// sqlMigrationContent SQL 迁移模板
+func sqlMigrationContent(version, name string) string {
+	return fmt.Sprintf(`-- %[1]s %[2]s
+-- 语句以分号结尾，存储过程和触发器可以用 DELIMITER 更换分隔符
+
+%[3]s
+-- 示例：
+-- CREATE TABLE IF NOT EXISTS users (
+--     id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+--     username VARCHAR(50) NOT NULL UNIQUE,
+--     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+-- ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+
+%[4]s
+-- 示例：
+-- DROP TABLE IF EXISTS users;
+
+`, version, name, migrator.SQLUpMarker, migrator.SQLDownMarker)
+}
+
+func init() {
+	createCmd.Flags().StringVarP(&targetDatabase, "database", "d", "", "为指定数据库创建迁移文件（写入迁移目录下的同名子目录）")
+	createCmd.Flags().StringVar(&migrationType, "type", "go", "迁移文件类型：go 或 sql")
+	rootCmd.AddCommand(createCmd)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/xiezhihuan/db-migrator/internal/database"
 	"github.com/xiezhihuan/db-migrator/internal/types"
@@ -21,9 +22,15 @@ var createDBCmd = &cobra.Command{
 	Long: `从SQL文件创建新数据库，支持：
 - 指定数据库名称和字符集
 - 检查数据库是否已存在
-- 解析SQL文件中的DDL语句
-- 按依赖关系执行创建语句
-- 支持表、视图、存储过程、触发器、索引等`,
+- 解析SQL文件中的DDL、DML和SET语句
+- 按文件顺序执行，依赖后面才创建的对象的语句移到该对象之后
+- 支持表、视图、存储过程、触发器、索引、ALTER/DROP/RENAME和数据语句
+- USE、CREATE DATABASE 等切换数据库的语句跳过并在结果中列出
+
+mysqldump 文件（根据文件头识别，或指定 --dump）按文件顺序流式执行，支持 .gz：
+- 默认只导入结构，--with-data 同时导入 INSERT 数据
+- --definer=strip 删除 DEFINER 子句，--definer='app'@'%' 替换为指定用户
+- --convert-charset / --convert-collation 替换字符集和排序规则`,
 	Example: `  # 从SQL文件创建数据库
   db-migrator create-db --name "my_new_shop" --from-sql "schema.sql"
   
@@ -31,7 +38,11 @@ var createDBCmd = &cobra.Command{
   db-migrator create-db --name "my_shop" --from-sql "schema.sql" --charset utf8mb4 --collation utf8mb4_unicode_ci
   
   # 如果数据库已存在则跳过
-  db-migrator create-db --name "my_shop" --from-sql "schema.sql" --if-exists skip`,
+  db-migrator create-db --name "my_shop" --from-sql "schema.sql" --if-exists skip
+
+  # 导入 MySQL 8 的 mysqldump 文件到 MySQL 5.7，包含数据，删除 DEFINER
+  db-migrator create-db --name "my_shop" --from-sql "dump.sql.gz" --with-data --definer strip \
+    --convert-collation utf8mb4_0900_ai_ci=utf8mb4_unicode_ci`,
 	RunE: runCreateDB,
 }
 
@@ -41,6 +52,12 @@ var (
 	createDBCharset   string
 	createDBCollation string
 	createDBIfExists  string
+
+	createDBDump             bool
+	createDBWithData         bool
+	createDBDefiner          string
+	createDBConvertCharset   []string
+	createDBConvertCollation []string
 )
 
 func init() {
@@ -52,6 +69,12 @@ func init() {
 	createDBCmd.Flags().StringVar(&createDBCollation, "collation", "utf8mb4_unicode_ci", "数据库排序规则")
 	createDBCmd.Flags().StringVar(&createDBIfExists, "if-exists", "error", "数据库已存在时的处理方式: error, skip, prompt")
 
+	createDBCmd.Flags().BoolVar(&createDBDump, "dump", false, "按 mysqldump 文件流式导入（默认根据文件头识别）")
+	createDBCmd.Flags().BoolVar(&createDBWithData, "with-data", false, "导入 mysqldump 文件中的数据")
+	createDBCmd.Flags().StringVar(&createDBDefiner, "definer", "keep", "DEFINER 处理: keep, strip, 或替换为 'user'@'host'")
+	createDBCmd.Flags().StringSliceVar(&createDBConvertCharset, "convert-charset", []string{}, "字符集替换 from=to，如 utf8=utf8mb4")
+	createDBCmd.Flags().StringSliceVar(&createDBConvertCollation, "convert-collation", []string{}, "排序规则替换 from=to，如 utf8mb4_0900_ai_ci=utf8mb4_unicode_ci")
+
 	createDBCmd.MarkFlagRequired("name")
 	createDBCmd.MarkFlagRequired("from-sql")
 }
@@ -118,7 +141,18 @@ func runCreateDB(cmd *cobra.Command, args []string) error {
 
 	// 执行创建
 	ctx := context.Background()
-	result, err := creator.CreateFromSQLFile(ctx, dbConfig, absPath)
+	dumpOptions, isDump, err := buildDumpOptions(cmd, absPath)
+	if err != nil {
+		return err
+	}
+
+	var result *types.CreateFromSQLResult
+	if isDump {
+		log.Printf("  导入方式: mysqldump 流式导入（包含数据: %v）", createDBWithData)
+		result, err = creator.LoadDump(ctx, dbConfig, absPath, dumpOptions)
+	} else {
+		result, err = creator.CreateFromSQLFile(ctx, dbConfig, absPath)
+	}
 	if err != nil {
 		if len(result.Errors) > 0 {
 			log.Printf("执行过程中发生错误:")
@@ -135,6 +169,64 @@ func runCreateDB(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// buildDumpOptions 是否按 mysqldump 文件导入及导入选项；指定了 dump 相关参数时也按 dump 导入
+func buildDumpOptions(cmd *cobra.Command, path string) (database.DumpOptions, bool, error) {
+	options := database.DumpOptions{WithData: createDBWithData}
+
+	switch definer := strings.TrimSpace(createDBDefiner); strings.ToLower(definer) {
+	case "keep", "":
+	case "strip":
+		options.Rewrite.StripDefiner = true
+	default:
+		options.Rewrite.Definer = definer
+	}
+
+	var err error
+	if options.Rewrite.Charsets, err = parseRenames("--convert-charset", createDBConvertCharset); err != nil {
+		return options, false, err
+	}
+	if options.Rewrite.Collations, err = parseRenames("--convert-collation", createDBConvertCollation); err != nil {
+		return options, false, err
+	}
+
+	flagged := false
+	for _, name := range []string{"dump", "with-data", "definer", "convert-charset", "convert-collation"} {
+		flagged = flagged || cmd.Flags().Changed(name)
+	}
+	if !flagged && !database.IsMySQLDump(path) {
+		return options, false, nil
+	}
+
+	var lastReport time.Time
+	options.Progress = func(progress database.DumpProgress) {
+		if time.Since(lastReport) < 2*time.Second {
+			return
+		}
+		lastReport = time.Now()
+		percent := float64(0)
+		if progress.TotalBytes > 0 {
+			percent = float64(progress.BytesRead) * 100 / float64(progress.TotalBytes)
+		}
+		log.Printf("⏳ 已处理 %.1f%% (%.1f/%.1f MB)，%d 条语句，第 %d 行",
+			percent, float64(progress.BytesRead)/(1<<20), float64(progress.TotalBytes)/(1<<20),
+			progress.Statements, progress.Line)
+	}
+	return options, true, nil
+}
+
+// parseRenames 解析 from=to 形式的替换列表，键为小写
+func parseRenames(flag string, values []string) (map[string]string, error) {
+	renames := make(map[string]string)
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("%s 格式为 from=to: %s", flag, value)
+		}
+		renames[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+	}
+	return renames, nil
+}
+
 // createRootConnection 创建根连接（不指定数据库）
 func createRootConnection(config *types.DatabaseConfig) (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/?charset=utf8mb4&parseTime=True&loc=Local",
@@ -177,6 +269,7 @@ func printCreateDBResult(result *types.CreateFromSQLResult) {
 	log.Printf("  SQL语句总数: %d", result.StatementsTotal)
 	log.Printf("  成功执行: %d", result.StatementsSuccess)
 	log.Printf("  执行失败: %d", result.StatementsFailed)
+	log.Printf("  跳过: %d", result.StatementsSkipped)
 	log.Printf("  执行时间: %s", result.ExecutionTime)
 
 	if len(result.CreatedObjects) > 0 {
@@ -189,7 +282,7 @@ func printCreateDBResult(result *types.CreateFromSQLResult) {
 		}
 
 		// 按类型顺序打印
-		typeOrder := []string{"CREATE_TABLE", "CREATE_VIEW", "CREATE_PROCEDURE", "CREATE_FUNCTION", "CREATE_TRIGGER", "CREATE_INDEX", "CREATE_OTHER"}
+		typeOrder := []string{"CREATE_TABLE", "CREATE_VIEW", "CREATE_PROCEDURE", "CREATE_FUNCTION", "CREATE_TRIGGER", "CREATE_INDEX", "CREATE_EVENT", "CREATE_OTHER"}
 		for _, objType := range typeOrder {
 			if objects, exists := typeCount[objType]; exists {
 				displayName := getTypeDisplayName(objType)
@@ -201,6 +294,13 @@ func printCreateDBResult(result *types.CreateFromSQLResult) {
 		}
 	}
 
+	if len(result.Skipped) > 0 {
+		log.Printf("\n⏭️  跳过的语句:")
+		for _, stmt := range result.Skipped {
+			log.Printf("  • 第%d行 %s %s: %s", stmt.Line, stmt.Type, stmt.Name, stmt.Reason)
+		}
+	}
+
 	if len(result.Errors) > 0 {
 		log.Printf("\n⚠️  警告/错误:")
 		for _, errMsg := range result.Errors {
@@ -226,6 +326,8 @@ func getTypeDisplayName(objType string) string {
 		return "触发器"
 	case "CREATE_INDEX":
 		return "索引"
+	case "CREATE_EVENT":
+		return "事件"
 	default:
 		return "其他对象"
 	}
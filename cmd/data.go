@@ -6,13 +6,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
+	"github.com/xiezhihuan/db-migrator/internal/builder"
 	"github.com/xiezhihuan/db-migrator/internal/database"
 	"github.com/xiezhihuan/db-migrator/internal/datacopy"
+	"github.com/xiezhihuan/db-migrator/internal/datafile"
+	"github.com/xiezhihuan/db-migrator/internal/seed"
 )
 
 var (
@@ -29,14 +36,26 @@ var (
 	copyTimeout    string
 	copyOnError    string
 	copyConfigFile string
+	copyMaskFile   string
+	copyVerify     bool
+	copyIncrement  []string
+	copySoftDelete []string
+	copyTombstone  string
+	copyResetSync  bool
+	copyContinuous bool
+	copyPollEvery  time.Duration
 
 	// 数据初始化相关参数
-	initDataType string
-	initDataFile string
-	initDataDir  string
-	initFromDB   string
-	initTables   []string
-	initStrategy string
+	initDataType    string
+	initDataFile    string
+	initDataDir     string
+	initFromDB      string
+	initTables      []string
+	initStrategy    string
+	initEnvironment string
+	initListTypes   bool
+	initStrategySet bool
+	initForce       bool
 )
 
 // copyDataCmd 数据复制命令
@@ -65,7 +84,20 @@ var copyDataCmd = &cobra.Command{
   db-migrator copy-data --source=main_db --target=backup_db --tables=orders --conditions="orders:status='completed'"
   
   # 使用配置文件复制
-  db-migrator copy-data --config=copy-config.json`,
+  db-migrator copy-data --config=copy-config.json
+
+  # 增量同步（只复制 updated_at 超过上次高水位的行，并删除已软删除的行）
+  db-migrator copy-data --source=headquarters --patterns=shop_* --tables=products --incremental=products:updated_at --soft-delete=products:deleted_at
+
+  # 租户迁移：全量复制后按增量列持续轮询，切换时 Ctrl+C 停止（每个表都需要 --incremental）
+  db-migrator copy-data --source=tenant_old --target=tenant_new --tables=users,orders --continuous \
+    --incremental=users:updated_at,orders:updated_at --soft-delete=users:deleted_at
+
+  # 复制后校验数据一致性
+  db-migrator copy-data --source=main_db --target=backup_db --tables=orders --verify
+
+  # 从生产库复制到测试库时脱敏
+  db-migrator copy-data --source=prod_shop --target=staging_shop --tables=users,orders --mask=mask-profile.yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := validateCopyFlags(); err != nil {
 			log.Fatalf("参数错误: %v", err)
@@ -101,9 +133,9 @@ var copyDataCmd = &cobra.Command{
 		fmt.Printf("📊 复制表: %s\n", strings.Join(copyTables, ", "))
 
 		// 创建复制配置
-		copyConfig, err := createCopyConfig()
+		copyConfig, err := buildCopyConfig()
 		if err != nil {
-			log.Fatalf("创建复制配置失败: %v", err)
+			log.Fatalf("%v", err)
 		}
 
 		// 创建数据库管理器
@@ -122,6 +154,25 @@ var copyDataCmd = &cobra.Command{
 			}
 		}
 
+		// 持续复制：全量复制后按增量列轮询源库，直到收到中断信号后切换
+		if copyContinuous {
+			if len(targetDBs) != 1 {
+				log.Fatalf("持续复制只支持单个目标数据库")
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			fmt.Println("🔁 持续复制已启动，按 Ctrl+C 停止")
+			replication := datacopy.ReplicationConfig{
+				PollInterval: copyPollEvery,
+			}
+			if err := copier.Replicate(ctx, copySourceDB, targetDBs[0], *copyConfig, replication, progressCallback); err != nil {
+				log.Fatalf("持续复制失败: %v", err)
+			}
+			fmt.Println("✅ 持续复制已停止，高水位已保存")
+			return
+		}
+
 		// 执行复制
 		ctx := context.Background()
 		if len(targetDBs) == 1 {
@@ -134,6 +185,13 @@ var copyDataCmd = &cobra.Command{
 			log.Fatalf("数据复制失败: %v", err)
 		}
 
+		// 复制后校验
+		if copyVerify {
+			if !verifyTargets(ctx, copier, copySourceDB, targetDBs, *copyConfig) {
+				log.Fatalf("数据校验未通过")
+			}
+		}
+
 		fmt.Println("\n🎉 数据复制完成")
 	},
 }
@@ -145,10 +203,12 @@ var initDataCmd = &cobra.Command{
 	Long: `为数据库初始化基础数据。
 
 支持的数据源：
-• JSON文件    - 从JSON文件读取数据
-• YAML文件    - 从YAML文件读取数据
+• 数据文件    - 从 CSV / JSONL / JSON / YAML 文件导入，文件名即表名
+                JSON/YAML 也可以是 {"表名": [...]} 形式的多表数据
+• 种子目录    - seeds/<表名>.yaml|json|jsonl|csv|sql，可用 manifest.yaml 声明
+                表的顺序、策略（insert/upsert/truncate）和适用环境
+• 内置数据    - --data-type 指定内置数据包，如 rbac、system_configs
 • 源数据库    - 从其他数据库复制数据
-• 内置数据    - 使用预定义的数据
 
 示例：
   # 为新租户初始化基础数据
@@ -158,11 +218,23 @@ var initDataCmd = &cobra.Command{
   db-migrator init-data --patterns=shop_* --data-file=shop-init-data.json
   
   # 为所有微服务初始化配置数据
-  db-migrator init-data --patterns=*_service --data-type=system_configs`,
+  db-migrator init-data --patterns=*_service --data-type=system_configs
+
+  # 为所有数据库导入 RBAC 默认角色和权限
+  db-migrator init-data --all --data-type=rbac
+
+  # 按清单导入种子目录，只导入适用于 dev 环境的种子
+  db-migrator init-data -d my_shop --data-dir=seeds --env=dev`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if initListTypes {
+			printSeedPacks()
+			return
+		}
+
 		if err := validateInitFlags(); err != nil {
 			log.Fatalf("参数错误: %v", err)
 		}
+		initStrategySet = cmd.Flags().Changed("strategy")
 
 		// 解析目标数据库
 		databases, err := resolveDatabases()
@@ -196,6 +268,19 @@ var initDataCmd = &cobra.Command{
 
 // 辅助函数
 
+// printSeedPacks 列出内置数据包
+func printSeedPacks() {
+	fmt.Println("📦 内置数据类型:")
+	for _, name := range seed.PackNames() {
+		set, err := seed.Pack(name)
+		if err != nil {
+			fmt.Printf("  • %s: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("  • %-16s %s（表: %s）\n", name, set.Description, strings.Join(set.Tables(), ", "))
+	}
+}
+
 func validateCopyFlags() error {
 	if copySourceDB == "" {
 		return fmt.Errorf("必须指定源数据库 --source")
@@ -226,6 +311,31 @@ func validateInitFlags() error {
 	return nil
 }
 
+// buildCopyConfig 创建复制配置，加载脱敏配置并校验
+func buildCopyConfig() (*datacopy.CopyConfig, error) {
+	copyConfig, err := createCopyConfig()
+	if err != nil {
+		return nil, fmt.Errorf("创建复制配置失败: %v", err)
+	}
+
+	// 加载脱敏配置
+	if copyMaskFile != "" {
+		profile, err := datacopy.LoadMaskingProfile(copyMaskFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载脱敏配置失败: %v", err)
+		}
+		copyConfig.Masking = profile
+		fmt.Printf("🔒 脱敏配置: %s\n", copyMaskFile)
+	}
+
+	// 校验字段映射和转换表达式，避免复制到一半才发现配置错误
+	if err := copyConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("复制配置无效: %v", err)
+	}
+
+	return copyConfig, nil
+}
+
 func createCopyConfig() (*datacopy.CopyConfig, error) {
 	// 如果指定了配置文件，从文件加载
 	if copyConfigFile != "" {
@@ -300,6 +410,29 @@ func createCopyConfig() (*datacopy.CopyConfig, error) {
 		}
 	}
 
+	// 解析增量同步配置
+	// 格式: table:column，软删除列格式同样为 table:column
+	incremental := make(map[string]datacopy.IncrementalConfig)
+	for _, item := range copyIncrement {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("增量同步配置格式错误: %s，应为 table:column", item)
+		}
+		incremental[parts[0]] = datacopy.IncrementalConfig{
+			Column:         strings.TrimSpace(parts[1]),
+			TombstoneTable: copyTombstone,
+		}
+	}
+	for _, item := range copySoftDelete {
+		parts := strings.SplitN(item, ":", 2)
+		inc, exists := incremental[parts[0]]
+		if len(parts) != 2 || !exists {
+			return nil, fmt.Errorf("软删除配置 %s 无效，表必须同时指定 --incremental", item)
+		}
+		inc.SoftDeleteColumn = strings.TrimSpace(parts[1])
+		incremental[parts[0]] = inc
+	}
+
 	config := &datacopy.CopyConfig{
 		Strategy:      strategy,
 		Scope:         scope,
@@ -309,6 +442,8 @@ func createCopyConfig() (*datacopy.CopyConfig, error) {
 		BatchSize:     copyBatchSize,
 		Timeout:       timeout,
 		OnError:       copyOnError,
+		Incremental:   incremental,
+		ResetSync:     copyResetSync,
 	}
 
 	return config, nil
@@ -378,23 +513,116 @@ func initializeFromDatabase(targetDB, sourceDB string) error {
 }
 
 func initializeFromFile(dbName, filename string) error {
-	// 从文件初始化数据
-	fmt.Printf("从文件 %s 初始化数据库 %s\n", filename, dbName)
-	// TODO: 实现文件数据初始化逻辑
-	return nil
+	strategy, err := seed.ParseStrategy(initStrategy)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(filename)
+	set, err := seed.FileSet(seedSetName(dir), os.DirFS(dir), filepath.Base(filename), strategy)
+	if err != nil {
+		return err
+	}
+	return applySeeds(dbName, set, "")
+}
+
+// seedSetName 种子目录在导入记录中的名称，以配置文件所在目录为项目根目录
+func seedSetName(dir string) string {
+	root := "."
+	if used := viper.ConfigFileUsed(); used != "" {
+		root = filepath.Dir(used)
+	}
+	return seed.DirSetName(dir, root)
 }
 
 func initializeBuiltinData(dbName, dataType string) error {
-	// 初始化内置数据
-	fmt.Printf("为数据库 %s 初始化内置数据类型: %s\n", dbName, dataType)
-	// TODO: 实现内置数据初始化逻辑
+	for _, name := range strings.Split(dataType, ",") {
+		set, err := seed.Pack(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📦 内置数据: %s - %s\n", strings.TrimPrefix(set.Name, seed.PackPrefix), set.Description)
+		if err := applySeeds(dbName, set, seedStrategyOverride()); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func initializeFromDirectory(dbName, dirPath string) error {
-	// 从目录初始化数据
-	fmt.Printf("从目录 %s 初始化数据库 %s\n", dirPath, dbName)
-	// TODO: 实现目录数据初始化逻辑
+	set, err := seed.LoadSet(seedSetName(dirPath), os.DirFS(dirPath))
+	if err != nil {
+		return err
+	}
+	if len(set.Entries) == 0 {
+		return fmt.Errorf("目录 %s 中没有种子文件", dirPath)
+	}
+	return applySeeds(dbName, set, seedStrategyOverride())
+}
+
+// seedStrategyOverride 显式指定 --strategy 时覆盖清单中的策略
+func seedStrategyOverride() builder.DataInsertStrategy {
+	if !initStrategySet {
+		return ""
+	}
+	strategy, err := seed.ParseStrategy(initStrategy)
+	if err != nil {
+		log.Fatalf("参数错误: %v", err)
+	}
+	return strategy
+}
+
+// applySeeds 将种子导入数据库并输出每个文件的结果
+func applySeeds(dbName string, set *seed.Set, strategy builder.DataInsertStrategy) error {
+	dbManager := database.NewManager(config)
+	defer dbManager.CloseAll()
+
+	db, err := dbManager.GetDatabase(dbName)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %v", err)
+	}
+
+	seeder := seed.NewSeeder(db)
+	seeder.SetHistory(seed.NewHistory(db, config.Migrator.SeedTable))
+	printed := 0
+	seeder.SetErrorHandler(func(entry seed.Entry, rowErr datafile.RowError) {
+		if printed < 10 {
+			fmt.Printf("  ❌ %s %v\n", entry.File, rowErr)
+		}
+		printed++
+	})
+
+	results, err := seeder.Apply(context.Background(), set, seed.ApplyOptions{
+		Environment: initEnvironment,
+		Tables:      initTables,
+		Strategy:    strategy,
+		Force:       initForce,
+	})
+
+	var failed int64
+	for _, result := range results {
+		if result.Skipped {
+			fmt.Printf("  ⏭️  %s: 跳过（%s）\n", result.Entry, result.Reason)
+			continue
+		}
+		if result.Reapplied {
+			fmt.Printf("  🔁 %s: 内容已变化，重新导入\n", result.Entry)
+		}
+		for _, table := range result.Tables {
+			icon := "✅"
+			if table.Failed > 0 {
+				icon = "⚠️ "
+			}
+			fmt.Printf("  %s %s -> %s [%s]: 读取 %d 行，导入 %d 行，失败 %d 行\n",
+				icon, result.Entry.File, table.Table, result.Entry.Strategy, table.Rows, table.Imported, table.Failed)
+		}
+		failed += result.Failed()
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 行导入失败", failed)
+	}
 	return nil
 }
 
@@ -412,17 +640,28 @@ func init() {
 	copyDataCmd.Flags().StringVar(&copyTimeout, "timeout", "30m", "超时时间")
 	copyDataCmd.Flags().StringVar(&copyOnError, "on-error", "stop", "错误处理: stop, continue, rollback")
 	copyDataCmd.Flags().StringVar(&copyConfigFile, "config", "", "复制配置文件")
+	copyDataCmd.Flags().StringVar(&copyMaskFile, "mask", "", "脱敏配置文件(YAML)")
+	copyDataCmd.Flags().BoolVar(&copyVerify, "verify", false, "复制完成后校验行数和校验和")
+	copyDataCmd.Flags().StringSliceVar(&copyIncrement, "incremental", []string{}, "增量同步 table:column（updated_at 或递增ID）")
+	copyDataCmd.Flags().StringSliceVar(&copySoftDelete, "soft-delete", []string{}, "软删除列 table:column，已删除的行从目标表删除")
+	copyDataCmd.Flags().StringVar(&copyTombstone, "tombstone-table", "", "源库墓碑表 (table_name, pk_value, deleted_at)")
+	copyDataCmd.Flags().BoolVar(&copyResetSync, "reset-sync", false, "忽略已保存的高水位，重新全量同步")
+	copyDataCmd.Flags().BoolVar(&copyContinuous, "continuous", false, "全量复制后按 --incremental 的增量列持续轮询源库，软删除和墓碑表中的删除同样传播")
+	copyDataCmd.Flags().DurationVar(&copyPollEvery, "poll-interval", datacopy.DefaultPollInterval, "持续复制的轮询间隔")
 
 	// 添加数据库选择参数
 	addDatabaseFlags(copyDataCmd)
 
 	// 数据初始化命令参数
-	initDataCmd.Flags().StringVar(&initDataType, "data-type", "", "内置数据类型")
+	initDataCmd.Flags().StringVar(&initDataType, "data-type", "", "内置数据类型，多个用逗号分隔（--list-types 查看）")
 	initDataCmd.Flags().StringVar(&initDataFile, "data-file", "", "数据文件路径")
 	initDataCmd.Flags().StringVar(&initDataDir, "data-dir", "", "数据目录路径")
 	initDataCmd.Flags().StringVar(&initFromDB, "from-db", "", "源数据库名称")
 	initDataCmd.Flags().StringSliceVar(&initTables, "tables", []string{}, "要初始化的表")
-	initDataCmd.Flags().StringVar(&initStrategy, "strategy", "merge", "初始化策略")
+	initDataCmd.Flags().StringVar(&initStrategy, "strategy", "merge", "初始化策略: insert, upsert(merge), truncate(overwrite), replace, ignore；种子目录和内置数据默认使用清单中的策略")
+	initDataCmd.Flags().StringVar(&initEnvironment, "env", os.Getenv("DB_MIGRATOR_ENV"), "当前环境，用于过滤清单中声明了 environments 的种子")
+	initDataCmd.Flags().BoolVar(&initForce, "force", false, "忽略种子导入记录，重新导入")
+	initDataCmd.Flags().BoolVar(&initListTypes, "list-types", false, "列出内置数据类型")
 
 	// 添加数据库选择参数
 	addDatabaseFlags(initDataCmd)
@@ -431,3 +670,32 @@ func init() {
 	rootCmd.AddCommand(copyDataCmd)
 	rootCmd.AddCommand(initDataCmd)
 }
+
+// printSeedHistory 显示数据库中已导入的种子，没有记录时不输出
+func printSeedHistory(dbManager *database.Manager, dbName string) {
+	db, err := dbManager.GetDatabase(dbName)
+	if err != nil {
+		fmt.Printf("  ⚠️  读取种子记录失败: %v\n", err)
+		return
+	}
+
+	records, err := seed.NewHistory(db, config.Migrator.SeedTable).Latest()
+	if err != nil {
+		fmt.Printf("  ⚠️  读取种子记录失败: %v\n", err)
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	fmt.Println("  🌱 种子数据:")
+	for _, record := range records {
+		checksum := record.Checksum
+		if len(checksum) > 12 {
+			checksum = checksum[:12]
+		}
+		fmt.Printf("    ✅ %s/%s [%s] %s - 导入 %d/%d 行 %s\n",
+			record.SeedSet, record.FilePath, record.Strategy, checksum,
+			record.Imported, record.RowsRead, record.AppliedAt.Format("2006-01-02 15:04:05"))
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/datacopy"
+)
+
+var verifyChunkSize int
+
+// dataCmd 数据操作命令组
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "数据操作",
+	Long:  `数据操作相关的子命令，如复制后的数据校验。`,
+}
+
+// dataVerifyCmd 数据校验命令
+var dataVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "校验两个数据库之间的数据是否一致",
+	Long: `按主键区间比较源数据库和目标数据库的行数与校验和，定位不一致的主键区间。
+
+条件、字段映射和脱敏规则与 copy-data 相同，源数据按复制时的规则计算期望值。
+没有主键或主键被转换的表按整表校验。
+
+示例：
+  # 校验订单表
+  db-migrator data verify --source=main_db --target=backup_db --tables=orders,order_items
+
+  # 使用复制配置文件校验
+  db-migrator data verify --source=main_db --target=backup_db --config=copy-config.json --chunk-size=5000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if copySourceDB == "" {
+			log.Fatalf("参数错误: 必须指定源数据库 --source")
+		}
+		if copyTargetDB == "" && len(copyTargetDBs) == 0 {
+			log.Fatalf("参数错误: 必须指定目标数据库 --target 或 --targets")
+		}
+		if len(copyTables) == 0 && copyConfigFile == "" {
+			log.Fatalf("参数错误: 必须指定要校验的表 --tables 或配置文件 --config")
+		}
+
+		targetDBs := copyTargetDBs
+		if copyTargetDB != "" {
+			targetDBs = []string{copyTargetDB}
+		}
+
+		copyConfig, err := buildCopyConfig()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if verifyChunkSize > 0 {
+			copyConfig.VerifyChunk = verifyChunkSize
+		}
+
+		copier := datacopy.NewCrossDatabaseCopier(database.NewManager(config))
+		if !verifyTargets(context.Background(), copier, copySourceDB, targetDBs, *copyConfig) {
+			log.Fatalf("数据校验未通过")
+		}
+	},
+}
+
+// verifyTargets 逐个校验目标数据库，全部一致时返回 true
+func verifyTargets(ctx context.Context, copier *datacopy.CrossDatabaseCopier, sourceDB string, targetDBs []string, copyConfig datacopy.CopyConfig) bool {
+	allOK := true
+
+	for _, targetDB := range targetDBs {
+		fmt.Printf("\n🔍 校验 %s -> %s\n", sourceDB, targetDB)
+
+		results, err := copier.VerifyBetweenDatabases(ctx, sourceDB, targetDB, copyConfig)
+		printVerification(results)
+		if err != nil {
+			fmt.Printf("❌ 校验失败: %v\n", err)
+			allOK = false
+			continue
+		}
+
+		for _, result := range results {
+			if !result.OK() {
+				allOK = false
+			}
+		}
+	}
+
+	if allOK {
+		fmt.Println("\n✅ 数据校验通过")
+	}
+	return allOK
+}
+
+// printVerification 打印校验结果
+func printVerification(results []datacopy.TableVerification) {
+	for _, result := range results {
+		switch {
+		case result.Skipped != "":
+			fmt.Printf("⚠️  %s: 跳过（%s）\n", result.Table, result.Skipped)
+			continue
+		case result.OK():
+			fmt.Printf("✅ %s: %d 行一致（%d 个区间）\n", result.Table, result.SourceRows, result.Chunks)
+		default:
+			fmt.Printf("❌ %s: 源 %d 行，目标 %d 行，%d/%d 个区间不一致\n",
+				result.Table, result.SourceRows, result.TargetRows, len(result.Mismatches), result.Chunks)
+			for _, mismatch := range result.Mismatches {
+				fmt.Printf("   • 主键区间 %s: 源 %d 行，目标 %d 行\n",
+					mismatch.Range(), mismatch.SourceRows, mismatch.TargetRows)
+			}
+		}
+		if result.Note != "" {
+			fmt.Printf("   ℹ️  %s\n", result.Note)
+		}
+	}
+}
+
+func init() {
+	dataVerifyCmd.Flags().StringVar(&copySourceDB, "source", "", "源数据库名称")
+	dataVerifyCmd.Flags().StringVar(&copyTargetDB, "target", "", "目标数据库名称")
+	dataVerifyCmd.Flags().StringSliceVar(&copyTargetDBs, "targets", []string{}, "多个目标数据库")
+	dataVerifyCmd.Flags().StringSliceVar(&copyTables, "tables", []string{}, "要校验的表名")
+	dataVerifyCmd.Flags().StringSliceVar(&copyConditions, "conditions", []string{}, "复制条件 table:condition")
+	dataVerifyCmd.Flags().StringSliceVar(&copyMappings, "mappings", []string{}, "字段映射 table:src=dst,src2=dst2")
+	dataVerifyCmd.Flags().StringVar(&copyConfigFile, "config", "", "复制配置文件")
+	dataVerifyCmd.Flags().StringVar(&copyMaskFile, "mask", "", "脱敏配置文件(YAML)")
+	dataVerifyCmd.Flags().StringVar(&copyTimeout, "timeout", "30m", "超时时间")
+	dataVerifyCmd.Flags().IntVar(&verifyChunkSize, "chunk-size", 10000, "每个主键区间的行数")
+
+	dataCmd.AddCommand(dataVerifyCmd)
+	rootCmd.AddCommand(dataCmd)
+}
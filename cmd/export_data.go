@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/datafile"
+)
+
+var (
+	exportTables    []string
+	exportFormat    string
+	exportWhere     []string
+	exportOutputDir string
+	exportGzip      bool
+	exportSQLBatch  int
+)
+
+// exportDataCmd 数据导出命令
+var exportDataCmd = &cobra.Command{
+	Use:   "export-data",
+	Short: "将表数据导出为 CSV / JSON Lines / JSON / SQL / YAML 文件",
+	Long: `将表数据逐行导出到文件，每个表一个文件，导出结果可以重新导入：
+• csv    - 首行为列名，NULL 写作 \N，二进制数据写作 X'十六进制'
+• jsonl  - 每行一个 JSON 对象
+• json   - JSON 数组，可用 DataBuilder.InsertFromJSON 导入
+• sql    - INSERT 语句，可用 insert-data --from-sql 导入
+• yaml   - YAML 列表，可用 DataBuilder.InsertFromYAML 导入
+
+--where 的格式为 table:condition，不带表名前缀时对所有表生效。
+未指定 --tables 时导出库中所有表。多个数据库时每个库输出到 <output>/<数据库名>/。`,
+	Example: `  # 导出种子数据为 YAML
+  db-migrator export-data -d my_shop --tables=system_configs,categories --format=yaml --output=seeds
+
+  # 导出已完成订单为压缩的 SQL
+  db-migrator export-data -d my_shop --tables=orders --format=sql --where="orders:status='completed'" --gzip
+
+  # 为所有店铺生成快照
+  db-migrator export-data --patterns=shop_* --format=jsonl --gzip --output=snapshots`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateDatabaseFlags(); err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+
+		format, err := datafile.ParseFormat(exportFormat)
+		if err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+
+		databases, err := resolveDatabases()
+		if err != nil {
+			log.Fatalf("解析数据库失败: %v", err)
+		}
+		if len(databases) == 0 {
+			if config.Database.Database == "" {
+				log.Fatalf("必须指定要导出的数据库")
+			}
+			databases = []string{config.Database.Database}
+		}
+
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		conditions, where := parseExportConditions(exportWhere, exportTables)
+
+		ctx := context.Background()
+		for _, dbName := range databases {
+			outputDir := exportOutputDir
+			if len(databases) > 1 {
+				outputDir = filepath.Join(exportOutputDir, dbName)
+			}
+
+			db, err := dbManager.GetDatabase(dbName)
+			if err != nil {
+				log.Fatalf("连接数据库 %s 失败: %v", dbName, err)
+			}
+
+			exporter := datafile.NewExporter(db, datafile.ExportConfig{
+				Tables:       exportTables,
+				Format:       format,
+				Conditions:   conditions,
+				Where:        where,
+				OutputDir:    outputDir,
+				Gzip:         exportGzip,
+				SQLBatchSize: exportSQLBatch,
+			})
+			exporter.SetProgressCallback(func(table string, rows int64) {
+				fmt.Printf("⏳ %s.%s: 已导出 %d 行\n", dbName, table, rows)
+			})
+
+			fmt.Printf("\n📦 导出数据库: %s -> %s\n", dbName, outputDir)
+			results, err := exporter.Export(ctx)
+			if err != nil {
+				log.Fatalf("导出失败: %v", err)
+			}
+
+			var total int64
+			for _, result := range results {
+				fmt.Printf("  ✅ %s: %d 行 -> %s\n", result.Table, result.Rows, result.File)
+				total += result.Rows
+			}
+			fmt.Printf("🎯 %s 导出完成: %d 个表，共 %d 行\n", dbName, len(results), total)
+		}
+	},
+}
+
+// parseExportConditions 解析 --where，前缀为表名时只作用于该表，否则作用于所有表。
+// 指定了 --tables 时前缀必须是其中的表，未指定时任何标识符形式的前缀都视为表名
+func parseExportConditions(items []string, tables []string) (map[string]string, string) {
+	conditions := make(map[string]string)
+	var global []string
+
+	for _, item := range items {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) == 2 && isConditionTable(strings.TrimSpace(parts[0]), tables) {
+			conditions[strings.TrimSpace(parts[0])] = parts[1]
+			continue
+		}
+		global = append(global, item)
+	}
+
+	return conditions, strings.Join(global, " AND ")
+}
+
+// conditionTablePattern 表名前缀的形式，条件本身的冒号（如时间字面量）前通常不是单独的标识符
+var conditionTablePattern = regexp.MustCompile(`^[A-Za-z0-9_$]+$`)
+
+// isConditionTable 判断 --where 的前缀是否为表名
+func isConditionTable(prefix string, tables []string) bool {
+	if len(tables) > 0 {
+		return contains(tables, prefix)
+	}
+	return conditionTablePattern.MatchString(prefix)
+}
+
+func init() {
+	exportDataCmd.Flags().StringSliceVar(&exportTables, "tables", []string{}, "要导出的表（默认所有表）")
+	exportDataCmd.Flags().StringVar(&exportFormat, "format", "jsonl", "导出格式: csv, jsonl, json, sql, yaml")
+	exportDataCmd.Flags().StringArrayVar(&exportWhere, "where", []string{}, "导出条件 table:condition 或 condition")
+	exportDataCmd.Flags().StringVarP(&exportOutputDir, "output", "o", "export", "输出目录")
+	exportDataCmd.Flags().BoolVar(&exportGzip, "gzip", false, "使用 gzip 压缩输出文件")
+	exportDataCmd.Flags().IntVar(&exportSQLBatch, "sql-batch-size", 500, "sql 格式每条 INSERT 的行数")
+
+	// 添加数据库选择参数
+	addDatabaseFlags(exportDataCmd)
+
+	rootCmd.AddCommand(exportDataCmd)
+}
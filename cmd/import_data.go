@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/datafile"
+)
+
+var (
+	importFiles       []string
+	importDir         string
+	importTable       string
+	importFormat      string
+	importColumns     []string
+	importDelimiter   string
+	importQuote       string
+	importNullValues  []string
+	importNoHeader    bool
+	importBatchSize   int
+	importOnConflict  string
+	importTruncate    bool
+	importMaxErrors   int
+	importErrorReport string
+)
+
+// importDataCmd 数据文件导入命令
+var importDataCmd = &cobra.Command{
+	Use:   "import-data",
+	Short: "将 CSV / JSON Lines / JSON / YAML 文件导入已有的表",
+	Long: `逐行读取数据文件，按目标列类型（information_schema）转换后分批写入已有的表。
+
+• csv    - 首行为列名（--no-header 时按表的列顺序），支持自定义分隔符、引号和 NULL 标记
+• jsonl  - 每行一个 JSON 对象
+• json   - JSON 数组，或 {"表名": [...]} 形式的多表数据
+• yaml   - YAML 列表，或 {表名: [...]} 形式的多表数据（整体加载到内存）
+
+未指定 --table 时使用文件名作为表名。转换失败或写入失败的行不会中断导入，
+错误逐行输出，并可通过 --error-report 写入 CSV 文件。`,
+	Example: `  # 导入 CSV，映射列名并忽略不需要的列
+  db-migrator import-data -d my_shop --file=users.csv --columns=user_name=username,remark=-
+
+  # 分号分隔、NULL 写作空串和 NULL 的 CSV
+  db-migrator import-data -d my_shop --file=legacy.csv --table=customers --delimiter=";" --null="" --null=NULL
+
+  # 导入 export-data 生成的目录，已存在的行更新
+  db-migrator import-data -d my_shop --dir=export --on-conflict=update --error-report=errors.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateDatabaseFlags(); err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+		if len(importFiles) == 0 && importDir == "" {
+			log.Fatalf("参数错误: 必须指定 --file 或 --dir")
+		}
+
+		importConfig, err := buildImportConfig()
+		if err != nil {
+			log.Fatalf("参数错误: %v", err)
+		}
+
+		files := importFiles
+		if importDir != "" {
+			dirFiles, err := datafile.DataFiles(importDir)
+			if err != nil {
+				log.Fatalf("读取目录失败: %v", err)
+			}
+			files = append(files, dirFiles...)
+		}
+		if importTable != "" && len(files) > 1 {
+			log.Fatalf("参数错误: --table 只能用于单个文件")
+		}
+
+		databases, err := resolveDatabases()
+		if err != nil {
+			log.Fatalf("解析数据库失败: %v", err)
+		}
+		if len(databases) == 0 {
+			if config.Database.Database == "" {
+				log.Fatalf("必须指定要导入的数据库")
+			}
+			databases = []string{config.Database.Database}
+		}
+
+		report, err := openImportErrorReport(importErrorReport)
+		if err != nil {
+			log.Fatalf("创建错误报告失败: %v", err)
+		}
+		defer report.Close()
+
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		failed := false
+		for _, dbName := range databases {
+			db, err := dbManager.GetDatabase(dbName)
+			if err != nil {
+				log.Fatalf("连接数据库 %s 失败: %v", dbName, err)
+			}
+
+			fmt.Printf("\n📥 导入数据库: %s\n", dbName)
+			importer := datafile.NewImporter(db, importConfig)
+			ok, err := importDataFiles(context.Background(), importer, dbName, files, report)
+			if err != nil {
+				log.Fatalf("导入失败: %v", err)
+			}
+			failed = failed || !ok
+		}
+
+		if failed {
+			report.Close()
+			dbManager.CloseAll()
+			if importErrorReport != "" {
+				fmt.Printf("\n⚠️  部分行导入失败，错误报告: %s\n", importErrorReport)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("\n🎉 数据导入完成")
+	},
+}
+
+// buildImportConfig 根据命令行参数创建导入配置
+func buildImportConfig() (datafile.ImportConfig, error) {
+	importConfig := datafile.ImportConfig{
+		Table:     importTable,
+		BatchSize: importBatchSize,
+		Truncate:  importTruncate,
+		MaxErrors: importMaxErrors,
+	}
+
+	if importFormat != "" {
+		format, err := datafile.ParseFormat(importFormat)
+		if err != nil {
+			return importConfig, err
+		}
+		importConfig.Format = format
+	}
+
+	strategy, err := datafile.ParseConflictStrategy(importOnConflict)
+	if err != nil {
+		return importConfig, err
+	}
+	importConfig.OnConflict = strategy
+
+	if len(importColumns) > 0 {
+		importConfig.Columns = make(map[string]string)
+		for _, mapping := range importColumns {
+			parts := strings.SplitN(mapping, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return importConfig, fmt.Errorf("无效的列映射: %s，格式为 file_field=column 或 file_field=-", mapping)
+			}
+			importConfig.Columns[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	delimiter, err := singleRune("--delimiter", importDelimiter)
+	if err != nil {
+		return importConfig, err
+	}
+	quote, err := singleRune("--quote", importQuote)
+	if err != nil {
+		return importConfig, err
+	}
+	importConfig.CSV = datafile.CSVOptions{
+		Delimiter:  delimiter,
+		Quote:      quote,
+		NullValues: importNullValues,
+		NoHeader:   importNoHeader,
+	}
+
+	return importConfig, nil
+}
+
+// singleRune 解析单字符参数，支持 \t
+func singleRune(flag, value string) (rune, error) {
+	if value == `\t` {
+		return '\t', nil
+	}
+	if utf8.RuneCountInString(value) != 1 {
+		return 0, fmt.Errorf("%s 必须是单个字符: %q", flag, value)
+	}
+	r, _ := utf8.DecodeRuneInString(value)
+	return r, nil
+}
+
+// importReport 行错误报告，写入 CSV 文件
+type importReport struct {
+	file *os.File
+	w    *csv.Writer
+}
+
+// openImportErrorReport 创建错误报告文件，path 为空时只输出到终端
+func openImportErrorReport(path string) (*importReport, error) {
+	report := &importReport{}
+	if path == "" {
+		return report, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	report.file = file
+	report.w = csv.NewWriter(file)
+	report.w.Write([]string{"database", "file", "table", "line", "column", "error", "record"})
+	return report, nil
+}
+
+func (r *importReport) write(dbName, file string, rowErr datafile.RowError) {
+	if r.w == nil {
+		return
+	}
+	r.w.Write([]string{dbName, file, rowErr.Table, strconv.FormatInt(rowErr.Line, 10), rowErr.Column, rowErr.Message, rowErr.Raw})
+}
+
+func (r *importReport) Close() {
+	if r.w != nil {
+		r.w.Flush()
+		r.file.Close()
+	}
+}
+
+// importDataFiles 依次导入文件并输出结果，返回是否所有行都导入成功
+func importDataFiles(ctx context.Context, importer *datafile.Importer, dbName string, files []string, report *importReport) (bool, error) {
+	ok := true
+	for _, file := range files {
+		printed := 0
+		importer.SetErrorHandler(func(rowErr datafile.RowError) {
+			report.write(dbName, file, rowErr)
+			if printed < 10 {
+				fmt.Printf("  ❌ %s %s: %v\n", file, rowErr.Table, rowErr)
+			} else if printed == 10 {
+				fmt.Println("  ... 更多错误见错误报告")
+			}
+			printed++
+		})
+		importer.SetProgressCallback(func(table string, rows int64) {
+			if rows > 0 && rows%100000 == 0 {
+				fmt.Printf("  ⏳ %s: 已读取 %d 行\n", table, rows)
+			}
+		})
+
+		results, err := importer.ImportFile(ctx, file)
+		for _, result := range results {
+			icon := "✅"
+			if result.Failed > 0 {
+				icon = "⚠️ "
+				ok = false
+			}
+			fmt.Printf("  %s %s -> %s: 读取 %d 行，导入 %d 行，失败 %d 行\n",
+				icon, file, result.Table, result.Rows, result.Imported, result.Failed)
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", file, err)
+		}
+	}
+	return ok, nil
+}
+
+func init() {
+	importDataCmd.Flags().StringSliceVar(&importFiles, "file", []string{}, "数据文件（可多次指定）")
+	importDataCmd.Flags().StringVar(&importDir, "dir", "", "数据目录，导入其中所有 csv/jsonl/json/yaml 文件")
+	importDataCmd.Flags().StringVar(&importTable, "table", "", "目标表（默认使用文件名）")
+	importDataCmd.Flags().StringVar(&importFormat, "format", "", "文件格式: csv, jsonl, json, yaml（默认根据扩展名判断）")
+	importDataCmd.Flags().StringSliceVar(&importColumns, "columns", []string{}, "列映射 file_field=column，映射为 - 时忽略该字段")
+	importDataCmd.Flags().StringVar(&importDelimiter, "delimiter", ",", "CSV 字段分隔符（\\t 表示制表符）")
+	importDataCmd.Flags().StringVar(&importQuote, "quote", `"`, "CSV 引号字符")
+	importDataCmd.Flags().StringArrayVar(&importNullValues, "null", []string{datafile.NullText}, "CSV 中表示 NULL 的文本（可多次指定）")
+	importDataCmd.Flags().BoolVar(&importNoHeader, "no-header", false, "CSV 没有标题行，按表的列顺序对应")
+	importDataCmd.Flags().IntVar(&importBatchSize, "batch-size", 1000, "每批插入的行数")
+	importDataCmd.Flags().StringVar(&importOnConflict, "on-conflict", "error", "主键/唯一键冲突处理: error, ignore, update, replace")
+	importDataCmd.Flags().BoolVar(&importTruncate, "truncate", false, "导入前清空表")
+	importDataCmd.Flags().IntVar(&importMaxErrors, "max-errors", 0, "错误行数超过该值时中止（0 表示不限制）")
+	importDataCmd.Flags().StringVar(&importErrorReport, "error-report", "", "错误报告文件（CSV）")
+
+	// 添加数据库选择参数
+	addDatabaseFlags(importDataCmd)
+
+	rootCmd.AddCommand(importDataCmd)
+}
@@ -3,13 +3,16 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/xiezhihuan/db-migrator/internal/builder"
 	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/seed"
 	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
 	"github.com/xiezhihuan/db-migrator/internal/types"
 
@@ -25,7 +28,9 @@ var insertDataCmd = &cobra.Command{
 - 支持批量插入和事务管理
 - 主键冲突时报错停止并回滚
 - 支持多数据库和模式匹配
-- 详细的进度显示和错误报告`,
+- 详细的进度显示和错误报告
+- 导入记录写入 seed_history，同一文件在每个数据库只导入一次，
+  文件内容变化后需要 --force 重新导入`,
 	Example: `  # 向单个数据库插入数据
   db-migrator insert-data --database "my_shop" --from-sql "data.sql"
   
@@ -53,13 +58,15 @@ var (
 	insertOnConflict      string
 	insertDryRun          bool
 	insertPreview         bool
+	insertForce           bool
 )
 
 func init() {
 	rootCmd.AddCommand(insertDataCmd)
 
-	insertDataCmd.Flags().StringVar(&insertOnConflict, "on-conflict", "error", "主键冲突处理: error, ignore")
+	insertDataCmd.Flags().StringVar(&insertOnConflict, "on-conflict", "error", "主键冲突处理: error, ignore, replace")
 	insertDataCmd.Flags().BoolVar(&insertDryRun, "dry-run", false, "仅解析SQL文件，不连接数据库")
+	insertDataCmd.Flags().BoolVar(&insertForce, "force", false, "忽略种子导入记录，重新导入")
 	insertDataCmd.Flags().BoolVar(&insertPreview, "preview", false, "预览解析到的INSERT语句（显示前10条）")
 
 	insertDataCmd.Flags().StringVar(&insertFromSQLFile, "from-sql", "", "包含INSERT语句的SQL文件路径 (必填)")
@@ -86,7 +93,7 @@ func runInsertData(cmd *cobra.Command, args []string) error {
 	}
 
 	// 验证on-conflict参数
-	validConflictStrategies := []string{"error", "ignore"}
+	validConflictStrategies := []string{"error", "ignore", "replace"}
 	if !contains(validConflictStrategies, insertOnConflict) {
 		return fmt.Errorf("无效的on-conflict值: %s，支持的值: %s",
 			insertOnConflict, strings.Join(validConflictStrategies, ", "))
@@ -132,6 +139,22 @@ func runInsertData(cmd *cobra.Command, args []string) error {
 		return runInsertDataDryRun()
 	}
 
+	// 已导入且内容未变化的数据库跳过
+	dbManager := database.NewManager(config)
+	defer dbManager.CloseAll()
+	tracker, err := newInsertSeedTracker(dbManager, insertFromSQLFile)
+	if err != nil {
+		return err
+	}
+	databases, err = tracker.pending(databases)
+	if err != nil {
+		return err
+	}
+	if len(databases) == 0 {
+		fmt.Println("✅ 所有数据库都已导入该文件，无需执行（使用 --force 重新导入）")
+		return nil
+	}
+
 	// 创建根连接（不指定数据库）
 	rootConn, err := createRootConnection(&config.Database)
 	if err != nil {
@@ -166,10 +189,13 @@ func runInsertData(cmd *cobra.Command, args []string) error {
 			}
 			return fmt.Errorf("数据插入失败: %v", err)
 		}
+		if err := tracker.record(databases[0], result); err != nil {
+			return err
+		}
 		printInsertResult(result)
 	} else {
 		// 多数据库插入
-		multiResult, err := executeMultiDatabaseInsert(ctx, inserter, databases, absPath, insertConfig)
+		multiResult, err := executeMultiDatabaseInsert(ctx, inserter, databases, absPath, insertConfig, tracker.record)
 		if err != nil {
 			return fmt.Errorf("多数据库插入失败: %v", err)
 		}
@@ -182,13 +208,39 @@ func runInsertData(cmd *cobra.Command, args []string) error {
 func runInsertDataDryRun() error {
 	fmt.Printf("🔍 解析SQL文件：%s\n", insertFromSQLFile)
 
-	parser := sqlparser.NewInsertParser()
-	insertStatements, err := parser.ParseInsertFile(insertFromSQLFile)
+	file, err := os.Open(insertFromSQLFile)
 	if err != nil {
-		return fmt.Errorf("解析SQL文件失败: %v", err)
+		return fmt.Errorf("打开SQL文件失败: %v", err)
 	}
+	defer file.Close()
 
-	fmt.Printf("✅ 成功解析 %d 条INSERT语句\n\n", len(insertStatements))
+	// 流式读取，只保留统计信息和前10条语句
+	parser := sqlparser.NewInsertParser()
+	reader := parser.NewInsertReader(file, insertBatchSize)
+	tableStats := make(map[string]int64)
+	var tables, previews []string
+	lastStatement := 0
+	for {
+		stmt, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("解析SQL文件失败: %v", err)
+		}
+
+		if _, ok := tableStats[stmt.TableName]; !ok {
+			tables = append(tables, stmt.TableName)
+		}
+		tableStats[stmt.TableName] += int64(len(stmt.Values))
+		// 同一条语句可能分多批返回
+		if reader.Statements() != lastStatement && len(previews) < 10 {
+			previews = append(previews, stmt.Statement)
+		}
+		lastStatement = reader.Statements()
+	}
+
+	fmt.Printf("✅ 成功解析 %d 条INSERT语句\n\n", reader.Statements())
 
 	// 显示识别到的变量
 	variables := parser.GetVariables()
@@ -201,28 +253,21 @@ func runInsertDataDryRun() error {
 	}
 
 	// 显示解析统计
-	tableStats := make(map[string]int)
-	for _, stmt := range insertStatements {
-		tableStats[stmt.TableName]++
-	}
-
 	fmt.Println("📊 **表统计信息：**")
-	for table, count := range tableStats {
-		fmt.Printf("  - %s: %d 条记录\n", table, count)
+	for _, table := range tables {
+		fmt.Printf("  - %s: %d 条记录\n", table, tableStats[table])
 	}
 
-	if insertPreview && len(insertStatements) > 0 {
+	if insertPreview && len(previews) > 0 {
 		fmt.Println("\n📝 **预览INSERT语句（前10条）：**")
-		for i, stmt := range insertStatements {
-			if i >= 10 {
-				fmt.Printf("  ... 还有 %d 条语句\n", len(insertStatements)-10)
-				break
-			}
-			preview := stmt.Statement
+		for i, preview := range previews {
 			if len(preview) > 100 {
 				preview = preview[:100] + "..."
 			}
-			fmt.Printf("  %d. %s\n", i+1, preview)
+			fmt.Printf("  %d. %s ...\n", i+1, preview)
+		}
+		if reader.Statements() > len(previews) {
+			fmt.Printf("  ... 还有 %d 条语句\n", reader.Statements()-len(previews))
 		}
 	}
 
@@ -230,7 +275,7 @@ func runInsertDataDryRun() error {
 }
 
 // executeMultiDatabaseInsert 执行多数据库插入
-func executeMultiDatabaseInsert(ctx context.Context, inserter *database.Inserter, databases []string, sqlFile string, config types.DataInsertConfig) (*types.MultiDatabaseInsertResult, error) {
+func executeMultiDatabaseInsert(ctx context.Context, inserter *database.Inserter, databases []string, sqlFile string, config types.DataInsertConfig, onSuccess func(string, *types.DataInsertResult) error) (*types.MultiDatabaseInsertResult, error) {
 	startTime := time.Now()
 
 	result := &types.MultiDatabaseInsertResult{
@@ -258,6 +303,10 @@ func executeMultiDatabaseInsert(ctx context.Context, inserter *database.Inserter
 		} else {
 			result.SuccessfulDatabases++
 			log.Printf("✅ 数据库 %s 插入成功", dbName)
+			if err := onSuccess(dbName, dbResult); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				log.Printf("⚠️  %v", err)
+			}
 		}
 
 		if dbResult != nil {
@@ -353,3 +402,87 @@ func printMultiInsertResult(result *types.MultiDatabaseInsertResult) {
 
 	log.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 }
+
+// insertSeedTracker 记录 insert-data 导入的 SQL 文件，与 init-data --data-file 共用导入记录
+type insertSeedTracker struct {
+	manager  *database.Manager
+	seedSet  string
+	file     string
+	checksum string
+	strategy builder.DataInsertStrategy
+}
+
+func newInsertSeedTracker(manager *database.Manager, sqlFile string) (*insertSeedTracker, error) {
+	dir := filepath.Dir(sqlFile)
+	file := filepath.Base(sqlFile)
+	checksum, err := seed.Checksum(os.DirFS(dir), file)
+	if err != nil {
+		return nil, fmt.Errorf("计算文件校验和失败: %v", err)
+	}
+
+	strategy := builder.StrategyInsertOnly
+	switch insertOnConflict {
+	case "ignore":
+		strategy = builder.StrategyIgnore
+	case "replace":
+		strategy = builder.StrategyReplace
+	}
+
+	return &insertSeedTracker{
+		manager:  manager,
+		seedSet:  seedSetName(dir),
+		file:     file,
+		checksum: checksum,
+		strategy: strategy,
+	}, nil
+}
+
+// pending 返回需要导入的数据库
+func (t *insertSeedTracker) pending(databases []string) ([]string, error) {
+	var pending []string
+	for _, dbName := range databases {
+		history, err := t.history(dbName)
+		if err != nil {
+			return nil, err
+		}
+		last, err := history.Last(t.seedSet, t.file)
+		if err != nil {
+			return nil, fmt.Errorf("读取数据库 %s 的种子记录失败: %v", dbName, err)
+		}
+
+		if apply, reason := seed.Decide(last, t.checksum, t.strategy, insertForce); !apply {
+			log.Printf("⏭️  数据库 %s 跳过: %s", dbName, reason)
+			continue
+		}
+		pending = append(pending, dbName)
+	}
+	return pending, nil
+}
+
+// record 写入导入记录
+func (t *insertSeedTracker) record(dbName string, result *types.DataInsertResult) error {
+	history, err := t.history(dbName)
+	if err != nil {
+		return err
+	}
+	return history.Record(seed.HistoryRecord{
+		SeedSet:  t.seedSet,
+		FilePath: t.file,
+		Checksum: t.checksum,
+		Strategy: t.strategy,
+		RowsRead: result.TotalRowsInserted,
+		Imported: result.TotalRowsInserted,
+	})
+}
+
+func (t *insertSeedTracker) history(dbName string) (*seed.History, error) {
+	db, err := t.manager.GetDatabase(dbName)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库 %s 失败: %v", dbName, err)
+	}
+	history := seed.NewHistory(db, config.Migrator.SeedTable)
+	if err := history.Ensure(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -62,7 +60,6 @@ func init() {
 	rootCmd.AddCommand(upCmd)
 	rootCmd.AddCommand(downCmd)
 	rootCmd.AddCommand(statusCmd)
-	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(versionCmd)
 	// rootCmd.AddCommand(discoverCmd) // 新增的数据库发现命令 - 稍后实现
 
@@ -73,9 +70,6 @@ func init() {
 
 	// 为down命令添加特定参数
 	downCmd.Flags().IntP("steps", "s", 1, "回滚步数")
-
-	// 为create命令添加数据库参数
-	createCmd.Flags().StringVarP(&targetDatabase, "database", "d", "", "为指定数据库创建迁移文件")
 }
 
 // initConfig 初始化配置
@@ -99,6 +93,7 @@ func initConfig() {
 	viper.SetDefault("database.charset", "utf8mb4")
 	viper.SetDefault("migrator.migrations_table", "schema_migrations")
 	viper.SetDefault("migrator.lock_table", "schema_migrations_lock")
+	viper.SetDefault("migrator.seed_table", "seed_history")
 	viper.SetDefault("migrator.auto_backup", false)
 	viper.SetDefault("migrator.dry_run", false)
 	viper.SetDefault("migrator.migrations_dir", "migrations")
@@ -321,6 +316,10 @@ var statusCmd = &cobra.Command{
 			log.Fatalf("获取迁移状态失败: %v", err)
 		}
 
+		// 种子记录单独连接读取
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
 		// 显示状态
 		for _, dbStatus := range multiStatuses {
 			fmt.Printf("\n📊 数据库: %s\n", dbStatus.Database)
@@ -328,7 +327,6 @@ var statusCmd = &cobra.Command{
 
 			if len(dbStatus.Statuses) == 0 {
 				fmt.Println("  📭 暂无迁移记录")
-				continue
 			}
 
 			for _, status := range dbStatus.Statuses {
@@ -347,25 +345,14 @@ var statusCmd = &cobra.Command{
 				fmt.Printf("  %s %s - %s (%s) %s\n",
 					statusIcon, status.Version, status.Description, statusText, appliedTime)
 			}
+
+			printSeedHistory(dbManager, dbStatus.Database)
 		}
 
 		fmt.Println("\n🎯 状态查看完成")
 	},
 }
 
-// createCmd 创建迁移命令
-var createCmd = &cobra.Command{
-	Use:   "create [name]",
-	Short: "创建新的迁移文件",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
-		if err := createMigrationFile(name); err != nil {
-			log.Fatalf("创建迁移文件失败: %v", err)
-		}
-	},
-}
-
 // versionCmd 版本命令
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -407,7 +394,7 @@ migrator:
 }
 
 func createMigrationsDir() error {
-	dir := "migrations"
+	dir := migrationsBaseDir()
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		return os.MkdirAll(dir, 0755)
 	}
@@ -421,118 +408,21 @@ func loadMigrations(m *migrator.Migrator) error {
 	return nil
 }
 
-func createMigrationFile(name string) error {
-	// 生成时间戳
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
-	filename := fmt.Sprintf("migrations/%s_%s.go", timestamp, name)
-
-	// 确保目录存在
-	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
-		return err
-	}
-
-	// 模板内容
-	template := `package migrations
-
-import (
-	"context"
-	"github.com/xiezhihuan/db-migrator/internal/builder"
-	"github.com/xiezhihuan/db-migrator/internal/types"
-)
-
-// %sMigration %s迁移
-type %sMigration struct{}
-
-// Version 返回迁移版本
-func (m *%sMigration) Version() string {
-	return "%s"
-}
-
-// Description 返回迁移描述
-func (m *%sMigration) Description() string {
-	return "%s"
-}
-
-// Up 执行向上迁移
-func (m *%sMigration) Up(ctx context.Context, db types.DB) error {
-	builder := builder.NewSQLBuilder(nil, db) // 注意：这里需要传入 checker
-	
-	// 示例：创建表
-	// err := builder.CreateTableIfNotExists(ctx, "users", ` + "`" + `
-	//     CREATE TABLE users (
-	//         id INT PRIMARY KEY AUTO_INCREMENT,
-	//         username VARCHAR(50) NOT NULL UNIQUE,
-	//         email VARCHAR(100) NOT NULL UNIQUE,
-	//         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	//     ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
-	// ` + "`" + `)
-	// if err != nil {
-	//     return err
-	// }
-
-	// 示例：添加列
-	// err = builder.AddColumnIfNotExists(ctx, "users", "phone", "VARCHAR(20)")
-	// if err != nil {
-	//     return err
-	// }
-
-	// 示例：创建索引
-	// err = builder.CreateIndexIfNotExists(ctx, "users", "idx_username", 
-	//     "CREATE INDEX idx_username ON users(username)")
-	// if err != nil {
-	//     return err
-	// }
-
-	return nil
-}
-
-// Down 执行向下迁移（回滚）
-func (m *%sMigration) Down(ctx context.Context, db types.DB) error {
-	builder := builder.NewSQLBuilder(nil, db) // 注意：这里需要传入 checker
-
-	// 示例：删除索引
-	// err := builder.DropIndexIfExists(ctx, "users", "idx_username")
-	// if err != nil {
-	//     return err
-	// }
-
-	// 示例：删除列
-	// err = builder.DropColumnIfExists(ctx, "users", "phone")
-	// if err != nil {
-	//     return err
-	// }
-
-	// 示例：删除表
-	// _, err = db.Exec("DROP TABLE IF EXISTS users")
-	// if err != nil {
-	//     return err
-	// }
-
-	return nil
-}
-`
-
-	content := fmt.Sprintf(template,
-		name, name, name, name, timestamp, name, name, name, name)
-
-	err := os.WriteFile(filename, []byte(content), 0644)
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("✅ 创建迁移文件: %s\n", filename)
-	fmt.Println("🚀 请编辑文件并实现 Up() 和 Down() 方法")
-	return nil
-}
-
 // 多数据库支持的辅助函数
 
 // createMultiMigrator 创建多数据库迁移器
 func createMultiMigrator() (*migrator.MultiMigrator, error) {
 	multiMigrator := migrator.NewMultiMigrator(config)
 
-	// 这里可以注册迁移文件
-	// TODO: 实现从目录加载迁移文件的逻辑
+	// Go 迁移需要在代码中注册，这里只加载迁移目录中的 SQL 迁移
+	count, err := multiMigrator.LoadMigrationsFromDirectory(migrationsBaseDir())
+	if err != nil {
+		multiMigrator.Close()
+		return nil, fmt.Errorf("加载迁移文件失败: %v", err)
+	}
+	if verbose && count > 0 {
+		fmt.Printf("📂 从 %s 加载了 %d 个 SQL 迁移\n", migrationsBaseDir(), count)
+	}
 
 	return multiMigrator, nil
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xiezhihuan/db-migrator/internal/database"
+	"github.com/xiezhihuan/db-migrator/internal/datacopy"
+)
+
+var (
+	syncSourceDB string
+	syncTargetDB string
+)
+
+// syncCmd 数据同步命令组
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "数据同步",
+	Long:  `查看 copy-data 持续复制（--continuous）和增量同步（--incremental）的状态。`,
+}
+
+// syncStatusCmd 同步状态命令
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看同步状态和延迟",
+	Long: `查看目标数据库中记录的增量同步高水位和距上次同步的时间。
+指定 --source 时只显示该源库的记录。
+
+示例：
+  db-migrator sync status --target=tenant_new
+  db-migrator sync status --source=tenant_old --target=tenant_new`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if syncTargetDB == "" {
+			log.Fatalf("参数错误: 必须指定目标数据库 --target")
+		}
+
+		dbManager := database.NewManager(config)
+		defer dbManager.CloseAll()
+
+		targetConn, err := dbManager.GetDatabase(syncTargetDB)
+		if err != nil {
+			log.Fatalf("连接目标数据库失败: %v", err)
+		}
+
+		fmt.Printf("\n📊 目标数据库: %s\n", syncTargetDB)
+		fmt.Println("---------------------------------------------------------------")
+
+		fmt.Println("📈 增量同步高水位:")
+		states, err := datacopy.ListSyncStates(targetConn, datacopy.DefaultSyncStateTable)
+		if err != nil || len(states) == 0 {
+			fmt.Println("  📭 暂无增量同步记录")
+		}
+		for _, state := range states {
+			if syncSourceDB != "" && state.SourceName != syncSourceDB {
+				continue
+			}
+			fmt.Printf("  • %s.%s [%s] 高水位 %s，复制 %d 行，删除 %d 行，同步于 %s（%s前）\n",
+				state.SourceName, state.TableName, state.SyncColumn, state.HighWater,
+				state.RowsCopied, state.RowsDeleted, state.SyncedAt.Format("2006-01-02 15:04:05"),
+				time.Since(state.SyncedAt).Truncate(time.Second))
+		}
+	},
+}
+
+func init() {
+	syncStatusCmd.Flags().StringVar(&syncSourceDB, "source", "", "源数据库名称")
+	syncStatusCmd.Flags().StringVar(&syncTargetDB, "target", "", "目标数据库名称")
+
+	syncCmd.AddCommand(syncStatusCmd)
+	rootCmd.AddCommand(syncCmd)
+}
@@ -1,15 +1,16 @@
 package pattern_example
 
 import (
-	"context"
 	"github.com/xiezhihuan/db-migrator/internal/builder"
-	"github.com/xiezhihuan/db-migrator/internal/checker"
-	"github.com/xiezhihuan/db-migrator/internal/types"
+	"github.com/xiezhihuan/db-migrator/internal/migrator"
 )
 
 // ShopDatabasesMigration 演示shop*模式匹配的迁移
 // 这个迁移会应用到所有以"shop"开头的数据库
-type ShopDatabasesMigration struct{}
+// 实现 UpWithContext/DownWithContext，检查器由迁移器按当前数据库创建
+type ShopDatabasesMigration struct {
+	migrator.BaseContextMigration
+}
 
 func (m *ShopDatabasesMigration) Version() string {
 	return "001"
@@ -22,10 +23,9 @@ func (m *ShopDatabasesMigration) Description() string {
 // 注意：这个迁移不实现MultiDatabaseMigration接口
 // 而是通过命令行参数 --patterns=shop* 来指定要应用的数据库
 
-func (m *ShopDatabasesMigration) Up(ctx context.Context, db types.DB) error {
-	// 由于我们不知道具体的数据库名，所以checker使用空字符串
-	checker := checker.NewMySQLChecker(db, "")
-	advancedBuilder := builder.NewAdvancedBuilder(checker, db)
+func (m *ShopDatabasesMigration) UpWithContext(mc *migrator.MigrationContext) error {
+	// mc.Database 是当前执行的 shop 数据库，mc.Advanced 的存在性检查针对该数据库
+	advancedBuilder := mc.Advanced
 
 	// 创建商品表（适用于所有商店数据库）
 	err := advancedBuilder.Table("products").
@@ -54,7 +54,7 @@ func (m *ShopDatabasesMigration) Up(ctx context.Context, db types.DB) error {
 		Index("sort_order").End().
 		Engine("InnoDB").
 		Comment("商品表（shop*数据库通用）").
-		Create(ctx)
+		Create(mc)
 	if err != nil {
 		return err
 	}
@@ -78,7 +78,7 @@ func (m *ShopDatabasesMigration) Up(ctx context.Context, db types.DB) error {
 		Index("is_active").End().
 		Engine("InnoDB").
 		Comment("商品分类表").
-		Create(ctx)
+		Create(mc)
 	if err != nil {
 		return err
 	}
@@ -104,10 +104,10 @@ func (m *ShopDatabasesMigration) Up(ctx context.Context, db types.DB) error {
 		Index("reference_type", "reference_id").End().
 		Engine("InnoDB").
 		Comment("库存操作日志表").
-		Create(ctx)
+		Create(mc)
 }
 
-func (m *ShopDatabasesMigration) Down(ctx context.Context, db types.DB) error {
+func (m *ShopDatabasesMigration) DownWithContext(mc *migrator.MigrationContext) error {
 	// 按依赖关系逆序删除表
 	tables := []string{
 		"inventory_logs",
@@ -116,7 +116,7 @@ func (m *ShopDatabasesMigration) Down(ctx context.Context, db types.DB) error {
 	}
 
 	for _, table := range tables {
-		_, err := db.Exec("DROP TABLE IF EXISTS " + table)
+		_, err := mc.DB.Exec("DROP TABLE IF EXISTS " + table)
 		if err != nil {
 			return err
 		}
@@ -39,8 +39,12 @@ func (ab *AdvancedBuilder) ModifyTable(name string) *TableModifier {
 
 // CreateView 创建视图
 func (ab *AdvancedBuilder) CreateView(ctx context.Context, viewName, query string) error {
+	if err := validateNames(viewName); err != nil {
+		return err
+	}
+
 	// 检查视图是否存在
-	exists, err := ab.checkViewExists(ctx, viewName)
+	exists, err := ab.checker.ViewExists(ctx, viewName)
 	if err != nil {
 		return fmt.Errorf("检查视图 %s 是否存在失败: %v", viewName, err)
 	}
@@ -50,7 +54,7 @@ func (ab *AdvancedBuilder) CreateView(ctx context.Context, viewName, query strin
 		return nil
 	}
 
-	sql := fmt.Sprintf("CREATE VIEW %s AS %s", viewName, query)
+	sql := fmt.Sprintf("CREATE VIEW %s AS %s", quoteTable(viewName), query)
 	_, err = ab.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("创建视图 %s 失败: %v", viewName, err)
@@ -62,7 +66,7 @@ func (ab *AdvancedBuilder) CreateView(ctx context.Context, viewName, query strin
 
 // DropView 删除视图
 func (ab *AdvancedBuilder) DropView(ctx context.Context, viewName string) error {
-	exists, err := ab.checkViewExists(ctx, viewName)
+	exists, err := ab.checker.ViewExists(ctx, viewName)
 	if err != nil {
 		return fmt.Errorf("检查视图 %s 是否存在失败: %v", viewName, err)
 	}
@@ -72,7 +76,7 @@ func (ab *AdvancedBuilder) DropView(ctx context.Context, viewName string) error
 		return nil
 	}
 
-	sql := fmt.Sprintf("DROP VIEW %s", viewName)
+	sql := fmt.Sprintf("DROP VIEW %s", quoteTable(viewName))
 	_, err = ab.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("删除视图 %s 失败: %v", viewName, err)
@@ -84,6 +88,10 @@ func (ab *AdvancedBuilder) DropView(ctx context.Context, viewName string) error
 
 // RenameTable 重命名表
 func (ab *AdvancedBuilder) RenameTable(ctx context.Context, oldName, newName string) error {
+	if err := validateNames(newName); err != nil {
+		return err
+	}
+
 	exists, err := ab.checker.TableExists(ctx, oldName)
 	if err != nil {
 		return fmt.Errorf("检查表 %s 是否存在失败: %v", oldName, err)
@@ -103,7 +111,7 @@ func (ab *AdvancedBuilder) RenameTable(ctx context.Context, oldName, newName str
 		return fmt.Errorf("表 %s 已存在，无法重命名", newName)
 	}
 
-	sql := fmt.Sprintf("RENAME TABLE %s TO %s", oldName, newName)
+	sql := fmt.Sprintf("RENAME TABLE %s TO %s", quoteTable(oldName), quoteTable(newName))
 	_, err = ab.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("重命名表失败: %v", err)
@@ -115,6 +123,10 @@ func (ab *AdvancedBuilder) RenameTable(ctx context.Context, oldName, newName str
 
 // CopyTable 复制表结构（可选择是否复制数据）
 func (ab *AdvancedBuilder) CopyTable(ctx context.Context, srcTable, destTable string, copyData bool) error {
+	if err := validateNames(destTable); err != nil {
+		return err
+	}
+
 	exists, err := ab.checker.TableExists(ctx, srcTable)
 	if err != nil {
 		return fmt.Errorf("检查源表 %s 是否存在失败: %v", srcTable, err)
@@ -138,9 +150,9 @@ func (ab *AdvancedBuilder) CopyTable(ctx context.Context, srcTable, destTable st
 	// 复制表结构
 	var sql string
 	if copyData {
-		sql = fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", destTable, srcTable)
+		sql = fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", quoteTable(destTable), quoteTable(srcTable))
 	} else {
-		sql = fmt.Sprintf("CREATE TABLE %s LIKE %s", destTable, srcTable)
+		sql = fmt.Sprintf("CREATE TABLE %s LIKE %s", quoteTable(destTable), quoteTable(srcTable))
 	}
 
 	_, err = ab.db.Exec(sql)
@@ -167,7 +179,7 @@ func (ab *AdvancedBuilder) TruncateTable(ctx context.Context, tableName string)
 		return fmt.Errorf("表 %s 不存在", tableName)
 	}
 
-	sql := fmt.Sprintf("TRUNCATE TABLE %s", tableName)
+	sql := fmt.Sprintf("TRUNCATE TABLE %s", quoteTable(tableName))
 	_, err = ab.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("清空表 %s 失败: %v", tableName, err)
@@ -180,14 +192,14 @@ func (ab *AdvancedBuilder) TruncateTable(ctx context.Context, tableName string)
 // CreateStoredProcedure 创建存储过程
 func (ab *AdvancedBuilder) CreateStoredProcedure(ctx context.Context, name, body string) error {
 	// 检查存储过程是否存在
-	exists, err := ab.checkProcedureExists(ctx, name)
+	exists, err := ab.checker.ProcedureExists(ctx, name)
 	if err != nil {
 		return fmt.Errorf("检查存储过程 %s 是否存在失败: %v", name, err)
 	}
 
 	if exists {
 		// 先删除再创建
-		dropSQL := fmt.Sprintf("DROP PROCEDURE IF EXISTS %s", name)
+		dropSQL := fmt.Sprintf("DROP PROCEDURE IF EXISTS %s", quoteTable(name))
 		_, err = ab.db.Exec(dropSQL)
 		if err != nil {
 			return fmt.Errorf("删除存储过程 %s 失败: %v", name, err)
@@ -207,14 +219,14 @@ func (ab *AdvancedBuilder) CreateStoredProcedure(ctx context.Context, name, body
 // CreateTrigger 创建触发器
 func (ab *AdvancedBuilder) CreateTrigger(ctx context.Context, name, body string) error {
 	// 检查触发器是否存在
-	exists, err := ab.checkTriggerExists(ctx, name)
+	exists, err := ab.checker.TriggerExists(ctx, name)
 	if err != nil {
 		return fmt.Errorf("检查触发器 %s 是否存在失败: %v", name, err)
 	}
 
 	if exists {
 		// 先删除再创建
-		dropSQL := fmt.Sprintf("DROP TRIGGER IF EXISTS %s", name)
+		dropSQL := fmt.Sprintf("DROP TRIGGER IF EXISTS %s", quoteTable(name))
 		_, err = ab.db.Exec(dropSQL)
 		if err != nil {
 			return fmt.Errorf("删除触发器 %s 失败: %v", name, err)
@@ -236,6 +248,9 @@ func (ab *AdvancedBuilder) BulkInsert(ctx context.Context, tableName string, col
 	if len(data) == 0 {
 		return nil
 	}
+	if err := validateNames(tableName, columns...); err != nil {
+		return err
+	}
 
 	exists, err := ab.checker.TableExists(ctx, tableName)
 	if err != nil {
@@ -272,8 +287,8 @@ func (ab *AdvancedBuilder) BulkInsert(ctx context.Context, tableName string, col
 		}
 
 		sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
-			tableName,
-			strings.Join(columns, ", "),
+			quoteTable(tableName),
+			quoteIdents(columns),
 			strings.Join(values, ", "))
 
 		_, err = ab.db.Exec(sql, args...)
@@ -325,6 +340,10 @@ func (tm *TableModifier) DropColumn(ctx context.Context, columnName string) erro
 
 // RenameColumn 重命名列
 func (tm *TableModifier) RenameColumn(ctx context.Context, oldName, newName, columnDef string) error {
+	if err := validateNames(tm.tableName, oldName, newName); err != nil {
+		return err
+	}
+
 	exists, err := tm.advancedBuilder.checker.ColumnExists(ctx, tm.tableName, oldName)
 	if err != nil {
 		return fmt.Errorf("检查列 %s.%s 是否存在失败: %v", tm.tableName, oldName, err)
@@ -336,7 +355,7 @@ func (tm *TableModifier) RenameColumn(ctx context.Context, oldName, newName, col
 	}
 
 	sql := fmt.Sprintf("ALTER TABLE %s CHANGE %s %s %s",
-		tm.tableName, oldName, newName, columnDef)
+		quoteTable(tm.tableName), quoteIdent(oldName), quoteIdent(newName), columnDef)
 	_, err = tm.advancedBuilder.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("重命名列失败: %v", err)
@@ -348,6 +367,10 @@ func (tm *TableModifier) RenameColumn(ctx context.Context, oldName, newName, col
 
 // AddIndex 添加索引
 func (tm *TableModifier) AddIndex(ctx context.Context, indexName string, columns []string, unique bool) error {
+	if err := validateNames(tm.tableName, append([]string{indexName}, columns...)...); err != nil {
+		return err
+	}
+
 	exists, err := tm.advancedBuilder.checker.IndexExists(ctx, tm.tableName, indexName)
 	if err != nil {
 		return fmt.Errorf("检查索引 %s 是否存在失败: %v", indexName, err)
@@ -361,10 +384,10 @@ func (tm *TableModifier) AddIndex(ctx context.Context, indexName string, columns
 	var sql string
 	if unique {
 		sql = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)",
-			indexName, tm.tableName, strings.Join(columns, ", "))
+			quoteIdent(indexName), quoteTable(tm.tableName), quoteIdents(columns))
 	} else {
 		sql = fmt.Sprintf("CREATE INDEX %s ON %s (%s)",
-			indexName, tm.tableName, strings.Join(columns, ", "))
+			quoteIdent(indexName), quoteTable(tm.tableName), quoteIdents(columns))
 	}
 
 	_, err = tm.advancedBuilder.db.Exec(sql)
@@ -409,6 +432,9 @@ func (cm *ColumnModifier) After(columnName string) *ColumnModifier {
 
 // Execute 执行列修改
 func (cm *ColumnModifier) Execute(ctx context.Context) error {
+	if err := validateNames(cm.tableModifier.tableName, cm.column.Name); err != nil {
+		return err
+	}
 	if cm.operation == "ADD" {
 		return cm.executeAddColumn(ctx)
 	} else if cm.operation == "MODIFY" {
@@ -433,10 +459,10 @@ func (cm *ColumnModifier) executeAddColumn(ctx context.Context) error {
 
 	columnDef := cm.buildColumnDefinition()
 	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s",
-		cm.tableModifier.tableName, columnDef)
+		quoteTable(cm.tableModifier.tableName), columnDef)
 
 	if cm.column.After != "" {
-		sql += fmt.Sprintf(" AFTER %s", cm.column.After)
+		sql += fmt.Sprintf(" AFTER %s", quoteIdent(cm.column.After))
 	}
 
 	_, err = cm.tableModifier.advancedBuilder.db.Exec(sql)
@@ -464,7 +490,7 @@ func (cm *ColumnModifier) executeModifyColumn(ctx context.Context) error {
 
 	columnDef := cm.buildColumnDefinition()
 	sql := fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s",
-		cm.tableModifier.tableName, columnDef)
+		quoteTable(cm.tableModifier.tableName), columnDef)
 
 	_, err = cm.tableModifier.advancedBuilder.db.Exec(sql)
 	if err != nil {
@@ -479,7 +505,7 @@ func (cm *ColumnModifier) executeModifyColumn(ctx context.Context) error {
 // buildColumnDefinition 构建列定义
 func (cm *ColumnModifier) buildColumnDefinition() string {
 	var parts []string
-	parts = append(parts, cm.column.Name)
+	parts = append(parts, quoteIdent(cm.column.Name))
 
 	// 构建类型定义
 	typeStr := string(cm.column.Type)
@@ -500,7 +526,7 @@ func (cm *ColumnModifier) buildColumnDefinition() string {
 			if v == "CURRENT_TIMESTAMP" || strings.Contains(v, "CURRENT_TIMESTAMP") {
 				parts = append(parts, fmt.Sprintf("DEFAULT %s", v))
 			} else {
-				parts = append(parts, fmt.Sprintf("DEFAULT '%s'", v))
+				parts = append(parts, "DEFAULT "+quoteString(v))
 			}
 		case int, int64, float64:
 			parts = append(parts, fmt.Sprintf("DEFAULT %v", v))
@@ -508,52 +534,8 @@ func (cm *ColumnModifier) buildColumnDefinition() string {
 	}
 
 	if cm.column.Comment != "" {
-		parts = append(parts, fmt.Sprintf("COMMENT '%s'", cm.column.Comment))
+		parts = append(parts, "COMMENT "+quoteString(cm.column.Comment))
 	}
 
 	return strings.Join(parts, " ")
 }
-
-// 辅助方法
-
-func (ab *AdvancedBuilder) checkViewExists(ctx context.Context, viewName string) (bool, error) {
-	query := `
-		SELECT COUNT(*) 
-		FROM information_schema.VIEWS 
-		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
-	`
-	var count int
-	err := ab.db.QueryRow(query, viewName).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
-}
-
-func (ab *AdvancedBuilder) checkProcedureExists(ctx context.Context, procName string) (bool, error) {
-	query := `
-		SELECT COUNT(*) 
-		FROM information_schema.ROUTINES 
-		WHERE ROUTINE_SCHEMA = DATABASE() AND ROUTINE_NAME = ? AND ROUTINE_TYPE = 'PROCEDURE'
-	`
-	var count int
-	err := ab.db.QueryRow(query, procName).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
-}
-
-func (ab *AdvancedBuilder) checkTriggerExists(ctx context.Context, triggerName string) (bool, error) {
-	query := `
-		SELECT COUNT(*) 
-		FROM information_schema.TRIGGERS 
-		WHERE TRIGGER_SCHEMA = DATABASE() AND TRIGGER_NAME = ?
-	`
-	var count int
-	err := ab.db.QueryRow(query, triggerName).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-	return count > 0, nil
-}
@@ -0,0 +1,261 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Alteration 收集对同一张表的多项修改，由 TableModifier.Alter 合并为一条 ALTER TABLE 语句，
+// 大表只需要重建一次
+type Alteration struct {
+	tableModifier *TableModifier
+	operations    []alterOperation
+	algorithm     string
+	lock          string
+}
+
+// alterAlgorithms ALTER TABLE 支持的 ALGORITHM 值
+var alterAlgorithms = map[string]bool{"DEFAULT": true, "INSTANT": true, "INPLACE": true, "COPY": true}
+
+// alterLocks ALTER TABLE 支持的 LOCK 值
+var alterLocks = map[string]bool{"DEFAULT": true, "NONE": true, "SHARED": true, "EXCLUSIVE": true}
+
+// alterOperation 一项修改，执行前检查存在性
+type alterOperation struct {
+	action  string // ADD_COLUMN, MODIFY_COLUMN, DROP_COLUMN, RENAME_COLUMN, ADD_INDEX, DROP_INDEX
+	name    string
+	newName string
+	column  *ColumnModifier
+	def     string // RENAME_COLUMN 的列定义
+	columns []string
+	unique  bool
+}
+
+// Alter 收集 fn 中的修改，检查存在性后用一条 ALTER TABLE 执行：
+//
+//	tm.Alter(ctx, func(a *Alteration) {
+//		a.AddColumn("phone", TypeVarchar, 20).Comment("手机号")
+//		a.AddColumn("age", TypeInt, 0).After("name")
+//		a.AddIndex("idx_users_phone", []string{"phone"}, false)
+//		a.Algorithm("INPLACE").Lock("NONE")
+//	})
+//
+// 与单独调用相同，已存在的列和索引跳过添加，不存在的跳过删除，修改不存在的列返回错误。
+func (tm *TableModifier) Alter(ctx context.Context, fn func(a *Alteration)) error {
+	a := &Alteration{tableModifier: tm}
+	fn(a)
+	return a.execute(ctx)
+}
+
+// AddColumn 添加列，返回的 ColumnModifier 用于设置列属性，不需要调用 Execute
+func (a *Alteration) AddColumn(name string, columnType ColumnType, size int) *ColumnModifier {
+	column := a.tableModifier.AddColumn(name, columnType, size)
+	a.operations = append(a.operations, alterOperation{action: "ADD_COLUMN", name: name, column: column})
+	return column
+}
+
+// ModifyColumn 修改列，返回的 ColumnModifier 用于设置列属性，不需要调用 Execute
+func (a *Alteration) ModifyColumn(name string, columnType ColumnType, size int) *ColumnModifier {
+	column := a.tableModifier.ModifyColumn(name, columnType, size)
+	a.operations = append(a.operations, alterOperation{action: "MODIFY_COLUMN", name: name, column: column})
+	return column
+}
+
+// DropColumn 删除列
+func (a *Alteration) DropColumn(name string) *Alteration {
+	a.operations = append(a.operations, alterOperation{action: "DROP_COLUMN", name: name})
+	return a
+}
+
+// RenameColumn 重命名列，columnDef 为新列的类型和属性
+func (a *Alteration) RenameColumn(oldName, newName, columnDef string) *Alteration {
+	a.operations = append(a.operations, alterOperation{action: "RENAME_COLUMN", name: oldName, newName: newName, def: columnDef})
+	return a
+}
+
+// AddIndex 添加索引
+func (a *Alteration) AddIndex(indexName string, columns []string, unique bool) *Alteration {
+	a.operations = append(a.operations, alterOperation{action: "ADD_INDEX", name: indexName, columns: columns, unique: unique})
+	return a
+}
+
+// DropIndex 删除索引
+func (a *Alteration) DropIndex(indexName string) *Alteration {
+	a.operations = append(a.operations, alterOperation{action: "DROP_INDEX", name: indexName})
+	return a
+}
+
+// Algorithm 设置 ALGORITHM 子句：DEFAULT、INSTANT、INPLACE 或 COPY
+func (a *Alteration) Algorithm(algorithm string) *Alteration {
+	a.algorithm = strings.ToUpper(algorithm)
+	return a
+}
+
+// Lock 设置 LOCK 子句：DEFAULT、NONE、SHARED 或 EXCLUSIVE
+func (a *Alteration) Lock(lock string) *Alteration {
+	a.lock = strings.ToUpper(lock)
+	return a
+}
+
+// execute 检查存在性后执行合并的 ALTER TABLE
+func (a *Alteration) execute(ctx context.Context) error {
+	tableName := a.tableModifier.tableName
+	if a.algorithm != "" && !alterAlgorithms[a.algorithm] {
+		return fmt.Errorf("无效的 ALGORITHM: %s", a.algorithm)
+	}
+	if a.lock != "" && !alterLocks[a.lock] {
+		return fmt.Errorf("无效的 LOCK: %s", a.lock)
+	}
+	var names []string
+	for _, op := range a.operations {
+		names = append(names, op.name)
+		if op.newName != "" {
+			names = append(names, op.newName)
+		}
+		names = append(names, op.columns...)
+	}
+	if err := validateNames(tableName, names...); err != nil {
+		return err
+	}
+
+	clauses, descriptions, err := a.clauses(ctx)
+	if err != nil {
+		return err
+	}
+	if len(clauses) == 0 {
+		fmt.Printf("表 %s 没有需要执行的修改，跳过\n", tableName)
+		return nil
+	}
+
+	if a.algorithm != "" {
+		clauses = append(clauses, "ALGORITHM="+a.algorithm)
+	}
+	if a.lock != "" {
+		clauses = append(clauses, "LOCK="+a.lock)
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s %s", quoteTable(tableName), strings.Join(clauses, ", "))
+	if _, err := a.tableModifier.advancedBuilder.db.Exec(sql); err != nil {
+		return fmt.Errorf("修改表 %s 失败: %v", tableName, err)
+	}
+
+	fmt.Printf("成功修改表 %s: %s\n", tableName, strings.Join(descriptions, "，"))
+	return nil
+}
+
+// clauses 检查每项修改的存在性，返回需要执行的子句和说明；
+// 同一批中先添加或删除的列和索引在后续检查中按修改后的状态处理
+func (a *Alteration) clauses(ctx context.Context) ([]string, []string, error) {
+	tableName := a.tableModifier.tableName
+	checker := a.tableModifier.advancedBuilder.checker
+
+	columns := make(map[string]bool) // 本批中已添加（true）或已删除（false）的列
+	indexes := make(map[string]bool)
+	columnExists := func(name string) (bool, error) {
+		if exists, ok := columns[strings.ToLower(name)]; ok {
+			return exists, nil
+		}
+		exists, err := checker.ColumnExists(ctx, tableName, name)
+		if err != nil {
+			return false, fmt.Errorf("检查列 %s.%s 是否存在失败: %v", tableName, name, err)
+		}
+		return exists, nil
+	}
+	indexExists := func(name string) (bool, error) {
+		if exists, ok := indexes[strings.ToLower(name)]; ok {
+			return exists, nil
+		}
+		exists, err := checker.IndexExists(ctx, tableName, name)
+		if err != nil {
+			return false, fmt.Errorf("检查索引 %s 是否存在失败: %v", name, err)
+		}
+		return exists, nil
+	}
+
+	var clauses, descriptions []string
+	for _, op := range a.operations {
+		switch op.action {
+		case "ADD_COLUMN", "MODIFY_COLUMN":
+			exists, err := columnExists(op.name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if op.action == "ADD_COLUMN" && exists {
+				fmt.Printf("列 %s.%s 已存在，跳过添加\n", tableName, op.name)
+				continue
+			}
+			if op.action == "MODIFY_COLUMN" && !exists {
+				return nil, nil, fmt.Errorf("列 %s.%s 不存在，无法修改", tableName, op.name)
+			}
+
+			clause := "ADD COLUMN " + op.column.buildColumnDefinition()
+			description := "添加列 " + op.name
+			if op.action == "MODIFY_COLUMN" {
+				clause = "MODIFY COLUMN " + op.column.buildColumnDefinition()
+				description = "修改列 " + op.name
+			}
+			if op.column.column.After != "" {
+				clause += " AFTER " + quoteIdent(op.column.column.After)
+			}
+			clauses = append(clauses, clause)
+			descriptions = append(descriptions, description)
+			columns[strings.ToLower(op.name)] = true
+
+		case "DROP_COLUMN":
+			exists, err := columnExists(op.name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				fmt.Printf("列 %s.%s 不存在，跳过删除\n", tableName, op.name)
+				continue
+			}
+			clauses = append(clauses, "DROP COLUMN "+quoteIdent(op.name))
+			descriptions = append(descriptions, "删除列 "+op.name)
+			columns[strings.ToLower(op.name)] = false
+
+		case "RENAME_COLUMN":
+			exists, err := columnExists(op.name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				fmt.Printf("列 %s.%s 不存在，跳过重命名\n", tableName, op.name)
+				continue
+			}
+			clauses = append(clauses, fmt.Sprintf("CHANGE %s %s %s", quoteIdent(op.name), quoteIdent(op.newName), op.def))
+			descriptions = append(descriptions, fmt.Sprintf("重命名列 %s 为 %s", op.name, op.newName))
+			columns[strings.ToLower(op.name)] = false
+			columns[strings.ToLower(op.newName)] = true
+
+		case "ADD_INDEX":
+			exists, err := indexExists(op.name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if exists {
+				fmt.Printf("索引 %s 已存在，跳过创建\n", op.name)
+				continue
+			}
+			clauses = append(clauses, addIndexClause(IndexDef{Name: op.name, Columns: op.columns, Unique: op.unique}))
+			descriptions = append(descriptions, "添加索引 "+op.name)
+			indexes[strings.ToLower(op.name)] = true
+
+		case "DROP_INDEX":
+			exists, err := indexExists(op.name)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				fmt.Printf("索引 %s 不存在，跳过删除\n", op.name)
+				continue
+			}
+			clauses = append(clauses, dropIndexClause(op.name))
+			descriptions = append(descriptions, "删除索引 "+op.name)
+			indexes[strings.ToLower(op.name)] = false
+		}
+	}
+
+	return clauses, descriptions, nil
+}
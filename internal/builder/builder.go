@@ -45,6 +45,10 @@ func (b *SQLBuilder) CreateTableIfNotExists(ctx context.Context, tableName, tabl
 
 // AddColumnIfNotExists 智能添加列
 func (b *SQLBuilder) AddColumnIfNotExists(ctx context.Context, tableName, columnName, columnDef string) error {
+	if err := validateNames(tableName, columnName); err != nil {
+		return err
+	}
+
 	exists, err := b.checker.ColumnExists(ctx, tableName, columnName)
 	if err != nil {
 		return fmt.Errorf("检查列 %s.%s 是否存在失败: %v", tableName, columnName, err)
@@ -55,7 +59,7 @@ func (b *SQLBuilder) AddColumnIfNotExists(ctx context.Context, tableName, column
 		return nil
 	}
 
-	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", tableName, columnName, columnDef)
+	sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quoteTable(tableName), quoteIdent(columnName), columnDef)
 	_, err = b.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("添加列 %s.%s 失败: %v", tableName, columnName, err)
@@ -77,7 +81,7 @@ func (b *SQLBuilder) DropColumnIfExists(ctx context.Context, tableName, columnNa
 		return nil
 	}
 
-	sql := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", tableName, columnName)
+	sql := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteTable(tableName), quoteIdent(columnName))
 	_, err = b.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("删除列 %s.%s 失败: %v", tableName, columnName, err)
@@ -120,7 +124,7 @@ func (b *SQLBuilder) DropIndexIfExists(ctx context.Context, tableName, indexName
 		return nil
 	}
 
-	sql := fmt.Sprintf("DROP INDEX %s ON %s", indexName, tableName)
+	sql := fmt.Sprintf("DROP INDEX %s ON %s", quoteIdent(indexName), quoteTable(tableName))
 	_, err = b.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("删除索引 %s 失败: %v", indexName, err)
@@ -139,7 +143,7 @@ func (b *SQLBuilder) CreateFunctionIfNotExists(ctx context.Context, functionName
 
 	if exists {
 		// 函数存在，先删除再创建（用于更新函数）
-		dropSQL := fmt.Sprintf("DROP FUNCTION IF EXISTS %s", functionName)
+		dropSQL := fmt.Sprintf("DROP FUNCTION IF EXISTS %s", quoteTable(functionName))
 		_, err = b.db.Exec(dropSQL)
 		if err != nil {
 			return fmt.Errorf("删除函数 %s 失败: %v", functionName, err)
@@ -168,7 +172,7 @@ func (b *SQLBuilder) DropFunctionIfExists(ctx context.Context, functionName stri
 		return nil
 	}
 
-	sql := fmt.Sprintf("DROP FUNCTION %s", functionName)
+	sql := fmt.Sprintf("DROP FUNCTION %s", quoteTable(functionName))
 	_, err = b.db.Exec(sql)
 	if err != nil {
 		return fmt.Errorf("删除函数 %s 失败: %v", functionName, err)
@@ -181,7 +185,7 @@ func (b *SQLBuilder) DropFunctionIfExists(ctx context.Context, functionName stri
 // InsertIfNotExists 智能插入数据
 func (b *SQLBuilder) InsertIfNotExists(ctx context.Context, tableName string, whereCondition string, insertSQL string) error {
 	// 检查数据是否存在
-	checkSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, whereCondition)
+	checkSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", quoteTable(tableName), whereCondition)
 	var count int
 	err := b.db.QueryRow(checkSQL).Scan(&count)
 	if err != nil {
@@ -205,7 +209,7 @@ func (b *SQLBuilder) InsertIfNotExists(ctx context.Context, tableName string, wh
 // UpdateIfExists 智能更新数据
 func (b *SQLBuilder) UpdateIfExists(ctx context.Context, tableName string, whereCondition string, updateSQL string) error {
 	// 检查数据是否存在
-	checkSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, whereCondition)
+	checkSQL := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", quoteTable(tableName), whereCondition)
 	var count int
 	err := b.db.QueryRow(checkSQL).Scan(&count)
 	if err != nil {
@@ -248,7 +252,7 @@ func (b *SQLBuilder) ExecuteRawSQL(ctx context.Context, sql string, description
 // BuildCreateTableSQL 构建创建表的SQL
 func BuildCreateTableSQL(tableName string, columns []ColumnDef, options ...TableOption) string {
 	var parts []string
-	parts = append(parts, fmt.Sprintf("CREATE TABLE %s (", tableName))
+	parts = append(parts, fmt.Sprintf("CREATE TABLE %s (", quoteTable(tableName)))
 
 	// 添加列定义
 	columnStrs := make([]string, len(columns))
@@ -280,7 +284,7 @@ type ColumnDef struct {
 
 func (c ColumnDef) String() string {
 	var parts []string
-	parts = append(parts, c.Name, c.Type)
+	parts = append(parts, quoteIdent(c.Name), c.Type)
 
 	if c.NotNull {
 		parts = append(parts, "NOT NULL")
@@ -299,7 +303,7 @@ func (c ColumnDef) String() string {
 	}
 
 	if c.Comment != "" {
-		parts = append(parts, fmt.Sprintf("COMMENT '%s'", c.Comment))
+		parts = append(parts, "COMMENT "+quoteString(c.Comment))
 	}
 
 	return strings.Join(parts, " ")
@@ -328,5 +332,5 @@ func (c CharsetOption) String() string {
 type CommentOption string
 
 func (c CommentOption) String() string {
-	return fmt.Sprintf("COMMENT='%s'", string(c))
+	return "COMMENT=" + quoteString(string(c))
 }
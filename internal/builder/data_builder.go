@@ -168,7 +168,7 @@ func (tdb *TableDataBuilder) replace(ctx context.Context, data []map[string]inte
 
 func (tdb *TableDataBuilder) truncateAndInsert(ctx context.Context, data []map[string]interface{}) error {
 	// 先清空表
-	_, err := tdb.dataBuilder.db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", tdb.tableName))
+	_, err := tdb.dataBuilder.db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", quoteTable(tdb.tableName)))
 	if err != nil {
 		return fmt.Errorf("清空表 %s 失败: %v", tdb.tableName, err)
 	}
@@ -187,11 +187,14 @@ func (tdb *TableDataBuilder) insertOrUpdate(ctx context.Context, data []map[stri
 	for col := range data[0] {
 		columns = append(columns, col)
 	}
+	if err := validateNames(tdb.tableName, columns...); err != nil {
+		return err
+	}
 
 	// 构建更新部分
 	updateParts := make([]string, len(columns))
 	for i, col := range columns {
-		updateParts[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+		updateParts[i] = fmt.Sprintf("%s = VALUES(%s)", quoteIdent(col), quoteIdent(col))
 	}
 
 	// 批量处理
@@ -203,8 +206,8 @@ func (tdb *TableDataBuilder) insertOrUpdate(ctx context.Context, data []map[stri
 
 		batch := data[i:end]
 		sql := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
-			tdb.tableName,
-			strings.Join(columns, ", "),
+			quoteTable(tdb.tableName),
+			quoteIdents(columns),
 			tdb.buildValuePlaceholders(len(columns), len(batch)),
 			strings.Join(updateParts, ", "))
 
@@ -228,6 +231,9 @@ func (tdb *TableDataBuilder) batchInsert(ctx context.Context, data []map[string]
 	for col := range data[0] {
 		columns = append(columns, col)
 	}
+	if err := validateNames(tdb.tableName, columns...); err != nil {
+		return err
+	}
 
 	// 批量处理
 	for i := 0; i < len(data); i += tdb.batchSize {
@@ -239,8 +245,8 @@ func (tdb *TableDataBuilder) batchInsert(ctx context.Context, data []map[string]
 		batch := data[i:end]
 		sql := fmt.Sprintf("%s INTO %s (%s) VALUES %s",
 			insertType,
-			tdb.tableName,
-			strings.Join(columns, ", "),
+			quoteTable(tdb.tableName),
+			quoteIdents(columns),
 			tdb.buildValuePlaceholders(len(columns), len(batch)))
 
 		args := tdb.flattenBatchData(batch, columns)
@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
+)
+
+// sqlDialect 构建器生成 SQL 使用的方言
+var sqlDialect = dialect.MySQL
+
+// quoteIdent 引用列名、索引名、约束名等标识符
+func quoteIdent(name string) string {
+	return sqlDialect.QuoteIdent(name)
+}
+
+// quoteTable 引用表名，支持 库名.表名
+func quoteTable(name string) string {
+	return sqlDialect.QuoteQualified(name)
+}
+
+// quoteIdents 引用多个标识符，以逗号分隔
+func quoteIdents(names []string) string {
+	return sqlDialect.QuoteIdents(names)
+}
+
+// quoteString 转义字符串字面量（注释、默认值、枚举值）
+func quoteString(value string) string {
+	return sqlDialect.QuoteString(value)
+}
+
+// validateNames 校验表名和其余标识符
+func validateNames(tableName string, names ...string) error {
+	if err := sqlDialect.ValidateQualified(tableName); err != nil {
+		return fmt.Errorf("无效的表名: %v", err)
+	}
+	for _, name := range names {
+		if err := sqlDialect.ValidateIdent(name); err != nil {
+			return fmt.Errorf("无效的标识符: %v", err)
+		}
+	}
+	return nil
+}
+
+// quoteColumnList 引用逗号分隔的列名，如外键的 "order_id,line_no"
+func quoteColumnList(columns string) string {
+	return quoteIdents(splitColumnList(columns))
+}
+
+// splitColumnList 拆分逗号分隔的列名并去掉空白
+func splitColumnList(columns string) []string {
+	names := strings.Split(columns, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+	}
+	return names
+}
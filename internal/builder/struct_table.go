@@ -0,0 +1,521 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CreateTableFromStruct 从结构体创建表。
+//
+// 列名取 db 标签的第一部分，没有时由字段名转为下划线命名（UserID -> user_id），db:"-" 跳过字段。
+// 其余部分为逗号分隔的选项，括号和单引号内的逗号不拆分：
+//
+//	primary_key、auto_increment、not_null、null、unsigned
+//	size:64、type:varchar(64)、type:decimal(10,2)、enum:a|b|c
+//	default:0、comment:'说明'
+//	unique、index、index:idx_x、unique:uk_x、fulltext、fulltext:ft_x（同名的字段组成联合索引）
+//	fk:users.id、on_delete:cascade、on_update:set_null
+//	embedded、prefix:addr_（展开结构体字段）
+//
+// 选项名不区分大小写并忽略下划线和空格，primaryKey、autoIncrement、not null、column:name、
+// uniqueIndex:uk_x 等 GORM 写法同样可用。指针和 sql.Null* 类型的列可以为 NULL，其余类型默认 NOT NULL；
+// 匿名嵌入的结构体和带 embedded 选项的结构体字段展开为列。标签错误在 Create 或 Sync 时返回。
+func CreateTableFromStruct(sqlBuilder *SQLBuilder, tableName string, structType interface{}) *TableBuilder {
+	tb := NewTableBuilder(sqlBuilder, tableName)
+
+	t := reflect.TypeOf(structType)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		tb.err = fmt.Errorf("表 %s 需要结构体定义，得到 %v", tableName, t)
+		return tb
+	}
+
+	m := &structMapper{tableBuilder: tb, indexes: make(map[string]int)}
+	if err := m.mapStruct(t, ""); err != nil {
+		tb.err = fmt.Errorf("表 %s 的结构体定义无效: %v", tableName, err)
+	}
+	return tb
+}
+
+// SyncFromStruct 按结构体的当前定义同步表：表不存在时创建，存在时添加缺少的列和索引、修改有差异的列。
+// 破坏性变更按默认的 DestructiveWarn 处理，需要其他策略时使用
+// CreateTableFromStruct(...).Destructive(policy).Sync(ctx)
+func SyncFromStruct(ctx context.Context, sqlBuilder *SQLBuilder, tableName string, structType interface{}) error {
+	return CreateTableFromStruct(sqlBuilder, tableName, structType).Sync(ctx)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// columnTypePattern type 选项的格式，如 varchar(64)、decimal(10,2)、int unsigned
+var columnTypePattern = regexp.MustCompile(`(?i)^([a-z]+)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?((?:\s+(?:unsigned|zerofill))*)$`)
+
+// enumTypePattern type 选项中的枚举，如 enum('a','b')
+var enumTypePattern = regexp.MustCompile(`(?i)^enum\s*\((.*)\)$`)
+
+// structMapper 把结构体字段映射为表的列、索引和外键
+type structMapper struct {
+	tableBuilder *TableBuilder
+	indexes      map[string]int // 索引名 -> tableBuilder.indexes 中的位置
+}
+
+// structTag 解析后的 db 标签
+type structTag struct {
+	name    string
+	options []tagOption
+}
+
+// tagOption 标签选项，key 为小写并去掉下划线和空格
+type tagOption struct {
+	key   string
+	value string
+	raw   string
+}
+
+func (t structTag) option(key string) (string, bool) {
+	for _, opt := range t.options {
+		if opt.key == key {
+			return opt.value, true
+		}
+	}
+	return "", false
+}
+
+// mapStruct 映射结构体的字段，prefix 为展开字段的列名前缀
+func (m *structMapper) mapStruct(t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := parseStructTag(field.Tag.Get("db"))
+		if tag.name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		_, embedded := tag.option("embedded")
+		if fieldType.Kind() == reflect.Struct && !isValueStruct(fieldType) && (embedded || field.Anonymous && tag.name == "") {
+			// 未导出的匿名结构体中的导出字段同样展开
+			embeddedPrefix, _ := tag.option("prefix")
+			if err := m.mapStruct(fieldType, prefix+embeddedPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if err := m.mapField(field, tag, prefix); err != nil {
+			return fmt.Errorf("字段 %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// mapField 把字段映射为一列，并记录标签中的索引和外键
+func (m *structMapper) mapField(field reflect.StructField, tag structTag, prefix string) error {
+	column := AdvancedColumn{Name: prefix + tag.name}
+	if name, ok := tag.option("column"); ok {
+		column.Name = prefix + name
+	} else if tag.name == "" {
+		column.Name = prefix + snakeCase(field.Name)
+	}
+	column.NotNull = !inferColumnType(field.Type, &column)
+
+	size := getColumnSize(field)
+	typeGiven, unsigned := false, false
+	var defaultValue *string
+	var foreignKey *ForeignKeyDef
+	var onDelete, onUpdate ReferenceAction
+
+	for _, opt := range tag.options {
+		switch opt.key {
+		case "column":
+			// 列名已在上面确定
+		case "primarykey", "pk":
+			column.PrimaryKey = true
+			column.NotNull = true
+		case "autoincrement":
+			column.AutoIncr = true
+		case "notnull":
+			column.NotNull = true
+		case "null":
+			column.NotNull = false
+		case "unsigned":
+			unsigned = true
+		case "size":
+			n, err := strconv.Atoi(opt.value)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("无效的 size: %s", opt.value)
+			}
+			size = n
+		case "type":
+			if err := parseColumnType(opt.value, &column); err != nil {
+				return err
+			}
+			typeGiven = true
+		case "enum":
+			column.Type = TypeEnum
+			column.Default = strings.Split(opt.value, "|") // 枚举值保存在 Default 中，与 TableBuilder.Enum 相同
+			typeGiven = true
+		case "default":
+			value := opt.value
+			defaultValue = &value
+		case "comment":
+			column.Comment = opt.value
+		case "unique":
+			if opt.value == "" {
+				column.Unique = true
+			} else {
+				m.addIndex(opt.value, column.Name, IndexUnique)
+			}
+		case "uniqueindex":
+			m.addIndex(opt.value, column.Name, IndexUnique)
+		case "index":
+			m.addIndex(opt.value, column.Name, IndexNormal)
+		case "fulltext":
+			m.addIndex(opt.value, column.Name, IndexFullText)
+		case "fk", "foreignkey":
+			dot := strings.LastIndex(opt.value, ".")
+			if dot <= 0 || dot == len(opt.value)-1 {
+				return fmt.Errorf("外键格式应为 表名.列名: %s", opt.raw)
+			}
+			foreignKey = &ForeignKeyDef{
+				Name:      fmt.Sprintf("fk_%s_%s", m.tableBuilder.baseName(), column.Name),
+				Column:    column.Name,
+				RefTable:  opt.value[:dot],
+				RefColumn: opt.value[dot+1:],
+			}
+		case "ondelete", "onupdate":
+			action, err := parseReferenceAction(opt.value)
+			if err != nil {
+				return err
+			}
+			if opt.key == "ondelete" {
+				onDelete = action
+			} else {
+				onUpdate = action
+			}
+		case "embedded", "prefix":
+			return fmt.Errorf("%s 只能用于结构体字段", opt.raw)
+		default:
+			return fmt.Errorf("无法识别的标签选项: %s", opt.raw)
+		}
+	}
+
+	if unsigned {
+		column.Unsigned = true
+	}
+	if size > 0 {
+		switch {
+		case typeGiven && column.Size == 0:
+			column.Size = size
+		case !typeGiven && column.Type == TypeText:
+			if size <= 255 {
+				column.Type = TypeVarchar
+				column.Size = size
+			}
+		}
+	}
+
+	if defaultValue != nil {
+		if column.Type == TypeEnum {
+			return fmt.Errorf("ENUM 列不支持 default 选项")
+		}
+		column.Default = structDefault(column.Type, *defaultValue)
+	}
+
+	if foreignKey != nil {
+		foreignKey.OnDelete = onDelete
+		foreignKey.OnUpdate = onUpdate
+		m.tableBuilder.foreignKeys = append(m.tableBuilder.foreignKeys, *foreignKey)
+	} else if onDelete != "" || onUpdate != "" {
+		return fmt.Errorf("on_delete/on_update 需要与 fk 一起使用")
+	}
+
+	m.tableBuilder.columns = append(m.tableBuilder.columns, column)
+	return nil
+}
+
+// addIndex 添加索引，同名索引追加列组成联合索引；name 为空时按 TableBuilder.Index 的规则命名
+func (m *structMapper) addIndex(name, column string, indexType IndexType) {
+	tb := m.tableBuilder
+	if name == "" {
+		prefix := "idx"
+		switch indexType {
+		case IndexUnique:
+			prefix = "uk"
+		case IndexFullText:
+			prefix = "ft"
+		}
+		name = fmt.Sprintf("%s_%s_%s", prefix, tb.baseName(), column)
+	}
+
+	if i, ok := m.indexes[strings.ToLower(name)]; ok {
+		tb.indexes[i].Columns = append(tb.indexes[i].Columns, column)
+		return
+	}
+	m.indexes[strings.ToLower(name)] = len(tb.indexes)
+	tb.indexes = append(tb.indexes, IndexDef{
+		Name:    name,
+		Columns: []string{column},
+		Type:    indexType,
+		Unique:  indexType == IndexUnique,
+	})
+}
+
+// inferColumnType 根据 Go 类型推断列类型，返回该类型能否表示 NULL
+func inferColumnType(t reflect.Type, column *AdvancedColumn) bool {
+	if t.Kind() == reflect.Ptr {
+		inferColumnType(t.Elem(), column)
+		return true
+	}
+	if inner, ok := nullValueType(t); ok {
+		inferColumnType(inner, column)
+		return true
+	}
+
+	switch {
+	case t == timeType:
+		column.Type = TypeDateTime
+		return false
+	case t.Name() == "Decimal":
+		column.Type = TypeDecimal
+		column.Size = 10*100 + 2 // DECIMAL(10,2)，可用 type 选项修改
+		return false
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		column.Type = TypeBlob
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int8:
+		column.Type = TypeTinyInt
+	case reflect.Int16:
+		column.Type = TypeSmallInt
+	case reflect.Int, reflect.Int32:
+		column.Type = TypeInt
+	case reflect.Int64:
+		column.Type = TypeBigInt
+	case reflect.Uint8:
+		column.Type, column.Unsigned = TypeTinyInt, true
+	case reflect.Uint16:
+		column.Type, column.Unsigned = TypeSmallInt, true
+	case reflect.Uint, reflect.Uint32:
+		column.Type, column.Unsigned = TypeInt, true
+	case reflect.Uint64:
+		column.Type, column.Unsigned = TypeBigInt, true
+	case reflect.String:
+		column.Type = TypeText
+	case reflect.Bool:
+		column.Type = TypeBoolean
+	case reflect.Float32:
+		column.Type = TypeFloat
+	case reflect.Float64:
+		column.Type = TypeDouble
+	case reflect.Map, reflect.Slice:
+		column.Type = TypeJson
+		return true
+	case reflect.Struct, reflect.Array:
+		column.Type = TypeJson
+	default:
+		column.Type = TypeText
+	}
+	return false
+}
+
+// nullValueType sql.NullString、sql.Null[T]、decimal.NullDecimal 等可空包装类型中值的类型：
+// 名称以 Null 开头，包含 Valid bool 和另一个字段的结构体
+func nullValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !strings.HasPrefix(t.Name(), "Null") || t.NumField() != 2 {
+		return nil, false
+	}
+	var inner reflect.Type
+	hasValid := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "Valid" && field.Type.Kind() == reflect.Bool {
+			hasValid = true
+		} else {
+			inner = field.Type
+		}
+	}
+	return inner, hasValid && inner != nil
+}
+
+// isValueStruct 映射为单列的结构体类型（时间、可空包装、Decimal）
+func isValueStruct(t reflect.Type) bool {
+	if _, ok := nullValueType(t); ok {
+		return true
+	}
+	return t == timeType || t.Name() == "Decimal"
+}
+
+// parseColumnType 解析 type 选项，如 varchar(64)、decimal(10,2)、int unsigned、enum('a','b')
+func parseColumnType(value string, column *AdvancedColumn) error {
+	value = strings.TrimSpace(value)
+	if match := enumTypePattern.FindStringSubmatch(value); match != nil {
+		var values []string
+		for _, v := range splitTagOptions(match[1]) {
+			values = append(values, unquoteTagValue(strings.TrimSpace(v)))
+		}
+		column.Type = TypeEnum
+		column.Size = 0
+		column.Default = values
+		return nil
+	}
+
+	match := columnTypePattern.FindStringSubmatch(value)
+	if match == nil {
+		return fmt.Errorf("无效的 type: %s", value)
+	}
+
+	column.Type = ColumnType(strings.ToUpper(match[1]))
+	if column.Type == "NUMERIC" {
+		column.Type = TypeDecimal
+	}
+	column.Size = 0
+	if match[2] != "" {
+		column.Size, _ = strconv.Atoi(match[2])
+	}
+	if match[3] != "" {
+		if column.Type != TypeDecimal {
+			return fmt.Errorf("只有 DECIMAL 支持精度和小数位: %s", value)
+		}
+		scale, _ := strconv.Atoi(match[3])
+		if scale >= 100 || scale > column.Size {
+			return fmt.Errorf("无效的 DECIMAL 小数位: %s", value)
+		}
+		column.Size = column.Size*100 + scale // 编码精度和小数位，与 TableBuilder.Decimal 相同
+	} else if column.Type == TypeDecimal && column.Size > 0 {
+		column.Size *= 100
+	}
+
+	// 指定类型时无符号以 type 为准，不沿用 Go 类型推断的结果
+	modifiers := strings.ToLower(match[4])
+	column.Zerofill = strings.Contains(modifiers, "zerofill")
+	column.Unsigned = column.Zerofill || strings.Contains(modifiers, "unsigned")
+	return nil
+}
+
+// parseReferenceAction 解析 on_delete/on_update 的值，如 cascade、set_null
+func parseReferenceAction(value string) (ReferenceAction, error) {
+	action := ReferenceAction(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), "_", " ")))
+	switch action {
+	case ActionCascade, ActionSetNull, ActionRestrict, ActionNoAction, ActionSetDefault:
+		return action, nil
+	}
+	return "", fmt.Errorf("无效的外键动作: %s", value)
+}
+
+// structDefault 按列类型转换 default 选项：布尔和数字列使用字面量，其余为字符串
+func structDefault(columnType ColumnType, value string) interface{} {
+	switch columnType {
+	case TypeBoolean:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case TypeTinyInt, TypeSmallInt, TypeInt, TypeBigInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case TypeDecimal, TypeFloat, TypeDouble:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// parseStructTag 解析 db 标签
+func parseStructTag(tag string) structTag {
+	parts := splitTagOptions(tag)
+	st := structTag{name: strings.TrimSpace(parts[0])}
+	if strings.Contains(st.name, ":") {
+		st.name = "" // 第一部分是选项（如 column:name），没有列名
+	} else {
+		parts = parts[1:]
+	}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, ":")
+		st.options = append(st.options, tagOption{
+			key:   normalizeTagKey(key),
+			value: unquoteTagValue(strings.TrimSpace(value)),
+			raw:   part,
+		})
+	}
+	return st
+}
+
+// splitTagOptions 按括号和单引号之外的逗号拆分
+func splitTagOptions(tag string) []string {
+	var parts []string
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+func normalizeTagKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("_", "", " ", "", "-", "").Replace(key)
+}
+
+// unquoteTagValue 去掉两端的单引号，并把两个连续的单引号还原为一个
+func unquoteTagValue(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	return value
+}
+
+// snakeCase 将驼峰命名转换为下划线命名，连续大写视为一个词：UserID -> user_id，HTTPStatus -> http_status
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var result strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				result.WriteRune('_')
+			}
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+	return result.String()
+}
+
+func getColumnSize(field reflect.StructField) int {
+	if tag := field.Tag.Get("size"); tag != "" {
+		if size, err := strconv.Atoi(tag); err == nil && size > 0 {
+			return size
+		}
+	}
+	return 0
+}
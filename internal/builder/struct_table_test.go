@@ -0,0 +1,58 @@
+package builder
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestCreateTableFromStructNullability(t *testing.T) {
+	type account struct {
+		ID       int            `db:"id,primary_key,auto_increment"`
+		Email    string         `db:"email,size:255"`
+		Nickname *string        `db:"nickname,size:64"`
+		Age      *int           `db:"age,not_null"`
+		Bio      sql.NullString `db:"bio"`
+		Score    int            `db:"score,null"`
+		Avatar   []byte         `db:"avatar"`
+	}
+
+	tb := CreateTableFromStruct(nil, "accounts", account{})
+	if err := tb.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{"id": true, "email": true, "nickname": false, "age": true, "bio": false, "score": false, "avatar": false}
+	if len(tb.columns) != len(want) {
+		t.Fatalf("生成 %d 列，期望 %d 列", len(tb.columns), len(want))
+	}
+	for _, col := range tb.columns {
+		if col.NotNull != want[col.Name] {
+			t.Errorf("列 %s NOT NULL = %t，期望 %t", col.Name, col.NotNull, want[col.Name])
+		}
+	}
+}
+
+func TestCreateTableFromStructTagErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"拼写错误的选项", struct {
+			Email string `db:"email,uniqe"`
+		}{}, "无法识别的标签选项: uniqe"},
+		{"拼写错误的索引", struct {
+			Email string `db:"email,idnex:idx_email"`
+		}{}, "无法识别的标签选项: idnex:idx_email"},
+		{"无效的 size", struct {
+			Name string `db:"name,size:abc"`
+		}{}, "无效的 size"},
+	}
+	for _, tt := range tests {
+		err := CreateTableFromStruct(nil, "accounts", tt.value).validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: 错误为 %v，期望包含 %q", tt.name, err, tt.want)
+		}
+	}
+}
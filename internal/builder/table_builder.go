@@ -3,9 +3,7 @@ package builder
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"strings"
-	"time"
 )
 
 // TableBuilder 高级表构建器
@@ -15,7 +13,12 @@ type TableBuilder struct {
 	columns     []AdvancedColumn
 	indexes     []IndexDef
 	foreignKeys []ForeignKeyDef
+	checks      []CheckDef
+	primaryKey  []string // PrimaryKey 指定的主键列，为空时使用列上的 PrimaryKey
+	partition   *PartitionDef
 	options     TableOptions
+	destructive DestructivePolicy
+	err         error // 构建过程中的错误（如结构体标签无效），在 Create/Sync 时返回
 }
 
 // AdvancedColumn 高级列定义
@@ -30,6 +33,11 @@ type AdvancedColumn struct {
 	Unique     bool
 	Comment    string
 	After      string // 在指定列之后添加
+	Unsigned   bool
+	Zerofill   bool
+	OnUpdate   string // ON UPDATE 表达式，如 CURRENT_TIMESTAMP
+	Generated  string // 生成列表达式，非空时为 GENERATED ALWAYS AS (表达式)
+	Stored     bool   // 生成列是否为 STORED，默认 VIRTUAL
 }
 
 // ColumnType 列类型枚举
@@ -61,10 +69,12 @@ const (
 
 // IndexDef 索引定义
 type IndexDef struct {
-	Name    string
-	Columns []string
-	Type    IndexType
-	Unique  bool
+	Name       string
+	Columns    []string
+	Type       IndexType
+	Unique     bool
+	Lengths    map[string]int  // 列的前缀长度
+	Descending map[string]bool // 降序的列
 }
 
 // IndexType 索引类型
@@ -87,6 +97,38 @@ type ForeignKeyDef struct {
 	OnUpdate  ReferenceAction
 }
 
+// CheckDef CHECK 约束定义
+type CheckDef struct {
+	Name       string
+	Expression string
+}
+
+// PartitionType 分区类型
+type PartitionType string
+
+const (
+	PartitionRange        PartitionType = "RANGE"
+	PartitionRangeColumns PartitionType = "RANGE COLUMNS"
+	PartitionList         PartitionType = "LIST"
+	PartitionListColumns  PartitionType = "LIST COLUMNS"
+	PartitionHash         PartitionType = "HASH"
+	PartitionKey          PartitionType = "KEY"
+)
+
+// PartitionDef 分区定义
+type PartitionDef struct {
+	Type       PartitionType
+	Expression string // 分区表达式或逗号分隔的列
+	Count      int    // HASH/KEY 分区数
+	Partitions []PartitionValue
+}
+
+// PartitionValue RANGE/LIST 的一个分区
+type PartitionValue struct {
+	Name   string
+	Values string // RANGE 为上界（如 2024 或 MAXVALUE），LIST 为逗号分隔的值
+}
+
 // ReferenceAction 引用动作
 type ReferenceAction string
 
@@ -276,10 +318,23 @@ func (tb *TableBuilder) Enum(name string, values []string) *ColumnBuilder {
 	}
 }
 
+// Column 添加任意类型的列，size 为长度（DECIMAL 为 精度*100+小数位）
+func (tb *TableBuilder) Column(name string, columnType ColumnType, size int) *ColumnBuilder {
+	column := AdvancedColumn{
+		Name: name,
+		Type: columnType,
+		Size: size,
+	}
+	return &ColumnBuilder{
+		tableBuilder: tb,
+		column:       &column,
+	}
+}
+
 // Timestamps 添加created_at和updated_at时间戳列
 func (tb *TableBuilder) Timestamps() *TableBuilder {
 	tb.Timestamp("created_at").Default("CURRENT_TIMESTAMP").Comment("创建时间").End()
-	tb.Timestamp("updated_at").Default("CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP").Comment("更新时间").End()
+	tb.Timestamp("updated_at").Default("CURRENT_TIMESTAMP").OnUpdate("CURRENT_TIMESTAMP").Comment("更新时间").End()
 	return tb
 }
 
@@ -291,7 +346,7 @@ func (tb *TableBuilder) SoftDeletes() *TableBuilder {
 
 // Index 添加普通索引
 func (tb *TableBuilder) Index(columns ...string) *IndexBuilder {
-	name := fmt.Sprintf("idx_%s_%s", tb.tableName, strings.Join(columns, "_"))
+	name := fmt.Sprintf("idx_%s_%s", tb.baseName(), strings.Join(columns, "_"))
 	return &IndexBuilder{
 		tableBuilder: tb,
 		indexDef: &IndexDef{
@@ -304,7 +359,7 @@ func (tb *TableBuilder) Index(columns ...string) *IndexBuilder {
 
 // Unique 添加唯一索引
 func (tb *TableBuilder) Unique(columns ...string) *IndexBuilder {
-	name := fmt.Sprintf("uk_%s_%s", tb.tableName, strings.Join(columns, "_"))
+	name := fmt.Sprintf("uk_%s_%s", tb.baseName(), strings.Join(columns, "_"))
 	return &IndexBuilder{
 		tableBuilder: tb,
 		indexDef: &IndexDef{
@@ -316,9 +371,86 @@ func (tb *TableBuilder) Unique(columns ...string) *IndexBuilder {
 	}
 }
 
+// FullText 添加全文索引
+func (tb *TableBuilder) FullText(columns ...string) *IndexBuilder {
+	name := fmt.Sprintf("ft_%s_%s", tb.baseName(), strings.Join(columns, "_"))
+	return &IndexBuilder{
+		tableBuilder: tb,
+		indexDef: &IndexDef{
+			Name:    name,
+			Columns: columns,
+			Type:    IndexFullText,
+		},
+	}
+}
+
+// Spatial 添加空间索引
+func (tb *TableBuilder) Spatial(columns ...string) *IndexBuilder {
+	name := fmt.Sprintf("sp_%s_%s", tb.baseName(), strings.Join(columns, "_"))
+	return &IndexBuilder{
+		tableBuilder: tb,
+		indexDef: &IndexDef{
+			Name:    name,
+			Columns: columns,
+			Type:    IndexSpatial,
+		},
+	}
+}
+
+// PrimaryKey 设置主键列，多列时为联合主键
+func (tb *TableBuilder) PrimaryKey(columns ...string) *TableBuilder {
+	tb.primaryKey = columns
+	return tb
+}
+
+// Check 添加 CHECK 约束
+func (tb *TableBuilder) Check(name, expression string) *TableBuilder {
+	tb.checks = append(tb.checks, CheckDef{Name: name, Expression: expression})
+	return tb
+}
+
+// PartitionByRange 按表达式的范围分区，用 Partition 添加分区
+func (tb *TableBuilder) PartitionByRange(expression string) *PartitionBuilder {
+	return tb.partitionBy(PartitionRange, expression)
+}
+
+// PartitionByRangeColumns 按列的范围分区
+func (tb *TableBuilder) PartitionByRangeColumns(columns ...string) *PartitionBuilder {
+	return tb.partitionBy(PartitionRangeColumns, quoteIdents(columns))
+}
+
+// PartitionByList 按表达式的取值列表分区，用 Partition 添加分区
+func (tb *TableBuilder) PartitionByList(expression string) *PartitionBuilder {
+	return tb.partitionBy(PartitionList, expression)
+}
+
+// PartitionByListColumns 按列的取值列表分区
+func (tb *TableBuilder) PartitionByListColumns(columns ...string) *PartitionBuilder {
+	return tb.partitionBy(PartitionListColumns, quoteIdents(columns))
+}
+
+// PartitionByHash 按表达式的哈希值分为 count 个分区
+func (tb *TableBuilder) PartitionByHash(expression string, count int) *TableBuilder {
+	tb.partition = &PartitionDef{Type: PartitionHash, Expression: expression, Count: count}
+	return tb
+}
+
+// PartitionByKey 按列（为空时为主键）的哈希值分为 count 个分区
+func (tb *TableBuilder) PartitionByKey(count int, columns ...string) *TableBuilder {
+	tb.partition = &PartitionDef{Type: PartitionKey, Expression: quoteIdents(columns), Count: count}
+	return tb
+}
+
+func (tb *TableBuilder) partitionBy(partitionType PartitionType, expression string) *PartitionBuilder {
+	return &PartitionBuilder{
+		tableBuilder: tb,
+		partition:    &PartitionDef{Type: partitionType, Expression: expression},
+	}
+}
+
 // ForeignKey 添加外键
 func (tb *TableBuilder) ForeignKey(column string) *ForeignKeyBuilder {
-	name := fmt.Sprintf("fk_%s_%s", tb.tableName, column)
+	name := fmt.Sprintf("fk_%s_%s", tb.baseName(), column)
 	return &ForeignKeyBuilder{
 		tableBuilder: tb,
 		foreignKey: &ForeignKeyDef{
@@ -340,6 +472,24 @@ func (tb *TableBuilder) Charset(charset string) *TableBuilder {
 	return tb
 }
 
+// Collation 设置排序规则
+func (tb *TableBuilder) Collation(collation string) *TableBuilder {
+	tb.options.Collation = collation
+	return tb
+}
+
+// AutoIncrement 设置自增起始值
+func (tb *TableBuilder) AutoIncrement(start int64) *TableBuilder {
+	tb.options.AutoIncr = start
+	return tb
+}
+
+// RowFormat 设置行格式，如 DYNAMIC、COMPRESSED
+func (tb *TableBuilder) RowFormat(format string) *TableBuilder {
+	tb.options.RowFormat = format
+	return tb
+}
+
 // Comment 设置表注释
 func (tb *TableBuilder) Comment(comment string) *TableBuilder {
 	tb.options.Comment = comment
@@ -348,45 +498,74 @@ func (tb *TableBuilder) Comment(comment string) *TableBuilder {
 
 // Create 创建表
 func (tb *TableBuilder) Create(ctx context.Context) error {
+	if err := tb.validate(); err != nil {
+		return err
+	}
 	sql := tb.buildCreateTableSQL()
 	return tb.sqlBuilder.CreateTableIfNotExists(ctx, tb.tableName, sql)
 }
 
+// validate 校验表名以及列、索引、外键、约束和分区的名称
+func (tb *TableBuilder) validate() error {
+	if tb.err != nil {
+		return tb.err
+	}
+
+	var names []string
+	for _, col := range tb.columns {
+		names = append(names, col.Name)
+	}
+	names = append(names, tb.primaryKey...)
+	for _, idx := range tb.indexes {
+		names = append(names, idx.Name)
+		names = append(names, idx.Columns...)
+	}
+	for _, fk := range tb.foreignKeys {
+		names = append(names, fk.Name)
+	}
+	for _, check := range tb.checks {
+		names = append(names, check.Name)
+	}
+	if tb.partition != nil {
+		for _, p := range tb.partition.Partitions {
+			names = append(names, p.Name)
+		}
+	}
+	if err := validateNames(tb.tableName, names...); err != nil {
+		return fmt.Errorf("表 %s 的定义无效: %v", tb.tableName, err)
+	}
+	return nil
+}
+
 // buildCreateTableSQL 构建创建表的SQL
 func (tb *TableBuilder) buildCreateTableSQL() string {
 	var parts []string
-	parts = append(parts, fmt.Sprintf("CREATE TABLE %s (", tb.tableName))
+	parts = append(parts, fmt.Sprintf("CREATE TABLE %s (", quoteTable(tb.tableName)))
 
 	// 添加列定义
 	var columnDefs []string
 	for _, col := range tb.columns {
-		columnDefs = append(columnDefs, "  "+tb.buildColumnDef(col))
+		columnDefs = append(columnDefs, "  "+tb.buildColumnDef(col, true))
+	}
+
+	// 联合主键或 PrimaryKey 指定的主键
+	if primaryKey := tb.primaryKeyColumns(); len(primaryKey) > 0 && !tb.inlinePrimaryKey() {
+		columnDefs = append(columnDefs, fmt.Sprintf("  PRIMARY KEY (%s)", quoteIdents(primaryKey)))
 	}
 
 	// 添加索引定义
 	for _, idx := range tb.indexes {
-		if idx.Unique {
-			columnDefs = append(columnDefs, fmt.Sprintf("  UNIQUE KEY %s (%s)",
-				idx.Name, strings.Join(idx.Columns, ", ")))
-		} else {
-			columnDefs = append(columnDefs, fmt.Sprintf("  KEY %s (%s)",
-				idx.Name, strings.Join(idx.Columns, ", ")))
-		}
+		columnDefs = append(columnDefs, "  "+indexDefSQL(idx))
 	}
 
 	// 添加外键定义
 	for _, fk := range tb.foreignKeys {
-		fkDef := fmt.Sprintf("  CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
-			fk.Name, fk.Column, fk.RefTable, fk.RefColumn)
-
-		if fk.OnDelete != "" {
-			fkDef += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
-		}
-		if fk.OnUpdate != "" {
-			fkDef += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
-		}
+		columnDefs = append(columnDefs, "  "+foreignKeyClause(fk))
+	}
 
-		columnDefs = append(columnDefs, fkDef)
+	// 添加CHECK约束
+	for _, check := range tb.checks {
+		columnDefs = append(columnDefs, fmt.Sprintf("  CONSTRAINT %s CHECK (%s)", quoteIdent(check.Name), check.Expression))
 	}
 
 	parts = append(parts, strings.Join(columnDefs, ",\n"))
@@ -399,54 +578,132 @@ func (tb *TableBuilder) buildCreateTableSQL() string {
 	if tb.options.Charset != "" {
 		parts = append(parts, fmt.Sprintf("DEFAULT CHARSET=%s", tb.options.Charset))
 	}
+	if tb.options.Collation != "" {
+		parts = append(parts, fmt.Sprintf("COLLATE=%s", tb.options.Collation))
+	}
+	if tb.options.AutoIncr > 0 {
+		parts = append(parts, fmt.Sprintf("AUTO_INCREMENT=%d", tb.options.AutoIncr))
+	}
+	if tb.options.RowFormat != "" {
+		parts = append(parts, fmt.Sprintf("ROW_FORMAT=%s", tb.options.RowFormat))
+	}
 	if tb.options.Comment != "" {
-		parts = append(parts, fmt.Sprintf("COMMENT='%s'", tb.options.Comment))
+		parts = append(parts, fmt.Sprintf("COMMENT=%s", quoteString(tb.options.Comment)))
+	}
+
+	// 添加分区
+	if tb.partition != nil {
+		parts = append(parts, partitionSQL(tb.partition))
 	}
 
 	return strings.Join(parts, "\n")
 }
 
-// buildColumnDef 构建列定义
-func (tb *TableBuilder) buildColumnDef(col AdvancedColumn) string {
-	var parts []string
-	parts = append(parts, col.Name)
+// baseName 不带库名的表名，用于生成索引和外键名称
+func (tb *TableBuilder) baseName() string {
+	name := tb.tableName
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "`")
+}
 
-	// 构建类型定义
-	typeStr := string(col.Type)
-	if col.Type == TypeEnum && col.Default != nil {
-		// 处理枚举类型
-		if values, ok := col.Default.([]string); ok {
-			quotedValues := make([]string, len(values))
-			for i, v := range values {
-				quotedValues[i] = fmt.Sprintf("'%s'", v)
-			}
-			typeStr = fmt.Sprintf("ENUM(%s)", strings.Join(quotedValues, ", "))
+// primaryKeyColumns 主键列，PrimaryKey 指定的优先
+func (tb *TableBuilder) primaryKeyColumns() []string {
+	if len(tb.primaryKey) > 0 {
+		return tb.primaryKey
+	}
+	var columns []string
+	for _, col := range tb.columns {
+		if col.PrimaryKey {
+			columns = append(columns, col.Name)
 		}
-	} else if col.Size > 0 {
-		if col.Type == TypeDecimal {
-			// 解码精度和小数位
-			precision := col.Size / 100
-			scale := col.Size % 100
-			typeStr = fmt.Sprintf("%s(%d,%d)", typeStr, precision, scale)
-		} else {
-			typeStr = fmt.Sprintf("%s(%d)", typeStr, col.Size)
+	}
+	return columns
+}
+
+// inlinePrimaryKey 主键是否写在列定义中（只有一列标记为主键时）
+func (tb *TableBuilder) inlinePrimaryKey() bool {
+	return len(tb.primaryKey) == 0 && len(tb.primaryKeyColumns()) == 1
+}
+
+// indexDefSQL 构建索引定义，如 UNIQUE KEY uk_users_email (email)、KEY idx_logs_path (path(100), created_at DESC)
+func indexDefSQL(idx IndexDef) string {
+	keyword := "KEY"
+	switch {
+	case idx.Type == IndexFullText:
+		keyword = "FULLTEXT KEY"
+	case idx.Type == IndexSpatial:
+		keyword = "SPATIAL KEY"
+	case idx.Unique || idx.Type == IndexUnique:
+		keyword = "UNIQUE KEY"
+	}
+
+	columns := make([]string, len(idx.Columns))
+	for i, col := range idx.Columns {
+		columns[i] = quoteIdent(col)
+		if length := idx.Lengths[col]; length > 0 {
+			columns[i] += fmt.Sprintf("(%d)", length)
+		}
+		if idx.Descending[col] {
+			columns[i] += " DESC"
+		}
+	}
+	return fmt.Sprintf("%s %s (%s)", keyword, quoteIdent(idx.Name), strings.Join(columns, ", "))
+}
+
+// partitionSQL 构建分区子句
+func partitionSQL(partition *PartitionDef) string {
+	sql := fmt.Sprintf("PARTITION BY %s (%s)", partition.Type, partition.Expression)
+	if partition.Count > 0 {
+		sql += fmt.Sprintf(" PARTITIONS %d", partition.Count)
+	}
+	if len(partition.Partitions) == 0 {
+		return sql
+	}
+
+	values := make([]string, len(partition.Partitions))
+	for i, p := range partition.Partitions {
+		switch {
+		case partition.Type == PartitionList || partition.Type == PartitionListColumns:
+			values[i] = fmt.Sprintf("  PARTITION %s VALUES IN (%s)", quoteIdent(p.Name), p.Values)
+		case strings.EqualFold(p.Values, "MAXVALUE"):
+			values[i] = fmt.Sprintf("  PARTITION %s VALUES LESS THAN MAXVALUE", quoteIdent(p.Name))
+		default:
+			values[i] = fmt.Sprintf("  PARTITION %s VALUES LESS THAN (%s)", quoteIdent(p.Name), p.Values)
 		}
 	}
-	parts = append(parts, typeStr)
+	return sql + " (\n" + strings.Join(values, ",\n") + "\n)"
+}
+
+// buildColumnDef 构建列定义，inlineKeys 为 false 时不包含 PRIMARY KEY 和 UNIQUE（ALTER TABLE 中单独处理）
+func (tb *TableBuilder) buildColumnDef(col AdvancedColumn, inlineKeys bool) string {
+	var parts []string
+	parts = append(parts, quoteIdent(col.Name))
+	parts = append(parts, columnTypeSQL(col))
+
+	// 生成列
+	if col.Generated != "" {
+		storage := "VIRTUAL"
+		if col.Stored {
+			storage = "STORED"
+		}
+		parts = append(parts, fmt.Sprintf("GENERATED ALWAYS AS (%s) %s", col.Generated, storage))
+	}
 
 	// 添加约束
 	if col.NotNull {
 		parts = append(parts, "NOT NULL")
 	}
 
-	// 添加默认值
-	if col.Default != nil && col.Type != TypeEnum {
+	// 添加默认值（生成列没有默认值）
+	if col.Default != nil && col.Type != TypeEnum && col.Generated == "" {
 		switch v := col.Default.(type) {
 		case string:
 			if v == "CURRENT_TIMESTAMP" || strings.Contains(v, "CURRENT_TIMESTAMP") {
 				parts = append(parts, fmt.Sprintf("DEFAULT %s", v))
 			} else {
-				parts = append(parts, fmt.Sprintf("DEFAULT '%s'", v))
+				parts = append(parts, "DEFAULT "+quoteString(v))
 			}
 		case int, int64, float64:
 			parts = append(parts, fmt.Sprintf("DEFAULT %v", v))
@@ -459,25 +716,60 @@ func (tb *TableBuilder) buildColumnDef(col AdvancedColumn) string {
 		}
 	}
 
+	if col.OnUpdate != "" {
+		parts = append(parts, fmt.Sprintf("ON UPDATE %s", col.OnUpdate))
+	}
+
 	if col.AutoIncr {
 		parts = append(parts, "AUTO_INCREMENT")
 	}
 
-	if col.PrimaryKey {
+	if col.PrimaryKey && inlineKeys && tb.inlinePrimaryKey() {
 		parts = append(parts, "PRIMARY KEY")
 	}
 
-	if col.Unique && !col.PrimaryKey {
+	if col.Unique && !col.PrimaryKey && inlineKeys {
 		parts = append(parts, "UNIQUE")
 	}
 
 	if col.Comment != "" {
-		parts = append(parts, fmt.Sprintf("COMMENT '%s'", col.Comment))
+		parts = append(parts, "COMMENT "+quoteString(col.Comment))
 	}
 
 	return strings.Join(parts, " ")
 }
 
+// columnTypeSQL 构建列类型，如 VARCHAR(255)、DECIMAL(10,2)、ENUM('a', 'b')
+func columnTypeSQL(col AdvancedColumn) string {
+	typeStr := string(col.Type)
+	if col.Type == TypeEnum && col.Default != nil {
+		// 处理枚举类型
+		if values, ok := col.Default.([]string); ok {
+			quotedValues := make([]string, len(values))
+			for i, v := range values {
+				quotedValues[i] = quoteString(v)
+			}
+			typeStr = fmt.Sprintf("ENUM(%s)", strings.Join(quotedValues, ", "))
+		}
+	} else if col.Size > 0 {
+		if col.Type == TypeDecimal {
+			// 解码精度和小数位
+			precision := col.Size / 100
+			scale := col.Size % 100
+			typeStr = fmt.Sprintf("%s(%d,%d)", typeStr, precision, scale)
+		} else {
+			typeStr = fmt.Sprintf("%s(%d)", typeStr, col.Size)
+		}
+	}
+	if col.Unsigned {
+		typeStr += " UNSIGNED"
+	}
+	if col.Zerofill {
+		typeStr += " ZEROFILL"
+	}
+	return typeStr
+}
+
 // ColumnBuilder 列构建器（链式调用）
 type ColumnBuilder struct {
 	tableBuilder *TableBuilder
@@ -508,6 +800,50 @@ func (cb *ColumnBuilder) Unique() *ColumnBuilder {
 	return cb
 }
 
+// PrimaryKey 设置为主键，多列设置时为联合主键
+func (cb *ColumnBuilder) PrimaryKey() *ColumnBuilder {
+	cb.column.PrimaryKey = true
+	cb.column.NotNull = true
+	return cb
+}
+
+// AutoIncrement 设置为自增
+func (cb *ColumnBuilder) AutoIncrement() *ColumnBuilder {
+	cb.column.AutoIncr = true
+	return cb
+}
+
+// Unsigned 设置为无符号
+func (cb *ColumnBuilder) Unsigned() *ColumnBuilder {
+	cb.column.Unsigned = true
+	return cb
+}
+
+// Zerofill 设置为零填充（隐含 UNSIGNED）
+func (cb *ColumnBuilder) Zerofill() *ColumnBuilder {
+	cb.column.Zerofill = true
+	cb.column.Unsigned = true
+	return cb
+}
+
+// OnUpdate 设置 ON UPDATE 表达式，如 CURRENT_TIMESTAMP
+func (cb *ColumnBuilder) OnUpdate(expression string) *ColumnBuilder {
+	cb.column.OnUpdate = expression
+	return cb
+}
+
+// GeneratedAs 设置为生成列（默认 VIRTUAL）
+func (cb *ColumnBuilder) GeneratedAs(expression string) *ColumnBuilder {
+	cb.column.Generated = expression
+	return cb
+}
+
+// Stored 生成列的值存储在表中
+func (cb *ColumnBuilder) Stored() *ColumnBuilder {
+	cb.column.Stored = true
+	return cb
+}
+
 // Comment 设置注释
 func (cb *ColumnBuilder) Comment(comment string) *ColumnBuilder {
 	cb.column.Comment = comment
@@ -538,6 +874,26 @@ func (ib *IndexBuilder) Name(name string) *IndexBuilder {
 	return ib
 }
 
+// Length 设置列的前缀长度
+func (ib *IndexBuilder) Length(column string, length int) *IndexBuilder {
+	if ib.indexDef.Lengths == nil {
+		ib.indexDef.Lengths = make(map[string]int)
+	}
+	ib.indexDef.Lengths[column] = length
+	return ib
+}
+
+// Desc 设置列为降序
+func (ib *IndexBuilder) Desc(columns ...string) *IndexBuilder {
+	if ib.indexDef.Descending == nil {
+		ib.indexDef.Descending = make(map[string]bool)
+	}
+	for _, col := range columns {
+		ib.indexDef.Descending[col] = true
+	}
+	return ib
+}
+
 // End 结束索引定义
 func (ib *IndexBuilder) End() *TableBuilder {
 	ib.tableBuilder.indexes = append(ib.tableBuilder.indexes, *ib.indexDef)
@@ -550,6 +906,12 @@ type ForeignKeyBuilder struct {
 	foreignKey   *ForeignKeyDef
 }
 
+// Name 设置外键名称
+func (fkb *ForeignKeyBuilder) Name(name string) *ForeignKeyBuilder {
+	fkb.foreignKey.Name = name
+	return fkb
+}
+
 // References 设置引用表和列
 func (fkb *ForeignKeyBuilder) References(table, column string) *ForeignKeyBuilder {
 	fkb.foreignKey.RefTable = table
@@ -575,125 +937,20 @@ func (fkb *ForeignKeyBuilder) End() *TableBuilder {
 	return fkb.tableBuilder
 }
 
-// CreateTableFromStruct 从结构体创建表
-func CreateTableFromStruct(sqlBuilder *SQLBuilder, tableName string, structType interface{}) *TableBuilder {
-	tb := NewTableBuilder(sqlBuilder, tableName)
-
-	t := reflect.TypeOf(structType)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-
-		// 跳过非导出字段
-		if !field.IsExported() {
-			continue
-		}
-
-		columnName := getColumnName(field)
-		if columnName == "-" {
-			continue
-		}
-
-		column := AdvancedColumn{
-			Name: columnName,
-		}
-
-		// 根据Go类型推断数据库类型
-		switch field.Type.Kind() {
-		case reflect.Int, reflect.Int32:
-			column.Type = TypeInt
-		case reflect.Int64:
-			column.Type = TypeBigInt
-		case reflect.String:
-			size := getColumnSize(field)
-			if size > 0 && size <= 255 {
-				column.Type = TypeVarchar
-				column.Size = size
-			} else {
-				column.Type = TypeText
-			}
-		case reflect.Bool:
-			column.Type = TypeBoolean
-		case reflect.Float32, reflect.Float64:
-			column.Type = TypeDouble
-		default:
-			if field.Type == reflect.TypeOf(time.Time{}) {
-				column.Type = TypeDateTime
-			} else {
-				column.Type = TypeText // 默认类型
-			}
-		}
-
-		// 处理标签
-		if tag := field.Tag.Get("db"); tag != "" {
-			parseDBTag(&column, tag)
-		}
-
-		tb.columns = append(tb.columns, column)
-	}
-
-	return tb
-}
-
-// 辅助函数
-func getColumnName(field reflect.StructField) string {
-	if tag := field.Tag.Get("db"); tag != "" {
-		parts := strings.Split(tag, ",")
-		if len(parts) > 0 && parts[0] != "" {
-			return parts[0]
-		}
-	}
-
-	// 将驼峰命名转换为下划线命名
-	name := field.Name
-	var result strings.Builder
-	for i, r := range name {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result.WriteRune('_')
-		}
-		result.WriteRune(r)
-	}
-	return strings.ToLower(result.String())
-}
-
-func getColumnSize(field reflect.StructField) int {
-	if tag := field.Tag.Get("size"); tag != "" {
-		if size := parseIntTag(tag); size > 0 {
-			return size
-		}
-	}
-	return 0
+// PartitionBuilder 分区构建器
+type PartitionBuilder struct {
+	tableBuilder *TableBuilder
+	partition    *PartitionDef
 }
 
-func parseDBTag(column *AdvancedColumn, tag string) {
-	parts := strings.Split(tag, ",")
-	for _, part := range parts[1:] { // 跳过第一个部分（列名）
-		part = strings.TrimSpace(part)
-		switch {
-		case part == "primary_key":
-			column.PrimaryKey = true
-			column.NotNull = true
-		case part == "auto_increment":
-			column.AutoIncr = true
-		case part == "not_null":
-			column.NotNull = true
-		case part == "unique":
-			column.Unique = true
-		case strings.HasPrefix(part, "default:"):
-			defaultValue := strings.TrimPrefix(part, "default:")
-			column.Default = defaultValue
-		case strings.HasPrefix(part, "comment:"):
-			comment := strings.TrimPrefix(part, "comment:")
-			column.Comment = comment
-		}
-	}
+// Partition 添加分区，RANGE 分区的 values 为上界（如 2024 或 MAXVALUE），LIST 分区为逗号分隔的值
+func (pb *PartitionBuilder) Partition(name, values string) *PartitionBuilder {
+	pb.partition.Partitions = append(pb.partition.Partitions, PartitionValue{Name: name, Values: values})
+	return pb
 }
 
-func parseIntTag(tag string) int {
-	var result int
-	fmt.Sscanf(tag, "%d", &result)
-	return result
+// End 结束分区定义
+func (pb *PartitionBuilder) End() *TableBuilder {
+	pb.tableBuilder.partition = pb.partition
+	return pb.tableBuilder
 }
@@ -0,0 +1,155 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCreateTableSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		build func(tb *TableBuilder)
+		want  string
+	}{
+		{
+			name:  "自增主键和时间戳",
+			table: "users",
+			build: func(tb *TableBuilder) {
+				tb.ID()
+				tb.String("email", 100).NotNull().Unique().End()
+				tb.Timestamps()
+			},
+			want: "CREATE TABLE `users` (\n" +
+				"  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',\n" +
+				"  `email` VARCHAR(100) NOT NULL UNIQUE,\n" +
+				"  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',\n" +
+				"  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'\n" +
+				")\nENGINE=InnoDB\nDEFAULT CHARSET=utf8mb4",
+		},
+		{
+			name:  "联合主键和生成列",
+			table: "order_items",
+			build: func(tb *TableBuilder) {
+				tb.Integer("order_id").NotNull().End()
+				tb.Integer("line_no").NotNull().End()
+				tb.Decimal("price", 10, 2).NotNull().Default(0).End()
+				tb.Integer("quantity").NotNull().Default(1).End()
+				tb.Decimal("amount", 12, 2).GeneratedAs("`price` * `quantity`").Stored().End()
+				tb.PrimaryKey("order_id", "line_no")
+				tb.Engine("")
+				tb.Charset("")
+			},
+			want: "CREATE TABLE `order_items` (\n" +
+				"  `order_id` INT NOT NULL,\n" +
+				"  `line_no` INT NOT NULL,\n" +
+				"  `price` DECIMAL(10,2) NOT NULL DEFAULT 0,\n" +
+				"  `quantity` INT NOT NULL DEFAULT 1,\n" +
+				"  `amount` DECIMAL(12,2) GENERATED ALWAYS AS (`price` * `quantity`) STORED,\n" +
+				"  PRIMARY KEY (`order_id`, `line_no`)\n" +
+				")",
+		},
+		{
+			name:  "索引选项和默认名称",
+			table: "app.logs",
+			build: func(tb *TableBuilder) {
+				tb.String("path", 500).End()
+				tb.Text("body").End()
+				tb.Column("location", "POINT", 0).NotNull().End()
+				tb.DateTime("created_at").End()
+				tb.Index("path", "created_at").Length("path", 100).Desc("created_at").End()
+				tb.FullText("path", "body").End()
+				tb.Spatial("location").End()
+				tb.Unique("path").Name("uk_path").End()
+				tb.Engine("")
+				tb.Charset("")
+			},
+			want: "CREATE TABLE `app`.`logs` (\n" +
+				"  `path` VARCHAR(500),\n" +
+				"  `body` TEXT,\n" +
+				"  `location` POINT NOT NULL,\n" +
+				"  `created_at` DATETIME,\n" +
+				"  KEY `idx_logs_path_created_at` (`path`(100), `created_at` DESC),\n" +
+				"  FULLTEXT KEY `ft_logs_path_body` (`path`, `body`),\n" +
+				"  SPATIAL KEY `sp_logs_location` (`location`),\n" +
+				"  UNIQUE KEY `uk_path` (`path`)\n" +
+				")",
+		},
+		{
+			name:  "外键和 CHECK 约束",
+			table: "profiles",
+			build: func(tb *TableBuilder) {
+				tb.ID()
+				tb.Integer("user_id").NotNull().End()
+				tb.Integer("age").End()
+				tb.ForeignKey("user_id").References("users", "id").OnDelete("CASCADE").End()
+				tb.Check("check`age", "`age` >= 0")
+				tb.Engine("")
+				tb.Charset("")
+				tb.Comment("会员's 资料")
+			},
+			want: "CREATE TABLE `profiles` (\n" +
+				"  `id` INT NOT NULL AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',\n" +
+				"  `user_id` INT NOT NULL,\n" +
+				"  `age` INT,\n" +
+				"  CONSTRAINT `fk_profiles_user_id` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,\n" +
+				"  CONSTRAINT `check``age` CHECK (`age` >= 0)\n" +
+				")\nCOMMENT='会员''s 资料'",
+		},
+		{
+			name:  "范围分区",
+			table: "sales",
+			build: func(tb *TableBuilder) {
+				tb.Integer("year").NotNull().End()
+				tb.PartitionByRange("`year`").
+					Partition("p2023", "2024").
+					Partition("pmax", "MAXVALUE").
+					End()
+				tb.Engine("")
+				tb.Charset("")
+			},
+			want: "CREATE TABLE `sales` (\n" +
+				"  `year` INT NOT NULL\n" +
+				")\n" +
+				"PARTITION BY RANGE (`year`) (\n" +
+				"  PARTITION `p2023` VALUES LESS THAN (2024),\n" +
+				"  PARTITION `pmax` VALUES LESS THAN MAXVALUE\n" +
+				")",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTableBuilder(nil, tt.table)
+			tt.build(tb)
+			if got := tb.buildCreateTableSQL(); got != tt.want {
+				t.Errorf("生成的SQL:\n%s\n期望:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableBuilderValidate(t *testing.T) {
+	tests := []struct {
+		table string
+		build func(tb *TableBuilder)
+		want  string
+	}{
+		{"users", func(tb *TableBuilder) { tb.ID() }, ""},
+		{"a.b.c", func(tb *TableBuilder) { tb.ID() }, "无效的表名"},
+		{"users", func(tb *TableBuilder) { tb.String("", 10).End() }, "无效的标识符"},
+		{"users", func(tb *TableBuilder) { tb.ID().Index("id").Name(strings.Repeat("x", 65)).End() }, "无效的标识符"},
+	}
+	for _, tt := range tests {
+		tb := NewTableBuilder(nil, tt.table)
+		tt.build(tb)
+		err := tb.validate()
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("表 %s: 意外的错误: %v", tt.table, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("表 %s 的错误为 %v，期望包含 %q", tt.table, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,662 @@
+package builder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// DestructivePolicy 同步表结构时对破坏性变更的处理方式
+//
+// 破坏性变更指可能丢失数据的操作：删除列、索引、外键，以及缩小列类型（如 VARCHAR(255) 改为 VARCHAR(50)）。
+type DestructivePolicy string
+
+const (
+	DestructiveNever DestructivePolicy = "never" // 不执行破坏性变更
+	DestructiveWarn  DestructivePolicy = "warn"  // 不执行，打印警告（默认）
+	DestructiveAllow DestructivePolicy = "allow" // 执行破坏性变更
+)
+
+// syncMode 同步模式
+type syncMode int
+
+const (
+	syncFull   syncMode = iota // 添加、修改和删除
+	syncEnsure                 // 只添加缺少的列、索引和外键
+)
+
+// ALTER TABLE 的执行阶段：先删除外键（被删除的列可能有外键），最后添加外键（引用的列需要先存在）
+const (
+	phaseDropForeignKeys = iota
+	phaseColumns
+	phaseAddForeignKeys
+	phaseCount
+)
+
+// syncChange 一项结构变更，对应 ALTER TABLE 的一个子句
+type syncChange struct {
+	phase       int
+	clause      string
+	description string
+	destructive bool
+}
+
+// Destructive 设置 Sync 对破坏性变更的处理方式
+func (tb *TableBuilder) Destructive(policy DestructivePolicy) *TableBuilder {
+	tb.destructive = policy
+	return tb
+}
+
+// Sync 同步表结构：表不存在时创建，存在时对比现有的列、索引和外键，
+// 用最少的 ALTER TABLE 语句使其与定义一致。破坏性变更按 Destructive 设置处理。
+func (tb *TableBuilder) Sync(ctx context.Context) error {
+	return tb.sync(ctx, syncFull)
+}
+
+// EnsureTable 表不存在时创建，存在时只添加缺少的列、索引和外键，不修改或删除已有结构
+func (tb *TableBuilder) EnsureTable(ctx context.Context) error {
+	return tb.sync(ctx, syncEnsure)
+}
+
+// sync 按模式同步表结构
+func (tb *TableBuilder) sync(ctx context.Context, mode syncMode) error {
+	if err := tb.validate(); err != nil {
+		return err
+	}
+
+	exists, err := tb.sqlBuilder.checker.TableExists(ctx, tb.tableName)
+	if err != nil {
+		return fmt.Errorf("检查表 %s 是否存在失败: %v", tb.tableName, err)
+	}
+	if !exists {
+		return tb.Create(ctx)
+	}
+
+	schema, err := loadTableSchema(ctx, tb.sqlBuilder.checker, tb.tableName)
+	if err != nil {
+		return fmt.Errorf("读取表 %s 的结构失败: %v", tb.tableName, err)
+	}
+
+	changes := tb.diff(schema, mode)
+	if len(changes) == 0 {
+		fmt.Printf("表 %s 结构已是最新，跳过同步\n", tb.tableName)
+		return nil
+	}
+
+	policy := tb.destructive
+	if policy == "" {
+		policy = DestructiveWarn
+	}
+
+	var phases [phaseCount][]syncChange
+	for _, change := range changes {
+		if change.destructive && policy != DestructiveAllow {
+			if policy == DestructiveWarn {
+				fmt.Printf("⚠️  跳过破坏性变更 %s: %s（使用 Destructive(DestructiveAllow) 执行）\n", tb.tableName, change.description)
+			}
+			continue
+		}
+		phases[change.phase] = append(phases[change.phase], change)
+	}
+
+	for _, phase := range phases {
+		if len(phase) == 0 {
+			continue
+		}
+		clauses := make([]string, len(phase))
+		for i, change := range phase {
+			clauses[i] = change.clause
+		}
+
+		sql := fmt.Sprintf("ALTER TABLE %s %s", quoteTable(tb.tableName), strings.Join(clauses, ", "))
+		if _, err := tb.sqlBuilder.db.Exec(sql); err != nil {
+			return fmt.Errorf("同步表 %s 失败: %v", tb.tableName, err)
+		}
+		for _, change := range phase {
+			fmt.Printf("同步表 %s: %s\n", tb.tableName, change.description)
+		}
+	}
+
+	return nil
+}
+
+// diff 对比定义与现有结构，返回需要的变更
+func (tb *TableBuilder) diff(schema *tableSchema, mode syncMode) []syncChange {
+	var changes []syncChange
+	add := func(phase int, destructive bool, clause, format string, args ...interface{}) {
+		changes = append(changes, syncChange{
+			phase:       phase,
+			clause:      clause,
+			description: fmt.Sprintf(format, args...),
+			destructive: destructive,
+		})
+	}
+
+	// 1. 列
+	wanted := make(map[string]bool)
+	primaryKey := make(map[string]bool)
+	for _, name := range tb.primaryKeyColumns() {
+		primaryKey[strings.ToLower(name)] = true
+	}
+	for i, col := range tb.columns {
+		wanted[strings.ToLower(col.Name)] = true
+		if primaryKey[strings.ToLower(col.Name)] {
+			col.NotNull = true // 主键列总是 NOT NULL
+		}
+		live := schema.column(col.Name)
+
+		if live == nil {
+			position := " FIRST"
+			if col.After != "" {
+				position = " AFTER " + quoteIdent(col.After)
+			} else if i > 0 {
+				position = " AFTER " + quoteIdent(tb.columns[i-1].Name)
+			}
+			add(phaseColumns, false, "ADD COLUMN "+tb.buildColumnDef(col, false)+position, "添加列 %s", col.Name)
+			continue
+		}
+
+		if mode == syncEnsure {
+			continue
+		}
+		if reasons := columnDifferences(col, live); len(reasons) > 0 {
+			add(phaseColumns, narrowsType(live.ColumnType, columnTypeSQL(col)),
+				"MODIFY COLUMN "+tb.buildColumnDef(col, false),
+				"修改列 %s（%s）", col.Name, strings.Join(reasons, "，"))
+		}
+	}
+
+	droppedColumns := make(map[string]bool)
+	if mode == syncFull {
+		for _, live := range schema.columns {
+			if !wanted[strings.ToLower(live.Name)] {
+				droppedColumns[strings.ToLower(live.Name)] = true
+				add(phaseColumns, true, "DROP COLUMN "+quoteIdent(live.Name), "删除列 %s", live.Name)
+			}
+		}
+	}
+
+	// 2. 索引（包括主键和列上的 UNIQUE）
+	wantedIndexes := make(map[string]bool)
+	for _, idx := range tb.desiredIndexes() {
+		wantedIndexes[strings.ToLower(idx.Name)] = true
+		live := schema.index(idx.Name)
+
+		switch {
+		case live == nil:
+			add(phaseColumns, false, addIndexClause(idx), "添加索引 %s", idx.Name)
+		case mode == syncFull && !sameIndex(idx, live):
+			add(phaseColumns, false, dropIndexClause(idx.Name)+", "+addIndexClause(idx),
+				"重建索引 %s (%s) -> (%s)", idx.Name, strings.Join(live.Columns, ", "), strings.Join(idx.Columns, ", "))
+		}
+	}
+
+	if mode == syncFull {
+		for _, live := range schema.indexes {
+			if wantedIndexes[strings.ToLower(live.Name)] || schema.foreignKey(live.Name) != nil {
+				continue // 外键自动创建的索引随外键删除
+			}
+			if allDropped(live.Columns, droppedColumns) {
+				continue // 随列一起删除
+			}
+			add(phaseColumns, true, dropIndexClause(live.Name), "删除索引 %s", live.Name)
+		}
+	}
+
+	// 3. 外键
+	wantedForeignKeys := make(map[string]bool)
+	for _, fk := range tb.foreignKeys {
+		wantedForeignKeys[strings.ToLower(fk.Name)] = true
+		live := schema.foreignKey(fk.Name)
+
+		switch {
+		case live == nil:
+			add(phaseAddForeignKeys, false, "ADD "+foreignKeyClause(fk), "添加外键 %s", fk.Name)
+		case mode == syncFull && !sameForeignKey(fk, live):
+			add(phaseDropForeignKeys, false, "DROP FOREIGN KEY "+quoteIdent(fk.Name), "删除外键 %s（重建）", fk.Name)
+			add(phaseAddForeignKeys, false, "ADD "+foreignKeyClause(fk), "重建外键 %s", fk.Name)
+		}
+	}
+
+	if mode == syncFull {
+		for _, live := range schema.foreignKeys {
+			if !wantedForeignKeys[strings.ToLower(live.Name)] {
+				add(phaseDropForeignKeys, true, "DROP FOREIGN KEY "+quoteIdent(live.Name), "删除外键 %s", live.Name)
+			}
+		}
+	}
+
+	return changes
+}
+
+// desiredIndexes 定义中的所有索引：主键、列上的 UNIQUE（索引名与列名相同）和 Index/Unique 定义的索引
+func (tb *TableBuilder) desiredIndexes() []IndexDef {
+	var indexes []IndexDef
+
+	if primary := tb.primaryKeyColumns(); len(primary) > 0 {
+		indexes = append(indexes, IndexDef{Name: "PRIMARY", Columns: primary, Unique: true})
+	}
+
+	for _, col := range tb.columns {
+		if col.Unique && !col.PrimaryKey {
+			indexes = append(indexes, IndexDef{Name: col.Name, Columns: []string{col.Name}, Type: IndexUnique, Unique: true})
+		}
+	}
+
+	return append(indexes, tb.indexes...)
+}
+
+// addIndexClause ALTER TABLE 中添加索引的子句
+func addIndexClause(idx IndexDef) string {
+	if idx.Name == "PRIMARY" {
+		return fmt.Sprintf("ADD PRIMARY KEY (%s)", quoteIdents(idx.Columns))
+	}
+	return "ADD " + indexDefSQL(idx)
+}
+
+// dropIndexClause ALTER TABLE 中删除索引的子句
+func dropIndexClause(name string) string {
+	if strings.EqualFold(name, "PRIMARY") {
+		return "DROP PRIMARY KEY"
+	}
+	return "DROP INDEX " + quoteIdent(name)
+}
+
+// foreignKeyClause 外键约束定义
+func foreignKeyClause(fk ForeignKeyDef) string {
+	clause := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteIdent(fk.Name), quoteColumnList(fk.Column), quoteTable(fk.RefTable), quoteColumnList(fk.RefColumn))
+	if fk.OnDelete != "" {
+		clause += fmt.Sprintf(" ON DELETE %s", fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		clause += fmt.Sprintf(" ON UPDATE %s", fk.OnUpdate)
+	}
+	return clause
+}
+
+// columnDifferences 返回列定义与现有列的差异说明，没有差异时返回空
+func columnDifferences(col AdvancedColumn, live *liveColumn) []string {
+	var reasons []string
+
+	wantType := normalizeColumnType(columnTypeSQL(col))
+	liveType := normalizeColumnType(live.ColumnType)
+	if wantType != liveType && !(wantType == "json" && liveType == "longtext") { // MariaDB 的 JSON 为 LONGTEXT
+		reasons = append(reasons, fmt.Sprintf("类型 %s -> %s", live.ColumnType, columnTypeSQL(col)))
+	}
+
+	notNull := col.NotNull || col.PrimaryKey
+	if notNull == live.Nullable {
+		if notNull {
+			reasons = append(reasons, "NULL -> NOT NULL")
+		} else {
+			reasons = append(reasons, "NOT NULL -> NULL")
+		}
+	}
+
+	wantDefault, wantOnUpdate := columnDefault(col)
+	liveDefault, liveOnUpdate := live.defaultValue()
+	if !sameDefault(wantDefault, liveDefault) {
+		reasons = append(reasons, fmt.Sprintf("默认值 %s -> %s", displayDefault(liveDefault), displayDefault(wantDefault)))
+	}
+	if !strings.EqualFold(wantOnUpdate, liveOnUpdate) {
+		reasons = append(reasons, "ON UPDATE")
+	}
+
+	// DEFAULT_GENERATED 表示默认值为表达式，不是生成列
+	extra := strings.ToLower(live.Extra)
+	virtual := strings.Contains(extra, "virtual generated")
+	stored := strings.Contains(extra, "stored generated")
+	if (col.Generated != "") != (virtual || stored) ||
+		(col.Generated != "" && col.Stored != stored) {
+		reasons = append(reasons, "生成列")
+	}
+	if col.AutoIncr != strings.Contains(extra, "auto_increment") {
+		reasons = append(reasons, "AUTO_INCREMENT")
+	}
+	if col.Comment != live.Comment {
+		reasons = append(reasons, "注释")
+	}
+	return reasons
+}
+
+// columnDefault 定义中的默认值和 ON UPDATE 表达式，默认值统一为小写的文本，没有默认值时为 nil
+func columnDefault(col AdvancedColumn) (*string, string) {
+	onUpdate := normalizeExpression(col.OnUpdate)
+	if col.Default == nil || col.Type == TypeEnum || col.Generated != "" {
+		return nil, onUpdate
+	}
+
+	var text string
+	switch v := col.Default.(type) {
+	case string:
+		text = v
+	case bool:
+		text = "0"
+		if v {
+			text = "1"
+		}
+	case int, int64, float64:
+		text = fmt.Sprintf("%v", v)
+	default:
+		return nil, onUpdate
+	}
+
+	upper := strings.ToUpper(text)
+	if i := strings.Index(upper, " ON UPDATE "); i >= 0 && strings.Contains(upper, "CURRENT_TIMESTAMP") {
+		onUpdate = normalizeExpression(text[i+len(" ON UPDATE "):])
+		text = text[:i]
+	}
+	text = normalizeExpression(text)
+	return &text, onUpdate
+}
+
+// defaultValue 现有列的默认值和 ON UPDATE 表达式，格式与 columnDefault 相同
+func (c *liveColumn) defaultValue() (*string, string) {
+	onUpdate := ""
+	extra := strings.ToLower(c.Extra)
+	if i := strings.Index(extra, "on update "); i >= 0 {
+		onUpdate = normalizeExpression(extra[i+len("on update "):])
+	}
+
+	if !c.Default.Valid || strings.EqualFold(c.Default.String, "NULL") {
+		return nil, onUpdate
+	}
+	text := c.Default.String
+	// MariaDB 的字符串默认值带引号
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		text = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	text = normalizeExpression(text)
+	return &text, onUpdate
+}
+
+// normalizeExpression CURRENT_TIMESTAMP、current_timestamp() 等写法统一为小写、去掉空括号
+func normalizeExpression(expr string) string {
+	expr = strings.TrimSpace(expr)
+	lower := strings.ToLower(expr)
+	if strings.HasPrefix(lower, "current_timestamp") || strings.HasPrefix(lower, "now(") {
+		lower = strings.ReplaceAll(lower, "()", "")
+		if lower == "now" {
+			lower = "current_timestamp"
+		}
+		return lower
+	}
+	return expr
+}
+
+// sameDefault 比较默认值，数字按数值比较（DECIMAL 列的 0 与 0.00 相同）
+func sameDefault(want, live *string) bool {
+	if want == nil || live == nil {
+		return want == nil && live == nil
+	}
+	if *want == *live {
+		return true
+	}
+	a, errA := strconv.ParseFloat(*want, 64)
+	b, errB := strconv.ParseFloat(*live, 64)
+	return errA == nil && errB == nil && a == b
+}
+
+func displayDefault(value *string) string {
+	if value == nil {
+		return "无"
+	}
+	return *value
+}
+
+var (
+	integerDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+	typeSizePattern     = regexp.MustCompile(`^([a-z]+)(?:\((\d+)(?:,(\d+))?\))?`)
+)
+
+// normalizeColumnType 统一类型写法：小写，去掉整数的显示宽度（TINYINT(1) 除外），BOOLEAN 即 TINYINT(1)
+func normalizeColumnType(columnType string) string {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	t = strings.ReplaceAll(t, ", ", ",")
+
+	switch t {
+	case "boolean", "bool":
+		return "tinyint(1)"
+	case "integer":
+		return "int"
+	case "decimal", "numeric":
+		return "decimal(10,0)"
+	}
+	if strings.HasPrefix(t, "tinyint(1)") {
+		return t
+	}
+	t = integerDisplayWidth.ReplaceAllString(t, "$1")
+	return strings.Replace(t, "integer", "int", 1)
+}
+
+// typeRanks 可以无损扩大的类型，序号越大容量越大
+var typeRanks = map[string]int{
+	"tinyint": 1, "smallint": 2, "mediumint": 3, "int": 4, "bigint": 5,
+	"char": 11, "varchar": 12, "tinytext": 13, "text": 14, "mediumtext": 15, "longtext": 16,
+	"tinyblob": 21, "blob": 22, "mediumblob": 23, "longblob": 24,
+	"float": 31, "double": 32,
+}
+
+// narrowsType 类型变更是否可能丢失数据：类型族不同、容量变小，或 ENUM/SET 去掉了已有的值
+func narrowsType(liveType, wantType string) bool {
+	from := normalizeColumnType(liveType)
+	to := normalizeColumnType(wantType)
+	if from == to {
+		return false
+	}
+
+	fromMatch := typeSizePattern.FindStringSubmatch(from)
+	toMatch := typeSizePattern.FindStringSubmatch(to)
+	if fromMatch == nil || toMatch == nil {
+		return true
+	}
+	fromBase, toBase := fromMatch[1], toMatch[1]
+
+	switch {
+	case fromBase == "enum" || fromBase == "set":
+		if fromBase != toBase {
+			return true
+		}
+		return !strings.Contains(to, strings.TrimSuffix(strings.TrimPrefix(from, fromBase+"("), ")"))
+	case fromBase == toBase:
+		// 同一类型比较长度和小数位
+		return atoi(toMatch[2]) < atoi(fromMatch[2]) || atoi(toMatch[3]) < atoi(fromMatch[3]) ||
+			strings.Contains(to, "unsigned") != strings.Contains(from, "unsigned")
+	}
+
+	fromRank, okFrom := typeRanks[fromBase]
+	toRank, okTo := typeRanks[toBase]
+	if !okFrom || !okTo || fromRank/10 != toRank/10 || toRank < fromRank {
+		return true
+	}
+	// CHAR/VARCHAR 改为 TEXT 类型时长度不再受限；VARCHAR 之间的长度在上面比较
+	return false
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// sameNames 不区分大小写比较名称列表
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// allDropped 索引的列是否都会被删除
+func allDropped(columns []string, dropped map[string]bool) bool {
+	for _, col := range columns {
+		if !dropped[strings.ToLower(col)] {
+			return false
+		}
+	}
+	return len(columns) > 0
+}
+
+// sameIndex 比较索引的唯一性、类型、列和前缀长度
+func sameIndex(idx IndexDef, live *liveIndex) bool {
+	if live.Unique != (idx.Unique || idx.Type == IndexUnique) || !sameNames(live.Columns, idx.Columns) {
+		return false
+	}
+
+	// BTREE 和 HASH 都是普通索引
+	wantType, liveType := "", strings.ToUpper(live.Type)
+	if idx.Type == IndexFullText || idx.Type == IndexSpatial {
+		wantType = string(idx.Type)
+	}
+	if liveType != string(IndexFullText) && liveType != string(IndexSpatial) {
+		liveType = ""
+	}
+	if wantType != liveType {
+		return false
+	}
+
+	for i, col := range idx.Columns {
+		subPart := 0
+		if i < len(live.SubParts) {
+			subPart = live.SubParts[i]
+		}
+		if idx.Lengths[col] != subPart {
+			return false
+		}
+	}
+	return true
+}
+
+// sameForeignKey 比较外键定义，列逐个比较，未指定的动作等同于 RESTRICT
+func sameForeignKey(fk ForeignKeyDef, live *liveForeignKey) bool {
+	action := func(a string) string {
+		a = strings.ToUpper(a)
+		if a == "" || a == string(ActionNoAction) {
+			return string(ActionRestrict)
+		}
+		return a
+	}
+	return sameNames(splitColumnList(fk.Column), live.Columns) &&
+		strings.EqualFold(fk.RefTable, live.RefTable) &&
+		sameNames(splitColumnList(fk.RefColumn), live.RefColumns) &&
+		action(string(fk.OnDelete)) == action(live.OnDelete) &&
+		action(string(fk.OnUpdate)) == action(live.OnUpdate)
+}
+
+// tableSchema 数据库中现有的表结构
+type tableSchema struct {
+	columns     []*liveColumn
+	indexes     []*liveIndex
+	foreignKeys []*liveForeignKey
+}
+
+type liveColumn struct {
+	Name       string
+	ColumnType string
+	Nullable   bool
+	Default    sql.NullString
+	Extra      string
+	Comment    string
+}
+
+type liveIndex struct {
+	Name     string
+	Unique   bool
+	Type     string // BTREE、HASH、FULLTEXT、SPATIAL
+	Columns  []string
+	SubParts []int // 每列的前缀长度，0 表示整列
+}
+
+type liveForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+	OnUpdate   string
+}
+
+func (s *tableSchema) column(name string) *liveColumn {
+	for _, col := range s.columns {
+		if strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return nil
+}
+
+func (s *tableSchema) index(name string) *liveIndex {
+	for _, idx := range s.indexes {
+		if strings.EqualFold(idx.Name, name) {
+			return idx
+		}
+	}
+	return nil
+}
+
+func (s *tableSchema) foreignKey(name string) *liveForeignKey {
+	for _, fk := range s.foreignKeys {
+		if strings.EqualFold(fk.Name, name) {
+			return fk
+		}
+	}
+	return nil
+}
+
+// loadTableSchema 通过检查器读取表的列、索引和外键
+func loadTableSchema(ctx context.Context, checker types.Checker, tableName string) (*tableSchema, error) {
+	if checker == nil {
+		return nil, fmt.Errorf("未设置存在性检查器")
+	}
+	schema := &tableSchema{}
+
+	table, err := checker.DescribeTable(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return nil, fmt.Errorf("表 %s 不存在", tableName)
+	}
+	for _, c := range table.Columns {
+		col := &liveColumn{
+			Name: c.Name, ColumnType: c.ColumnType, Nullable: c.Nullable,
+			Extra: c.Extra, Comment: c.Comment,
+		}
+		if c.Default != nil {
+			col.Default = sql.NullString{String: *c.Default, Valid: true}
+		}
+		schema.columns = append(schema.columns, col)
+	}
+
+	indexes, err := checker.ListIndexes(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexes {
+		schema.indexes = append(schema.indexes, &liveIndex{
+			Name: idx.Name, Unique: idx.Unique, Type: idx.Type, Columns: idx.Columns, SubParts: idx.SubParts,
+		})
+	}
+
+	foreignKeys, err := checker.ListForeignKeys(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, fk := range foreignKeys {
+		schema.foreignKeys = append(schema.foreignKeys, &liveForeignKey{
+			Name: fk.Name, Columns: fk.Columns, RefTable: fk.RefTable,
+			RefColumns: fk.RefColumns, OnDelete: fk.OnDelete, OnUpdate: fk.OnUpdate,
+		})
+	}
+	return schema, nil
+}
@@ -0,0 +1,271 @@
+package builder
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// syncTestTable 同步测试使用的表定义
+func syncTestTable() *TableBuilder {
+	tb := NewTableBuilder(nil, "posts")
+	tb.ID()
+	tb.Integer("user_id").NotNull().End()
+	tb.Integer("org_id").NotNull().End()
+	tb.String("title", 200).NotNull().End()
+	tb.Text("body").End()
+	tb.Timestamp("created_at").Default("CURRENT_TIMESTAMP").End()
+	tb.Index("title").Length("title", 100).End()
+	tb.FullText("body").End()
+	tb.ForeignKey("user_id, org_id").Name("fk_posts_member").
+		References("members", "user_id,org_id").OnDelete(ActionCascade).End()
+	return tb
+}
+
+// syncTestSchema 与 syncTestTable 一致的现有结构
+func syncTestSchema() *tableSchema {
+	return &tableSchema{
+		columns: []*liveColumn{
+			{Name: "id", ColumnType: "int(11)", Extra: "auto_increment", Comment: "主键ID"},
+			{Name: "user_id", ColumnType: "int"},
+			{Name: "org_id", ColumnType: "int"},
+			{Name: "title", ColumnType: "varchar(200)"},
+			{Name: "body", ColumnType: "text", Nullable: true},
+			{Name: "created_at", ColumnType: "timestamp", Nullable: true,
+				Default: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, Extra: "DEFAULT_GENERATED"},
+		},
+		indexes: []*liveIndex{
+			{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"id"}, SubParts: []int{0}},
+			{Name: "idx_posts_title", Type: "BTREE", Columns: []string{"title"}, SubParts: []int{100}},
+			{Name: "ft_posts_body", Type: "FULLTEXT", Columns: []string{"body"}, SubParts: []int{0}},
+			{Name: "fk_posts_member", Type: "BTREE", Columns: []string{"user_id", "org_id"}, SubParts: []int{0, 0}},
+		},
+		foreignKeys: []*liveForeignKey{
+			{Name: "fk_posts_member", Columns: []string{"user_id", "org_id"}, RefTable: "members",
+				RefColumns: []string{"user_id", "org_id"}, OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+		},
+	}
+}
+
+func TestTableDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   syncMode
+		change func(tb *TableBuilder, schema *tableSchema)
+		want   []string // 变更说明，破坏性变更以 ! 开头
+	}{
+		{
+			name:   "结构一致",
+			change: func(tb *TableBuilder, schema *tableSchema) {},
+		},
+		{
+			name: "扩大列类型",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.columns[3].ColumnType = "varchar(100)"
+			},
+			want: []string{"修改列 title（类型 varchar(100) -> VARCHAR(200)）"},
+		},
+		{
+			name: "缩小列类型",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.columns[3].ColumnType = "varchar(255)"
+			},
+			want: []string{"!修改列 title（类型 varchar(255) -> VARCHAR(200)）"},
+		},
+		{
+			name: "可空性和生成列",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.columns[1].Nullable = true
+				schema.columns[4].Extra = "VIRTUAL GENERATED"
+			},
+			want: []string{"修改列 user_id（NULL -> NOT NULL）", "修改列 body（生成列）"},
+		},
+		{
+			name: "添加和删除列",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				tb.String("slug", 64).After("title").End()
+				schema.columns = append(schema.columns, &liveColumn{Name: "legacy", ColumnType: "int", Nullable: true})
+			},
+			want: []string{"添加列 slug", "!删除列 legacy"},
+		},
+		{
+			name: "只补齐缺少的结构",
+			mode: syncEnsure,
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				tb.Index("created_at").End()
+				schema.columns[3].ColumnType = "varchar(255)"
+				schema.columns = append(schema.columns, &liveColumn{Name: "legacy", ColumnType: "int", Nullable: true})
+			},
+			want: []string{"添加索引 idx_posts_created_at"},
+		},
+		{
+			name: "索引前缀长度变化",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.indexes[1].SubParts = []int{50}
+			},
+			want: []string{"重建索引 idx_posts_title (title) -> (title)"},
+		},
+		{
+			name: "索引类型变化",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.indexes[2].Type = "BTREE"
+			},
+			want: []string{"重建索引 ft_posts_body (body) -> (body)"},
+		},
+		{
+			name: "HASH 与 BTREE 都是普通索引",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.indexes[1].Type = "HASH"
+			},
+		},
+		{
+			name: "删除多余的索引",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.indexes = append(schema.indexes, &liveIndex{Name: "idx_old", Type: "BTREE", Columns: []string{"title"}, SubParts: []int{0}})
+			},
+			want: []string{"!删除索引 idx_old"},
+		},
+		{
+			name: "外键列顺序变化",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.foreignKeys[0].Columns = []string{"org_id", "user_id"}
+			},
+			want: []string{"删除外键 fk_posts_member（重建）", "重建外键 fk_posts_member"},
+		},
+		{
+			name: "外键动作变化",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.foreignKeys[0].OnDelete = "RESTRICT"
+			},
+			want: []string{"删除外键 fk_posts_member（重建）", "重建外键 fk_posts_member"},
+		},
+		{
+			name: "删除多余的外键",
+			change: func(tb *TableBuilder, schema *tableSchema) {
+				schema.foreignKeys = append(schema.foreignKeys, &liveForeignKey{
+					Name: "fk_posts_org", Columns: []string{"org_id"}, RefTable: "orgs", RefColumns: []string{"id"},
+				})
+			},
+			want: []string{"!删除外键 fk_posts_org"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb, schema := syncTestTable(), syncTestSchema()
+			tt.change(tb, schema)
+
+			var got []string
+			for _, change := range tb.diff(schema, tt.mode) {
+				description := change.description
+				if change.destructive {
+					description = "!" + description
+				}
+				got = append(got, description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("变更为 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableDiffClauses(t *testing.T) {
+	tb, schema := syncTestTable(), syncTestSchema()
+	tb.String("slug", 64).After("title").End()
+	schema.indexes[1].SubParts = []int{50}
+	schema.foreignKeys[0].OnDelete = "RESTRICT"
+
+	want := []string{
+		"ADD COLUMN `slug` VARCHAR(64) AFTER `title`",
+		"DROP INDEX `idx_posts_title`, ADD KEY `idx_posts_title` (`title`(100))",
+		"DROP FOREIGN KEY `fk_posts_member`",
+		"ADD CONSTRAINT `fk_posts_member` FOREIGN KEY (`user_id`, `org_id`) REFERENCES `members` (`user_id`, `org_id`) ON DELETE CASCADE",
+	}
+	var got []string
+	for _, change := range tb.diff(schema, syncFull) {
+		got = append(got, change.clause)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("子句为:\n%s\n期望:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNarrowsType(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"int(11)", "INT", false},
+		{"int", "BIGINT", false},
+		{"bigint", "INT", true},
+		{"int unsigned", "INT", true},
+		{"varchar(100)", "VARCHAR(255)", false},
+		{"varchar(255)", "VARCHAR(100)", true},
+		{"varchar(255)", "TEXT", false},
+		{"text", "VARCHAR(255)", true},
+		{"decimal(10,2)", "DECIMAL(12,2)", false},
+		{"decimal(10,2)", "DECIMAL(10,1)", true},
+		{"enum('a','b')", "ENUM('a', 'b', 'c')", false},
+		{"enum('a','b')", "ENUM('a')", true},
+		{"int", "VARCHAR(20)", true},
+		{"float", "DOUBLE", false},
+		{"tinyint(1)", "BOOLEAN", false},
+	}
+	for _, tt := range tests {
+		if got := narrowsType(tt.from, tt.to); got != tt.want {
+			t.Errorf("narrowsType(%q, %q) = %t，期望 %t", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestColumnDifferences(t *testing.T) {
+	tests := []struct {
+		name string
+		col  AdvancedColumn
+		live liveColumn
+		want []string
+	}{
+		{
+			name: "DEFAULT_GENERATED 不是生成列",
+			col:  AdvancedColumn{Name: "created_at", Type: TypeTimestamp, Default: "CURRENT_TIMESTAMP"},
+			live: liveColumn{ColumnType: "timestamp", Nullable: true,
+				Default: sql.NullString{String: "current_timestamp()", Valid: true}, Extra: "DEFAULT_GENERATED"},
+		},
+		{
+			name: "存储方式不同的生成列",
+			col:  AdvancedColumn{Name: "total", Type: TypeInt, Generated: "`a` + `b`", Stored: true},
+			live: liveColumn{ColumnType: "int", Nullable: true, Extra: "VIRTUAL GENERATED"},
+			want: []string{"生成列"},
+		},
+		{
+			name: "MariaDB 带引号的字符串默认值",
+			col:  AdvancedColumn{Name: "status", Type: TypeVarchar, Size: 20, Default: "it's"},
+			live: liveColumn{ColumnType: "varchar(20)", Nullable: true, Default: sql.NullString{String: "'it''s'", Valid: true}},
+		},
+		{
+			name: "DECIMAL 默认值按数值比较",
+			col:  AdvancedColumn{Name: "price", Type: TypeDecimal, Size: 1002, NotNull: true, Default: 0},
+			live: liveColumn{ColumnType: "decimal(10,2)", Default: sql.NullString{String: "0.00", Valid: true}},
+		},
+		{
+			name: "ON UPDATE 和注释",
+			col:  AdvancedColumn{Name: "updated_at", Type: TypeTimestamp, OnUpdate: "CURRENT_TIMESTAMP", Comment: "更新时间"},
+			live: liveColumn{ColumnType: "timestamp", Nullable: true},
+			want: []string{"ON UPDATE", "注释"},
+		},
+		{
+			name: "MariaDB 的 JSON 为 LONGTEXT",
+			col:  AdvancedColumn{Name: "meta", Type: TypeJson},
+			live: liveColumn{ColumnType: "longtext", Nullable: true},
+		},
+	}
+	for _, tt := range tests {
+		live := tt.live
+		live.Name = tt.col.Name
+		if got := columnDifferences(tt.col, &live); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: 差异为 %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package checker
+
+import (
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// MetadataCache 缓存 information_schema 的查询结果，避免迁移中重复查询同一张表的元数据。
+// 缓存的结果会被多个调用方共享，调用方不能修改返回的切片和结构体
+type MetadataCache struct {
+	mu      sync.Mutex
+	entries map[string]interface{}
+}
+
+// NewMetadataCache 创建元数据缓存
+func NewMetadataCache() *MetadataCache {
+	return &MetadataCache{entries: make(map[string]interface{})}
+}
+
+// Invalidate 清空缓存，结构变更后调用
+func (c *MetadataCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]interface{})
+}
+
+// load 返回缓存的结果，没有时调用 fn 查询并缓存，查询失败不缓存
+func (c *MetadataCache) load(key string, fn func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	value, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := fn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = value
+	c.mu.Unlock()
+	return value, nil
+}
+
+// cacheProvider 携带元数据缓存的数据库连接
+type cacheProvider interface {
+	MetadataCache() *MetadataCache
+}
+
+// CachingDB 包装数据库连接，通过 Exec、Query 或 QueryRow 执行 DDL 或调用存储过程后清空元数据缓存。
+// NewMySQLChecker 收到 CachingDB 时自动使用它的缓存
+type CachingDB struct {
+	types.DB
+	cache *MetadataCache
+}
+
+// NewCachingDB 创建带元数据缓存的数据库连接
+func NewCachingDB(db types.DB, cache *MetadataCache) *CachingDB {
+	return &CachingDB{DB: db, cache: cache}
+}
+
+// MetadataCache 返回连接使用的元数据缓存
+func (d *CachingDB) MetadataCache() *MetadataCache {
+	return d.cache
+}
+
+// Exec 执行SQL语句，DDL 执行后清空缓存
+func (d *CachingDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	result, err := d.DB.Exec(query, args...)
+	if isDDL(query) {
+		d.cache.Invalidate() // 执行失败也可能已部分生效
+	}
+	return result, err
+}
+
+// Query 查询数据，DDL 和存储过程调用执行后清空缓存
+func (d *CachingDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := d.DB.Query(query, args...)
+	if isDDL(query) {
+		d.cache.Invalidate()
+	}
+	return rows, err
+}
+
+// QueryRow 查询单行数据，DDL 和存储过程调用执行后清空缓存
+func (d *CachingDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	row := d.DB.QueryRow(query, args...)
+	if isDDL(query) {
+		d.cache.Invalidate()
+	}
+	return row
+}
+
+// isDDL 判断语句是否可能改变结构，存储过程中可能执行 DDL，CALL 同样视为 DDL
+func isDDL(query string) bool {
+	switch strings.ToUpper(firstKeyword(query)) {
+	case "CREATE", "ALTER", "DROP", "RENAME", "TRUNCATE", "CALL":
+		return true
+	}
+	return false
+}
+
+// firstKeyword 跳过空白和注释，返回语句的第一个单词；
+// 条件注释（/*!50001 ... */、/*M!100100 ... */）中的内容会被执行，按语句内容处理
+func firstKeyword(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		switch {
+		case strings.HasPrefix(query, "--"), strings.HasPrefix(query, "#"):
+			end := strings.IndexByte(query, '\n')
+			if end < 0 {
+				return ""
+			}
+			query = query[end+1:]
+		case strings.HasPrefix(query, "/*!"), strings.HasPrefix(query, "/*M!"):
+			query = strings.TrimLeft(query[strings.IndexByte(query, '!')+1:], "0123456789")
+		case strings.HasPrefix(query, "*/"):
+			query = query[2:] // 空的条件注释
+		case strings.HasPrefix(query, "/*"):
+			end := strings.Index(query, "*/")
+			if end < 0 {
+				return ""
+			}
+			query = query[end+2:]
+		default:
+			end := strings.IndexFunc(query, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			})
+			if end < 0 {
+				return query
+			}
+			return query[:end]
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package checker
+
+import "testing"
+
+func TestIsDDL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"CREATE TABLE t (id INT)", true},
+		{"  alter table t add column c int", true},
+		{"-- 注释\nDROP INDEX idx ON t", true},
+		{"/* 说明 */ RENAME TABLE a TO b", true},
+		{"/*!50001 CREATE ALGORITHM=UNDEFINED VIEW v AS SELECT 1 */", true},
+		{"/*!40101 SET NAMES utf8mb4 */", false},
+		{"/*M!100100 DROP TABLE t */", true},
+		{"/*!*/ TRUNCATE t", true},
+		{"CALL add_audit_columns('orders')", true},
+		{"SELECT * FROM information_schema.TABLES", false},
+		{"INSERT INTO t VALUES (1)", false},
+		{"/* CREATE TABLE t */ SELECT 1", false},
+		{"# 注释", false},
+	}
+	for _, tt := range tests {
+		if got := isDDL(tt.query); got != tt.want {
+			t.Errorf("isDDL(%q) = %t，期望 %t", tt.query, got, tt.want)
+		}
+	}
+}
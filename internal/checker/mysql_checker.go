@@ -13,18 +13,38 @@ import (
 type MySQLChecker struct {
 	db       types.DB
 	database string
+	cache    *MetadataCache
 }
 
-// NewMySQLChecker 创建MySQL检查器
+// NewMySQLChecker 创建MySQL检查器，db 为 CachingDB 时自动使用其元数据缓存
 func NewMySQLChecker(db types.DB, database string) *MySQLChecker {
-	return &MySQLChecker{
+	checker := &MySQLChecker{
 		db:       db,
 		database: database,
 	}
+	if provider, ok := db.(cacheProvider); ok {
+		checker.cache = provider.MetadataCache()
+	}
+	return checker
+}
+
+// WithCache 使用元数据缓存，存在性检查改为按表或按库批量读取后在内存中判断
+func (c *MySQLChecker) WithCache(cache *MetadataCache) *MySQLChecker {
+	c.cache = cache
+	return c
 }
 
 // TableExists 检查表是否存在
 func (c *MySQLChecker) TableExists(ctx context.Context, tableName string) (bool, error) {
+	if c.cache != nil {
+		objects, err := c.tableTypes()
+		if err != nil {
+			return false, fmt.Errorf("检查表 %s 是否存在失败: %v", tableName, err)
+		}
+		_, exists := objects[strings.ToLower(tableName)]
+		return exists, nil
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.TABLES 
@@ -42,6 +62,14 @@ func (c *MySQLChecker) TableExists(ctx context.Context, tableName string) (bool,
 
 // ColumnExists 检查列是否存在
 func (c *MySQLChecker) ColumnExists(ctx context.Context, tableName, columnName string) (bool, error) {
+	if c.cache != nil {
+		table, err := c.DescribeTable(ctx, tableName)
+		if err != nil {
+			return false, fmt.Errorf("检查列 %s.%s 是否存在失败: %v", tableName, columnName, err)
+		}
+		return table != nil && table.Column(columnName) != nil, nil
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.COLUMNS 
@@ -59,6 +87,19 @@ func (c *MySQLChecker) ColumnExists(ctx context.Context, tableName, columnName s
 
 // IndexExists 检查索引是否存在
 func (c *MySQLChecker) IndexExists(ctx context.Context, tableName, indexName string) (bool, error) {
+	if c.cache != nil {
+		indexes, err := c.ListIndexes(ctx, tableName)
+		if err != nil {
+			return false, fmt.Errorf("检查索引 %s 是否存在失败: %v", indexName, err)
+		}
+		for _, idx := range indexes {
+			if strings.EqualFold(idx.Name, indexName) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.STATISTICS 
@@ -76,6 +117,10 @@ func (c *MySQLChecker) IndexExists(ctx context.Context, tableName, indexName str
 
 // FunctionExists 检查函数是否存在
 func (c *MySQLChecker) FunctionExists(ctx context.Context, functionName string) (bool, error) {
+	if c.cache != nil {
+		return c.routineExists(functionName, "FUNCTION")
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.ROUTINES 
@@ -93,6 +138,10 @@ func (c *MySQLChecker) FunctionExists(ctx context.Context, functionName string)
 
 // ProcedureExists 检查存储过程是否存在
 func (c *MySQLChecker) ProcedureExists(ctx context.Context, procedureName string) (bool, error) {
+	if c.cache != nil {
+		return c.routineExists(procedureName, "PROCEDURE")
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.ROUTINES 
@@ -110,6 +159,14 @@ func (c *MySQLChecker) ProcedureExists(ctx context.Context, procedureName string
 
 // ConstraintExists 检查约束是否存在
 func (c *MySQLChecker) ConstraintExists(ctx context.Context, tableName, constraintName string) (bool, error) {
+	if c.cache != nil {
+		constraints, err := c.tableConstraints(tableName)
+		if err != nil {
+			return false, fmt.Errorf("检查约束 %s 是否存在失败: %v", constraintName, err)
+		}
+		return constraints[strings.ToLower(constraintName)], nil
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.TABLE_CONSTRAINTS 
@@ -127,6 +184,16 @@ func (c *MySQLChecker) ConstraintExists(ctx context.Context, tableName, constrai
 
 // TriggerExists 检查触发器是否存在
 func (c *MySQLChecker) TriggerExists(ctx context.Context, triggerName string) (bool, error) {
+	if c.cache != nil {
+		value, err := c.cache.load("triggers:"+c.database, func() (interface{}, error) {
+			return c.nameSet(`SELECT TRIGGER_NAME FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA = ?`, c.database)
+		})
+		if err != nil {
+			return false, fmt.Errorf("检查触发器 %s 是否存在失败: %v", triggerName, err)
+		}
+		return value.(map[string]bool)[strings.ToLower(triggerName)], nil
+	}
+
 	query := `
 		SELECT COUNT(*) 
 		FROM information_schema.TRIGGERS 
@@ -142,6 +209,342 @@ func (c *MySQLChecker) TriggerExists(ctx context.Context, triggerName string) (b
 	return count > 0, nil
 }
 
+// ViewExists 检查视图是否存在
+func (c *MySQLChecker) ViewExists(ctx context.Context, viewName string) (bool, error) {
+	if c.cache != nil {
+		objects, err := c.tableTypes()
+		if err != nil {
+			return false, fmt.Errorf("检查视图 %s 是否存在失败: %v", viewName, err)
+		}
+		return objects[strings.ToLower(viewName)] == "VIEW", nil
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM information_schema.VIEWS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+	`
+
+	var count int
+	err := c.db.QueryRow(query, c.database, viewName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("检查视图 %s 是否存在失败: %v", viewName, err)
+	}
+
+	return count > 0, nil
+}
+
+// DatabaseExists 检查数据库是否存在
+func (c *MySQLChecker) DatabaseExists(ctx context.Context, databaseName string) (bool, error) {
+	value, err := c.cached("database:"+strings.ToLower(databaseName), func() (interface{}, error) {
+		var count int
+		err := c.db.QueryRow(`
+			SELECT COUNT(*)
+			FROM information_schema.SCHEMATA
+			WHERE SCHEMA_NAME = ?
+		`, databaseName).Scan(&count)
+		return count > 0, err
+	})
+	if err != nil {
+		return false, fmt.Errorf("检查数据库 %s 是否存在失败: %v", databaseName, err)
+	}
+	return value.(bool), nil
+}
+
+// DescribeTable 获取表的选项和列，表不存在时返回 nil
+func (c *MySQLChecker) DescribeTable(ctx context.Context, tableName string) (*types.TableInfo, error) {
+	value, err := c.cached("table:"+c.database+"."+strings.ToLower(tableName), func() (interface{}, error) {
+		return c.describeTable(tableName)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取表 %s 结构失败: %v", tableName, err)
+	}
+	return value.(*types.TableInfo), nil
+}
+
+func (c *MySQLChecker) describeTable(tableName string) (*types.TableInfo, error) {
+	table := &types.TableInfo{}
+	var engine, collation, charset, rowFormat, comment sql.NullString
+	var autoIncrement sql.NullInt64
+	err := c.db.QueryRow(`
+		SELECT t.TABLE_NAME, t.TABLE_TYPE, t.ENGINE, t.TABLE_COLLATION, cs.CHARACTER_SET_NAME,
+		       t.ROW_FORMAT, t.AUTO_INCREMENT, t.TABLE_COMMENT
+		FROM information_schema.TABLES t
+		LEFT JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY cs
+		       ON cs.COLLATION_NAME = t.TABLE_COLLATION
+		WHERE t.TABLE_SCHEMA = ? AND t.TABLE_NAME = ?
+	`, c.database, tableName).Scan(&table.Name, &table.Type, &engine, &collation, &charset,
+		&rowFormat, &autoIncrement, &comment)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	table.Engine = engine.String
+	table.Collation = collation.String
+	table.Charset = charset.String
+	table.RowFormat = rowFormat.String
+	table.AutoIncrement = autoIncrement.Int64
+	table.Comment = comment.String
+
+	rows, err := c.db.Query(`
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA,
+		       COLUMN_COMMENT, COALESCE(GENERATION_EXPRESSION, '')
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`, c.database, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col types.ColumnInfo
+		var nullable string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&col.Name, &col.DataType, &col.ColumnType, &nullable, &defaultValue,
+			&col.Extra, &col.Comment, &col.Generated); err != nil {
+			return nil, err
+		}
+		col.Nullable = nullable == "YES"
+		if defaultValue.Valid {
+			col.Default = &defaultValue.String
+		}
+		table.Columns = append(table.Columns, col)
+	}
+	return table, rows.Err()
+}
+
+// ListIndexes 获取表的索引（包括主键），按索引名排序
+func (c *MySQLChecker) ListIndexes(ctx context.Context, tableName string) ([]types.IndexInfo, error) {
+	value, err := c.cached("indexes:"+c.database+"."+strings.ToLower(tableName), func() (interface{}, error) {
+		return c.listIndexes(tableName)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取表 %s 索引失败: %v", tableName, err)
+	}
+	return value.([]types.IndexInfo), nil
+}
+
+func (c *MySQLChecker) listIndexes(tableName string) ([]types.IndexInfo, error) {
+	rows, err := c.db.Query(`
+		SELECT INDEX_NAME, NON_UNIQUE, COLUMN_NAME, SUB_PART, INDEX_TYPE
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX
+	`, c.database, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []types.IndexInfo
+	for rows.Next() {
+		var name, indexType string
+		var nonUnique int
+		var column sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&name, &nonUnique, &column, &subPart, &indexType); err != nil {
+			return nil, err
+		}
+		if n := len(indexes); n == 0 || indexes[n-1].Name != name {
+			indexes = append(indexes, types.IndexInfo{Name: name, Unique: nonUnique == 0, Type: strings.ToUpper(indexType)})
+		}
+		idx := &indexes[len(indexes)-1]
+		idx.Columns = append(idx.Columns, column.String) // 函数索引的 COLUMN_NAME 为 NULL
+		idx.SubParts = append(idx.SubParts, int(subPart.Int64))
+	}
+	return indexes, rows.Err()
+}
+
+// ListForeignKeys 获取表的外键，按外键名排序
+func (c *MySQLChecker) ListForeignKeys(ctx context.Context, tableName string) ([]types.ForeignKeyInfo, error) {
+	value, err := c.cached("foreign_keys:"+c.database+"."+strings.ToLower(tableName), func() (interface{}, error) {
+		return c.listForeignKeys(tableName)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取表 %s 外键失败: %v", tableName, err)
+	}
+	return value.([]types.ForeignKeyInfo), nil
+}
+
+func (c *MySQLChecker) listForeignKeys(tableName string) ([]types.ForeignKeyInfo, error) {
+	rows, err := c.db.Query(`
+		SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME,
+		       k.REFERENCED_COLUMN_NAME, r.DELETE_RULE, r.UPDATE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		  ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION
+	`, c.database, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foreignKeys []types.ForeignKeyInfo
+	for rows.Next() {
+		var name, column, refSchema, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&name, &column, &refSchema, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+		if n := len(foreignKeys); n == 0 || foreignKeys[n-1].Name != name {
+			if refSchema != c.database {
+				refTable = refSchema + "." + refTable // 引用其他库的表时带上库名
+			}
+			foreignKeys = append(foreignKeys, types.ForeignKeyInfo{
+				Name: name, RefTable: refTable, OnDelete: onDelete, OnUpdate: onUpdate,
+			})
+		}
+		fk := &foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+	return foreignKeys, rows.Err()
+}
+
+// ListTables 获取所有表名（不包括视图），按名称排序
+func (c *MySQLChecker) ListTables(ctx context.Context) ([]string, error) {
+	value, err := c.cached("tables:"+c.database, func() (interface{}, error) {
+		rows, err := c.db.Query(`
+			SELECT TABLE_NAME
+			FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'
+			ORDER BY TABLE_NAME
+		`, c.database)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		var tables []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			tables = append(tables, name)
+		}
+		return tables, rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取表列表失败: %v", err)
+	}
+	return value.([]string), nil
+}
+
+// cached 有缓存时从缓存读取，没有缓存时直接查询
+func (c *MySQLChecker) cached(key string, load func() (interface{}, error)) (interface{}, error) {
+	if c.cache == nil {
+		return load()
+	}
+	return c.cache.load(key, load)
+}
+
+// tableTypes 库中所有表和视图的名称（小写）到类型的映射，只在有缓存时使用
+func (c *MySQLChecker) tableTypes() (map[string]string, error) {
+	value, err := c.cache.load("objects:"+c.database, func() (interface{}, error) {
+		rows, err := c.db.Query(`
+			SELECT TABLE_NAME, TABLE_TYPE
+			FROM information_schema.TABLES
+			WHERE TABLE_SCHEMA = ?
+		`, c.database)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		objects := make(map[string]string)
+		for rows.Next() {
+			var name, tableType string
+			if err := rows.Scan(&name, &tableType); err != nil {
+				return nil, err
+			}
+			objects[strings.ToLower(name)] = tableType
+		}
+		return objects, rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]string), nil
+}
+
+// routineExists 从缓存的函数和存储过程列表中查找，只在有缓存时使用
+func (c *MySQLChecker) routineExists(name, routineType string) (bool, error) {
+	value, err := c.cache.load("routines:"+c.database, func() (interface{}, error) {
+		return c.nameSet(`
+			SELECT CONCAT(ROUTINE_TYPE, ':', ROUTINE_NAME)
+			FROM information_schema.ROUTINES
+			WHERE ROUTINE_SCHEMA = ?
+		`, c.database)
+	})
+	if err != nil {
+		return false, fmt.Errorf("检查 %s %s 是否存在失败: %v", routineType, name, err)
+	}
+	return value.(map[string]bool)[strings.ToLower(routineType+":"+name)], nil
+}
+
+// tableConstraints 缓存的表约束名集合，只在有缓存时使用
+func (c *MySQLChecker) tableConstraints(tableName string) (map[string]bool, error) {
+	value, err := c.cache.load("constraints:"+c.database+"."+strings.ToLower(tableName), func() (interface{}, error) {
+		return c.nameSet(`
+			SELECT CONSTRAINT_NAME
+			FROM information_schema.TABLE_CONSTRAINTS
+			WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		`, c.database, tableName)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]bool), nil
+}
+
+// nameSet 查询单列名称，返回小写名称集合
+func (c *MySQLChecker) nameSet(query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names, rows.Err()
+}
+
+// PrimaryKeyColumns 获取当前库中表的主键列，按主键中的顺序返回，没有主键时返回空
+func PrimaryKeyColumns(db types.DB, tableName string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY ORDINAL_POSITION
+	`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
 // GetTableColumns 获取表的所有列信息
 func (c *MySQLChecker) GetTableColumns(ctx context.Context, tableName string) ([]ColumnInfo, error) {
 	query := `
@@ -0,0 +1,350 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
+)
+
+// MigrationOptions 基线迁移的生成选项
+type MigrationOptions struct {
+	Package     string // 包名，默认 migrations
+	Database    string // 来源数据库，只用于注释
+	Target      string // 目标数据库，非空时生成 Database() 方法，迁移只应用到该数据库
+	Version     string
+	Name        string // 迁移结构体名前缀，如 Baseline
+	Description string
+}
+
+// builderTypes 与 builder 包中常量同名的列类型
+var builderTypes = map[string]string{
+	"INT": "TypeInt", "BIGINT": "TypeBigInt", "SMALLINT": "TypeSmallInt", "TINYINT": "TypeTinyInt",
+	"FLOAT": "TypeFloat", "DOUBLE": "TypeDouble", "TEXT": "TypeText", "LONGTEXT": "TypeLongText",
+	"JSON": "TypeJson", "DATE": "TypeDate", "DATETIME": "TypeDateTime", "TIMESTAMP": "TypeTimestamp",
+	"TIME": "TypeTime", "BLOB": "TypeBlob", "LONGBLOB": "TypeLongBlob",
+}
+
+// builderActions 外键动作对应的 builder 常量
+var builderActions = map[string]string{
+	"CASCADE":     "ActionCascade",
+	"SET NULL":    "ActionSetNull",
+	"SET DEFAULT": "ActionSetDefault",
+}
+
+// GenerateMigration 生成用 builder.AdvancedBuilder 重建表结构的基线迁移源码，
+// 表按外键依赖排序，Down 按相反顺序删除
+func GenerateMigration(opts MigrationOptions, tables []*Table) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "migrations"
+	}
+	if opts.Name == "" {
+		opts.Name = "Baseline"
+	}
+	if opts.Description == "" {
+		opts.Description = fmt.Sprintf("数据库 %s 的基线结构", opts.Database)
+	}
+
+	ordered, cyclic := sortByDependency(tables)
+	typeName := GoName(opts.Name) + "Migration"
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "// %s %s\n", typeName, singleLine(opts.Description))
+	fmt.Fprintf(&src, "type %s struct {\n\tmigrator.BaseContextMigration\n}\n\n", typeName)
+	fmt.Fprintf(&src, "// Version 返回迁移版本\nfunc (m *%s) Version() string {\n\treturn %q\n}\n\n", typeName, opts.Version)
+	fmt.Fprintf(&src, "// Description 返回迁移描述\nfunc (m *%s) Description() string {\n\treturn %q\n}\n\n", typeName, singleLine(opts.Description))
+	if opts.Target != "" {
+		fmt.Fprintf(&src, "// Database 返回目标数据库\nfunc (m *%s) Database() string {\n\treturn %q\n}\n\n", typeName, opts.Target)
+		fmt.Fprintf(&src, "// Databases 返回目标数据库列表\nfunc (m *%s) Databases() []string {\n\treturn nil\n}\n\n", typeName)
+	}
+
+	fmt.Fprintf(&src, "// UpWithContext 创建 %d 张表\n", len(ordered))
+	fmt.Fprintf(&src, "func (m *%s) UpWithContext(mc *migrator.MigrationContext) error {\n", typeName)
+	src.WriteString("\tab := mc.Advanced\n")
+	if len(cyclic) > 0 {
+		fmt.Fprintf(&src, "\n\t// 注意：表 %s 之间存在循环外键，需要调整创建顺序或拆出外键\n", strings.Join(cyclic, ", "))
+	}
+	for _, table := range ordered {
+		src.WriteString("\n")
+		writeTableChain(&src, table)
+	}
+	src.WriteString("\n\treturn nil\n}\n\n")
+
+	src.WriteString("// DownWithContext 按依赖的相反顺序删除表\n")
+	fmt.Fprintf(&src, "func (m *%s) DownWithContext(mc *migrator.MigrationContext) error {\n", typeName)
+	if len(ordered) > 0 {
+		src.WriteString("\ttables := []string{\n")
+		for i := len(ordered) - 1; i >= 0; i-- {
+			fmt.Fprintf(&src, "\t\t%q,\n", dialect.MySQL.QuoteIdent(ordered[i].Name))
+		}
+		src.WriteString("\t}\n")
+		src.WriteString("\tfor _, table := range tables {\n")
+		src.WriteString("\t\tif _, err := mc.DB.Exec(\"DROP TABLE IF EXISTS \" + table); err != nil {\n")
+		src.WriteString("\t\t\treturn fmt.Errorf(\"删除表 %s 失败: %v\", table, err)\n\t\t}\n\t}\n")
+	}
+	src.WriteString("\treturn nil\n}\n")
+
+	// 只有用到 builder 的常量时才导入 builder
+	var file strings.Builder
+	fmt.Fprintf(&file, "// Code generated by db-migrator codegen migration from database %s.\n\n", opts.Database)
+	fmt.Fprintf(&file, "package %s\n\n", opts.Package)
+	file.WriteString("import (\n\t\"fmt\"\n\n")
+	if strings.Contains(src.String(), "builder.") {
+		file.WriteString("\t\"github.com/xiezhihuan/db-migrator/internal/builder\"\n")
+	}
+	file.WriteString("\t\"github.com/xiezhihuan/db-migrator/internal/migrator\"\n)\n\n")
+	file.WriteString(src.String())
+
+	formatted, err := format.Source([]byte(file.String()))
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %v", err)
+	}
+	return formatted, nil
+}
+
+// writeTableChain 写出一张表的 ab.Table(...)...Create(mc) 链式调用
+func writeTableChain(w *strings.Builder, table *Table) {
+	var notes []string
+	var calls []string
+
+	singlePK := ""
+	if len(table.PrimaryKey) == 1 {
+		singlePK = table.PrimaryKey[0]
+	}
+	for _, col := range table.Columns {
+		call, columnNotes := columnChain(col, strings.EqualFold(col.Name, singlePK))
+		calls = append(calls, call)
+		notes = append(notes, columnNotes...)
+	}
+
+	if len(table.PrimaryKey) > 1 {
+		calls = append(calls, fmt.Sprintf("PrimaryKey(%s)", quoteArgs(table.PrimaryKey)))
+	}
+	for _, idx := range table.Indexes {
+		method := "Index"
+		switch {
+		case idx.Type == "FULLTEXT":
+			method = "FullText"
+		case idx.Type == "SPATIAL":
+			method = "Spatial"
+		case idx.Unique:
+			method = "Unique"
+		}
+		call := fmt.Sprintf("%s(%s).Name(%q)", method, quoteArgs(idx.Columns), idx.Name)
+		for _, column := range idx.Columns {
+			if length, ok := idx.Lengths[column]; ok {
+				call += fmt.Sprintf(".Length(%q, %d)", column, length)
+			}
+		}
+		calls = append(calls, call+".End()")
+	}
+	for _, fk := range table.ForeignKeys {
+		call := fmt.Sprintf("ForeignKey(%q).Name(%q).References(%q, %q)",
+			strings.Join(fk.Columns, ","), fk.Name, fk.RefTable, strings.Join(fk.RefColumns, ","))
+		if action, ok := builderActions[strings.ToUpper(fk.OnDelete)]; ok {
+			call += fmt.Sprintf(".OnDelete(builder.%s)", action)
+		}
+		if action, ok := builderActions[strings.ToUpper(fk.OnUpdate)]; ok {
+			call += fmt.Sprintf(".OnUpdate(builder.%s)", action)
+		}
+		calls = append(calls, call+".End()")
+	}
+	for _, check := range table.Checks {
+		calls = append(calls, fmt.Sprintf("Check(%q, %q)", check.Name, check.Expression))
+	}
+	if table.Engine != "" {
+		calls = append(calls, fmt.Sprintf("Engine(%q)", table.Engine))
+	}
+	if table.Charset != "" {
+		calls = append(calls, fmt.Sprintf("Charset(%q)", table.Charset))
+	}
+	if table.Collation != "" {
+		calls = append(calls, fmt.Sprintf("Collation(%q)", table.Collation))
+	}
+	if table.Comment != "" {
+		calls = append(calls, fmt.Sprintf("Comment(%q)", table.Comment))
+	}
+
+	for _, note := range notes {
+		fmt.Fprintf(w, "\t// 注意：%s\n", note)
+	}
+	fmt.Fprintf(w, "\tif err := ab.Table(%q).\n", table.Name)
+	for _, call := range calls {
+		fmt.Fprintf(w, "\t\t%s.\n", call)
+	}
+	w.WriteString("\t\tCreate(mc); err != nil {\n")
+	fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"创建表 %s 失败: %%v\", err)\n\t}\n", strings.ReplaceAll(table.Name, "%", "%%"))
+}
+
+// columnChain 一列的链式调用，如 String("name", 64).NotNull().Comment("名称").End()
+func columnChain(col *Column, primaryKey bool) (string, []string) {
+	var notes []string
+	call := columnConstructor(col)
+
+	if col.Zerofill {
+		call += ".Zerofill()"
+	} else if col.Unsigned {
+		call += ".Unsigned()"
+	}
+	if col.Generated != "" {
+		call += fmt.Sprintf(".GeneratedAs(%q)", col.Generated)
+		if col.Stored {
+			call += ".Stored()"
+		}
+	}
+	if primaryKey {
+		call += ".PrimaryKey()"
+	} else if !col.Nullable {
+		call += ".NotNull()"
+	}
+	if col.AutoIncrement {
+		call += ".AutoIncrement()"
+	}
+	if col.Default != nil && col.Generated == "" {
+		switch {
+		case col.DefaultExpr && strings.HasPrefix(strings.ToUpper(*col.Default), "CURRENT_TIMESTAMP"):
+			call += fmt.Sprintf(".Default(%q)", *col.Default)
+		case col.DefaultExpr || col.DataType == "bit":
+			notes = append(notes, fmt.Sprintf("列 %s 的默认值 %s 需要手动设置", col.Name, *col.Default))
+		default:
+			call += fmt.Sprintf(".Default(%s)", defaultLiteral(col))
+		}
+	}
+	if col.OnUpdate != "" {
+		call += fmt.Sprintf(".OnUpdate(%q)", col.OnUpdate)
+	}
+	if col.Comment != "" {
+		call += fmt.Sprintf(".Comment(%q)", col.Comment)
+	}
+	return call + ".End()", notes
+}
+
+// columnConstructor 选择与列类型对应的 TableBuilder 方法，没有专用方法时使用 Column
+func columnConstructor(col *Column) string {
+	if col.Zerofill {
+		// 零填充需要保留显示宽度
+		return fmt.Sprintf("Column(%q, builder.ColumnType(%q), 0)", col.Name, rawColumnType(col))
+	}
+
+	switch col.DataType {
+	case "varchar":
+		return fmt.Sprintf("String(%q, %d)", col.Name, col.Length)
+	case "text":
+		return fmt.Sprintf("Text(%q)", col.Name)
+	case "int", "integer":
+		return fmt.Sprintf("Integer(%q)", col.Name)
+	case "bigint":
+		return fmt.Sprintf("BigInteger(%q)", col.Name)
+	case "decimal", "numeric":
+		return fmt.Sprintf("Decimal(%q, %d, %d)", col.Name, col.Length, col.Scale)
+	case "tinyint":
+		if col.Length == 1 && !col.Unsigned {
+			return fmt.Sprintf("Boolean(%q)", col.Name)
+		}
+	case "datetime", "timestamp":
+		if col.Length == 0 {
+			method := "DateTime"
+			if col.DataType == "timestamp" {
+				method = "Timestamp"
+			}
+			return fmt.Sprintf("%s(%q)", method, col.Name)
+		}
+	case "date":
+		return fmt.Sprintf("Date(%q)", col.Name)
+	case "json":
+		return fmt.Sprintf("Json(%q)", col.Name)
+	case "enum":
+		// Enum 用默认值字段暂存枚举值，有默认值时改用完整类型
+		if col.Default == nil {
+			return fmt.Sprintf("Enum(%q, %s)", col.Name, stringSlice(col.Values))
+		}
+	}
+
+	columnType := rawColumnType(col)
+	if constant, ok := builderTypes[columnType]; ok {
+		return fmt.Sprintf("Column(%q, builder.%s, 0)", col.Name, constant)
+	}
+	return fmt.Sprintf("Column(%q, builder.ColumnType(%q), 0)", col.Name, columnType)
+}
+
+// rawColumnType 去掉 unsigned、zerofill 后的完整类型，类型名大写，括号内的内容保持原样
+func rawColumnType(col *Column) string {
+	columnType := col.ColumnType
+	for _, modifier := range []string{" zerofill", " unsigned"} {
+		if i := strings.LastIndex(strings.ToLower(columnType), modifier); i >= 0 && !strings.Contains(columnType[i:], ")") {
+			columnType = columnType[:i] + columnType[i+len(modifier):]
+		}
+	}
+	if i := strings.Index(columnType, "("); i >= 0 {
+		return strings.ToUpper(columnType[:i]) + columnType[i:]
+	}
+	return strings.ToUpper(columnType)
+}
+
+// defaultLiteral 默认值的 Go 字面量，数值列生成数字，其余生成字符串
+func defaultLiteral(col *Column) string {
+	value := *col.Default
+	switch col.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value
+		}
+	case "decimal", "numeric", "float", "double", "real":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			literal := strconv.FormatFloat(f, 'f', -1, 64)
+			if !strings.Contains(literal, ".") {
+				literal += ".0"
+			}
+			return literal
+		}
+	}
+	return strconv.Quote(value)
+}
+
+// sortByDependency 按外键依赖排序，被引用的表在前；返回存在循环依赖的表
+func sortByDependency(tables []*Table) ([]*Table, []string) {
+	byName := make(map[string]*Table)
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	var ordered []*Table
+	var cyclic []string
+	state := make(map[string]int) // 1 访问中，2 已完成
+	var visit func(table *Table)
+	visit = func(table *Table) {
+		switch state[table.Name] {
+		case 1:
+			cyclic = append(cyclic, table.Name)
+			return
+		case 2:
+			return
+		}
+		state[table.Name] = 1
+		for _, fk := range table.ForeignKeys {
+			if ref, ok := byName[fk.RefTable]; ok && ref != table {
+				visit(ref)
+			}
+		}
+		state[table.Name] = 2
+		ordered = append(ordered, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return ordered, cyclic
+}
+
+func quoteArgs(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func stringSlice(values []string) string {
+	return "[]string{" + quoteArgs(values) + "}"
+}
@@ -0,0 +1,382 @@
+// Package codegen 读取已有数据库的结构，生成带 db 标签的 Go 结构体、重建结构的基线迁移和用于比较的结构快照
+package codegen
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// Table 表结构
+type Table struct {
+	Name        string        `json:"name"`
+	Engine      string        `json:"engine,omitempty"`
+	Charset     string        `json:"charset,omitempty"`
+	Collation   string        `json:"collation,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+	Columns     []*Column     `json:"columns"`
+	PrimaryKey  []string      `json:"primary_key,omitempty"`
+	Indexes     []*Index      `json:"indexes,omitempty"`
+	ForeignKeys []*ForeignKey `json:"foreign_keys,omitempty"`
+	Checks      []*Check      `json:"checks,omitempty"`
+}
+
+// Column 列结构
+type Column struct {
+	Name          string   `json:"name"`
+	DataType      string   `json:"data_type"`   // 小写的基本类型，如 varchar、int
+	ColumnType    string   `json:"column_type"` // 完整类型，如 varchar(64)、int unsigned
+	Nullable      bool     `json:"nullable"`
+	Default       *string  `json:"default,omitempty"`      // 默认值，NULL 或没有默认值时为 nil
+	DefaultExpr   bool     `json:"default_expr,omitempty"` // 默认值是表达式（如 CURRENT_TIMESTAMP），不是字面量
+	AutoIncrement bool     `json:"auto_increment,omitempty"`
+	OnUpdate      string   `json:"on_update,omitempty"`
+	Generated     string   `json:"generated,omitempty"` // 生成列表达式
+	Stored        bool     `json:"stored,omitempty"`
+	Comment       string   `json:"comment,omitempty"`
+	Unsigned      bool     `json:"unsigned,omitempty"`
+	Zerofill      bool     `json:"zerofill,omitempty"`
+	Length        int      `json:"length,omitempty"` // 类型括号中的第一个数字：字符长度、DECIMAL 精度、时间精度
+	Scale         int      `json:"scale,omitempty"`  // DECIMAL 小数位
+	Values        []string `json:"values,omitempty"`
+}
+
+// Index 索引结构（不包括主键）
+type Index struct {
+	Name    string         `json:"name"`
+	Columns []string       `json:"columns"`
+	Lengths map[string]int `json:"lengths,omitempty"`
+	Unique  bool           `json:"unique"`
+	Type    string         `json:"type"` // BTREE、FULLTEXT、SPATIAL
+}
+
+// ForeignKey 外键结构
+type ForeignKey struct {
+	Name       string   `json:"name"`
+	Columns    []string `json:"columns"`
+	RefTable   string   `json:"ref_table"`
+	RefColumns []string `json:"ref_columns"`
+	OnDelete   string   `json:"on_delete,omitempty"`
+	OnUpdate   string   `json:"on_update,omitempty"`
+}
+
+// Check CHECK 约束
+type Check struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// Column 按名称查找列
+func (t *Table) Column(name string) *Column {
+	for _, col := range t.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return nil
+}
+
+// typeArgsPattern 类型括号中的参数，如 decimal(10,2) 中的 10 和 2
+var typeArgsPattern = regexp.MustCompile(`^\w+\((\d+)(?:,(\d+))?\)`)
+
+// LoadSchema 从 information_schema 读取数据库中的表结构，tables 为空时读取所有表，按表名排序
+func LoadSchema(db types.DB, database string, tables []string) ([]*Table, error) {
+	wanted := make(map[string]bool)
+	for _, table := range tables {
+		wanted[strings.ToLower(table)] = true
+	}
+
+	var result []*Table
+	byName := make(map[string]*Table)
+
+	rows, err := db.Query(`
+		SELECT t.TABLE_NAME, COALESCE(t.ENGINE, ''), COALESCE(c.CHARACTER_SET_NAME, ''),
+		       COALESCE(t.TABLE_COLLATION, ''), COALESCE(t.TABLE_COMMENT, '')
+		FROM information_schema.TABLES t
+		LEFT JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY c
+		       ON c.COLLATION_NAME = t.TABLE_COLLATION
+		WHERE t.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'
+		ORDER BY t.TABLE_NAME`, database)
+	if err != nil {
+		return nil, fmt.Errorf("查询表失败: %v", err)
+	}
+	for rows.Next() {
+		table := &Table{}
+		if err := rows.Scan(&table.Name, &table.Engine, &table.Charset, &table.Collation, &table.Comment); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("读取表信息失败: %v", err)
+		}
+		if len(wanted) > 0 && !wanted[strings.ToLower(table.Name)] {
+			continue
+		}
+		result = append(result, table)
+		byName[strings.ToLower(table.Name)] = table
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("查询表失败: %v", err)
+	}
+
+	for _, table := range tables {
+		if byName[strings.ToLower(table)] == nil {
+			return nil, fmt.Errorf("表 %s 不存在", table)
+		}
+	}
+
+	if err := loadColumns(db, database, byName); err != nil {
+		return nil, err
+	}
+	if err := loadIndexes(db, database, byName); err != nil {
+		return nil, err
+	}
+	if err := loadForeignKeys(db, database, byName); err != nil {
+		return nil, err
+	}
+	loadChecks(db, database, byName)
+
+	return result, nil
+}
+
+func loadColumns(db types.DB, database string, tables map[string]*Table) error {
+	rows, err := db.Query(`
+		SELECT TABLE_NAME, COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT,
+		       EXTRA, COALESCE(COLUMN_COMMENT, ''), COALESCE(GENERATION_EXPRESSION, '')
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION`, database)
+	if err != nil {
+		return fmt.Errorf("查询列失败: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName, nullable, extra string
+		var defaultValue sql.NullString
+		col := &Column{}
+		if err := rows.Scan(&tableName, &col.Name, &col.DataType, &col.ColumnType, &nullable,
+			&defaultValue, &extra, &col.Comment, &col.Generated); err != nil {
+			return fmt.Errorf("读取列信息失败: %v", err)
+		}
+		table := tables[strings.ToLower(tableName)]
+		if table == nil {
+			continue
+		}
+
+		col.DataType = strings.ToLower(col.DataType)
+		col.Nullable = nullable == "YES"
+		columnType := strings.ToLower(col.ColumnType)
+		col.Unsigned = strings.Contains(columnType, "unsigned")
+		col.Zerofill = strings.Contains(columnType, "zerofill")
+		if match := typeArgsPattern.FindStringSubmatch(columnType); match != nil {
+			col.Length, _ = strconv.Atoi(match[1])
+			if match[2] != "" {
+				col.Scale, _ = strconv.Atoi(match[2])
+			}
+		}
+		if col.DataType == "enum" || col.DataType == "set" {
+			col.Values = parseEnumValues(col.ColumnType)
+		}
+
+		extra = strings.ToLower(extra)
+		col.AutoIncrement = strings.Contains(extra, "auto_increment")
+		if i := strings.Index(extra, "on update "); i >= 0 {
+			col.OnUpdate = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(extra[i+len("on update "):]), "()"))
+		}
+		if col.Generated != "" {
+			col.Stored = strings.Contains(extra, "stored")
+		}
+		col.Default, col.DefaultExpr = columnDefault(defaultValue, extra, col.DataType)
+
+		table.Columns = append(table.Columns, col)
+	}
+	return rows.Err()
+}
+
+// columnDefault 解析默认值：MariaDB 的字符串默认值带引号，表达式不带；MySQL 8 的表达式默认值在 EXTRA 中标记 DEFAULT_GENERATED
+func columnDefault(value sql.NullString, extra, dataType string) (*string, bool) {
+	if !value.Valid || strings.EqualFold(value.String, "NULL") {
+		return nil, false
+	}
+	text := value.String
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		text = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+		return &text, false
+	}
+
+	upper := strings.ToUpper(text)
+	isExpr := strings.Contains(extra, "default_generated") ||
+		strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.HasPrefix(upper, "NOW(")
+	if isExpr && strings.HasPrefix(upper, "CURRENT_TIMESTAMP") {
+		text = strings.TrimSuffix(upper, "()")
+	}
+	return &text, isExpr
+}
+
+// parseEnumValues 解析 enum('a','b') 中的值，还原值中转义的单引号
+func parseEnumValues(columnType string) []string {
+	open := strings.Index(columnType, "(")
+	close := strings.LastIndex(columnType, ")")
+	if open < 0 || close <= open {
+		return nil
+	}
+
+	var values []string
+	body := columnType[open+1 : close]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\'' {
+			continue
+		}
+		var sb strings.Builder
+		for i++; i < len(body); i++ {
+			if body[i] == '\'' {
+				if i+1 < len(body) && body[i+1] == '\'' {
+					sb.WriteByte('\'')
+					i++
+					continue
+				}
+				break
+			}
+			sb.WriteByte(body[i])
+		}
+		values = append(values, sb.String())
+	}
+	return values
+}
+
+func loadIndexes(db types.DB, database string, tables map[string]*Table) error {
+	rows, err := db.Query(`
+		SELECT TABLE_NAME, INDEX_NAME, NON_UNIQUE, COLUMN_NAME, SUB_PART, INDEX_TYPE
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`, database)
+	if err != nil {
+		return fmt.Errorf("查询索引失败: %v", err)
+	}
+	defer rows.Close()
+
+	functional := make(map[string]bool) // 函数索引无法用列表示，整体跳过
+	indexes := make(map[string]*Index)
+	for rows.Next() {
+		var tableName, indexName, indexType string
+		var nonUnique int
+		var columnName sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&tableName, &indexName, &nonUnique, &columnName, &subPart, &indexType); err != nil {
+			return fmt.Errorf("读取索引信息失败: %v", err)
+		}
+		table := tables[strings.ToLower(tableName)]
+		if table == nil {
+			continue
+		}
+		key := strings.ToLower(tableName + "." + indexName)
+		if !columnName.Valid || functional[key] {
+			functional[key] = true
+			continue
+		}
+
+		if indexName == "PRIMARY" {
+			table.PrimaryKey = append(table.PrimaryKey, columnName.String)
+			continue
+		}
+
+		idx := indexes[key]
+		if idx == nil {
+			idx = &Index{Name: indexName, Unique: nonUnique == 0, Type: strings.ToUpper(indexType)}
+			indexes[key] = idx
+			table.Indexes = append(table.Indexes, idx)
+		}
+		idx.Columns = append(idx.Columns, columnName.String)
+		if subPart.Valid && subPart.Int64 > 0 {
+			if idx.Lengths == nil {
+				idx.Lengths = make(map[string]int)
+			}
+			idx.Lengths[columnName.String] = int(subPart.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		kept := table.Indexes[:0]
+		for _, idx := range table.Indexes {
+			if !functional[strings.ToLower(table.Name+"."+idx.Name)] {
+				kept = append(kept, idx)
+			}
+		}
+		table.Indexes = kept
+	}
+	return nil
+}
+
+func loadForeignKeys(db types.DB, database string, tables map[string]*Table) error {
+	rows, err := db.Query(`
+		SELECT k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_SCHEMA,
+		       k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.DELETE_RULE, r.UPDATE_RULE
+		FROM information_schema.KEY_COLUMN_USAGE k
+		JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		  ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+		 AND r.TABLE_NAME = k.TABLE_NAME
+		WHERE k.TABLE_SCHEMA = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+		ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, database)
+	if err != nil {
+		return fmt.Errorf("查询外键失败: %v", err)
+	}
+	defer rows.Close()
+
+	foreignKeys := make(map[string]*ForeignKey)
+	for rows.Next() {
+		var tableName, name, column, refSchema, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&tableName, &name, &column, &refSchema, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return fmt.Errorf("读取外键信息失败: %v", err)
+		}
+		table := tables[strings.ToLower(tableName)]
+		if table == nil {
+			continue
+		}
+		if !strings.EqualFold(refSchema, database) {
+			refTable = refSchema + "." + refTable
+		}
+
+		key := strings.ToLower(tableName + "." + name)
+		fk := foreignKeys[key]
+		if fk == nil {
+			fk = &ForeignKey{Name: name, RefTable: refTable, OnDelete: onDelete, OnUpdate: onUpdate}
+			foreignKeys[key] = fk
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, column)
+		fk.RefColumns = append(fk.RefColumns, refColumn)
+	}
+	return rows.Err()
+}
+
+// loadChecks 读取 CHECK 约束，MySQL 8.0.16 之前没有 CHECK_CONSTRAINTS 表，查询失败时忽略
+func loadChecks(db types.DB, database string, tables map[string]*Table) {
+	rows, err := db.Query(`
+		SELECT tc.TABLE_NAME, cc.CONSTRAINT_NAME, cc.CHECK_CLAUSE
+		FROM information_schema.TABLE_CONSTRAINTS tc
+		JOIN information_schema.CHECK_CONSTRAINTS cc
+		  ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ? AND tc.CONSTRAINT_TYPE = 'CHECK'
+		ORDER BY tc.TABLE_NAME, cc.CONSTRAINT_NAME`, database)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName string
+		check := &Check{}
+		if err := rows.Scan(&tableName, &check.Name, &check.Expression); err != nil {
+			return
+		}
+		if table := tables[strings.ToLower(tableName)]; table != nil {
+			table.Checks = append(table.Checks, check)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot 数据库结构快照，用于确认数据库与预期结构一致
+type Snapshot struct {
+	Database  string    `json:"database"`
+	CreatedAt time.Time `json:"created_at"`
+	Tables    []*Table  `json:"tables"`
+}
+
+// SaveSnapshot 把表结构写入 JSON 快照文件
+func SaveSnapshot(filename, database string, tables []*Table) error {
+	snapshot := Snapshot{Database: database, CreatedAt: time.Now(), Tables: tables}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %v", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入快照文件失败: %v", err)
+	}
+	return nil
+}
+
+// LoadSnapshot 读取 JSON 快照文件
+func LoadSnapshot(filename string) (*Snapshot, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照文件失败: %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("解析快照文件 %s 失败: %v", filename, err)
+	}
+	return &snapshot, nil
+}
+
+// intDisplayWidth 整数类型的显示宽度，MySQL 8.0.19 起不再显示，比较时忽略
+var intDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+
+// CompareSchema 比较预期结构和实际结构，返回差异说明，结构一致时返回空。
+// 比较表、列定义、主键、索引、外键和 CHECK 约束，不比较注释和排序规则
+func CompareSchema(expected, actual []*Table) []string {
+	var diffs []string
+	actualTables := tablesByName(actual)
+	expectedTables := tablesByName(expected)
+
+	for _, want := range expected {
+		have, ok := actualTables[strings.ToLower(want.Name)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("缺少表 %s", want.Name))
+			continue
+		}
+		for _, diff := range compareTable(want, have) {
+			diffs = append(diffs, fmt.Sprintf("表 %s: %s", want.Name, diff))
+		}
+	}
+	for _, table := range actual {
+		if _, ok := expectedTables[strings.ToLower(table.Name)]; !ok {
+			diffs = append(diffs, fmt.Sprintf("多出表 %s", table.Name))
+		}
+	}
+	return diffs
+}
+
+// compareTable 比较一张表
+func compareTable(want, have *Table) []string {
+	var diffs []string
+	if !strings.EqualFold(want.Engine, have.Engine) {
+		diffs = append(diffs, fmt.Sprintf("存储引擎 %s -> %s", want.Engine, have.Engine))
+	}
+	if !strings.EqualFold(want.Charset, have.Charset) {
+		diffs = append(diffs, fmt.Sprintf("字符集 %s -> %s", want.Charset, have.Charset))
+	}
+
+	haveColumns := make(map[string]*Column)
+	for _, col := range have.Columns {
+		haveColumns[strings.ToLower(col.Name)] = col
+	}
+	wantColumns := make(map[string]bool)
+	for _, col := range want.Columns {
+		wantColumns[strings.ToLower(col.Name)] = true
+		live, ok := haveColumns[strings.ToLower(col.Name)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("缺少列 %s", col.Name))
+			continue
+		}
+		for _, diff := range compareColumn(col, live) {
+			diffs = append(diffs, fmt.Sprintf("列 %s %s", col.Name, diff))
+		}
+	}
+	for _, col := range have.Columns {
+		if !wantColumns[strings.ToLower(col.Name)] {
+			diffs = append(diffs, fmt.Sprintf("多出列 %s", col.Name))
+		}
+	}
+
+	if wantPK, havePK := columnList(want.PrimaryKey), columnList(have.PrimaryKey); wantPK != havePK {
+		diffs = append(diffs, fmt.Sprintf("主键 (%s) -> (%s)", wantPK, havePK))
+	}
+
+	diffs = append(diffs, compareNamed("索引", indexDefs(want.Indexes), indexDefs(have.Indexes))...)
+	diffs = append(diffs, compareNamed("外键", foreignKeyDefs(want.ForeignKeys), foreignKeyDefs(have.ForeignKeys))...)
+	diffs = append(diffs, compareNamed("CHECK 约束", checkDefs(want.Checks), checkDefs(have.Checks))...)
+	return diffs
+}
+
+// compareColumn 比较列的类型、可空、默认值、自增和生成表达式
+func compareColumn(want, have *Column) []string {
+	var diffs []string
+	if wantType, haveType := normalizeType(want.ColumnType), normalizeType(have.ColumnType); wantType != haveType {
+		diffs = append(diffs, fmt.Sprintf("类型 %s -> %s", want.ColumnType, have.ColumnType))
+	}
+	if want.Nullable != have.Nullable {
+		diffs = append(diffs, fmt.Sprintf("可空 %t -> %t", want.Nullable, have.Nullable))
+	}
+	if wantDefault, haveDefault := defaultText(want), defaultText(have); wantDefault != haveDefault {
+		diffs = append(diffs, fmt.Sprintf("默认值 %s -> %s", wantDefault, haveDefault))
+	}
+	if want.AutoIncrement != have.AutoIncrement {
+		diffs = append(diffs, fmt.Sprintf("自增 %t -> %t", want.AutoIncrement, have.AutoIncrement))
+	}
+	if !strings.EqualFold(want.OnUpdate, have.OnUpdate) {
+		diffs = append(diffs, fmt.Sprintf("ON UPDATE %s -> %s", want.OnUpdate, have.OnUpdate))
+	}
+	if normalizeExpr(want.Generated) != normalizeExpr(have.Generated) || want.Stored != have.Stored {
+		diffs = append(diffs, fmt.Sprintf("生成表达式 %s -> %s", want.Generated, have.Generated))
+	}
+	return diffs
+}
+
+// compareNamed 按名称比较索引、外键等定义
+func compareNamed(kind string, want, have map[string]string) []string {
+	var diffs []string
+	for _, name := range sortedNames(want) {
+		live, ok := have[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("缺少%s %s", kind, name))
+		case live != want[name]:
+			diffs = append(diffs, fmt.Sprintf("%s %s 定义不同: %s -> %s", kind, name, want[name], live))
+		}
+	}
+	for _, name := range sortedNames(have) {
+		if _, ok := want[name]; !ok {
+			diffs = append(diffs, fmt.Sprintf("多出%s %s", kind, name))
+		}
+	}
+	return diffs
+}
+
+func indexDefs(indexes []*Index) map[string]string {
+	defs := make(map[string]string)
+	for _, idx := range indexes {
+		columns := make([]string, len(idx.Columns))
+		for i, col := range idx.Columns {
+			columns[i] = strings.ToLower(col)
+			if length, ok := idx.Lengths[col]; ok {
+				columns[i] += fmt.Sprintf("(%d)", length)
+			}
+		}
+		kind := strings.ToUpper(idx.Type)
+		if idx.Unique {
+			kind = "UNIQUE " + kind
+		}
+		defs[strings.ToLower(idx.Name)] = fmt.Sprintf("%s (%s)", kind, strings.Join(columns, ", "))
+	}
+	return defs
+}
+
+func foreignKeyDefs(foreignKeys []*ForeignKey) map[string]string {
+	defs := make(map[string]string)
+	for _, fk := range foreignKeys {
+		defs[strings.ToLower(fk.Name)] = fmt.Sprintf("(%s) -> %s (%s) ON DELETE %s ON UPDATE %s",
+			columnList(fk.Columns), strings.ToLower(fk.RefTable), columnList(fk.RefColumns),
+			normalizeAction(fk.OnDelete), normalizeAction(fk.OnUpdate))
+	}
+	return defs
+}
+
+func checkDefs(checks []*Check) map[string]string {
+	defs := make(map[string]string)
+	for _, check := range checks {
+		defs[strings.ToLower(check.Name)] = normalizeExpr(check.Expression)
+	}
+	return defs
+}
+
+// normalizeType 小写并去掉整数显示宽度
+func normalizeType(columnType string) string {
+	return intDisplayWidth.ReplaceAllString(strings.ToLower(strings.TrimSpace(columnType)), "$1")
+}
+
+// normalizeExpr 去掉表达式中的空白、反引号和外层括号并小写，抵消不同版本的格式差异
+func normalizeExpr(expr string) string {
+	expr = strings.ToLower(strings.Join(strings.Fields(expr), ""))
+	expr = strings.ReplaceAll(expr, "`", "")
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+// normalizeAction RESTRICT 和 NO ACTION 在 InnoDB 中行为相同
+func normalizeAction(action string) string {
+	action = strings.ToUpper(action)
+	if action == "" || action == "NO ACTION" {
+		return "RESTRICT"
+	}
+	return action
+}
+
+func defaultText(col *Column) string {
+	if col.Default == nil {
+		return "NULL"
+	}
+	if col.DefaultExpr {
+		return strings.ToUpper(*col.Default)
+	}
+	return "'" + *col.Default + "'"
+}
+
+func columnList(columns []string) string {
+	return strings.ToLower(strings.Join(columns, ", "))
+}
+
+func tablesByName(tables []*Table) map[string]*Table {
+	byName := make(map[string]*Table)
+	for _, table := range tables {
+		byName[strings.ToLower(table.Name)] = table
+	}
+	return byName
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,381 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms 生成 Go 名称时全部大写的缩写
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"QPS": true, "RAM": true, "RPC": true, "SKU": true, "SLA": true, "SMTP": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+}
+
+// GenerateStructs 为表生成带 db 和 json 标签的结构体源码，标签可以被 builder.CreateTableFromStruct 解析
+func GenerateStructs(packageName string, tables []*Table) ([]byte, error) {
+	var body strings.Builder
+	imports := make(map[string]bool)
+
+	for i, table := range tables {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		writeStruct(&body, table, imports)
+	}
+
+	var src strings.Builder
+	src.WriteString("// Code generated by db-migrator codegen structs. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", packageName)
+	if len(imports) > 0 {
+		var paths []string
+		for path := range imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		src.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.WriteString(body.String())
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败: %v", err)
+	}
+	return formatted, nil
+}
+
+// writeStruct 写出一张表对应的结构体
+func writeStruct(w *strings.Builder, table *Table, imports map[string]bool) {
+	tags := make(map[string][]string)
+	notes := make(map[string][]string)
+	for _, col := range table.Columns {
+		tags[col.Name] = nil
+	}
+	addIndexTags(table, tags, notes)
+
+	structName := GoName(table.Name)
+	if table.Comment != "" {
+		fmt.Fprintf(w, "// %s %s\n", structName, singleLine(table.Comment))
+	} else {
+		fmt.Fprintf(w, "// %s 对应表 %s\n", structName, table.Name)
+	}
+	for _, check := range table.Checks {
+		notes[""] = append(notes[""], fmt.Sprintf("CHECK 约束 %s: %s", check.Name, singleLine(check.Expression)))
+	}
+	for _, note := range notes[""] {
+		fmt.Fprintf(w, "// 注意：%s\n", note)
+	}
+
+	fmt.Fprintf(w, "type %s struct {\n", structName)
+	used := make(map[string]int)
+	for _, col := range table.Columns {
+		goType, options, columnNotes := fieldType(col)
+		for _, path := range typeImports(goType) {
+			imports[path] = true
+		}
+
+		name := GoName(col.Name)
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, used[name])
+		}
+
+		options = append(options, columnOptions(table, col)...)
+		options = append(options, tags[col.Name]...)
+		if col.Generated != "" {
+			columnNotes = append(columnNotes, "生成列 "+singleLine(col.Generated))
+		} else if col.Default != nil && !tagDefault(col) {
+			columnNotes = append(columnNotes, "默认值 "+*col.Default)
+		}
+		columnNotes = append(columnNotes, notes[col.Name]...)
+
+		tag := strings.Join(append([]string{col.Name}, options...), ",")
+		line := fmt.Sprintf("\t%s %s `db:\"%s\" json:\"%s\"`", name, goType, escapeTag(tag), col.Name)
+		if len(columnNotes) > 0 {
+			line += " // " + strings.Join(columnNotes, "；")
+		}
+		w.WriteString(line + "\n")
+	}
+	w.WriteString("}\n")
+}
+
+// fieldType 列对应的 Go 类型、描述类型的标签选项和无法用标签表示的说明
+func fieldType(col *Column) (string, []string, []string) {
+	var goType string
+	var options, notes []string
+	typeOption := func() {
+		options = append(options, "type:"+typeWithoutModifiers(col))
+	}
+
+	switch col.DataType {
+	case "tinyint":
+		if col.Length == 1 && !col.Unsigned {
+			goType = "bool"
+		} else {
+			goType = intType("int8", col)
+		}
+	case "smallint":
+		goType = intType("int16", col)
+	case "mediumint":
+		goType = intType("int32", col)
+		typeOption()
+	case "int", "integer":
+		goType = intType("int32", col)
+	case "bigint":
+		goType = intType("int64", col)
+	case "year":
+		goType = "int16"
+		typeOption()
+	case "decimal", "numeric":
+		goType = "string"
+		typeOption()
+	case "float":
+		goType = "float32"
+	case "double", "real":
+		goType = "float64"
+	case "bit":
+		goType = "uint64"
+		typeOption()
+	case "varchar":
+		goType = "string"
+		if col.Length > 0 && col.Length <= 255 {
+			options = append(options, fmt.Sprintf("size:%d", col.Length))
+		} else {
+			typeOption()
+		}
+	case "char", "tinytext", "mediumtext", "longtext", "time":
+		goType = "string"
+		typeOption()
+	case "text":
+		goType = "string"
+	case "enum":
+		goType = "string"
+		options = append(options, enumOption(col.Values))
+	case "set":
+		goType = "string"
+		options = append(options, "type:text")
+		notes = append(notes, "原类型 "+col.ColumnType)
+	case "json":
+		goType = "json.RawMessage"
+		typeOption()
+	case "blob":
+		goType = "[]byte"
+	case "binary", "varbinary", "tinyblob", "mediumblob", "longblob":
+		goType = "[]byte"
+		typeOption()
+	case "date":
+		goType = "time.Time"
+		typeOption()
+	case "datetime":
+		goType = "time.Time"
+		if col.Length > 0 {
+			typeOption()
+		}
+	case "timestamp":
+		goType = "time.Time"
+		typeOption()
+	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		goType = "[]byte"
+		typeOption()
+	default:
+		goType = "string"
+		typeOption()
+	}
+
+	if col.Zerofill {
+		options = append(options, "type:"+col.ColumnType)
+	}
+
+	switch {
+	case col.Nullable && !strings.HasPrefix(goType, "[]") && goType != "json.RawMessage":
+		goType = "*" + goType
+	case !col.Nullable && (strings.HasPrefix(goType, "[]") || goType == "json.RawMessage"):
+		options = append(options, "not_null")
+	}
+	return goType, options, notes
+}
+
+// columnOptions 主键、自增、默认值和注释选项
+func columnOptions(table *Table, col *Column) []string {
+	var options []string
+	for _, name := range table.PrimaryKey {
+		if strings.EqualFold(name, col.Name) {
+			options = append(options, "primary_key")
+		}
+	}
+	if col.AutoIncrement {
+		options = append(options, "auto_increment")
+	}
+	if col.Default != nil && tagDefault(col) {
+		value := *col.Default
+		if !col.DefaultExpr {
+			value = tagValue(value)
+		} else if col.OnUpdate != "" {
+			value += " ON UPDATE " + col.OnUpdate
+		}
+		options = append(options, "default:"+value)
+	}
+	if col.Comment != "" {
+		options = append(options, "comment:"+tagValue(singleLine(col.Comment)))
+	}
+	return options
+}
+
+// tagDefault 默认值能否写成 default 选项：枚举列和 CURRENT_TIMESTAMP 以外的表达式不能
+func tagDefault(col *Column) bool {
+	if col.DataType == "enum" || col.Generated != "" {
+		return false
+	}
+	return !col.DefaultExpr || strings.HasPrefix(strings.ToUpper(*col.Default), "CURRENT_TIMESTAMP")
+}
+
+// addIndexTags 把索引和外键写成列的标签选项，无法表示的写入说明
+func addIndexTags(table *Table, tags map[string][]string, notes map[string][]string) {
+	for _, idx := range table.Indexes {
+		switch {
+		case idx.Type == "SPATIAL":
+			notes[""] = append(notes[""], fmt.Sprintf("空间索引 %s (%s) 需要手动创建", idx.Name, strings.Join(idx.Columns, ", ")))
+			continue
+		case idx.Type == "FULLTEXT":
+			for _, col := range idx.Columns {
+				tags[col] = append(tags[col], "fulltext:"+idx.Name)
+			}
+		case idx.Unique && len(idx.Columns) == 1 && idx.Name == idx.Columns[0]:
+			tags[idx.Columns[0]] = append(tags[idx.Columns[0]], "unique")
+		case idx.Unique:
+			for _, col := range idx.Columns {
+				tags[col] = append(tags[col], "unique:"+idx.Name)
+			}
+		default:
+			for _, col := range idx.Columns {
+				tags[col] = append(tags[col], "index:"+idx.Name)
+			}
+		}
+		if len(idx.Lengths) > 0 {
+			notes[idx.Columns[0]] = append(notes[idx.Columns[0]], fmt.Sprintf("索引 %s 使用了前缀长度", idx.Name))
+		}
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Columns) != 1 {
+			notes[""] = append(notes[""], fmt.Sprintf("联合外键 %s (%s) -> %s (%s) 需要手动创建",
+				fk.Name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", ")))
+			continue
+		}
+		column := fk.Columns[0]
+		tags[column] = append(tags[column], fmt.Sprintf("fk:%s.%s", fk.RefTable, fk.RefColumns[0]))
+		if action := referenceAction(fk.OnDelete); action != "" {
+			tags[column] = append(tags[column], "on_delete:"+action)
+		}
+		if action := referenceAction(fk.OnUpdate); action != "" {
+			tags[column] = append(tags[column], "on_update:"+action)
+		}
+	}
+}
+
+// referenceAction 非默认的外键动作，转为标签写法（SET NULL -> set_null）
+func referenceAction(rule string) string {
+	rule = strings.ToUpper(rule)
+	if rule == "" || rule == "RESTRICT" || rule == "NO ACTION" {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(rule, " ", "_"))
+}
+
+func intType(base string, col *Column) string {
+	if col.Unsigned {
+		return "u" + base
+	}
+	return base
+}
+
+// typeWithoutModifiers 去掉 unsigned、zerofill 以外的完整类型，如 decimal(10,2)、datetime(3)
+func typeWithoutModifiers(col *Column) string {
+	columnType := strings.ToLower(col.ColumnType)
+	if col.Unsigned {
+		return columnType
+	}
+	return strings.TrimSpace(strings.Replace(columnType, "zerofill", "", 1))
+}
+
+// enumOption 枚举值写成 enum:a|b，值中包含分隔符时写成 type:enum('a','b')
+func enumOption(values []string) string {
+	special := false
+	for _, v := range values {
+		if strings.ContainsAny(v, "|,()'") || strings.TrimSpace(v) != v || v == "" {
+			special = true
+		}
+	}
+	if !special {
+		return "enum:" + strings.Join(values, "|")
+	}
+
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return "type:enum(" + strings.Join(quoted, ",") + ")"
+}
+
+// tagValue 包含逗号、括号、引号或首尾空格的值用单引号包裹
+func tagValue(value string) string {
+	if value == "" || strings.ContainsAny(value, ",()'") || strings.TrimSpace(value) != value {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
+
+// escapeTag 转义结构体标签中的双引号和反斜杠
+func escapeTag(tag string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(tag)
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func typeImports(goType string) []string {
+	switch strings.TrimPrefix(goType, "*") {
+	case "time.Time":
+		return []string{"time"}
+	case "json.RawMessage":
+		return []string{"encoding/json"}
+	}
+	return nil
+}
+
+// GoName 把表名或列名转换为导出的 Go 名称：user_id -> UserID，order_items -> OrderItems
+func GoName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, word := range words {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		sb.WriteRune(unicode.ToUpper(runes[0]))
+		sb.WriteString(string(runes[1:]))
+	}
+
+	result := sb.String()
+	if result == "" {
+		return "X"
+	}
+	if unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+	return result
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
@@ -101,77 +102,110 @@ func (c *Creator) ExecuteSQLFile(ctx context.Context, dbName, filePath string) e
 	}
 
 	if len(statements) == 0 {
-		log.Printf("警告: SQL文件 '%s' 中没有找到有效的DDL语句", filePath)
+		log.Printf("警告: SQL文件 '%s' 中没有找到有效的SQL语句", filePath)
 		return nil
 	}
 
+	_, _, err = c.executeStatements(ctx, dbName, statements)
+	return err
+}
+
+// executeStatements 按依赖顺序执行语句，返回已执行和跳过的语句
+func (c *Creator) executeStatements(ctx context.Context, dbName string, statements []types.SQLStatement) ([]types.SQLStatement, []types.SkippedStatement, error) {
 	// 验证语句
 	if err := c.parser.ValidateStatements(statements); err != nil {
-		return fmt.Errorf("验证SQL语句失败: %v", err)
+		return nil, nil, fmt.Errorf("验证SQL语句失败: %v", err)
 	}
 
 	// 按依赖关系排序
 	sortedStatements, err := c.parser.SortByDependencies(statements)
 	if err != nil {
-		return fmt.Errorf("排序SQL语句失败: %v", err)
+		return nil, nil, fmt.Errorf("排序SQL语句失败: %v", err)
 	}
 
 	// 连接到目标数据库
 	dbConn, err := c.connectToDatabase(dbName)
 	if err != nil {
-		return fmt.Errorf("连接数据库失败: %v", err)
+		return nil, nil, fmt.Errorf("连接数据库失败: %v", err)
 	}
 	defer dbConn.Close()
 
-	// 开始事务
+	// 开始事务，所有语句在同一连接上执行，SET 设置的会话变量对后续语句生效
 	tx, err := dbConn.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("开始事务失败: %v", err)
+		return nil, nil, fmt.Errorf("开始事务失败: %v", err)
 	}
 	defer tx.Rollback()
 
 	// 执行所有语句
-	var createdObjects []types.ObjectInfo
-	var errors []string
+	var executed []types.SQLStatement
+	var skipped []types.SkippedStatement
 
-	log.Printf("开始执行 %d 个DDL语句...", len(sortedStatements))
+	log.Printf("开始执行 %d 个SQL语句...", len(sortedStatements))
 
 	for i, stmt := range sortedStatements {
+		if reason := skipReason(stmt); reason != "" {
+			skipped = append(skipped, types.SkippedStatement{Line: stmt.Line, Type: stmt.Type, Name: stmt.Name, Reason: reason})
+			log.Printf("[%d/%d] 跳过 %s %s (第%d行): %s", i+1, len(sortedStatements), stmt.Type, stmt.Name, stmt.Line, reason)
+			continue
+		}
+
 		log.Printf("[%d/%d] 执行 %s: %s", i+1, len(sortedStatements), stmt.Type, stmt.Name)
 
 		_, err := tx.ExecContext(ctx, stmt.Statement)
 		if err != nil {
-			errMsg := fmt.Sprintf("执行语句失败 (%s: %s): %v", stmt.Type, stmt.Name, err)
-			errors = append(errors, errMsg)
+			errMsg := fmt.Sprintf("执行语句失败 (第%d行 %s: %s): %v", stmt.Line, stmt.Type, stmt.Name, err)
 			log.Printf("错误: %s", errMsg)
-			return fmt.Errorf("执行SQL语句失败: %v", err)
+			return executed, skipped, fmt.Errorf("执行SQL语句失败 (第%d行): %v", stmt.Line, err)
 		}
 
-		createdObjects = append(createdObjects, types.ObjectInfo{
-			Type: stmt.Type,
-			Name: stmt.Name,
-		})
+		executed = append(executed, stmt)
 	}
 
 	// 提交事务
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("提交事务失败: %v", err)
+		return executed, skipped, fmt.Errorf("提交事务失败: %v", err)
 	}
 
-	log.Printf("成功执行所有DDL语句，共创建 %d 个数据库对象", len(createdObjects))
+	log.Printf("成功执行 %d 个SQL语句，跳过 %d 个", len(executed), len(skipped))
 
-	// 打印创建的对象摘要
+	// 打印执行的语句摘要
 	typeCount := make(map[string]int)
-	for _, obj := range createdObjects {
-		typeCount[obj.Type]++
+	for _, stmt := range executed {
+		typeCount[stmt.Type]++
 	}
 
-	log.Printf("创建对象摘要:")
-	for objType, count := range typeCount {
-		log.Printf("  %s: %d 个", c.getTypeDisplayName(objType), count)
+	log.Printf("执行语句摘要:")
+	for stmtType, count := range typeCount {
+		log.Printf("  %s: %d 个", c.getTypeDisplayName(stmtType), count)
 	}
 
-	return nil
+	return executed, skipped, nil
+}
+
+// skipReason 返回不执行该语句的原因，目标数据库由调用方指定，切换或创建其他数据库的语句跳过
+func skipReason(stmt types.SQLStatement) string {
+	switch stmt.Type {
+	case "USE":
+		return "不切换数据库，语句在目标数据库中执行"
+	case "CREATE_DATABASE", "ALTER_DATABASE", "DROP_DATABASE":
+		return "目标数据库已单独创建"
+	}
+	return ""
+}
+
+// createdObjects 已执行的CREATE语句创建的对象
+func createdObjects(statements []types.SQLStatement) []types.ObjectInfo {
+	objects := []types.ObjectInfo{}
+	for _, stmt := range statements {
+		if strings.HasPrefix(stmt.Type, "CREATE_") {
+			objects = append(objects, types.ObjectInfo{
+				Type: stmt.Type,
+				Name: stmt.Name,
+			})
+		}
+	}
+	return objects
 }
 
 // connectToDatabase 连接到指定数据库
@@ -217,6 +251,16 @@ func (c *Creator) getTypeDisplayName(objType string) string {
 		return "触发器"
 	case "CREATE_INDEX":
 		return "索引"
+	case "CREATE_EVENT":
+		return "事件"
+	case "ALTER_TABLE":
+		return "修改表"
+	case "INSERT", "REPLACE":
+		return "插入数据"
+	case "UPDATE", "DELETE", "TRUNCATE":
+		return "修改数据"
+	case "SET":
+		return "变量设置"
 	default:
 		return "其他对象"
 	}
@@ -260,24 +304,17 @@ func (c *Creator) CreateFromSQLFile(ctx context.Context, dbConfig types.Database
 	}
 
 	// 3. 执行SQL语句
-	err = c.ExecuteSQLFile(ctx, dbConfig.Name, sqlFilePath)
+	executed, skipped, err := c.executeStatements(ctx, dbConfig.Name, statements)
+	result.StatementsSuccess = len(executed)
+	result.StatementsSkipped = len(skipped)
+	result.Skipped = skipped
+	result.CreatedObjects = createdObjects(executed)
+	result.ExecutionTime = time.Since(startTime).String()
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Sprintf("执行SQL文件失败: %v", err))
-		result.StatementsFailed = result.StatementsTotal
-		result.ExecutionTime = time.Since(startTime).String()
+		result.StatementsFailed = result.StatementsTotal - result.StatementsSuccess - result.StatementsSkipped
 		return result, err
 	}
 
-	result.StatementsSuccess = result.StatementsTotal
-	result.ExecutionTime = time.Since(startTime).String()
-
-	// 4. 收集创建的对象信息
-	for _, stmt := range statements {
-		result.CreatedObjects = append(result.CreatedObjects, types.ObjectInfo{
-			Type: stmt.Type,
-			Name: stmt.Name,
-		})
-	}
-
 	return result, nil
 }
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// dumpBatchBytes 逐个读取 INSERT/REPLACE 值组时每批执行的最大字节数
+const dumpBatchBytes = 1 << 20
+
+// DumpOptions mysqldump 文件导入选项
+type DumpOptions struct {
+	WithData bool                     // 同时导入 INSERT/REPLACE 数据，默认只导入结构
+	Rewrite  sqlparser.RewriteOptions // DEFINER、字符集和排序规则改写
+	Progress func(DumpProgress)       // 每执行一条语句后调用
+}
+
+// DumpProgress 导入进度
+type DumpProgress struct {
+	BytesRead  int64 // 已读取的文件字节数（压缩文件为压缩后的字节数）
+	TotalBytes int64
+	Statements int // 已处理的语句数
+	Line       int // 当前语句所在行
+}
+
+// LoadDump 创建数据库并按文件顺序流式执行 mysqldump 文件，同一时间只在内存中保留一条语句，
+// INSERT/REPLACE 的值组逐个读取，最多保留一批（dumpBatchBytes）
+//
+// 与 CreateFromSQLFile 不同，语句不会按依赖关系重新排序：dump 文件本身的顺序是可执行的，
+// 并通过 SET FOREIGN_KEY_CHECKS=0 处理外键。支持 .gz 压缩文件。
+func (c *Creator) LoadDump(ctx context.Context, dbConfig types.DatabaseCreateConfig, filePath string, options DumpOptions) (*types.CreateFromSQLResult, error) {
+	startTime := time.Now()
+	result := &types.CreateFromSQLResult{
+		DatabaseName:   dbConfig.Name,
+		Errors:         []string{},
+		CreatedObjects: []types.ObjectInfo{},
+	}
+	fail := func(format string, err error) (*types.CreateFromSQLResult, error) {
+		err = fmt.Errorf(format, err)
+		result.Errors = append(result.Errors, err.Error())
+		result.ExecutionTime = time.Since(startTime).String()
+		return result, err
+	}
+
+	// 1. 创建数据库
+	if err := c.CreateDatabase(ctx, dbConfig); err != nil {
+		return fail("创建数据库失败: %v", err)
+	}
+	result.DatabaseCreated = true
+
+	// 2. 打开文件
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fail("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fail("读取文件信息失败: %v", err)
+	}
+	counter := &countingReader{r: file}
+	reader, err := dumpReader(counter, filePath)
+	if err != nil {
+		return fail("解压文件失败: %v", err)
+	}
+
+	// 3. 连接目标数据库，所有语句在同一连接上执行，SET 设置的会话变量对后续语句生效
+	dbConn, err := c.connectToDatabase(dbConfig.Name)
+	if err != nil {
+		return fail("连接数据库失败: %v", err)
+	}
+	defer dbConn.Close()
+
+	conn, err := dbConn.Conn(ctx)
+	if err != nil {
+		return fail("获取数据库连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	// 4. 逐条执行
+	scanner := sqlparser.NewScanner(reader)
+	scanner.StreamValues()
+	parser := sqlparser.NewParser()
+	dataSkipped := 0
+
+	for {
+		statement, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail("解析SQL文件失败: %v", err)
+		}
+
+		stmt, err := parser.ParseStatement(statement)
+		if err != nil {
+			return fail("解析SQL文件失败: %v", err)
+		}
+		if stmt == nil {
+			continue
+		}
+		result.StatementsTotal++
+
+		switch {
+		case skipReason(*stmt) != "":
+			result.StatementsSkipped++
+			result.Skipped = append(result.Skipped, types.SkippedStatement{
+				Line: stmt.Line, Type: stmt.Type, Name: stmt.Name, Reason: skipReason(*stmt),
+			})
+
+		case !options.WithData && isDumpDataStatement(stmt.Type):
+			result.StatementsSkipped++
+			dataSkipped++
+
+		default:
+			if err := execDumpStatement(ctx, conn, scanner, statement, options.Rewrite); err != nil {
+				result.StatementsFailed++
+				return fail("执行SQL语句失败: %v", fmt.Errorf("第%d行 %s %s: %v", stmt.Line, stmt.Type, stmt.Name, err))
+			}
+			result.StatementsSuccess++
+			if strings.HasPrefix(stmt.Type, "CREATE_") {
+				result.CreatedObjects = append(result.CreatedObjects, types.ObjectInfo{Type: stmt.Type, Name: stmt.Name})
+			}
+		}
+
+		if options.Progress != nil {
+			options.Progress(DumpProgress{
+				BytesRead:  counter.n,
+				TotalBytes: info.Size(),
+				Statements: result.StatementsTotal,
+				Line:       stmt.Line,
+			})
+		}
+	}
+
+	if dataSkipped > 0 {
+		log.Printf("跳过 %d 条数据语句（INSERT/LOCK TABLES），使用 --with-data 导入数据", dataSkipped)
+	}
+	result.ExecutionTime = time.Since(startTime).String()
+	return result, nil
+}
+
+// execDumpStatement 执行一条语句，流式读取的 INSERT/REPLACE 按 dumpBatchBytes 分批执行；
+// 跳过的语句不需要调用，未读取的值组由 scanner.Next 跳过
+func execDumpStatement(ctx context.Context, conn *sql.Conn, scanner *sqlparser.Scanner, statement *sqlparser.Statement, rewrite sqlparser.RewriteOptions) error {
+	if !statement.Streaming {
+		_, err := conn.ExecContext(ctx, sqlparser.Rewrite(statement, rewrite))
+		return err
+	}
+
+	var batch strings.Builder
+	flush := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+		_, err := conn.ExecContext(ctx, statement.Text+" "+batch.String())
+		batch.Reset()
+		return err
+	}
+
+	for {
+		row, err := scanner.NextRow()
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+		if batch.Len() > 0 {
+			batch.WriteString(",")
+		}
+		batch.WriteString("(")
+		batch.WriteString(row.Text)
+		batch.WriteString(")")
+		if batch.Len() >= dumpBatchBytes {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// isDumpDataStatement 只导入结构时跳过的语句
+func isDumpDataStatement(stmtType string) bool {
+	switch stmtType {
+	case "INSERT", "REPLACE", "LOCK_TABLES", "UNLOCK_TABLES":
+		return true
+	}
+	return false
+}
+
+// IsMySQLDump 根据文件头判断是否为 mysqldump / mariadb-dump 生成的文件
+func IsMySQLDump(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	reader, err := dumpReader(file, filePath)
+	if err != nil {
+		return false
+	}
+	line, _ := bufio.NewReader(reader).ReadString('\n')
+	return strings.HasPrefix(line, "-- MySQL dump") || strings.HasPrefix(line, "-- MariaDB dump")
+}
+
+// dumpReader .gz 文件返回解压后的内容
+func dumpReader(r io.Reader, filePath string) (io.Reader, error) {
+	if !strings.HasSuffix(strings.ToLower(filePath), ".gz") {
+		return r, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// countingReader 统计已读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
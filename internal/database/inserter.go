@@ -4,19 +4,30 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
 	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
 	"github.com/xiezhihuan/db-migrator/internal/types"
 )
 
+// maxPlaceholders MySQL 预处理语句的占位符上限
+const maxPlaceholders = 65535
+
+// maxInsertErrors 结果中保留的错误数，其余只计数
+const maxInsertErrors = 100
+
+// maxPreparedStatements 每个连接缓存的预处理语句数
+const maxPreparedStatements = 64
+
 // Inserter 数据插入器实现
 type Inserter struct {
 	rootConn *sql.DB
 	config   *types.DatabaseConfig
-	parser   types.InsertSQLParser
 }
 
 // NewInserter 创建新的数据插入器
@@ -24,11 +35,13 @@ func NewInserter(rootConn *sql.DB, config *types.DatabaseConfig) *Inserter {
 	return &Inserter{
 		rootConn: rootConn,
 		config:   config,
-		parser:   sqlparser.NewInsertParser(),
 	}
 }
 
 // InsertFromSQLFile 从SQL文件插入数据
+//
+// 文件按批流式读取和执行，内存占用与 BatchSize 相关而与文件大小无关；
+// 值通过预处理语句的参数传递，不拼接到SQL中。
 func (i *Inserter) InsertFromSQLFile(ctx context.Context, dbName, filePath string, config types.DataInsertConfig) (*types.DataInsertResult, error) {
 	startTime := time.Now()
 
@@ -41,80 +54,147 @@ func (i *Inserter) InsertFromSQLFile(ctx context.Context, dbName, filePath strin
 		TableResults:         []types.TableInsertResult{},
 		Errors:               []types.InsertError{},
 	}
-
-	// 1. 解析SQL文件
-	statements, err := i.parser.ParseInsertFile(filePath)
-	if err != nil {
+	fail := func(format string, err error) (*types.DataInsertResult, error) {
+		err = fmt.Errorf(format, err)
+		result.Errors = append(result.Errors, types.InsertError{ErrorMessage: err.Error()})
 		result.ExecutionTime = time.Since(startTime).String()
-		result.Errors = append(result.Errors, types.InsertError{
-			ErrorMessage: fmt.Sprintf("解析SQL文件失败: %v", err),
-		})
 		return result, err
 	}
 
-	result.TotalStatements = len(statements)
-	if len(statements) == 0 {
-		result.ExecutionTime = time.Since(startTime).String()
-		return result, fmt.Errorf("SQL文件中没有找到有效的INSERT语句")
+	// 1. 打开SQL文件
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fail("打开SQL文件失败: %v", err)
 	}
+	defer file.Close()
 
-	// 2. 验证INSERT语句
-	if err := i.parser.ValidateInsertStatements(statements); err != nil {
-		result.ExecutionTime = time.Since(startTime).String()
-		result.Errors = append(result.Errors, types.InsertError{
-			ErrorMessage: fmt.Sprintf("验证INSERT语句失败: %v", err),
-		})
-		return result, err
+	info, err := file.Stat()
+	if err != nil {
+		return fail("读取文件信息失败: %v", err)
 	}
+	counter := &countingReader{r: file}
+	reader := sqlparser.NewInsertParser().NewInsertReader(counter, config.BatchSize)
 
-	// 3. 验证表存在性
-	if config.ValidateTables {
-		tables := i.parser.ExtractTableNames(statements)
-		if err := i.ValidateTablesExist(ctx, dbName, tables); err != nil {
-			result.ExecutionTime = time.Since(startTime).String()
-			result.Errors = append(result.Errors, types.InsertError{
-				ErrorMessage: fmt.Sprintf("表存在性验证失败: %v", err),
-			})
-			return result, err
-		}
+	// 2. 连接数据库
+	dbConn, err := i.connectToDatabase(dbName)
+	if err != nil {
+		return fail("连接数据库失败: %v", err)
 	}
+	defer dbConn.Close()
 
-	// 4. 执行插入操作
-	if err := i.ExecuteInsertStatements(ctx, dbName, statements, config); err != nil {
-		result.ExecutionTime = time.Since(startTime).String()
-		result.FailedStatements = result.TotalStatements
-		result.Errors = append(result.Errors, types.InsertError{
-			ErrorMessage: fmt.Sprintf("执行插入操作失败: %v", err),
-		})
-		return result, err
+	inserter, err := i.newBatchInserter(ctx, dbConn, config)
+	if err != nil {
+		return fail("%v", err)
 	}
+	defer inserter.close()
 
-	// 5. 统计结果
-	result.SuccessfulStatements = result.TotalStatements
-	result.ExecutionTime = time.Since(startTime).String()
-
-	// 统计每个表的插入结果
+	// 3. 逐批验证并执行
 	tableStats := make(map[string]*types.TableInsertResult)
-	for _, stmt := range statements {
-		if tableStats[stmt.TableName] == nil {
-			tableStats[stmt.TableName] = &types.TableInsertResult{
-				TableName:          stmt.TableName,
-				RowsInserted:       0,
-				StatementsExecuted: 0,
+	var tables []string
+	failedStatements := make(map[int]bool)
+	lastProgress := time.Now()
+
+	for {
+		stmt, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail("解析SQL文件失败: %v", err)
+		}
+
+		stats, ok := tableStats[stmt.TableName]
+		if !ok {
+			// 第一次遇到的表验证存在性
+			if config.ValidateTables {
+				exists, err := i.checkTableExists(ctx, dbConn, dbName, stmt.TableName)
+				if err != nil {
+					return fail("表存在性验证失败: %v", fmt.Errorf("检查表 %s 存在性失败: %v", stmt.TableName, err))
+				}
+				if !exists {
+					return fail("表存在性验证失败: %v", fmt.Errorf("表 %s 不存在", stmt.TableName))
+				}
 			}
+			stats = &types.TableInsertResult{TableName: stmt.TableName}
+			tableStats[stmt.TableName] = stats
+			tables = append(tables, stmt.TableName)
+		}
+
+		if err := validateInsertBatch(stmt); err != nil {
+			return fail("验证INSERT语句失败: %v", err)
+		}
+
+		rows, err := inserter.insert(ctx, stmt)
+		stats.RowsInserted += rows
+		if err != nil {
+			errMsg := fmt.Sprintf("执行INSERT语句失败 (表: %s, 行: %d): %v", stmt.TableName, stmt.LineNumber, err)
+			log.Printf("❌ %s", errMsg)
+			failedStatements[stmt.LineNumber] = true
+			if len(result.Errors) < maxInsertErrors {
+				result.Errors = append(result.Errors, types.InsertError{
+					TableName:    stmt.TableName,
+					LineNumber:   stmt.LineNumber,
+					ErrorMessage: errMsg,
+				})
+			}
+			if config.StopOnError {
+				result.TotalStatements = reader.Statements()
+				result.FailedStatements = len(failedStatements)
+				result.ExecutionTime = time.Since(startTime).String()
+				return result, fmt.Errorf("%s", errMsg)
+			}
+		}
+
+		if config.ProgressCallback != nil && time.Since(lastProgress) >= time.Second {
+			config.ProgressCallback("执行INSERT", counter.n, info.Size(), nil)
+			lastProgress = time.Now()
 		}
-		tableStats[stmt.TableName].StatementsExecuted++
-		tableStats[stmt.TableName].RowsInserted += int64(len(stmt.Values))
 	}
 
-	for _, tableResult := range tableStats {
+	result.TotalStatements = reader.Statements()
+	if result.TotalStatements == 0 {
+		result.ExecutionTime = time.Since(startTime).String()
+		return result, fmt.Errorf("SQL文件中没有找到有效的INSERT语句")
+	}
+
+	// 4. 提交事务
+	if err := inserter.commit(); err != nil {
+		return fail("提交事务失败: %v", err)
+	}
+	if config.ProgressCallback != nil {
+		config.ProgressCallback("完成", info.Size(), info.Size(), nil)
+	}
+
+	// 5. 统计结果
+	result.FailedStatements = len(failedStatements)
+	result.SuccessfulStatements = result.TotalStatements - result.FailedStatements
+	for _, tableName := range tables {
+		tableResult := tableStats[tableName]
+		tableResult.StatementsExecuted = inserter.statements[tableName]
 		result.TableResults = append(result.TableResults, *tableResult)
 		result.TotalRowsInserted += tableResult.RowsInserted
 	}
+	result.ExecutionTime = time.Since(startTime).String()
 
+	log.Printf("✅ 成功插入 %d 行数据", result.TotalRowsInserted)
 	return result, nil
 }
 
+// validateInsertBatch 检查每组值的列数
+func validateInsertBatch(stmt *types.InsertStatement) error {
+	expected := len(stmt.Columns)
+	if expected == 0 {
+		expected = len(stmt.Values[0])
+	}
+	for j, values := range stmt.Values {
+		if len(values) != expected {
+			return fmt.Errorf("第%d行 表 %s 第%d组值的列数不匹配：期望%d列，实际%d列",
+				stmt.LineNumber, stmt.TableName, j+1, expected, len(values))
+		}
+	}
+	return nil
+}
+
 // ValidateTablesExist 验证表是否存在
 func (i *Inserter) ValidateTablesExist(ctx context.Context, dbName string, tables []string) error {
 	dbConn, err := i.connectToDatabase(dbName)
@@ -145,15 +225,11 @@ func (i *Inserter) ExecuteInsertStatements(ctx context.Context, dbName string, s
 	}
 	defer dbConn.Close()
 
-	// 开始事务（如果配置要求）
-	var tx *sql.Tx
-	if config.UseTransaction {
-		tx, err = dbConn.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("开始事务失败: %v", err)
-		}
-		defer tx.Rollback() // 如果没有提交，自动回滚
+	inserter, err := i.newBatchInserter(ctx, dbConn, config)
+	if err != nil {
+		return err
 	}
+	defer inserter.close()
 
 	log.Printf("开始执行 %d 个INSERT语句...", len(statements))
 
@@ -167,31 +243,22 @@ func (i *Inserter) ExecuteInsertStatements(ctx context.Context, dbName string, s
 		log.Printf("[%d/%d] 插入数据到表: %s (%d行)",
 			idx+1, len(statements), stmt.TableName, len(stmt.Values))
 
-		var execErr error
-		if config.UseTransaction {
-			execErr = i.executeInsertStatement(ctx, tx, stmt, config)
-		} else {
-			execErr = i.executeInsertStatementDB(ctx, dbConn, stmt, config)
-		}
-
-		if execErr != nil {
+		rows, err := inserter.insert(ctx, &stmt)
+		totalRows += rows
+		if err != nil {
 			errMsg := fmt.Sprintf("执行INSERT语句失败 (表: %s, 行: %d): %v",
-				stmt.TableName, stmt.LineNumber, execErr)
+				stmt.TableName, stmt.LineNumber, err)
 			log.Printf("❌ %s", errMsg)
 
 			if config.StopOnError {
-				return fmt.Errorf(errMsg)
+				return fmt.Errorf("%s", errMsg)
 			}
-		} else {
-			totalRows += int64(len(stmt.Values))
 		}
 	}
 
 	// 提交事务
-	if config.UseTransaction && tx != nil {
-		if err := tx.Commit(); err != nil {
-			return fmt.Errorf("提交事务失败: %v", err)
-		}
+	if err := inserter.commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %v", err)
 	}
 
 	log.Printf("✅ 成功插入 %d 行数据", totalRows)
@@ -203,141 +270,169 @@ func (i *Inserter) ExecuteInsertStatements(ctx context.Context, dbName string, s
 	return nil
 }
 
-// executeInsertStatement 使用事务执行INSERT语句
-func (i *Inserter) executeInsertStatement(ctx context.Context, tx *sql.Tx, stmt types.InsertStatement, config types.DataInsertConfig) error {
-	// 批量处理
-	return i.executeBatchInsert(ctx, tx, stmt, config)
+// execer *sql.Tx 和 *sql.Conn 共有的执行方法
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
-// executeInsertStatementDB 使用数据库连接执行INSERT语句
-func (i *Inserter) executeInsertStatementDB(ctx context.Context, db *sql.DB, stmt types.InsertStatement, config types.DataInsertConfig) error {
-	// 批量处理
-	return i.executeBatchInsertDB(ctx, db, stmt, config)
+// batchInserter 使用预处理语句批量插入，相同的SQL只预处理一次
+type batchInserter struct {
+	conn       *sql.Conn
+	tx         *sql.Tx
+	execer     execer
+	config     types.DataInsertConfig
+	prepared   map[string]*sql.Stmt
+	statements map[string]int // 每个表执行的INSERT语句数
 }
 
-// executeBatchInsert 批量执行INSERT（事务版本）
-func (i *Inserter) executeBatchInsert(ctx context.Context, tx *sql.Tx, stmt types.InsertStatement, config types.DataInsertConfig) error {
-	batchSize := config.BatchSize
-	totalBatches := (len(stmt.Values) + batchSize - 1) / batchSize
-
-	for batchIndex := 0; batchIndex < totalBatches; batchIndex++ {
-		start := batchIndex * batchSize
-		end := start + batchSize
-		if end > len(stmt.Values) {
-			end = len(stmt.Values)
-		}
-
-		batchValues := stmt.Values[start:end]
-		batchSQL := i.buildBatchInsertSQL(stmt.TableName, stmt.Columns, batchValues)
-
-		log.Printf("  批次 %d/%d: 插入 %d 行", batchIndex+1, totalBatches, len(batchValues))
+// newBatchInserter 获取一个连接，UseTransaction 时在该连接上开始事务
+func (i *Inserter) newBatchInserter(ctx context.Context, db *sql.DB, config types.DataInsertConfig) (*batchInserter, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接失败: %v", err)
+	}
 
-		_, err := tx.ExecContext(ctx, batchSQL)
+	b := &batchInserter{
+		conn:       conn,
+		execer:     conn,
+		config:     config,
+		prepared:   make(map[string]*sql.Stmt),
+		statements: make(map[string]int),
+	}
+	if config.UseTransaction {
+		tx, err := conn.BeginTx(ctx, nil)
 		if err != nil {
-			if i.isDuplicateKeyError(err) && config.OnConflict == "ignore" {
-				log.Printf("⚠️ 批次 %d 忽略重复键错误", batchIndex+1)
-				continue
-			}
-			return fmt.Errorf("批次 %d 执行失败: %v", batchIndex+1, err)
+			conn.Close()
+			return nil, fmt.Errorf("开始事务失败: %v", err)
 		}
+		b.tx = tx
+		b.execer = tx
 	}
-
-	return nil
+	return b, nil
 }
 
-// executeBatchInsertDB 批量执行INSERT（数据库连接版本）
-func (i *Inserter) executeBatchInsertDB(ctx context.Context, db *sql.DB, stmt types.InsertStatement, config types.DataInsertConfig) error {
-	batchSize := config.BatchSize
-	totalBatches := (len(stmt.Values) + batchSize - 1) / batchSize
+// insert 插入一条语句的值，按 BatchSize 和占位符上限拆分，返回成功插入的行数
+func (b *batchInserter) insert(ctx context.Context, stmt *types.InsertStatement) (int64, error) {
+	if len(stmt.Values) == 0 {
+		return 0, nil
+	}
+	b.statements[stmt.TableName]++
+
+	batchSize := b.config.BatchSize
+	if batchSize <= 0 || batchSize > len(stmt.Values) {
+		batchSize = len(stmt.Values)
+	}
+	if width := len(stmt.Values[0]); width > 0 && batchSize > maxPlaceholders/width {
+		batchSize = maxPlaceholders / width
+	}
 
-	for batchIndex := 0; batchIndex < totalBatches; batchIndex++ {
-		start := batchIndex * batchSize
+	inserted := int64(0)
+	for start := 0; start < len(stmt.Values); start += batchSize {
 		end := start + batchSize
 		if end > len(stmt.Values) {
 			end = len(stmt.Values)
 		}
+		rows := stmt.Values[start:end]
 
-		batchValues := stmt.Values[start:end]
-		batchSQL := i.buildBatchInsertSQL(stmt.TableName, stmt.Columns, batchValues)
+		query, args := buildInsertSQL(stmt.TableName, stmt.Columns, rows, b.config.OnConflict)
+		if err := b.exec(ctx, query, args); err != nil {
+			return inserted, fmt.Errorf("第 %d-%d 组值执行失败: %v", start+1, end, err)
+		}
+		inserted += int64(len(rows))
+	}
+	return inserted, nil
+}
 
-		log.Printf("  批次 %d/%d: 插入 %d 行", batchIndex+1, totalBatches, len(batchValues))
+// exec 执行语句，预处理语句按SQL缓存，缓存已满时直接执行
+func (b *batchInserter) exec(ctx context.Context, query string, args []interface{}) error {
+	prepared, ok := b.prepared[query]
+	if !ok {
+		if len(b.prepared) >= maxPreparedStatements {
+			_, err := b.execer.ExecContext(ctx, query, args...)
+			return err
+		}
 
-		_, err := db.ExecContext(ctx, batchSQL)
-		if err != nil {
-			if i.isDuplicateKeyError(err) && config.OnConflict == "ignore" {
-				log.Printf("⚠️ 批次 %d 忽略重复键错误", batchIndex+1)
-				continue
-			}
-			return fmt.Errorf("批次 %d 执行失败: %v", batchIndex+1, err)
+		var err error
+		if prepared, err = b.execer.PrepareContext(ctx, query); err != nil {
+			return fmt.Errorf("预处理语句失败: %v", err)
 		}
+		b.prepared[query] = prepared
 	}
 
-	return nil
+	_, err := prepared.ExecContext(ctx, args...)
+	return err
+}
+
+// commit 提交事务（如果有）
+func (b *batchInserter) commit() error {
+	if b.tx == nil {
+		return nil
+	}
+	err := b.tx.Commit()
+	b.tx = nil
+	return err
 }
 
-// buildBatchInsertSQL 构建批量INSERT SQL
-func (i *Inserter) buildBatchInsertSQL(tableName string, columns []string, values [][]interface{}) string {
+// close 关闭预处理语句，未提交的事务回滚
+func (b *batchInserter) close() {
+	for _, prepared := range b.prepared {
+		prepared.Close()
+	}
+	if b.tx != nil {
+		b.tx.Rollback()
+	}
+	b.conn.Close()
+}
+
+// buildInsertSQL 构建带占位符的批量INSERT SQL，返回SQL和参数
+//
+// 值通过参数绑定，types.RawSQL 表达式（如 NOW()）原样写入SQL；
+// onConflict 为 ignore 时使用 INSERT IGNORE，replace 时使用 REPLACE。
+func buildInsertSQL(tableName string, columns []string, rows [][]interface{}, onConflict string) (string, []interface{}) {
 	var sql strings.Builder
 
-	sql.WriteString("INSERT INTO `")
-	sql.WriteString(tableName)
-	sql.WriteString("`")
+	switch onConflict {
+	case "ignore":
+		sql.WriteString("INSERT IGNORE INTO ")
+	case "replace":
+		sql.WriteString("REPLACE INTO ")
+	default:
+		sql.WriteString("INSERT INTO ")
+	}
+	sql.WriteString(dialect.MySQL.QuoteQualified(tableName))
 
 	// 添加列名（如果指定）
 	if len(columns) > 0 {
 		sql.WriteString(" (")
-		for idx, col := range columns {
-			if idx > 0 {
-				sql.WriteString(", ")
-			}
-			sql.WriteString("`")
-			sql.WriteString(col)
-			sql.WriteString("`")
-		}
+		sql.WriteString(dialect.MySQL.QuoteIdents(columns))
 		sql.WriteString(")")
 	}
 
 	sql.WriteString(" VALUES ")
 
-	// 添加值
-	for idx, valueRow := range values {
+	// 添加占位符
+	args := make([]interface{}, 0, len(rows)*len(rows[0]))
+	for idx, row := range rows {
 		if idx > 0 {
 			sql.WriteString(", ")
 		}
 		sql.WriteString("(")
-		for j, value := range valueRow {
+		for j, value := range row {
 			if j > 0 {
 				sql.WriteString(", ")
 			}
-			sql.WriteString(i.formatValue(value))
+			if raw, ok := value.(types.RawSQL); ok {
+				sql.WriteString(string(raw))
+				continue
+			}
+			sql.WriteString("?")
+			args = append(args, value)
 		}
 		sql.WriteString(")")
 	}
 
-	return sql.String()
-}
-
-// formatValue 格式化值
-func (i *Inserter) formatValue(value interface{}) string {
-	if value == nil {
-		return "NULL"
-	}
-
-	switch v := value.(type) {
-	case string:
-		return fmt.Sprintf("'%s'", strings.ReplaceAll(v, "'", "''"))
-	case int64, int, int32:
-		return fmt.Sprintf("%v", v)
-	case float64, float32:
-		return fmt.Sprintf("%v", v)
-	case bool:
-		if v {
-			return "TRUE"
-		}
-		return "FALSE"
-	default:
-		return fmt.Sprintf("'%v'", v)
-	}
+	return sql.String(), args
 }
 
 // checkTableExists 检查表是否存在
@@ -383,14 +478,3 @@ func (i *Inserter) connectToDatabase(dbName string) (*sql.DB, error) {
 
 	return db, nil
 }
-
-// isDuplicateKeyError 判断是否是重复键错误
-func (i *Inserter) isDuplicateKeyError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "duplicate entry") ||
-		strings.Contains(errStr, "duplicate key") ||
-		strings.Contains(errStr, "unique constraint")
-}
@@ -128,6 +128,11 @@ func (m *Manager) CloseAll() error {
 	return nil
 }
 
+// GetDatabaseConfig 获取数据库的连接配置
+func (m *Manager) GetDatabaseConfig(name string) (*types.DatabaseConfig, error) {
+	return m.getDatabaseConfig(name)
+}
+
 // getDatabaseConfig 获取数据库配置
 func (m *Manager) getDatabaseConfig(name string) (*types.DatabaseConfig, error) {
 	// 首先检查预配置的数据库
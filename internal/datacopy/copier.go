@@ -3,13 +3,16 @@ package datacopy
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
 	"github.com/xiezhihuan/db-migrator/internal/types"
 )
 
+// sqlDialect 复制时生成 SQL 使用的方言
+var sqlDialect = dialect.MySQL
+
 // CopyStrategy 数据复制策略
 type CopyStrategy string
 
@@ -31,22 +34,81 @@ const (
 )
 
 // FieldMapping 字段映射配置
+//
+// SourceField 为空时表示计算列：目标列 TargetField 的值完全由 Transform 表达式生成。
+// 同一行的所有表达式都基于源数据的原始值求值，互不影响。
 type FieldMapping struct {
 	SourceField string `json:"source_field"`
 	TargetField string `json:"target_field"`
-	Transform   string `json:"transform,omitempty"` // SQL转换表达式
+	Transform   string `json:"transform,omitempty"` // 转换表达式，语法见 Expression
+}
+
+// compiledMapping 编译后的字段映射
+type compiledMapping struct {
+	FieldMapping
+	expression *Expression
 }
 
 // CopyConfig 复制配置
 type CopyConfig struct {
-	Strategy      CopyStrategy              `json:"strategy"`
-	Scope         CopyScope                 `json:"scope"`
-	Tables        []string                  `json:"tables"`
-	Conditions    map[string]string         `json:"conditions,omitempty"`     // 表名 -> WHERE条件
-	FieldMappings map[string][]FieldMapping `json:"field_mappings,omitempty"` // 表名 -> 字段映射
-	BatchSize     int                       `json:"batch_size"`
-	Timeout       time.Duration             `json:"timeout"`
-	OnError       string                    `json:"on_error"` // "stop", "continue", "rollback"
+	Strategy       CopyStrategy                      `json:"strategy"`
+	Scope          CopyScope                         `json:"scope"`
+	Tables         []string                          `json:"tables"`
+	Conditions     map[string]string                 `json:"conditions,omitempty"`     // 表名 -> WHERE条件
+	FieldMappings  map[string][]FieldMapping         `json:"field_mappings,omitempty"` // 表名 -> 字段映射
+	BatchSize      int                               `json:"batch_size"`
+	Timeout        time.Duration                     `json:"timeout"`
+	OnError        string                            `json:"on_error"`                   // "stop", "continue", "rollback"
+	Lookups        map[string]map[string]interface{} `json:"lookups,omitempty"`          // 映射表名 -> (源值 -> 目标值)，供 LOOKUP() 使用
+	Masking        *MaskingProfile                   `json:"masking,omitempty"`          // 脱敏配置，在字段映射之后、行转换器之前执行
+	VerifyChunk    int                               `json:"verify_chunk,omitempty"`     // 校验时每个主键区间的行数，默认10000
+	Incremental    map[string]IncrementalConfig      `json:"incremental,omitempty"`      // 表名 -> 增量同步配置
+	SyncStateTable string                            `json:"sync_state_table,omitempty"` // 目标库中的同步状态表，默认 data_sync_state
+	ResetSync      bool                              `json:"reset_sync,omitempty"`       // 忽略已保存的高水位，重新全量同步
+	Transformers   []RowTransformer                  `json:"-"`                          // 行转换器，按顺序在字段映射之后执行
+}
+
+// Validate 校验复制配置，编译所有字段转换表达式和脱敏规则，确保在复制任何数据前发现错误
+func (c CopyConfig) Validate() error {
+	if _, err := compileFieldMappings(c); err != nil {
+		return err
+	}
+	if err := validateIncremental(c); err != nil {
+		return err
+	}
+	_, err := compileMaskingProfile(c.Masking)
+	return err
+}
+
+// compileFieldMappings 编译所有表的字段映射
+func compileFieldMappings(config CopyConfig) (map[string][]compiledMapping, error) {
+	compiled := make(map[string][]compiledMapping, len(config.FieldMappings))
+
+	for tableName, mappings := range config.FieldMappings {
+		for i, mapping := range mappings {
+			if mapping.SourceField == "" && mapping.TargetField == "" {
+				return nil, fmt.Errorf("表 %s 第%d个字段映射缺少 source_field 和 target_field", tableName, i+1)
+			}
+			if mapping.SourceField == "" && mapping.Transform == "" {
+				return nil, fmt.Errorf("表 %s 第%d个字段映射是计算列 %s，必须指定 transform", tableName, i+1, mapping.TargetField)
+			}
+			if mapping.TargetField == "" {
+				mapping.TargetField = mapping.SourceField
+			}
+
+			cm := compiledMapping{FieldMapping: mapping}
+			if mapping.Transform != "" {
+				expr, err := CompileExpression(mapping.Transform, config.Lookups)
+				if err != nil {
+					return nil, fmt.Errorf("表 %s 字段 %s 的转换表达式无效: %v", tableName, mapping.TargetField, err)
+				}
+				cm.expression = expr
+			}
+			compiled[tableName] = append(compiled[tableName], cm)
+		}
+	}
+
+	return compiled, nil
 }
 
 // ProgressCallback 进度回调函数
@@ -58,6 +120,9 @@ type DataCopier struct {
 	targetDB   types.DB
 	config     CopyConfig
 	onProgress ProgressCallback
+	mappings   map[string][]compiledMapping
+	masker     *masker
+	sourceName string // 来源标识，用于区分同步状态
 }
 
 // NewDataCopier 创建数据复制器
@@ -71,6 +136,12 @@ func NewDataCopier(sourceDB, targetDB types.DB, config CopyConfig) *DataCopier {
 	if config.OnError == "" {
 		config.OnError = "stop"
 	}
+	if config.VerifyChunk <= 0 {
+		config.VerifyChunk = 10000
+	}
+	if config.SyncStateTable == "" {
+		config.SyncStateTable = DefaultSyncStateTable
+	}
 
 	return &DataCopier{
 		sourceDB: sourceDB,
@@ -90,6 +161,11 @@ func (dc *DataCopier) CopyData(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, dc.config.Timeout)
 	defer cancel()
 
+	// 编译字段映射和脱敏规则，配置错误在复制任何数据前返回
+	if err := dc.prepare(); err != nil {
+		return err
+	}
+
 	var errors []string
 
 	for _, tableName := range dc.config.Tables {
@@ -122,6 +198,27 @@ func (dc *DataCopier) CopyData(ctx context.Context) error {
 	return nil
 }
 
+// prepare 编译字段映射和脱敏规则
+func (dc *DataCopier) prepare() error {
+	if err := validateIncremental(dc.config); err != nil {
+		return err
+	}
+
+	mappings, err := compileFieldMappings(dc.config)
+	if err != nil {
+		return err
+	}
+	dc.mappings = mappings
+
+	masker, err := compileMaskingProfile(dc.config.Masking)
+	if err != nil {
+		return err
+	}
+	dc.masker = masker
+
+	return nil
+}
+
 // copyTable 复制单个表
 func (dc *DataCopier) copyTable(ctx context.Context, tableName string) error {
 	// 检查源表是否存在
@@ -142,8 +239,14 @@ func (dc *DataCopier) copyTable(ctx context.Context, tableName string) error {
 		return fmt.Errorf("目标表 %s 不存在", tableName)
 	}
 
-	// 获取表总行数（用于进度显示）
-	totalRows, err := dc.getTableRowCount(dc.sourceDB, tableName)
+	// 读取增量同步状态
+	sync, err := dc.beginIncremental(tableName)
+	if err != nil {
+		return err
+	}
+
+	// 获取待复制的行数（用于进度显示）
+	totalRows, err := dc.getSourceRowCount(tableName, sync)
 	if err != nil {
 		return fmt.Errorf("获取表行数失败: %v", err)
 	}
@@ -156,19 +259,19 @@ func (dc *DataCopier) copyTable(ctx context.Context, tableName string) error {
 	}
 
 	// 执行数据复制
-	return dc.copyTableData(ctx, tableName, totalRows)
+	return dc.copyTableData(ctx, tableName, totalRows, sync)
 }
 
-// copyTableData 复制表数据
-func (dc *DataCopier) copyTableData(ctx context.Context, tableName string, totalRows int64) error {
+// copyTableData 复制表数据，sync 不为空时只复制增量数据并在完成后保存高水位
+func (dc *DataCopier) copyTableData(ctx context.Context, tableName string, totalRows int64, sync *incrementalRun) error {
 	// 构建查询SQL
-	selectSQL, err := dc.buildSelectSQL(tableName)
+	selectSQL, args, err := dc.buildSelectSQL(tableName, sync)
 	if err != nil {
 		return err
 	}
 
 	// 查询源数据
-	rows, err := dc.sourceDB.Query(selectSQL)
+	rows, err := dc.sourceDB.Query(selectSQL, args...)
 	if err != nil {
 		return fmt.Errorf("查询源数据失败: %v", err)
 	}
@@ -180,13 +283,48 @@ func (dc *DataCopier) copyTableData(ctx context.Context, tableName string, total
 		return fmt.Errorf("获取列信息失败: %v", err)
 	}
 
+	// 校验字段映射引用的源列
+	if err := dc.validateMappingColumns(tableName, columns); err != nil {
+		return err
+	}
+
 	// 映射字段名
 	targetColumns := dc.mapColumns(tableName, columns)
 
-	// 批量处理数据
+	// 敏感列必须配置脱敏规则
+	if dc.masker != nil {
+		if err := dc.masker.checkSensitiveColumns(tableName, columns, targetColumns); err != nil {
+			return err
+		}
+	}
+
+	// 定位增量列并先按墓碑表传播删除
+	if sync != nil {
+		if err := sync.bind(dc, tableName, columns); err != nil {
+			return err
+		}
+		if err := dc.applyTombstones(tableName, sync); err != nil {
+			return err
+		}
+	}
+
+	// 批量处理数据，行转换器可能改变列集合，列集合变化时先提交当前批次
 	var processedRows int64
+	batchColumns := targetColumns
 	batch := make([][]interface{}, 0, dc.config.BatchSize)
 
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := dc.insertBatch(tableName, batchColumns, batch); err != nil {
+			return fmt.Errorf("批量插入失败: %v", err)
+		}
+		processedRows += int64(len(batch))
+		batch = batch[:0] // 清空批次
+		return nil
+	}
+
 	for rows.Next() {
 		// 创建值容器
 		values := make([]interface{}, len(columns))
@@ -200,43 +338,79 @@ func (dc *DataCopier) copyTableData(ctx context.Context, tableName string, total
 			return fmt.Errorf("扫描数据失败: %v", err)
 		}
 
+		// 记录高水位，软删除的行从目标表删除而不是写入
+		if sync != nil {
+			sync.observe(values)
+			if sync.deleted(values) {
+				if len(sync.pendingDeletes) >= dc.config.BatchSize {
+					if err := dc.flushDeletes(tableName, sync); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+
 		// 转换数据
-		transformedValues, err := dc.transformValues(tableName, columns, values)
+		transformedValues, err := dc.transformValues(tableName, columns, targetColumns, values)
 		if err != nil {
 			return fmt.Errorf("转换数据失败: %v", err)
 		}
 
-		batch = append(batch, transformedValues)
+		// 执行行转换器
+		outputRows, err := dc.applyRowTransformers(ctx, tableName, targetColumns, transformedValues)
+		if err != nil {
+			return fmt.Errorf("行转换失败: %v", err)
+		}
 
-		// 达到批次大小时执行插入
-		if len(batch) >= dc.config.BatchSize {
-			if err := dc.insertBatch(tableName, targetColumns, batch); err != nil {
-				return fmt.Errorf("批量插入失败: %v", err)
+		for _, out := range outputRows {
+			if !sameColumns(batchColumns, out.columns) {
+				if err := flush(); err != nil {
+					return err
+				}
+				batchColumns = out.columns
 			}
+			batch = append(batch, out.values)
 
-			processedRows += int64(len(batch))
-			batch = batch[:0] // 清空批次
+			// 达到批次大小时执行插入
+			if len(batch) >= dc.config.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
 
-			// 通知进度
-			if dc.onProgress != nil {
-				dc.onProgress(tableName, processedRows, totalRows, nil)
-			}
+				// 通知进度
+				if dc.onProgress != nil {
+					dc.onProgress(tableName, processedRows, totalRows, nil)
+				}
 
-			// 检查上下文是否被取消
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+				// 检查上下文是否被取消
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+				}
 			}
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("读取源数据失败: %v", err)
+	}
+
 	// 处理剩余数据
-	if len(batch) > 0 {
-		if err := dc.insertBatch(tableName, targetColumns, batch); err != nil {
-			return fmt.Errorf("插入剩余数据失败: %v", err)
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// 保存增量同步状态
+	if sync != nil {
+		if err := dc.flushDeletes(tableName, sync); err != nil {
+			return err
+		}
+		sync.state.RowsCopied = processedRows
+		if err := dc.saveSyncState(sync); err != nil {
+			return err
 		}
-		processedRows += int64(len(batch))
 	}
 
 	// 通知完成
@@ -247,33 +421,85 @@ func (dc *DataCopier) copyTableData(ctx context.Context, tableName string, total
 	return nil
 }
 
-// buildSelectSQL 构建查询SQL
-func (dc *DataCopier) buildSelectSQL(tableName string) (string, error) {
-	sql := fmt.Sprintf("SELECT * FROM %s", tableName)
+// buildSelectSQL 构建查询SQL，增量同步时按增量列升序读取高水位之后的行
+func (dc *DataCopier) buildSelectSQL(tableName string, sync *incrementalRun) (string, []interface{}, error) {
+	where, args := dc.sourceFilter(tableName, sync)
+	sql := fmt.Sprintf("SELECT * FROM %s%s", sqlDialect.QuoteQualified(tableName), where)
+
+	if sync != nil {
+		sql += " ORDER BY " + sqlDialect.QuoteIdent(sync.config.Column)
+	}
+
+	return sql, args, nil
+}
+
+// sourceFilter 构建复制条件和增量条件的 WHERE 子句
+func (dc *DataCopier) sourceFilter(tableName string, sync *incrementalRun) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
 
 	// 添加条件
 	if condition, exists := dc.config.Conditions[tableName]; exists && condition != "" {
-		sql += " WHERE " + condition
+		conditions = append(conditions, "("+condition+")")
+	}
+
+	if sync != nil {
+		if condition, incArgs := sync.filter(dc.config.Strategy); condition != "" {
+			conditions = append(conditions, condition)
+			args = append(args, incArgs...)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// validateMappingColumns 校验字段映射和转换表达式引用的列在源表中存在
+func (dc *DataCopier) validateMappingColumns(tableName string, sourceColumns []string) error {
+	columnSet := make(map[string]bool, len(sourceColumns))
+	for _, col := range sourceColumns {
+		columnSet[col] = true
+	}
+
+	for _, mapping := range dc.mappings[tableName] {
+		if mapping.SourceField != "" && !columnSet[mapping.SourceField] {
+			return fmt.Errorf("字段映射引用的源列 %s 不存在", mapping.SourceField)
+		}
+		if mapping.expression == nil {
+			continue
+		}
+		for _, col := range mapping.expression.Columns() {
+			if !columnSet[col] {
+				return fmt.Errorf("字段 %s 的转换表达式引用的源列 %s 不存在", mapping.TargetField, col)
+			}
+		}
 	}
 
-	return sql, nil
+	return nil
 }
 
-// mapColumns 映射列名
+// mapColumns 映射列名，计算列追加在源列之后
 func (dc *DataCopier) mapColumns(tableName string, sourceColumns []string) []string {
-	mappings, exists := dc.config.FieldMappings[tableName]
+	mappings, exists := dc.mappings[tableName]
 	if !exists {
 		return sourceColumns
 	}
 
 	// 创建映射关系
 	fieldMap := make(map[string]string)
+	var computedColumns []string
 	for _, mapping := range mappings {
+		if mapping.SourceField == "" {
+			computedColumns = append(computedColumns, mapping.TargetField)
+			continue
+		}
 		fieldMap[mapping.SourceField] = mapping.TargetField
 	}
 
 	// 应用映射
-	targetColumns := make([]string, len(sourceColumns))
+	targetColumns := make([]string, len(sourceColumns), len(sourceColumns)+len(computedColumns))
 	for i, col := range sourceColumns {
 		if mapped, exists := fieldMap[col]; exists {
 			targetColumns[i] = mapped
@@ -282,63 +508,58 @@ func (dc *DataCopier) mapColumns(tableName string, sourceColumns []string) []str
 		}
 	}
 
-	return targetColumns
+	return append(targetColumns, computedColumns...)
 }
 
-// transformValues 转换数据值
-func (dc *DataCopier) transformValues(tableName string, columns []string, values []interface{}) ([]interface{}, error) {
-	mappings, exists := dc.config.FieldMappings[tableName]
-	if !exists {
+// transformValues 转换数据值，先执行字段转换表达式，再按目标列名执行脱敏
+func (dc *DataCopier) transformValues(tableName string, columns, targetColumns []string, values []interface{}) ([]interface{}, error) {
+	mappings, exists := dc.mappings[tableName]
+	if !exists && dc.masker == nil {
 		return values, nil
 	}
 
-	// 应用转换
-	for _, mapping := range mappings {
-		if mapping.Transform != "" {
-			// 找到对应的列索引
-			for i, col := range columns {
-				if col == mapping.SourceField {
-					// 这里简化处理，实际应该执行SQL转换
-					// 可以扩展为支持更复杂的转换逻辑
-					transformed, err := dc.applyTransform(mapping.Transform, values[i])
-					if err != nil {
-						return nil, err
-					}
-					values[i] = transformed
-					break
-				}
-			}
-		}
+	// 所有表达式都基于源数据原始值求值
+	row := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		row[col] = values[i]
 	}
 
-	return values, nil
-}
+	result := make([]interface{}, len(values), len(values)+len(mappings))
+	copy(result, values)
 
-// applyTransform 应用数据转换
-func (dc *DataCopier) applyTransform(transform string, value interface{}) (interface{}, error) {
-	// 简单的转换支持，可以扩展
-	switch transform {
-	case "UPPER":
-		if str, ok := value.(string); ok {
-			return strings.ToUpper(str), nil
+	for _, mapping := range mappings {
+		if mapping.expression == nil {
+			continue
+		}
+
+		if mapping.SourceField == "" {
+			computed, err := mapping.expression.Evaluate(row, nil)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, computed)
+			continue
 		}
-	case "LOWER":
-		if str, ok := value.(string); ok {
-			return strings.ToLower(str), nil
+
+		for i, col := range columns {
+			if col == mapping.SourceField {
+				transformed, err := mapping.expression.Evaluate(row, values[i])
+				if err != nil {
+					return nil, err
+				}
+				result[i] = transformed
+				break
+			}
 		}
-	case "NOW()":
-		return time.Now(), nil
 	}
 
-	// 正则替换示例: REPLACE(field, 'old', 'new')
-	replacePattern := regexp.MustCompile(`REPLACE\(field,\s*'([^']+)',\s*'([^']*)'\)`)
-	if matches := replacePattern.FindStringSubmatch(transform); len(matches) == 3 {
-		if str, ok := value.(string); ok {
-			return strings.ReplaceAll(str, matches[1], matches[2]), nil
+	if dc.masker != nil {
+		if err := dc.masker.apply(tableName, targetColumns, result, row); err != nil {
+			return nil, err
 		}
 	}
 
-	return value, nil
+	return result, nil
 }
 
 // insertBatch 批量插入数据
@@ -346,6 +567,13 @@ func (dc *DataCopier) insertBatch(tableName string, columns []string, batch [][]
 	if len(batch) == 0 {
 		return nil
 	}
+	for _, col := range columns {
+		if err := sqlDialect.ValidateIdent(col); err != nil {
+			return fmt.Errorf("表 %s 的列名无效: %v", tableName, err)
+		}
+	}
+	table := sqlDialect.QuoteQualified(tableName)
+	columnList := sqlDialect.QuoteIdents(columns)
 
 	// 构建插入SQL
 	var insertType string
@@ -371,19 +599,20 @@ func (dc *DataCopier) insertBatch(tableName string, columns []string, batch [][]
 		// 构建UPDATE部分
 		updateParts := make([]string, len(columns))
 		for i, col := range columns {
-			updateParts[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+			quoted := sqlDialect.QuoteIdent(col)
+			updateParts[i] = fmt.Sprintf("%s = VALUES(%s)", quoted, quoted)
 		}
 
 		sql = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE %s",
-			tableName,
-			strings.Join(columns, ", "),
+			table,
+			columnList,
 			allPlaceholders,
 			strings.Join(updateParts, ", "))
 	} else {
 		sql = fmt.Sprintf("%s INTO %s (%s) VALUES %s",
 			insertType,
-			tableName,
-			strings.Join(columns, ", "),
+			table,
+			columnList,
 			allPlaceholders)
 	}
 
@@ -409,14 +638,25 @@ func (dc *DataCopier) tableExists(db types.DB, tableName string) (bool, error) {
 	return count > 0, nil
 }
 
+// getSourceRowCount 统计源表中待复制的行数
+func (dc *DataCopier) getSourceRowCount(tableName string, sync *incrementalRun) (int64, error) {
+	if sync == nil {
+		return dc.getTableRowCount(dc.sourceDB, tableName)
+	}
+	where, args := dc.sourceFilter(tableName, sync)
+	var count int64
+	err := dc.sourceDB.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s%s", sqlDialect.QuoteQualified(tableName), where), args...).Scan(&count)
+	return count, err
+}
+
 func (dc *DataCopier) getTableRowCount(db types.DB, tableName string) (int64, error) {
 	var count int64
-	err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&count)
+	err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", sqlDialect.QuoteQualified(tableName))).Scan(&count)
 	return count, err
 }
 
 func (dc *DataCopier) truncateTable(db types.DB, tableName string) error {
-	_, err := db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", tableName))
+	_, err := db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", sqlDialect.QuoteQualified(tableName)))
 	return err
 }
 
@@ -449,6 +689,7 @@ func (cdc *CrossDatabaseCopier) CopyBetweenDatabases(ctx context.Context, source
 	// 创建复制器
 	copier := NewDataCopier(sourceConn, targetConn, config)
 	copier.SetProgressCallback(callback)
+	copier.sourceName = sourceDB
 
 	// 执行复制
 	return copier.CopyData(ctx)
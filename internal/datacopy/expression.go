@@ -0,0 +1,660 @@
+package datacopy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression 编译后的字段转换表达式
+//
+// 表达式语法是MySQL表达式的一个安全子集，完全在Go中求值，不会发送到数据库执行：
+//   - 常量：数字、'字符串'、NULL、TRUE、FALSE
+//   - 列引用：source_col 或 `source col`，field 表示当前映射的源字段值
+//   - 运算：+ - * / %、= != <> < <= > >=、AND OR NOT、IS [NOT] NULL、[NOT] IN (...)
+//   - 条件：CASE [x] WHEN ... THEN ... [ELSE ...] END、IF(cond, a, b)
+//   - 函数：见 expressionFunctions，例如 CONCAT、DATE_FORMAT、MD5、LOOKUP
+type Expression struct {
+	source  string
+	root    exprNode
+	columns []string
+	lookups map[string]map[string]interface{}
+}
+
+// CompileExpression 编译转换表达式，语法错误、未知函数和参数个数错误都会在这里报告
+func CompileExpression(source string, lookups map[string]map[string]interface{}) (*Expression, error) {
+	trimmed := strings.TrimSpace(source)
+	if trimmed == "" {
+		return nil, fmt.Errorf("表达式不能为空")
+	}
+
+	// 兼容旧写法：单独的 UPPER / LOWER / TRIM 作用于当前字段
+	switch strings.ToUpper(trimmed) {
+	case "UPPER", "LOWER", "TRIM":
+		trimmed = strings.ToUpper(trimmed) + "(field)"
+	}
+
+	tokens, err := tokenizeExpression(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("表达式 %q 解析失败: %v", source, err)
+	}
+
+	p := &exprParser{tokens: tokens, lookups: lookups, columns: make(map[string]bool)}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, fmt.Errorf("表达式 %q 解析失败: %v", source, err)
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("表达式 %q 解析失败: 第%d个字符处存在多余内容 %q", source, tok.pos+1, tok.text)
+	}
+
+	columns := make([]string, 0, len(p.columns))
+	for col := range p.columns {
+		columns = append(columns, col)
+	}
+
+	return &Expression{
+		source:  source,
+		root:    root,
+		columns: columns,
+		lookups: lookups,
+	}, nil
+}
+
+// String 返回原始表达式文本
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Columns 返回表达式引用的源列（不包括 field）
+func (e *Expression) Columns() []string {
+	return e.columns
+}
+
+// Evaluate 基于一行源数据求值，current 为当前映射字段的原始值
+func (e *Expression) Evaluate(row map[string]interface{}, current interface{}) (interface{}, error) {
+	ec := &evalContext{
+		row:     row,
+		current: current,
+		lookups: e.lookups,
+	}
+	value, err := e.root.eval(ec)
+	if err != nil {
+		return nil, fmt.Errorf("表达式 %q 求值失败: %v", e.source, err)
+	}
+	return value, nil
+}
+
+// evalContext 表达式求值上下文
+type evalContext struct {
+	row     map[string]interface{}
+	current interface{}
+	lookups map[string]map[string]interface{}
+}
+
+// 词法分析
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenQuotedIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenizeExpression 将表达式切分为词法单元
+func tokenizeExpression(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, exprToken{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case r == '\'' || r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				if c == '\\' && i+1 < len(runes) {
+					sb.WriteRune(unescapeRune(runes[i+1]))
+					i += 2
+					continue
+				}
+				if c == r {
+					// 连续两个引号表示转义
+					if i+1 < len(runes) && runes[i+1] == r {
+						sb.WriteRune(r)
+						i += 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(c)
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("第%d个字符处的字符串没有结束引号", start+1)
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: sb.String(), pos: start})
+
+		case r == '`':
+			start := i
+			end := i + 1
+			for end < len(runes) && runes[end] != '`' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("第%d个字符处的标识符没有结束反引号", start+1)
+			}
+			tokens = append(tokens, exprToken{kind: tokenQuotedIdent, text: string(runes[start+1 : end]), pos: start})
+			i = end + 1
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			seenDot := false
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || (runes[i] == '.' && !seenDot)) {
+				if runes[i] == '.' {
+					seenDot = true
+				}
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "<=", ">=", "<>", "!=":
+				tokens = append(tokens, exprToken{kind: tokenOperator, text: two, pos: start})
+				i += 2
+				continue
+			}
+			if strings.ContainsRune("+-*/%=<>", r) {
+				tokens = append(tokens, exprToken{kind: tokenOperator, text: string(r), pos: start})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("第%d个字符处存在无法识别的字符 %q", start+1, string(r))
+		}
+	}
+
+	tokens = append(tokens, exprToken{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// unescapeRune 处理反斜杠转义字符
+func unescapeRune(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	default:
+		return r
+	}
+}
+
+// 语法分析
+
+type exprParser struct {
+	tokens  []exprToken
+	pos     int
+	lookups map[string]map[string]interface{}
+	columns map[string]bool
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword 判断当前词法单元是否为指定关键字
+func (p *exprParser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *exprParser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		tok := p.peek()
+		return fmt.Errorf("第%d个字符处期望 %s，实际为 %q", tok.pos+1, keyword, tok.text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) expect(kind tokenKind, text string) error {
+	tok := p.peek()
+	if tok.kind != kind {
+		return fmt.Errorf("第%d个字符处期望 %q，实际为 %q", tok.pos+1, text, tok.text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseExpression() (exprNode, error) {
+	return p.parseOr()
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenOperator && isComparisonOperator(tok.text):
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: tok.text, left: left, right: right}, nil
+
+	case p.isKeyword("IS"):
+		p.next()
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullNode{operand: left, negate: negate}, nil
+
+	case p.isKeyword("IN") || (p.isKeyword("NOT") && p.tokens[p.pos+1].kind == tokenIdent && strings.EqualFold(p.tokens[p.pos+1].text, "IN")):
+		negate := false
+		if p.isKeyword("NOT") {
+			p.next()
+			negate = true
+		}
+		p.next() // IN
+		if err := p.expect(tokenLParen, "("); err != nil {
+			return nil, err
+		}
+		list, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("第%d个字符处的 IN 列表不能为空", tok.pos+1)
+		}
+		return &inNode{operand: left, list: list, negate: negate}, nil
+	}
+
+	return left, nil
+}
+
+func isComparisonOperator(op string) bool {
+	switch op {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOperator || (tok.text != "+" && tok.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOperator || (tok.text != "*" && tok.text != "/" && tok.text != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "-" || tok.text == "+") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "+" {
+			return operand, nil
+		}
+		return &arithmeticNode{op: "-", left: &literalNode{value: int64(0)}, right: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		if strings.Contains(tok.text, ".") {
+			f, err := strconv.ParseFloat(tok.text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("第%d个字符处的数字 %q 无效", tok.pos+1, tok.text)
+			}
+			return &literalNode{value: f}, nil
+		}
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个字符处的数字 %q 无效", tok.pos+1, tok.text)
+		}
+		return &literalNode{value: n}, nil
+
+	case tokenString:
+		return &literalNode{value: tok.text}, nil
+
+	case tokenQuotedIdent:
+		p.columns[tok.text] = true
+		return &columnNode{name: tok.text}, nil
+
+	case tokenLParen:
+		inner, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokenIdent:
+		upper := strings.ToUpper(tok.text)
+		switch upper {
+		case "NULL":
+			return &literalNode{value: nil}, nil
+		case "TRUE":
+			return &literalNode{value: true}, nil
+		case "FALSE":
+			return &literalNode{value: false}, nil
+		case "CASE":
+			return p.parseCase()
+		case "INTERVAL":
+			return p.parseInterval(tok)
+		}
+
+		if p.peek().kind == tokenLParen {
+			p.next()
+			return p.parseFunctionCall(tok)
+		}
+
+		if upper == "FIELD" {
+			return &currentFieldNode{}, nil
+		}
+
+		p.columns[tok.text] = true
+		return &columnNode{name: tok.text}, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("表达式意外结束")
+	}
+
+	return nil, fmt.Errorf("第%d个字符处存在意外的 %q", tok.pos+1, tok.text)
+}
+
+// parseArguments 解析逗号分隔的参数列表，调用前已消费左括号
+func (p *exprParser) parseArguments() ([]exprNode, error) {
+	var args []exprNode
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return args, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, fmt.Errorf("第%d个字符处期望 \",\" 或 \")\"，实际为 %q", tok.pos+1, tok.text)
+		}
+	}
+}
+
+// parseFunctionCall 解析函数调用并校验函数名与参数个数
+func (p *exprParser) parseFunctionCall(name exprToken) (exprNode, error) {
+	upper := strings.ToUpper(name.text)
+	spec, exists := expressionFunctions[upper]
+	if !exists {
+		return nil, fmt.Errorf("第%d个字符处存在未知函数 %s", name.pos+1, name.text)
+	}
+
+	args, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) < spec.minArgs || (spec.maxArgs >= 0 && len(args) > spec.maxArgs) {
+		return nil, fmt.Errorf("第%d个字符处的函数 %s 参数个数错误: %s，实际 %d 个",
+			name.pos+1, upper, describeArity(spec.minArgs, spec.maxArgs), len(args))
+	}
+
+	if upper == "LOOKUP" {
+		if err := p.validateLookup(name, args[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &callNode{name: upper, fn: spec.fn, args: args}, nil
+}
+
+// validateLookup 校验 LOOKUP 引用的映射表在配置中存在
+func (p *exprParser) validateLookup(name exprToken, tableArg exprNode) error {
+	literal, ok := tableArg.(*literalNode)
+	if !ok {
+		return fmt.Errorf("第%d个字符处的 LOOKUP 第一个参数必须是映射表名字符串", name.pos+1)
+	}
+	tableName, ok := literal.value.(string)
+	if !ok {
+		return fmt.Errorf("第%d个字符处的 LOOKUP 第一个参数必须是映射表名字符串", name.pos+1)
+	}
+	if _, exists := p.lookups[tableName]; !exists {
+		return fmt.Errorf("第%d个字符处的 LOOKUP 引用了未定义的映射表 %q", name.pos+1, tableName)
+	}
+	return nil
+}
+
+func describeArity(min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("至少 %d 个", min)
+	case min == max:
+		return fmt.Sprintf("需要 %d 个", min)
+	default:
+		return fmt.Sprintf("需要 %d-%d 个", min, max)
+	}
+}
+
+// parseCase 解析 CASE 表达式，CASE 关键字已被消费
+func (p *exprParser) parseCase() (exprNode, error) {
+	node := &caseNode{}
+
+	if !p.isKeyword("WHEN") && !p.isKeyword("END") {
+		operand, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		node.operand = operand
+	}
+
+	for p.isKeyword("WHEN") {
+		p.next()
+		cond, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		result, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		node.whens = append(node.whens, caseWhen{cond: cond, result: result})
+	}
+
+	if len(node.whens) == 0 {
+		tok := p.peek()
+		return nil, fmt.Errorf("第%d个字符处的 CASE 至少需要一个 WHEN 分支", tok.pos+1)
+	}
+
+	if p.isKeyword("ELSE") {
+		p.next()
+		elseResult, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		node.elseResult = elseResult
+	}
+
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseInterval 解析 INTERVAL n UNIT，INTERVAL 关键字已被消费
+func (p *exprParser) parseInterval(start exprToken) (exprNode, error) {
+	amount, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	unitTok := p.next()
+	if unitTok.kind != tokenIdent {
+		return nil, fmt.Errorf("第%d个字符处的 INTERVAL 缺少时间单位", start.pos+1)
+	}
+	unit := strings.ToUpper(unitTok.text)
+	if _, ok := intervalUnits[unit]; !ok {
+		return nil, fmt.Errorf("第%d个字符处存在不支持的时间单位 %s", unitTok.pos+1, unitTok.text)
+	}
+	return &intervalNode{amount: amount, unit: unit}, nil
+}
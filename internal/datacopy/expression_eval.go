@@ -0,0 +1,607 @@
+package datacopy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exprNode 表达式语法树节点
+type exprNode interface {
+	eval(ec *evalContext) (interface{}, error)
+}
+
+// literalNode 常量
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(ec *evalContext) (interface{}, error) {
+	return n.value, nil
+}
+
+// columnNode 源列引用
+type columnNode struct {
+	name string
+}
+
+func (n *columnNode) eval(ec *evalContext) (interface{}, error) {
+	value, exists := ec.row[n.name]
+	if !exists {
+		return nil, fmt.Errorf("源数据中不存在列 %s", n.name)
+	}
+	return normalizeValue(value), nil
+}
+
+// currentFieldNode 当前映射字段（field）
+type currentFieldNode struct{}
+
+func (n *currentFieldNode) eval(ec *evalContext) (interface{}, error) {
+	return normalizeValue(ec.current), nil
+}
+
+// logicalNode AND / OR
+type logicalNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (n *logicalNode) eval(ec *evalContext) (interface{}, error) {
+	left, err := n.left.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	leftTrue := isTruthy(left)
+	if n.op == "AND" && !leftTrue && left != nil {
+		return false, nil
+	}
+	if n.op == "OR" && leftTrue {
+		return true, nil
+	}
+
+	right, err := n.right.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	rightTrue := isTruthy(right)
+
+	if n.op == "AND" {
+		if left == nil || right == nil {
+			if right != nil && !rightTrue {
+				return false, nil
+			}
+			return nil, nil
+		}
+		return rightTrue, nil
+	}
+
+	if rightTrue {
+		return true, nil
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+	return false, nil
+}
+
+// notNode NOT
+type notNode struct {
+	operand exprNode
+}
+
+func (n *notNode) eval(ec *evalContext) (interface{}, error) {
+	value, err := n.operand.eval(ec)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return !isTruthy(value), nil
+}
+
+// compareNode 比较运算
+type compareNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (n *compareNode) eval(ec *evalContext) (interface{}, error) {
+	left, err := n.left.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	cmp, err := compareValues(left, right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "=":
+		return cmp == 0, nil
+	case "!=", "<>":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return nil, fmt.Errorf("未知的比较运算符 %s", n.op)
+}
+
+// isNullNode IS [NOT] NULL
+type isNullNode struct {
+	operand exprNode
+	negate  bool
+}
+
+func (n *isNullNode) eval(ec *evalContext) (interface{}, error) {
+	value, err := n.operand.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	return (value == nil) != n.negate, nil
+}
+
+// inNode [NOT] IN (...)
+type inNode struct {
+	operand exprNode
+	list    []exprNode
+	negate  bool
+}
+
+func (n *inNode) eval(ec *evalContext) (interface{}, error) {
+	value, err := n.operand.eval(ec)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	for _, item := range n.list {
+		candidate, err := item.eval(ec)
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil {
+			continue
+		}
+		cmp, err := compareValues(value, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if cmp == 0 {
+			return !n.negate, nil
+		}
+	}
+	return n.negate, nil
+}
+
+// arithmeticNode 四则运算
+type arithmeticNode struct {
+	op    string
+	left  exprNode
+	right exprNode
+}
+
+func (n *arithmeticNode) eval(ec *evalContext) (interface{}, error) {
+	left, err := n.left.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(ec)
+	if err != nil {
+		return nil, err
+	}
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	// 时间 +/- INTERVAL
+	if iv, ok := right.(intervalValue); ok && (n.op == "+" || n.op == "-") {
+		t, err := toTime(left)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "-" {
+			iv.amount = -iv.amount
+		}
+		return iv.addTo(t), nil
+	}
+
+	l, err := toNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := toNumber(right)
+	if err != nil {
+		return nil, err
+	}
+
+	li, lInt := l.(int64)
+	ri, rInt := r.(int64)
+	if lInt && rInt {
+		switch n.op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "%":
+			if ri == 0 {
+				return nil, nil
+			}
+			return li % ri, nil
+		}
+	}
+
+	lf, rf := toFloat(l), toFloat(r)
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, nil // 与MySQL一致，除以0得到NULL
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("未知的运算符 %s", n.op)
+}
+
+// caseNode CASE 表达式
+type caseNode struct {
+	operand    exprNode
+	whens      []caseWhen
+	elseResult exprNode
+}
+
+type caseWhen struct {
+	cond   exprNode
+	result exprNode
+}
+
+func (n *caseNode) eval(ec *evalContext) (interface{}, error) {
+	var operand interface{}
+	if n.operand != nil {
+		value, err := n.operand.eval(ec)
+		if err != nil {
+			return nil, err
+		}
+		operand = value
+	}
+
+	for _, when := range n.whens {
+		cond, err := when.cond.eval(ec)
+		if err != nil {
+			return nil, err
+		}
+
+		matched := false
+		if n.operand != nil {
+			if operand != nil && cond != nil {
+				cmp, err := compareValues(operand, cond)
+				if err != nil {
+					return nil, err
+				}
+				matched = cmp == 0
+			}
+		} else {
+			matched = isTruthy(cond)
+		}
+
+		if matched {
+			return when.result.eval(ec)
+		}
+	}
+
+	if n.elseResult != nil {
+		return n.elseResult.eval(ec)
+	}
+	return nil, nil
+}
+
+// callNode 函数调用
+type callNode struct {
+	name string
+	fn   exprFunc
+	args []exprNode
+}
+
+func (n *callNode) eval(ec *evalContext) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(ec)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+	value, err := n.fn(ec, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.name, err)
+	}
+	return value, nil
+}
+
+// intervalNode INTERVAL n UNIT
+type intervalNode struct {
+	amount exprNode
+	unit   string
+}
+
+func (n *intervalNode) eval(ec *evalContext) (interface{}, error) {
+	value, err := n.amount.eval(ec)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	amount, err := toInt(value)
+	if err != nil {
+		return nil, err
+	}
+	return intervalValue{amount: amount, unit: n.unit}, nil
+}
+
+// intervalValue 时间间隔
+type intervalValue struct {
+	amount int64
+	unit   string
+}
+
+var intervalUnits = map[string]bool{
+	"SECOND": true,
+	"MINUTE": true,
+	"HOUR":   true,
+	"DAY":    true,
+	"WEEK":   true,
+	"MONTH":  true,
+	"YEAR":   true,
+}
+
+func (iv intervalValue) addTo(t time.Time) time.Time {
+	n := int(iv.amount)
+	switch iv.unit {
+	case "SECOND":
+		return t.Add(time.Duration(n) * time.Second)
+	case "MINUTE":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "HOUR":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "DAY":
+		return t.AddDate(0, 0, n)
+	case "WEEK":
+		return t.AddDate(0, 0, 7*n)
+	case "MONTH":
+		return t.AddDate(0, n, 0)
+	case "YEAR":
+		return t.AddDate(n, 0, 0)
+	}
+	return t
+}
+
+// 值转换辅助函数
+
+// normalizeValue 将驱动返回的值统一为 string / int64 / float64 / bool / time.Time
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return float64(v)
+		}
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+// toString 将值转换为字符串
+func toString(value interface{}) string {
+	switch v := normalizeValue(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// toNumber 将值转换为 int64 或 float64
+func toNumber(value interface{}) (interface{}, error) {
+	switch v := normalizeValue(value).(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("无法将 %q 转换为数字", v)
+	case time.Time:
+		return v.Unix(), nil
+	default:
+		return nil, fmt.Errorf("无法将 %v 转换为数字", v)
+	}
+}
+
+func toFloat(number interface{}) float64 {
+	switch v := number.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// toInt 将值转换为整数（小数四舍五入）
+func toInt(value interface{}) (int64, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := n.(int64); ok {
+		return i, nil
+	}
+	return int64(math.Round(n.(float64))), nil
+}
+
+// timeLayouts 字符串转时间时尝试的格式
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// toTime 将值转换为时间
+func toTime(value interface{}) (time.Time, error) {
+	switch v := normalizeValue(value).(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("无法将 %q 转换为日期时间", v)
+	case int64:
+		return time.Unix(v, 0), nil
+	default:
+		return time.Time{}, fmt.Errorf("无法将 %v 转换为日期时间", v)
+	}
+}
+
+// isTruthy 判断条件值是否为真（NULL 为假）
+func isTruthy(value interface{}) bool {
+	switch v := normalizeValue(value).(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		n, err := toNumber(v)
+		if err != nil {
+			return false
+		}
+		return toFloat(n) != 0
+	case time.Time:
+		return !v.IsZero()
+	}
+	return true
+}
+
+// compareValues 比较两个非NULL值，数字按数值比较，时间按时间比较，其余按字符串比较
+func compareValues(a, b interface{}) (int, error) {
+	a, b = normalizeValue(a), normalizeValue(b)
+
+	if ta, ok := a.(time.Time); ok {
+		tb, err := toTime(b)
+		if err != nil {
+			return 0, err
+		}
+		return compareTime(ta, tb), nil
+	}
+	if tb, ok := b.(time.Time); ok {
+		ta, err := toTime(a)
+		if err != nil {
+			return 0, err
+		}
+		return compareTime(ta, tb), nil
+	}
+
+	_, aStr := a.(string)
+	_, bStr := b.(string)
+	if !aStr || !bStr {
+		na, errA := toNumber(a)
+		nb, errB := toNumber(b)
+		if errA == nil && errB == nil {
+			fa, fb := toFloat(na), toFloat(nb)
+			switch {
+			case fa < fb:
+				return -1, nil
+			case fa > fb:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+
+	return strings.Compare(toString(a), toString(b)), nil
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,496 @@
+package datacopy
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// exprFunc 表达式函数实现
+type exprFunc func(ec *evalContext, args []interface{}) (interface{}, error)
+
+// exprFuncSpec 函数定义（maxArgs 为 -1 表示不限）
+type exprFuncSpec struct {
+	minArgs int
+	maxArgs int
+	fn      exprFunc
+}
+
+// expressionFunctions 表达式支持的函数
+var expressionFunctions map[string]exprFuncSpec
+
+func init() {
+	expressionFunctions = map[string]exprFuncSpec{
+		// 字符串函数
+		"UPPER": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return strings.ToUpper(toString(args[0])), nil })},
+		"LOWER": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return strings.ToLower(toString(args[0])), nil })},
+		"TRIM":  {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return strings.TrimSpace(toString(args[0])), nil })},
+		"LTRIM": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) {
+			return strings.TrimLeft(toString(args[0]), " \t\r\n"), nil
+		})},
+		"RTRIM": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) {
+			return strings.TrimRight(toString(args[0]), " \t\r\n"), nil
+		})},
+		"LENGTH": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return int64(len(toString(args[0]))), nil })},
+		"CHAR_LENGTH": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) {
+			return int64(utf8.RuneCountInString(toString(args[0]))), nil
+		})},
+		"REVERSE":   {1, 1, nullSafe(fnReverse)},
+		"SUBSTRING": {2, 3, nullSafe(fnSubstring)},
+		"SUBSTR":    {2, 3, nullSafe(fnSubstring)},
+		"LEFT":      {2, 2, nullSafe(fnLeft)},
+		"RIGHT":     {2, 2, nullSafe(fnRight)},
+		"REPLACE": {3, 3, nullSafe(func(args []interface{}) (interface{}, error) {
+			return strings.ReplaceAll(toString(args[0]), toString(args[1]), toString(args[2])), nil
+		})},
+		"LPAD":      {3, 3, nullSafe(func(args []interface{}) (interface{}, error) { return pad(args, true) })},
+		"RPAD":      {3, 3, nullSafe(func(args []interface{}) (interface{}, error) { return pad(args, false) })},
+		"CONCAT":    {1, -1, nullSafe(fnConcat)},
+		"CONCAT_WS": {2, -1, fnConcatWS},
+
+		// 空值与条件函数
+		"COALESCE": {1, -1, fnCoalesce},
+		"IFNULL":   {2, 2, fnCoalesce},
+		"NULLIF":   {2, 2, fnNullIf},
+		"IF":       {3, 3, fnIf},
+
+		// 数学函数
+		"ABS":     {1, 1, nullSafe(fnAbs)},
+		"ROUND":   {1, 2, nullSafe(fnRound)},
+		"FLOOR":   {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return mathFloat(args[0], math.Floor) })},
+		"CEIL":    {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return mathFloat(args[0], math.Ceil) })},
+		"CEILING": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return mathFloat(args[0], math.Ceil) })},
+		"MOD":     {2, 2, nullSafe(fnMod)},
+
+		// 日期函数
+		"NOW": {0, 0, func(ec *evalContext, args []interface{}) (interface{}, error) { return time.Now(), nil }},
+		"CURDATE": {0, 0, func(ec *evalContext, args []interface{}) (interface{}, error) {
+			return time.Now().Format("2006-01-02"), nil
+		}},
+		"DATE":           {1, 1, nullSafe(fnDate)},
+		"DATE_FORMAT":    {2, 2, nullSafe(fnDateFormat)},
+		"DATE_ADD":       {2, 2, nullSafe(func(args []interface{}) (interface{}, error) { return dateAdd(args, 1) })},
+		"DATE_SUB":       {2, 2, nullSafe(func(args []interface{}) (interface{}, error) { return dateAdd(args, -1) })},
+		"UNIX_TIMESTAMP": {0, 1, fnUnixTimestamp},
+		"FROM_UNIXTIME":  {1, 2, nullSafe(fnFromUnixTime)},
+
+		// 哈希函数
+		"MD5":  {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return hashHex(md5.New(), args[0]), nil })},
+		"SHA1": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) { return hashHex(sha1.New(), args[0]), nil })},
+		"SHA2": {2, 2, nullSafe(fnSHA2)},
+		"CRC32": {1, 1, nullSafe(func(args []interface{}) (interface{}, error) {
+			return int64(crc32.ChecksumIEEE([]byte(toString(args[0])))), nil
+		})},
+		"UUID": {0, 0, fnUUID},
+
+		// 映射表查询: LOOKUP('映射表名', key [, 默认值])
+		"LOOKUP": {2, 3, fnLookup},
+	}
+}
+
+// nullSafe 包装函数：任一参数为NULL时返回NULL（MySQL语义）
+func nullSafe(fn func(args []interface{}) (interface{}, error)) exprFunc {
+	return func(ec *evalContext, args []interface{}) (interface{}, error) {
+		for _, arg := range args {
+			if arg == nil {
+				return nil, nil
+			}
+		}
+		return fn(args)
+	}
+}
+
+func fnReverse(args []interface{}) (interface{}, error) {
+	runes := []rune(toString(args[0]))
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes), nil
+}
+
+// fnSubstring SUBSTRING(str, pos [, len])，pos 从1开始，负数表示从末尾计算
+func fnSubstring(args []interface{}) (interface{}, error) {
+	runes := []rune(toString(args[0]))
+	pos, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var start int
+	switch {
+	case pos > 0:
+		start = int(pos) - 1
+	case pos < 0:
+		start = len(runes) + int(pos)
+	default:
+		return "", nil
+	}
+	if start < 0 || start >= len(runes) {
+		return "", nil
+	}
+
+	end := len(runes)
+	if len(args) == 3 {
+		length, err := toInt(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if length <= 0 {
+			return "", nil
+		}
+		if start+int(length) < end {
+			end = start + int(length)
+		}
+	}
+	return string(runes[start:end]), nil
+}
+
+func fnLeft(args []interface{}) (interface{}, error) {
+	runes := []rune(toString(args[0]))
+	n, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return "", nil
+	}
+	if int(n) > len(runes) {
+		n = int64(len(runes))
+	}
+	return string(runes[:n]), nil
+}
+
+func fnRight(args []interface{}) (interface{}, error) {
+	runes := []rune(toString(args[0]))
+	n, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return "", nil
+	}
+	if int(n) > len(runes) {
+		n = int64(len(runes))
+	}
+	return string(runes[len(runes)-int(n):]), nil
+}
+
+// pad 实现 LPAD / RPAD，结果长度超出时截断
+func pad(args []interface{}, left bool) (interface{}, error) {
+	runes := []rune(toString(args[0]))
+	length, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	padding := []rune(toString(args[2]))
+
+	if length < 0 {
+		return nil, nil
+	}
+	if int(length) <= len(runes) {
+		return string(runes[:length]), nil
+	}
+	if len(padding) == 0 {
+		return nil, nil
+	}
+
+	fill := make([]rune, 0, int(length)-len(runes))
+	for len(fill) < int(length)-len(runes) {
+		fill = append(fill, padding[len(fill)%len(padding)])
+	}
+	if left {
+		return string(fill) + string(runes), nil
+	}
+	return string(runes) + string(fill), nil
+}
+
+func fnConcat(args []interface{}) (interface{}, error) {
+	var sb strings.Builder
+	for _, arg := range args {
+		sb.WriteString(toString(arg))
+	}
+	return sb.String(), nil
+}
+
+// fnConcatWS CONCAT_WS(sep, a, b, ...)，跳过NULL参数
+func fnConcatWS(ec *evalContext, args []interface{}) (interface{}, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	var parts []string
+	for _, arg := range args[1:] {
+		if arg != nil {
+			parts = append(parts, toString(arg))
+		}
+	}
+	return strings.Join(parts, toString(args[0])), nil
+}
+
+func fnCoalesce(ec *evalContext, args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnNullIf(ec *evalContext, args []interface{}) (interface{}, error) {
+	if args[0] == nil || args[1] == nil {
+		return args[0], nil
+	}
+	cmp, err := compareValues(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if cmp == 0 {
+		return nil, nil
+	}
+	return args[0], nil
+}
+
+func fnIf(ec *evalContext, args []interface{}) (interface{}, error) {
+	if isTruthy(args[0]) {
+		return args[1], nil
+	}
+	return args[2], nil
+}
+
+func fnAbs(args []interface{}) (interface{}, error) {
+	n, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := n.(int64); ok {
+		if i < 0 {
+			return -i, nil
+		}
+		return i, nil
+	}
+	return math.Abs(n.(float64)), nil
+}
+
+func fnRound(args []interface{}) (interface{}, error) {
+	n, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	decimals := int64(0)
+	if len(args) == 2 {
+		if decimals, err = toInt(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	if i, ok := n.(int64); ok && decimals >= 0 {
+		return i, nil
+	}
+	factor := math.Pow(10, float64(decimals))
+	rounded := math.Round(toFloat(n)*factor) / factor
+	if decimals <= 0 {
+		return int64(rounded), nil
+	}
+	return rounded, nil
+}
+
+func mathFloat(value interface{}, fn func(float64) float64) (interface{}, error) {
+	n, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := n.(int64); ok {
+		return i, nil
+	}
+	return int64(fn(n.(float64))), nil
+}
+
+func fnMod(args []interface{}) (interface{}, error) {
+	node := &arithmeticNode{op: "%", left: &literalNode{value: args[0]}, right: &literalNode{value: args[1]}}
+	return node.eval(nil)
+}
+
+func fnDate(args []interface{}) (interface{}, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+func fnDateFormat(args []interface{}) (interface{}, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return formatMySQLDate(t, toString(args[1])), nil
+}
+
+// dateAdd DATE_ADD(date, INTERVAL n UNIT)，第二个参数为整数时按天计算
+func dateAdd(args []interface{}, sign int64) (interface{}, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+	iv, ok := args[1].(intervalValue)
+	if !ok {
+		days, err := toInt(args[1])
+		if err != nil {
+			return nil, err
+		}
+		iv = intervalValue{amount: days, unit: "DAY"}
+	}
+	iv.amount *= sign
+	return iv.addTo(t), nil
+}
+
+func fnUnixTimestamp(ec *evalContext, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return time.Now().Unix(), nil
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+	t, err := toTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return t.Unix(), nil
+}
+
+func fnFromUnixTime(args []interface{}) (interface{}, error) {
+	seconds, err := toInt(args[0])
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(seconds, 0)
+	if len(args) == 2 {
+		return formatMySQLDate(t, toString(args[1])), nil
+	}
+	return t, nil
+}
+
+func hashHex(h interface {
+	Write([]byte) (int, error)
+	Sum([]byte) []byte
+}, value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		h.Write(b)
+	} else {
+		h.Write([]byte(toString(value)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fnSHA2(args []interface{}) (interface{}, error) {
+	bits, err := toInt(args[1])
+	if err != nil {
+		return nil, err
+	}
+	switch bits {
+	case 0, 256:
+		return hashHex(sha256.New(), args[0]), nil
+	case 224:
+		return hashHex(sha256.New224(), args[0]), nil
+	case 384:
+		return hashHex(sha512.New384(), args[0]), nil
+	case 512:
+		return hashHex(sha512.New(), args[0]), nil
+	}
+	return nil, fmt.Errorf("不支持的哈希长度 %d", bits)
+}
+
+func fnUUID(ec *evalContext, args []interface{}) (interface{}, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// fnLookup 从配置的映射表中查找值，找不到时返回默认值或NULL
+func fnLookup(ec *evalContext, args []interface{}) (interface{}, error) {
+	table := ec.lookups[toString(args[0])]
+	if args[1] != nil {
+		if value, exists := table[toString(args[1])]; exists {
+			return value, nil
+		}
+	}
+	if len(args) == 3 {
+		return args[2], nil
+	}
+	return nil, nil
+}
+
+// formatMySQLDate 按MySQL DATE_FORMAT 格式说明符格式化时间
+func formatMySQLDate(t time.Time, format string) string {
+	var sb strings.Builder
+	runes := []rune(format)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' || i+1 >= len(runes) {
+			sb.WriteRune(runes[i])
+			continue
+		}
+		i++
+		switch runes[i] {
+		case 'Y':
+			sb.WriteString(t.Format("2006"))
+		case 'y':
+			sb.WriteString(t.Format("06"))
+		case 'm':
+			sb.WriteString(t.Format("01"))
+		case 'c':
+			sb.WriteString(fmt.Sprintf("%d", int(t.Month())))
+		case 'M':
+			sb.WriteString(t.Format("January"))
+		case 'b':
+			sb.WriteString(t.Format("Jan"))
+		case 'd':
+			sb.WriteString(t.Format("02"))
+		case 'e':
+			sb.WriteString(fmt.Sprintf("%d", t.Day()))
+		case 'j':
+			sb.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case 'H':
+			sb.WriteString(t.Format("15"))
+		case 'k':
+			sb.WriteString(fmt.Sprintf("%d", t.Hour()))
+		case 'h', 'I':
+			sb.WriteString(t.Format("03"))
+		case 'l':
+			sb.WriteString(t.Format("3"))
+		case 'i':
+			sb.WriteString(t.Format("04"))
+		case 's', 'S':
+			sb.WriteString(t.Format("05"))
+		case 'f':
+			sb.WriteString(fmt.Sprintf("%06d", t.Nanosecond()/1000))
+		case 'p':
+			sb.WriteString(t.Format("PM"))
+		case 'W':
+			sb.WriteString(t.Format("Monday"))
+		case 'a':
+			sb.WriteString(t.Format("Mon"))
+		case 'T':
+			sb.WriteString(t.Format("15:04:05"))
+		case 'r':
+			sb.WriteString(t.Format("03:04:05 PM"))
+		case '%':
+			sb.WriteRune('%')
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+
+	return sb.String()
+}
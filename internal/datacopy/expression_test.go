@@ -0,0 +1,206 @@
+package datacopy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// evalExpression 编译并基于测试行求值
+func evalExpression(t *testing.T, source string) interface{} {
+	t.Helper()
+	row := map[string]interface{}{
+		"id":      int64(7),
+		"name":    []byte("Alice"),
+		"price":   "12.50",
+		"qty":     uint32(3),
+		"deleted": nil,
+		"created": time.Date(2024, 1, 31, 10, 0, 0, 0, time.Local),
+	}
+	lookups := map[string]map[string]interface{}{"status": {"1": "active"}}
+
+	expr, err := CompileExpression(source, lookups)
+	if err != nil {
+		t.Fatalf("编译 %q 失败: %v", source, err)
+	}
+	value, err := expr.Evaluate(row, "  field value ")
+	if err != nil {
+		t.Fatalf("求值 %q 失败: %v", source, err)
+	}
+	return value
+}
+
+func TestExpressionPrecedence(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"1 + 2 * 3", int64(7)},
+		{"(1 + 2) * 3", int64(9)},
+		{"10 - 4 - 3", int64(3)},
+		{"2 * 3 % 4", int64(2)},
+		{"-2 * 3", int64(-6)},
+		{"- -2", int64(2)},
+		{"7 / 2", 3.5},
+		{"1 + 2 = 3", true},
+		{"1 < 2 AND 3 < 2", false},
+		{"TRUE OR FALSE AND FALSE", true},
+		{"(TRUE OR FALSE) AND FALSE", false},
+		{"NOT 1 = 2", true},
+		{"NOT TRUE AND FALSE", false},
+		{"NOT (TRUE AND FALSE)", true},
+		{"id + 1 IN (7, 8)", true},
+		{"id NOT IN (1, 2)", true},
+		{"CASE WHEN id > 5 THEN 'big' ELSE 'small' END", "big"},
+		{"CASE id WHEN 1 THEN 'one' WHEN 7 THEN 'seven' END", "seven"},
+		{"IF(id % 2 = 1, 'odd', 'even')", "odd"},
+		{"UPPER", "  FIELD VALUE "},
+		{"TRIM(field)", "field value"},
+		{"CONCAT(name, '-', id)", "Alice-7"},
+		{"LOOKUP('status', 1)", "active"},
+	}
+	for _, tt := range tests {
+		if got := evalExpression(t, tt.source); got != tt.want {
+			t.Errorf("%s = %#v，期望 %#v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionNullSemantics(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"NULL + 1", nil},
+		{"deleted * 2", nil},
+		{"NULL = NULL", nil},
+		{"deleted = 1", nil},
+		{"deleted IS NULL", true},
+		{"deleted IS NOT NULL", false},
+		{"NOT NULL", nil},
+		{"NULL AND FALSE", false},
+		{"FALSE AND NULL", false},
+		{"NULL AND TRUE", nil},
+		{"NULL OR TRUE", true},
+		{"NULL OR FALSE", nil},
+		{"deleted IN (1, 2)", nil},
+		{"1 IN (NULL, 1)", true},
+		{"CASE deleted WHEN NULL THEN 'x' ELSE 'y' END", "y"},
+		{"CASE WHEN NULL THEN 'x' END", nil},
+		{"COALESCE(deleted, NULL, 'fallback')", "fallback"},
+		{"IFNULL(deleted, 0)", int64(0)},
+		{"NULLIF(id, 7)", nil},
+		{"CONCAT('a', NULL)", nil},
+		{"CONCAT_WS('-', 'a', NULL, 'b')", "a-b"},
+		{"1 / 0", nil},
+		{"5 % 0", nil},
+	}
+	for _, tt := range tests {
+		if got := evalExpression(t, tt.source); got != tt.want {
+			t.Errorf("%s = %#v，期望 %#v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionTypeCoercion(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"price * 2", 25.0},
+		{"qty + 1", int64(4)},
+		{"'3' + 4", int64(7)},
+		{"'2.5' + 1", 3.5},
+		{"TRUE + 1", int64(2)},
+		{"price > 9", true},
+		{"'10' > '9'", false},
+		{"10 > '9'", true},
+		{"name = 'Alice'", true},
+		{"id = '7'", true},
+		{"created > '2024-01-01'", true},
+		{"created + INTERVAL 1 MONTH = '2024-03-02 10:00:00'", true},
+		{"DATE_FORMAT(created - INTERVAL 1 DAY, '%Y-%m-%d')", "2024-01-30"},
+		{"CONCAT(1.5, TRUE)", "1.51"},
+		{"LENGTH('中文')", int64(6)},
+		{"CHAR_LENGTH('中文')", int64(2)},
+		{"'abc'", "abc"},
+		{"'it''s'", "it's"},
+		{"'a\\tb'", "a\tb"},
+	}
+	for _, tt := range tests {
+		if got := evalExpression(t, tt.source); got != tt.want {
+			t.Errorf("%s = %#v，期望 %#v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionCompileErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"", "表达式不能为空"},
+		{"'abc", "第1个字符处的字符串没有结束引号"},
+		{"1 + `col", "第5个字符处的标识符没有结束反引号"},
+		{"1 # 2", "第3个字符处存在无法识别的字符"},
+		{"1 2", "第3个字符处存在多余内容"},
+		{"1 +", "表达式意外结束"},
+		{"(1 + 2", "第7个字符处期望 \")\""},
+		{"NOPE(1)", "第1个字符处存在未知函数 NOPE"},
+		{"UPPER(1, 2)", "第1个字符处的函数 UPPER 参数个数错误: 需要 1 个，实际 2 个"},
+		{"CONCAT()", "至少 1 个，实际 0 个"},
+		{"x IN ()", "第3个字符处的 IN 列表不能为空"},
+		{"CASE END", "第6个字符处的 CASE 至少需要一个 WHEN 分支"},
+		{"CASE WHEN 1 'a' END", "第13个字符处期望 THEN"},
+		{"created + INTERVAL 1 FORTNIGHT", "第22个字符处存在不支持的时间单位 FORTNIGHT"},
+		{"LOOKUP('missing', 1)", "第1个字符处的 LOOKUP 引用了未定义的映射表 \"missing\""},
+		{"LOOKUP(name, 1)", "LOOKUP 第一个参数必须是映射表名字符串"},
+		{"中文 + )", "第6个字符处存在意外的 \")\""},
+	}
+	lookups := map[string]map[string]interface{}{"status": {}}
+	for _, tt := range tests {
+		_, err := CompileExpression(tt.source, lookups)
+		if err == nil {
+			t.Errorf("%q 应该编译失败", tt.source)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q 的错误为 %q，期望包含 %q", tt.source, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestExpressionEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"missing + 1", "源数据中不存在列 missing"},
+		{"name + 1", "无法将 \"Alice\" 转换为数字"},
+		{"name + INTERVAL 1 DAY", "无法将 \"Alice\" 转换为日期时间"},
+	}
+	for _, tt := range tests {
+		expr, err := CompileExpression(tt.source, nil)
+		if err != nil {
+			t.Fatalf("编译 %q 失败: %v", tt.source, err)
+		}
+		_, err = expr.Evaluate(map[string]interface{}{"name": "Alice"}, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q 的错误为 %v，期望包含 %q", tt.source, err, tt.want)
+		}
+	}
+}
+
+func TestExpressionColumns(t *testing.T) {
+	expr, err := CompileExpression("CONCAT(first_name, ' ', `last name`, field)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := map[string]bool{}
+	for _, col := range expr.Columns() {
+		columns[col] = true
+	}
+	if len(columns) != 2 || !columns["first_name"] || !columns["last name"] {
+		t.Errorf("Columns() = %v，期望 first_name 和 last name", expr.Columns())
+	}
+}
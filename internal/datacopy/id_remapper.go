@@ -0,0 +1,293 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// IDAllocator 为源记录ID分配目标ID
+type IDAllocator interface {
+	Allocate(ctx context.Context, table string, sourceID interface{}) (interface{}, error)
+}
+
+// IDAllocatorFunc 函数形式的ID分配器
+type IDAllocatorFunc func(ctx context.Context, table string, sourceID interface{}) (interface{}, error)
+
+// Allocate 实现 IDAllocator
+func (f IDAllocatorFunc) Allocate(ctx context.Context, table string, sourceID interface{}) (interface{}, error) {
+	return f(ctx, table, sourceID)
+}
+
+// KeepIDs 保留原ID，只记录映射关系（用于让依赖表的外键校验生效）
+func KeepIDs() IDAllocator {
+	return IDAllocatorFunc(func(ctx context.Context, table string, sourceID interface{}) (interface{}, error) {
+		return sourceID, nil
+	})
+}
+
+// OffsetIDs 目标ID = 源ID + offset，适合合并多个来源时错开ID区间
+func OffsetIDs(offset int64) IDAllocator {
+	return IDAllocatorFunc(func(ctx context.Context, table string, sourceID interface{}) (interface{}, error) {
+		id, err := toInt(sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("源ID %v 不是整数: %v", sourceID, err)
+		}
+		return id + offset, nil
+	})
+}
+
+// SequentialIDs 从 start 开始顺序分配目标ID
+func SequentialIDs(start int64) IDAllocator {
+	var mu sync.Mutex
+	next := start
+	return IDAllocatorFunc(func(ctx context.Context, table string, sourceID interface{}) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := next
+		next++
+		return id, nil
+	})
+}
+
+// AfterMaxID 从目标表当前最大ID之后开始顺序分配，首次分配时查询 MAX(column)
+func AfterMaxID(targetDB types.DB, column string) IDAllocator {
+	var mu sync.Mutex
+	next := make(map[string]int64)
+	return IDAllocatorFunc(func(ctx context.Context, table string, sourceID interface{}) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		id, loaded := next[table]
+		if !loaded {
+			var maxID int64
+			query := fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) FROM %s", sqlDialect.QuoteIdent(column), sqlDialect.QuoteQualified(table))
+			if err := targetDB.QueryRow(query).Scan(&maxID); err != nil {
+				return nil, fmt.Errorf("查询目标表 %s 最大ID失败: %v", table, err)
+			}
+			id = maxID + 1
+		}
+		next[table] = id + 1
+		return id, nil
+	})
+}
+
+// MissingReferencePolicy 外键引用的源ID没有映射时的处理方式
+type MissingReferencePolicy string
+
+const (
+	MissingReferenceError MissingReferencePolicy = "error" // 返回错误（默认）
+	MissingReferenceKeep  MissingReferencePolicy = "keep"  // 保留原值
+	MissingReferenceNull  MissingReferencePolicy = "null"  // 置为NULL
+	MissingReferenceSkip  MissingReferencePolicy = "skip"  // 跳过该行
+)
+
+// idKeyRule 主键重映射规则
+type idKeyRule struct {
+	column    string
+	allocator IDAllocator
+}
+
+// idReferenceRule 外键重写规则
+type idReferenceRule struct {
+	column   string
+	refTable string
+}
+
+// IDRemapper 内置的ID重映射转换器
+//
+// 为主表分配新ID并记录 源ID -> 目标ID，随后在依赖表中按记录改写外键列。
+// 依赖表必须排在被引用表之后复制（CopyConfig.Tables 的顺序）。
+type IDRemapper struct {
+	mu        sync.Mutex
+	keys      map[string]idKeyRule
+	refs      map[string][]idReferenceRule
+	mappings  map[string]map[string]interface{}
+	onMissing MissingReferencePolicy
+}
+
+// NewIDRemapper 创建ID重映射转换器
+func NewIDRemapper() *IDRemapper {
+	return &IDRemapper{
+		keys:      make(map[string]idKeyRule),
+		refs:      make(map[string][]idReferenceRule),
+		mappings:  make(map[string]map[string]interface{}),
+		onMissing: MissingReferenceError,
+	}
+}
+
+// RemapKey 为表的主键列分配新ID
+func (r *IDRemapper) RemapKey(table, column string, allocator IDAllocator) *IDRemapper {
+	r.keys[table] = idKeyRule{column: column, allocator: allocator}
+	return r
+}
+
+// RemapReference 按 refTable 的ID映射改写 table.column
+func (r *IDRemapper) RemapReference(table, column, refTable string) *IDRemapper {
+	r.refs[table] = append(r.refs[table], idReferenceRule{column: column, refTable: refTable})
+	return r
+}
+
+// OnMissingReference 设置外键找不到映射时的处理方式
+func (r *IDRemapper) OnMissingReference(policy MissingReferencePolicy) *IDRemapper {
+	r.onMissing = policy
+	return r
+}
+
+// Transform 实现 RowTransformer
+func (r *IDRemapper) Transform(ctx context.Context, table string, row map[string]interface{}) ([]map[string]interface{}, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 先分配本表主键，使自引用（如 parent_id）能找到同表已复制的记录
+	if rule, exists := r.keys[table]; exists {
+		sourceID, err := requireColumn(table, row, rule.column)
+		if err != nil {
+			return nil, false, err
+		}
+		if sourceID != nil {
+			key := idKey(sourceID)
+			targetID, mapped := r.mappings[table][key]
+			if !mapped {
+				targetID, err = rule.allocator.Allocate(ctx, table, sourceID)
+				if err != nil {
+					return nil, false, fmt.Errorf("为 %s.%s=%v 分配新ID失败: %v", table, rule.column, key, err)
+				}
+				r.record(table, key, targetID)
+			}
+			row[rule.column] = targetID
+		}
+	}
+
+	for _, ref := range r.refs[table] {
+		sourceID, err := requireColumn(table, row, ref.column)
+		if err != nil {
+			return nil, false, err
+		}
+		if sourceID == nil {
+			continue
+		}
+
+		key := idKey(sourceID)
+		if targetID, mapped := r.mappings[ref.refTable][key]; mapped {
+			row[ref.column] = targetID
+			continue
+		}
+
+		switch r.onMissing {
+		case MissingReferenceKeep:
+		case MissingReferenceNull:
+			row[ref.column] = nil
+		case MissingReferenceSkip:
+			return nil, true, nil
+		default:
+			return nil, false, fmt.Errorf("%s.%s=%s 引用的 %s 记录没有ID映射，请确认 %s 已先复制",
+				table, ref.column, key, ref.refTable, ref.refTable)
+		}
+	}
+
+	return []map[string]interface{}{row}, false, nil
+}
+
+// Lookup 查询源ID对应的目标ID
+func (r *IDRemapper) Lookup(table string, sourceID interface{}) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	targetID, exists := r.mappings[table][idKey(sourceID)]
+	return targetID, exists
+}
+
+// Mappings 返回指定表的 源ID -> 目标ID 映射副本
+func (r *IDRemapper) Mappings(table string) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[string]interface{}, len(r.mappings[table]))
+	for k, v := range r.mappings[table] {
+		result[k] = v
+	}
+	return result
+}
+
+// SaveMappings 将映射关系保存到目标库的映射表（不存在时自动创建），以便后续批次继续使用
+func (r *IDRemapper) SaveMappings(db types.DB, mappingTable string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := createMappingTable(db, mappingTable); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (entity_type, source_id, target_id) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE target_id = VALUES(target_id)
+	`, sqlDialect.QuoteQualified(mappingTable))
+
+	for table, mapping := range r.mappings {
+		for sourceID, targetID := range mapping {
+			if _, err := db.Exec(query, table, sourceID, idKey(targetID)); err != nil {
+				return fmt.Errorf("保存ID映射 %s:%s 失败: %v", table, sourceID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadMappings 从映射表加载此前保存的映射关系
+func (r *IDRemapper) LoadMappings(db types.DB, mappingTable string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := createMappingTable(db, mappingTable); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT entity_type, source_id, target_id FROM %s", sqlDialect.QuoteQualified(mappingTable)))
+	if err != nil {
+		return fmt.Errorf("读取ID映射失败: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, sourceID, targetID string
+		if err := rows.Scan(&table, &sourceID, &targetID); err != nil {
+			return fmt.Errorf("扫描ID映射失败: %v", err)
+		}
+		r.record(table, sourceID, targetID)
+	}
+
+	return rows.Err()
+}
+
+// record 记录映射，调用方需持有锁
+func (r *IDRemapper) record(table, sourceKey string, targetID interface{}) {
+	if r.mappings[table] == nil {
+		r.mappings[table] = make(map[string]interface{})
+	}
+	r.mappings[table][sourceKey] = targetID
+}
+
+// idKey 将ID统一为字符串作为映射键（驱动可能返回 int64 或 []byte）
+func idKey(id interface{}) string {
+	return toString(id)
+}
+
+// createMappingTable 创建ID映射表
+func createMappingTable(db types.DB, mappingTable string) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			entity_type VARCHAR(100) NOT NULL,
+			source_id VARCHAR(100) NOT NULL,
+			target_id VARCHAR(100) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (entity_type, source_id)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`, sqlDialect.QuoteQualified(mappingTable))
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建ID映射表 %s 失败: %v", mappingTable, err)
+	}
+	return nil
+}
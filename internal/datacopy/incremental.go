@@ -0,0 +1,356 @@
+package datacopy
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// IncrementalConfig 单表增量同步配置
+//
+// 每次只复制 Column 大于（merge/ignore 策略为大于等于）上次高水位的行，
+// 高水位按 来源库+表 保存在目标库的同步状态表中。Column 上应当有索引。
+type IncrementalConfig struct {
+	Column           string `json:"column"`                       // 增量列：updated_at 或单调递增的ID
+	SoftDeleteColumn string `json:"soft_delete_column,omitempty"` // 软删除列，值非空且非0的行从目标表删除（软删除时需同时更新增量列）
+	TombstoneTable   string `json:"tombstone_table,omitempty"`    // 源库墓碑表，结构为 (table_name, pk_value, deleted_at)
+}
+
+// SyncState 同步状态，记录在目标库的同步状态表中
+type SyncState struct {
+	SourceName    string    `json:"source_name"`
+	TableName     string    `json:"table_name"`
+	SyncColumn    string    `json:"sync_column"`
+	HighWater     string    `json:"high_water"`
+	TombstoneMark string    `json:"tombstone_mark"`
+	RowsCopied    int64     `json:"rows_copied"`
+	RowsDeleted   int64     `json:"rows_deleted"`
+	SyncedAt      time.Time `json:"synced_at"`
+}
+
+// DefaultSyncStateTable 默认的同步状态表名
+const DefaultSyncStateTable = "data_sync_state"
+
+// incrementalRun 单表一次增量同步的运行状态
+type incrementalRun struct {
+	config          IncrementalConfig
+	state           SyncState
+	columnIndex     int
+	softDeleteIndex int
+	keyIndexes      []int
+	targetKey       []string
+	pendingDeletes  [][]interface{}
+}
+
+// validateIncremental 校验增量同步配置
+func validateIncremental(config CopyConfig) error {
+	for tableName, inc := range config.Incremental {
+		if inc.Column == "" {
+			return fmt.Errorf("表 %s 的增量同步配置缺少 column", tableName)
+		}
+		if config.Strategy == CopyStrategyOverwrite {
+			return fmt.Errorf("表 %s 配置了增量同步，不能使用 overwrite 策略", tableName)
+		}
+	}
+	return nil
+}
+
+// beginIncremental 读取表的同步状态，未配置增量同步时返回 nil
+func (dc *DataCopier) beginIncremental(tableName string) (*incrementalRun, error) {
+	inc, exists := dc.config.Incremental[tableName]
+	if !exists {
+		return nil, nil
+	}
+
+	run := &incrementalRun{
+		config: inc,
+		state:  SyncState{SourceName: dc.sourceName, TableName: tableName, SyncColumn: inc.Column},
+	}
+
+	if err := dc.ensureSyncStateTable(); err != nil {
+		return nil, err
+	}
+
+	if dc.config.ResetSync {
+		return run, nil
+	}
+
+	state, err := dc.loadSyncState(tableName)
+	if err != nil {
+		return nil, err
+	}
+	// 增量列变化后之前的高水位失效，重新全量同步
+	if state != nil && state.SyncColumn == inc.Column {
+		run.state.HighWater = state.HighWater
+		run.state.TombstoneMark = state.TombstoneMark
+	}
+
+	return run, nil
+}
+
+// filter 返回增量条件
+func (run *incrementalRun) filter(strategy CopyStrategy) (string, []interface{}) {
+	if run.state.HighWater == "" {
+		return "", nil
+	}
+	// 可重复写入的策略使用 >=，避免同一时间戳内后提交的行被遗漏
+	op := ">="
+	if strategy == CopyStrategyInsertNew {
+		op = ">"
+	}
+	return fmt.Sprintf("%s %s ?", sqlDialect.QuoteIdent(run.config.Column), op), []interface{}{run.state.HighWater}
+}
+
+// bind 在列信息确定后定位增量列、软删除列和主键
+func (run *incrementalRun) bind(dc *DataCopier, tableName string, columns []string) error {
+	run.columnIndex = indexOf(columns, run.config.Column)
+	if run.columnIndex < 0 {
+		return fmt.Errorf("增量列 %s 在源表中不存在", run.config.Column)
+	}
+
+	run.softDeleteIndex = -1
+	if run.config.SoftDeleteColumn != "" {
+		run.softDeleteIndex = indexOf(columns, run.config.SoftDeleteColumn)
+		if run.softDeleteIndex < 0 {
+			return fmt.Errorf("软删除列 %s 在源表中不存在", run.config.SoftDeleteColumn)
+		}
+	}
+
+	if run.config.SoftDeleteColumn == "" && run.config.TombstoneTable == "" {
+		return nil
+	}
+
+	// 传播删除需要按主键定位目标行
+	sourceKey, err := dc.primaryKey(dc.sourceDB, tableName)
+	if err != nil {
+		return fmt.Errorf("获取主键失败: %v", err)
+	}
+	targetKey, reason := dc.targetKey(tableName, sourceKey)
+	if reason != "" {
+		return fmt.Errorf("无法传播删除: %s", reason)
+	}
+	if run.config.TombstoneTable != "" && len(targetKey) != 1 {
+		return fmt.Errorf("墓碑表只支持单列主键")
+	}
+
+	run.targetKey = targetKey
+	run.keyIndexes = make([]int, len(sourceKey))
+	for i, col := range sourceKey {
+		run.keyIndexes[i] = indexOf(columns, col)
+	}
+	return nil
+}
+
+// observe 记录增量列的值，源数据按增量列升序读取，最后一个非空值即新的高水位
+func (run *incrementalRun) observe(values []interface{}) {
+	if value := normalizeValue(values[run.columnIndex]); value != nil {
+		run.state.HighWater = toString(value)
+	}
+}
+
+// deleted 判断行是否已被软删除，已删除的行记入待删除列表
+func (run *incrementalRun) deleted(values []interface{}) bool {
+	if run.softDeleteIndex < 0 || !isSoftDeleted(values[run.softDeleteIndex]) {
+		return false
+	}
+
+	key := make([]interface{}, len(run.keyIndexes))
+	for i, idx := range run.keyIndexes {
+		key[i] = values[idx]
+	}
+	run.pendingDeletes = append(run.pendingDeletes, key)
+	return true
+}
+
+// isSoftDeleted 软删除列的值非空、非0、非零时间时视为已删除
+func isSoftDeleted(value interface{}) bool {
+	switch v := normalizeValue(value).(type) {
+	case nil:
+		return false
+	case time.Time:
+		return !v.IsZero()
+	default:
+		s := toString(v)
+		return s != "" && s != "0" && !strings.HasPrefix(s, "0000-00-00")
+	}
+}
+
+// flushDeletes 删除目标表中已被软删除的行
+func (dc *DataCopier) flushDeletes(tableName string, run *incrementalRun) error {
+	if len(run.pendingDeletes) == 0 {
+		return nil
+	}
+	affected, err := dc.deleteRows(tableName, run.targetKey, run.pendingDeletes)
+	if err != nil {
+		return fmt.Errorf("删除目标行失败: %v", err)
+	}
+	run.state.RowsDeleted += affected
+	run.pendingDeletes = run.pendingDeletes[:0]
+	return nil
+}
+
+// applyTombstones 按墓碑表删除目标行，在复制变更行之前执行，避免删除后重新创建的行被误删
+func (dc *DataCopier) applyTombstones(tableName string, run *incrementalRun) error {
+	if run.config.TombstoneTable == "" {
+		return nil
+	}
+
+	query := fmt.Sprintf("SELECT pk_value, deleted_at FROM %s WHERE table_name = ?", sqlDialect.QuoteQualified(run.config.TombstoneTable))
+	args := []interface{}{tableName}
+	if run.state.TombstoneMark != "" {
+		query += " AND deleted_at >= ?"
+		args = append(args, run.state.TombstoneMark)
+	}
+	query += " ORDER BY deleted_at"
+
+	rows, err := dc.sourceDB.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("查询墓碑表 %s 失败: %v", run.config.TombstoneTable, err)
+	}
+	defer rows.Close()
+
+	var keys [][]interface{}
+	for rows.Next() {
+		var pkValue string
+		var deletedAt interface{}
+		if err := rows.Scan(&pkValue, &deletedAt); err != nil {
+			return fmt.Errorf("扫描墓碑记录失败: %v", err)
+		}
+		keys = append(keys, []interface{}{pkValue})
+		if value := normalizeValue(deletedAt); value != nil {
+			run.state.TombstoneMark = toString(value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += dc.config.BatchSize {
+		end := start + dc.config.BatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		affected, err := dc.deleteRows(tableName, run.targetKey, keys[start:end])
+		if err != nil {
+			return fmt.Errorf("按墓碑表删除目标行失败: %v", err)
+		}
+		run.state.RowsDeleted += affected
+	}
+
+	return nil
+}
+
+// deleteRows 按主键批量删除目标行
+func (dc *DataCopier) deleteRows(tableName string, key []string, keys [][]interface{}) (int64, error) {
+	tuple := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(key)), ", ") + ")"
+	placeholders := strings.TrimSuffix(strings.Repeat(tuple+", ", len(keys)), ", ")
+
+	args := make([]interface{}, 0, len(keys)*len(key))
+	for _, k := range keys {
+		args = append(args, k...)
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (%s)", sqlDialect.QuoteQualified(tableName), sqlDialect.QuoteIdents(key), placeholders)
+	result, err := dc.targetDB.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ensureSyncStateTable 创建同步状态表
+func (dc *DataCopier) ensureSyncStateTable() error {
+	return CreateSyncStateTable(dc.targetDB, dc.config.SyncStateTable)
+}
+
+// CreateSyncStateTable 在目标库创建同步状态表
+func CreateSyncStateTable(db types.DB, stateTable string) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			source_name VARCHAR(255) NOT NULL,
+			table_name VARCHAR(255) NOT NULL,
+			sync_column VARCHAR(255) NOT NULL,
+			high_water VARCHAR(64) NOT NULL DEFAULT '',
+			tombstone_mark VARCHAR(64) NOT NULL DEFAULT '',
+			rows_copied BIGINT NOT NULL DEFAULT 0,
+			rows_deleted BIGINT NOT NULL DEFAULT 0,
+			synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			PRIMARY KEY (source_name, table_name)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`, sqlDialect.QuoteQualified(stateTable))
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建同步状态表 %s 失败: %v", stateTable, err)
+	}
+	return nil
+}
+
+// loadSyncState 读取表的同步状态，不存在时返回 nil
+func (dc *DataCopier) loadSyncState(tableName string) (*SyncState, error) {
+	state := SyncState{SourceName: dc.sourceName, TableName: tableName}
+	query := fmt.Sprintf(`
+		SELECT sync_column, high_water, tombstone_mark, rows_copied, rows_deleted, synced_at
+		FROM %s WHERE source_name = ? AND table_name = ?
+	`, sqlDialect.QuoteQualified(dc.config.SyncStateTable))
+
+	err := dc.targetDB.QueryRow(query, dc.sourceName, tableName).Scan(
+		&state.SyncColumn, &state.HighWater, &state.TombstoneMark,
+		&state.RowsCopied, &state.RowsDeleted, &state.SyncedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取同步状态失败: %v", err)
+	}
+	return &state, nil
+}
+
+// saveSyncState 保存本次同步的高水位和行数
+func (dc *DataCopier) saveSyncState(run *incrementalRun) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (source_name, table_name, sync_column, high_water, tombstone_mark, rows_copied, rows_deleted)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			sync_column = VALUES(sync_column),
+			high_water = VALUES(high_water),
+			tombstone_mark = VALUES(tombstone_mark),
+			rows_copied = VALUES(rows_copied),
+			rows_deleted = VALUES(rows_deleted)
+	`, sqlDialect.QuoteQualified(dc.config.SyncStateTable))
+
+	s := run.state
+	if _, err := dc.targetDB.Exec(query, s.SourceName, s.TableName, s.SyncColumn, s.HighWater, s.TombstoneMark, s.RowsCopied, s.RowsDeleted); err != nil {
+		return fmt.Errorf("保存同步状态失败: %v", err)
+	}
+	return nil
+}
+
+// ListSyncStates 列出目标库中记录的所有同步状态
+func ListSyncStates(db types.DB, stateTable string) ([]SyncState, error) {
+	if stateTable == "" {
+		stateTable = DefaultSyncStateTable
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT source_name, table_name, sync_column, high_water, tombstone_mark, rows_copied, rows_deleted, synced_at
+		FROM %s ORDER BY source_name, table_name
+	`, sqlDialect.QuoteQualified(stateTable)))
+	if err != nil {
+		return nil, fmt.Errorf("读取同步状态失败: %v", err)
+	}
+	defer rows.Close()
+
+	var states []SyncState
+	for rows.Next() {
+		var s SyncState
+		if err := rows.Scan(&s.SourceName, &s.TableName, &s.SyncColumn, &s.HighWater, &s.TombstoneMark,
+			&s.RowsCopied, &s.RowsDeleted, &s.SyncedAt); err != nil {
+			return nil, fmt.Errorf("扫描同步状态失败: %v", err)
+		}
+		states = append(states, s)
+	}
+	return states, rows.Err()
+}
@@ -0,0 +1,412 @@
+package datacopy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskType 脱敏规则类型
+type MaskType string
+
+const (
+	MaskKeep      MaskType = "keep"       // 明确保留原值（用于放行被标记为敏感的列）
+	MaskNull      MaskType = "null"       // 置为NULL
+	MaskFixed     MaskType = "fixed"      // 替换为固定值
+	MaskHash      MaskType = "hash"       // 确定性哈希，相同输入得到相同输出，保持关联一致
+	MaskFakeName  MaskType = "fake_name"  // 确定性的假姓名
+	MaskFakeEmail MaskType = "fake_email" // 确定性的假邮箱
+	MaskEmail     MaskType = "email"      // 邮箱打码，保留域名
+	MaskPhone     MaskType = "phone"      // 手机号打码，默认保留前3后4位
+	MaskRedact    MaskType = "redact"     // 通用打码
+	MaskDateShift MaskType = "date_shift" // 日期偏移
+	MaskFormat    MaskType = "format"     // 保留格式的替换：数字换数字、字母换字母，其它字符不变
+	MaskExpr      MaskType = "expression" // 使用转换表达式，语法见 Expression
+)
+
+// MaskRule 单列脱敏规则
+type MaskRule struct {
+	Type       MaskType    `yaml:"type" json:"type"`
+	Value      interface{} `yaml:"value,omitempty" json:"value,omitempty"`           // fixed 的替换值
+	Length     int         `yaml:"length,omitempty" json:"length,omitempty"`         // hash 输出长度，默认16
+	Domain     string      `yaml:"domain,omitempty" json:"domain,omitempty"`         // fake_email 的域名，默认 example.com
+	Locale     string      `yaml:"locale,omitempty" json:"locale,omitempty"`         // fake_name 的语言：zh（默认）、en
+	KeepFirst  *int        `yaml:"keep_first,omitempty" json:"keep_first,omitempty"` // 保留开头字符数
+	KeepLast   *int        `yaml:"keep_last,omitempty" json:"keep_last,omitempty"`   // 保留结尾字符数
+	MaskChar   string      `yaml:"mask_char,omitempty" json:"mask_char,omitempty"`   // 打码字符，默认 *
+	Days       int         `yaml:"days,omitempty" json:"days,omitempty"`             // date_shift 最大偏移天数
+	KeyColumn  string      `yaml:"key_column,omitempty" json:"key_column,omitempty"` // date_shift 按该源列取偏移量，同一实体的日期偏移一致
+	Expression string      `yaml:"expression,omitempty" json:"expression,omitempty"` // expression 类型的表达式
+}
+
+// MaskingProfile 脱敏配置
+//
+// Tables 按 表名 -> 目标列名 -> 规则 组织，表名 "*" 的规则对所有表生效。
+// Sensitive 为 "表名.列名" 通配模式，匹配的列必须配置规则，否则拒绝复制。
+type MaskingProfile struct {
+	Salt      string                         `yaml:"salt,omitempty" json:"salt,omitempty"`
+	SaltEnv   string                         `yaml:"salt_env,omitempty" json:"salt_env,omitempty"` // 从环境变量读取盐，优先于 Salt
+	Sensitive []string                       `yaml:"sensitive,omitempty" json:"sensitive,omitempty"`
+	Tables    map[string]map[string]MaskRule `yaml:"tables" json:"tables"`
+}
+
+// LoadMaskingProfile 从YAML文件加载脱敏配置
+func LoadMaskingProfile(filename string) (*MaskingProfile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取脱敏配置失败: %v", err)
+	}
+
+	var profile MaskingProfile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("解析脱敏配置失败: %v", err)
+	}
+
+	if _, err := compileMaskingProfile(&profile); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// masker 编译后的脱敏配置
+type masker struct {
+	salt      []byte
+	sensitive []string
+	rules     map[string]map[string]*compiledMaskRule
+}
+
+// compiledMaskRule 编译后的脱敏规则
+type compiledMaskRule struct {
+	MaskRule
+	expression *Expression
+}
+
+// compileMaskingProfile 校验并编译脱敏配置，profile 为空时返回 nil
+func compileMaskingProfile(profile *MaskingProfile) (*masker, error) {
+	if profile == nil {
+		return nil, nil
+	}
+
+	m := &masker{
+		salt:      []byte(profile.Salt),
+		sensitive: profile.Sensitive,
+		rules:     make(map[string]map[string]*compiledMaskRule, len(profile.Tables)),
+	}
+
+	if profile.SaltEnv != "" {
+		salt := os.Getenv(profile.SaltEnv)
+		if salt == "" {
+			return nil, fmt.Errorf("脱敏配置指定的环境变量 %s 为空", profile.SaltEnv)
+		}
+		m.salt = []byte(salt)
+	}
+
+	for _, pattern := range profile.Sensitive {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("敏感列模式 %q 无效: %v", pattern, err)
+		}
+	}
+
+	for tableName, columns := range profile.Tables {
+		m.rules[tableName] = make(map[string]*compiledMaskRule, len(columns))
+		for column, rule := range columns {
+			compiled, err := compileMaskRule(rule)
+			if err != nil {
+				return nil, fmt.Errorf("表 %s 列 %s 的脱敏规则无效: %v", tableName, column, err)
+			}
+			m.rules[tableName][column] = compiled
+		}
+	}
+
+	return m, nil
+}
+
+// compileMaskRule 校验单条规则
+func compileMaskRule(rule MaskRule) (*compiledMaskRule, error) {
+	compiled := &compiledMaskRule{MaskRule: rule}
+
+	switch rule.Type {
+	case MaskKeep, MaskNull, MaskFixed, MaskHash, MaskFakeEmail, MaskEmail, MaskPhone, MaskRedact, MaskFormat:
+	case MaskFakeName:
+		if rule.Locale != "" && rule.Locale != "zh" && rule.Locale != "en" {
+			return nil, fmt.Errorf("不支持的 locale: %s", rule.Locale)
+		}
+	case MaskDateShift:
+		if rule.Days <= 0 {
+			return nil, fmt.Errorf("date_shift 必须指定正数 days")
+		}
+	case MaskExpr:
+		if rule.Expression == "" {
+			return nil, fmt.Errorf("expression 类型必须指定 expression")
+		}
+		expr, err := CompileExpression(rule.Expression, nil)
+		if err != nil {
+			return nil, err
+		}
+		compiled.expression = expr
+	case "":
+		return nil, fmt.Errorf("缺少 type")
+	default:
+		return nil, fmt.Errorf("未知的脱敏类型: %s", rule.Type)
+	}
+
+	if len([]rune(rule.MaskChar)) > 1 {
+		return nil, fmt.Errorf("mask_char 只能是单个字符")
+	}
+
+	return compiled, nil
+}
+
+// rule 查找表列对应的规则，表级规则优先于 "*"
+func (m *masker) rule(tableName, column string) *compiledMaskRule {
+	if rule, exists := m.rules[tableName][column]; exists {
+		return rule
+	}
+	return m.rules["*"][column]
+}
+
+// isSensitive 判断列是否被标记为敏感
+func (m *masker) isSensitive(tableName, column string) bool {
+	for _, pattern := range m.sensitive {
+		if matched, _ := path.Match(pattern, tableName+"."+column); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSensitiveColumns 拒绝复制没有脱敏规则的敏感列
+//
+// 源列名和映射后的目标列名任一命中敏感模式，目标列都必须有规则。
+// 计算列的值由表达式生成，同样按目标列名检查。
+func (m *masker) checkSensitiveColumns(tableName string, sourceColumns, targetColumns []string) error {
+	var unmasked []string
+	for i, target := range targetColumns {
+		sensitive := m.isSensitive(tableName, target)
+		if i < len(sourceColumns) && m.isSensitive(tableName, sourceColumns[i]) {
+			sensitive = true
+		}
+		if sensitive && m.rule(tableName, target) == nil {
+			unmasked = append(unmasked, target)
+		}
+	}
+
+	if len(unmasked) > 0 {
+		return fmt.Errorf("表 %s 的敏感列 %s 没有配置脱敏规则，拒绝复制", tableName, strings.Join(unmasked, ", "))
+	}
+	return nil
+}
+
+// apply 对一行目标值执行脱敏，row 为源数据原始值
+func (m *masker) apply(tableName string, targetColumns []string, values []interface{}, row map[string]interface{}) error {
+	for i, column := range targetColumns {
+		rule := m.rule(tableName, column)
+		if rule == nil {
+			continue
+		}
+		masked, err := m.mask(rule, values[i], row)
+		if err != nil {
+			return fmt.Errorf("列 %s 脱敏失败: %v", column, err)
+		}
+		values[i] = masked
+	}
+	return nil
+}
+
+// mask 按规则处理单个值
+func (m *masker) mask(rule *compiledMaskRule, value interface{}, row map[string]interface{}) (interface{}, error) {
+	switch rule.Type {
+	case MaskKeep:
+		return value, nil
+	case MaskNull:
+		return nil, nil
+	case MaskFixed:
+		return rule.Value, nil
+	case MaskExpr:
+		return rule.expression.Evaluate(row, value)
+	}
+
+	value = normalizeValue(value)
+	if value == nil {
+		return nil, nil
+	}
+
+	switch rule.Type {
+	case MaskHash:
+		length := rule.Length
+		if length <= 0 {
+			length = 16
+		}
+		return m.hashString(toString(value), length), nil
+	case MaskFakeName:
+		return m.fakeName(toString(value), rule.Locale), nil
+	case MaskFakeEmail:
+		domain := rule.Domain
+		if domain == "" {
+			domain = "example.com"
+		}
+		return fmt.Sprintf("user_%s@%s", m.hashString(toString(value), 10), domain), nil
+	case MaskEmail:
+		return redactEmail(toString(value), rule), nil
+	case MaskPhone:
+		return redact(toString(value), intOr(rule.KeepFirst, 3), intOr(rule.KeepLast, 4), maskChar(rule)), nil
+	case MaskRedact:
+		return redact(toString(value), intOr(rule.KeepFirst, 0), intOr(rule.KeepLast, 0), maskChar(rule)), nil
+	case MaskFormat:
+		return m.preserveFormat(toString(value), intOr(rule.KeepFirst, 0), intOr(rule.KeepLast, 0)), nil
+	case MaskDateShift:
+		return m.shiftDate(value, rule, row)
+	}
+
+	return value, nil
+}
+
+// digest 计算带盐的 HMAC-SHA256，counter 用于扩展出更长的伪随机序列
+func (m *masker) digest(input string, counter uint32) []byte {
+	mac := hmac.New(sha256.New, m.salt)
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], counter)
+	mac.Write(prefix[:])
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// hashString 确定性哈希，返回指定长度的十六进制串
+func (m *masker) hashString(input string, length int) string {
+	var sb strings.Builder
+	for counter := uint32(0); sb.Len() < length; counter++ {
+		sb.WriteString(hex.EncodeToString(m.digest(input, counter)))
+	}
+	return sb.String()[:length]
+}
+
+// hashUint 确定性地将输入映射为无符号整数
+func (m *masker) hashUint(input string) uint64 {
+	return binary.BigEndian.Uint64(m.digest(input, 0))
+}
+
+var (
+	zhSurnames   = []string{"王", "李", "张", "刘", "陈", "杨", "黄", "赵", "吴", "周", "徐", "孙", "马", "朱", "胡", "郭", "何", "林", "罗", "高"}
+	zhGivenNames = []string{"伟", "芳", "娜", "敏", "静", "强", "磊", "洋", "艳", "勇", "军", "杰", "娟", "涛", "明", "超", "秀英", "丽", "华", "建国", "志强", "晓明", "海燕", "雪"}
+	enFirstNames = []string{"James", "Mary", "John", "Linda", "Robert", "Susan", "Michael", "Karen", "David", "Nancy", "William", "Lisa", "Thomas", "Emma", "Daniel", "Olivia"}
+	enLastNames  = []string{"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Anderson", "Clark", "Lewis", "Walker", "Young", "Allen", "King", "Wright", "Scott"}
+)
+
+// fakeName 根据输入确定性地生成假姓名
+func (m *masker) fakeName(input, locale string) string {
+	h := m.hashUint(input)
+	if locale == "en" {
+		first := enFirstNames[h%uint64(len(enFirstNames))]
+		last := enLastNames[(h>>16)%uint64(len(enLastNames))]
+		return first + " " + last
+	}
+	surname := zhSurnames[h%uint64(len(zhSurnames))]
+	given := zhGivenNames[(h>>16)%uint64(len(zhGivenNames))]
+	return surname + given
+}
+
+// redactEmail 邮箱打码：保留本地部分的首字符和完整域名
+func redactEmail(email string, rule *compiledMaskRule) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return redact(email, intOr(rule.KeepFirst, 1), 0, maskChar(rule))
+	}
+	local := redact(email[:at], intOr(rule.KeepFirst, 1), intOr(rule.KeepLast, 0), maskChar(rule))
+	return local + email[at:]
+}
+
+// redact 保留首尾字符，中间替换为打码字符
+func redact(s string, keepFirst, keepLast int, mask rune) string {
+	runes := []rune(s)
+	if keepFirst+keepLast >= len(runes) {
+		keepFirst, keepLast = 0, 0
+		if len(runes) > 1 {
+			keepFirst = 1
+		}
+	}
+	for i := keepFirst; i < len(runes)-keepLast; i++ {
+		runes[i] = mask
+	}
+	return string(runes)
+}
+
+// preserveFormat 保留格式替换：数字换数字、字母换同大小写字母，其它字符不变
+func (m *masker) preserveFormat(s string, keepFirst, keepLast int) string {
+	runes := []rune(s)
+	var stream []byte
+	for counter := uint32(0); len(stream) < len(runes); counter++ {
+		stream = append(stream, m.digest(s, counter)...)
+	}
+
+	for i, r := range runes {
+		if i < keepFirst || i >= len(runes)-keepLast {
+			continue
+		}
+		b := stream[i]
+		switch {
+		case r >= '0' && r <= '9':
+			runes[i] = rune('0' + b%10)
+		case r >= 'a' && r <= 'z':
+			runes[i] = rune('a' + b%26)
+		case r >= 'A' && r <= 'Z':
+			runes[i] = rune('A' + b%26)
+		}
+	}
+	return string(runes)
+}
+
+// shiftDate 在 [-days, days] 范围内确定性地偏移日期（不为0）
+func (m *masker) shiftDate(value interface{}, rule *compiledMaskRule, row map[string]interface{}) (interface{}, error) {
+	t, err := toTime(value)
+	if err != nil {
+		return nil, err
+	}
+
+	key := toString(value)
+	if rule.KeyColumn != "" {
+		keyValue, exists := row[rule.KeyColumn]
+		if !exists {
+			return nil, fmt.Errorf("key_column %s 不存在", rule.KeyColumn)
+		}
+		key = toString(normalizeValue(keyValue))
+	}
+
+	offset := int(m.hashUint(key)%uint64(rule.Days*2)) - rule.Days
+	if offset >= 0 {
+		offset++
+	}
+	shifted := t.AddDate(0, 0, offset)
+
+	// 保持输入的类型：time.Time 原样返回，字符串按原精度格式化
+	if s, ok := value.(string); ok {
+		if len(s) == len("2006-01-02") {
+			return shifted.Format("2006-01-02"), nil
+		}
+		return shifted.Format("2006-01-02 15:04:05"), nil
+	}
+	return shifted, nil
+}
+
+// intOr 返回可选整数的值或默认值
+func intOr(v *int, def int) int {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// maskChar 返回打码字符
+func maskChar(rule *compiledMaskRule) rune {
+	if rule.MaskChar == "" {
+		return '*'
+	}
+	return []rune(rule.MaskChar)[0]
+}
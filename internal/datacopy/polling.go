@@ -0,0 +1,49 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultPollInterval 轮询复制的默认间隔
+const DefaultPollInterval = 5 * time.Second
+
+// replicateByPolling 没有注册 binlog 读取实现时的持续复制：按增量列轮询源表
+//
+// 每轮执行一次增量同步，复制增量列不低于高水位的行并传播软删除和墓碑表中的删除，
+// 高水位保存在同步状态表中，因此中断后可以从上次的位置继续。
+// 物理删除只能通过软删除列或墓碑表传播，这是轮询相对 binlog 的限制。
+func (cdc *CrossDatabaseCopier) replicateByPolling(ctx context.Context, copier *DataCopier, rc ReplicationConfig) error {
+	for _, table := range copier.config.Tables {
+		if _, exists := copier.config.Incremental[table]; !exists {
+			return fmt.Errorf("当前构建未包含 binlog 读取实现，轮询复制需要为表 %s 配置增量列（--incremental=%s:updated_at）", table, table)
+		}
+	}
+
+	interval := rc.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// 首轮没有高水位时即全量复制
+		if err := copier.CopyData(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("轮询复制失败: %v", err)
+		}
+		// 重置同步状态只作用于第一轮
+		copier.config.ResetSync = false
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package datacopy
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// BinlogPosition binlog 位点
+type BinlogPosition struct {
+	File string `json:"file"`
+	Pos  uint32 `json:"pos"`
+}
+
+// String 返回位点的可读形式
+func (p BinlogPosition) String() string {
+	if p.File == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Pos)
+}
+
+// ChangeType 行变更类型
+type ChangeType string
+
+const (
+	ChangeInsert ChangeType = "insert"
+	ChangeUpdate ChangeType = "update"
+	ChangeDelete ChangeType = "delete"
+	ChangeCommit ChangeType = "commit" // 事务提交，应用后保存检查点
+)
+
+// ChangeEvent 从 binlog（ROW 格式）解析出的一次行变更
+//
+// Rows 为按表定义顺序排列的列值：插入和更新为变更后的镜像，删除为删除前的镜像。
+// Columns 为空时按源表 information_schema 中的列顺序解释。
+type ChangeEvent struct {
+	Type      ChangeType
+	Schema    string // 变更所在的数据库，binlog 包含整个实例的变更
+	Table     string
+	Columns   []string
+	Rows      [][]interface{}
+	Position  BinlogPosition // 该事件结束后的位点
+	Timestamp time.Time      // 事件在源库上的提交时间
+}
+
+// ChangeStream binlog 事件流
+type ChangeStream interface {
+	Next(ctx context.Context) (*ChangeEvent, error)
+	Close() error
+}
+
+// ReplicationSource 作为从库连接源库所需的参数
+type ReplicationSource struct {
+	types.DatabaseConfig
+	ServerID uint32
+}
+
+// ChangeStreamOpener 从指定位点打开 binlog 事件流
+type ChangeStreamOpener func(ctx context.Context, source ReplicationSource, from BinlogPosition) (ChangeStream, error)
+
+var (
+	streamMu     sync.RWMutex
+	streamOpener ChangeStreamOpener
+)
+
+// RegisterChangeStream 注册 binlog 读取实现
+//
+// 本模块不包含 binlog 读取实现，命令行的 --continuous 始终按增量列轮询源表。
+// 嵌入本包的程序可以在 init 中注册基于复制协议客户端的实现，与 database/sql 注册驱动的方式相同。
+func RegisterChangeStream(opener ChangeStreamOpener) {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	streamOpener = opener
+}
+
+// ReplicationConfig 持续复制配置
+type ReplicationConfig struct {
+	Source          ReplicationSource
+	CheckpointTable string        // 目标库中的检查点表，默认 data_sync_checkpoint
+	SkipSnapshot    bool          // 没有检查点时跳过初始全量复制
+	PollInterval    time.Duration // 未注册 binlog 读取实现时轮询源表的间隔，默认 DefaultPollInterval
+}
+
+// DefaultCheckpointTable 默认的检查点表名
+const DefaultCheckpointTable = "data_sync_checkpoint"
+
+// Checkpoint 复制检查点
+type Checkpoint struct {
+	SourceName  string
+	Position    BinlogPosition
+	LastEventAt time.Time
+	UpdatedAt   time.Time
+}
+
+// Replicate 持续复制：首次运行时记录源库 binlog 位点并全量复制，
+// 之后从检查点开始读取 binlog，将插入/更新/删除应用到目标库，直到 ctx 被取消（切换时）。
+//
+// 全量复制与位点之间的变更会在回放时再次应用，插入和更新按主键 upsert，删除按主键执行，因此可以重复回放。
+// 未注册 binlog 读取实现时（默认）改为按 CopyConfig.Incremental 中的增量列轮询源表，见 replicateByPolling。
+func (cdc *CrossDatabaseCopier) Replicate(ctx context.Context, sourceDB, targetDB string, config CopyConfig, rc ReplicationConfig, callback ProgressCallback) error {
+	streamMu.RLock()
+	opener := streamOpener
+	streamMu.RUnlock()
+	if rc.CheckpointTable == "" {
+		rc.CheckpointTable = DefaultCheckpointTable
+	}
+
+	sourceConn, err := cdc.dbManager.GetDatabase(sourceDB)
+	if err != nil {
+		return fmt.Errorf("连接源数据库失败: %v", err)
+	}
+	targetConn, err := cdc.dbManager.GetDatabase(targetDB)
+	if err != nil {
+		return fmt.Errorf("连接目标数据库失败: %v", err)
+	}
+
+	// 持续复制按主键回放，只能使用 merge 策略
+	config.Strategy = CopyStrategyMerge
+	copier := NewDataCopier(sourceConn, targetConn, config)
+	copier.SetProgressCallback(callback)
+	copier.sourceName = sourceDB
+	if err := copier.prepare(); err != nil {
+		return err
+	}
+
+	if opener == nil {
+		return cdc.replicateByPolling(ctx, copier, rc)
+	}
+
+	if err := CreateCheckpointTable(targetConn, rc.CheckpointTable); err != nil {
+		return err
+	}
+	checkpoint, err := LoadCheckpoint(targetConn, rc.CheckpointTable, sourceDB)
+	if err != nil {
+		return err
+	}
+
+	// 首次运行：先记录位点再全量复制
+	if checkpoint == nil {
+		position, err := CurrentBinlogPosition(sourceConn)
+		if err != nil {
+			return err
+		}
+		if !rc.SkipSnapshot {
+			if err := copier.CopyData(ctx); err != nil {
+				return fmt.Errorf("初始全量复制失败: %v", err)
+			}
+		}
+		checkpoint = &Checkpoint{SourceName: sourceDB, Position: position}
+		if err := saveCheckpoint(targetConn, rc.CheckpointTable, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	stream, err := opener(ctx, rc.Source, checkpoint.Position)
+	if err != nil {
+		return fmt.Errorf("打开 binlog 事件流失败: %v", err)
+	}
+	defer stream.Close()
+
+	applier := &changeApplier{
+		copier:  copier,
+		schema:  rc.Source.Database,
+		tables:  make(map[string]bool),
+		columns: make(map[string][]string),
+	}
+	for _, table := range config.Tables {
+		applier.tables[table] = true
+	}
+
+	for {
+		event, err := stream.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("读取 binlog 失败: %v", err)
+		}
+
+		if err := applier.apply(event); err != nil {
+			if callback != nil {
+				callback(event.Table, 0, 0, err)
+			}
+			return fmt.Errorf("应用 %s 位点 %s 的变更失败: %v", event.Table, event.Position, err)
+		}
+
+		if event.Type == ChangeCommit {
+			checkpoint.Position = event.Position
+			checkpoint.LastEventAt = event.Timestamp
+			if err := saveCheckpoint(targetConn, rc.CheckpointTable, checkpoint); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// changeApplier 将行变更应用到目标库
+type changeApplier struct {
+	copier  *DataCopier
+	schema  string
+	tables  map[string]bool
+	columns map[string][]string
+}
+
+// apply 应用一次变更，其它数据库和未配置复制的表直接忽略
+func (a *changeApplier) apply(event *ChangeEvent) error {
+	if event.Type == ChangeCommit || !a.tables[event.Table] || len(event.Rows) == 0 {
+		return nil
+	}
+	if a.schema != "" && event.Schema != a.schema {
+		return nil
+	}
+
+	dc := a.copier
+	columns := event.Columns
+	if len(columns) == 0 {
+		cached, exists := a.columns[event.Table]
+		if !exists {
+			var err error
+			cached, err = dc.queryColumns(dc.sourceDB, event.Table)
+			if err != nil {
+				return fmt.Errorf("获取源表列信息失败: %v", err)
+			}
+			a.columns[event.Table] = cached
+		}
+		columns = cached
+	}
+	targetColumns := dc.mapColumns(event.Table, columns)
+
+	if event.Type == ChangeDelete {
+		sourceKey, err := dc.primaryKey(dc.sourceDB, event.Table)
+		if err != nil {
+			return fmt.Errorf("获取主键失败: %v", err)
+		}
+		targetKey, reason := dc.targetKey(event.Table, sourceKey)
+		if reason != "" {
+			return fmt.Errorf("无法回放删除: %s", reason)
+		}
+
+		keyIndex := make([]int, len(sourceKey))
+		for i, col := range sourceKey {
+			keyIndex[i] = indexOf(columns, col)
+			if keyIndex[i] < 0 {
+				return fmt.Errorf("删除事件缺少主键列 %s", col)
+			}
+		}
+
+		keys := make([][]interface{}, len(event.Rows))
+		for i, row := range event.Rows {
+			if len(row) != len(columns) {
+				return fmt.Errorf("删除事件的列数 %d 与列名数 %d 不一致", len(row), len(columns))
+			}
+			keys[i] = make([]interface{}, len(sourceKey))
+			for j, index := range keyIndex {
+				keys[i][j] = row[index]
+			}
+		}
+		_, err = dc.deleteRows(event.Table, targetKey, keys)
+		return err
+	}
+
+	var batch [][]interface{}
+	for _, row := range event.Rows {
+		values, err := dc.transformValues(event.Table, columns, targetColumns, row)
+		if err != nil {
+			return fmt.Errorf("转换数据失败: %v", err)
+		}
+		batch = append(batch, values)
+	}
+	return dc.insertBatch(event.Table, targetColumns, batch)
+}
+
+// CurrentBinlogPosition 查询源库当前的 binlog 位点
+func CurrentBinlogPosition(db types.DB) (BinlogPosition, error) {
+	var position BinlogPosition
+
+	// MySQL 8.4 起使用 SHOW BINARY LOG STATUS
+	for _, query := range []string{"SHOW BINARY LOG STATUS", "SHOW MASTER STATUS"} {
+		rows, err := db.Query(query)
+		if err != nil {
+			continue
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			return position, err
+		}
+		if !rows.Next() {
+			return position, fmt.Errorf("源库未开启 binlog")
+		}
+
+		values := make([]sql.RawBytes, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return position, fmt.Errorf("读取 binlog 位点失败: %v", err)
+		}
+
+		position.File = string(values[0])
+		pos, err := toInt(string(values[1]))
+		if err != nil {
+			return position, fmt.Errorf("解析 binlog 位点失败: %v", err)
+		}
+		position.Pos = uint32(pos)
+		return position, nil
+	}
+
+	return position, fmt.Errorf("查询 binlog 位点失败，请确认账号具有 REPLICATION CLIENT 权限")
+}
+
+// CreateCheckpointTable 在目标库创建检查点表
+func CreateCheckpointTable(db types.DB, checkpointTable string) error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			source_name VARCHAR(255) NOT NULL PRIMARY KEY,
+			binlog_file VARCHAR(255) NOT NULL,
+			binlog_pos BIGINT UNSIGNED NOT NULL,
+			last_event_at DATETIME NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`, sqlDialect.QuoteQualified(checkpointTable))
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("创建检查点表 %s 失败: %v", checkpointTable, err)
+	}
+	return nil
+}
+
+// LoadCheckpoint 读取来源库的检查点，不存在时返回 nil
+func LoadCheckpoint(db types.DB, checkpointTable, sourceName string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{SourceName: sourceName}
+	var lastEventAt sql.NullTime
+
+	query := fmt.Sprintf("SELECT binlog_file, binlog_pos, last_event_at, updated_at FROM %s WHERE source_name = ?", sqlDialect.QuoteQualified(checkpointTable))
+	err := db.QueryRow(query, sourceName).Scan(&checkpoint.Position.File, &checkpoint.Position.Pos, &lastEventAt, &checkpoint.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %v", err)
+	}
+
+	checkpoint.LastEventAt = lastEventAt.Time
+	return checkpoint, nil
+}
+
+// ListCheckpoints 列出目标库中的所有检查点
+func ListCheckpoints(db types.DB, checkpointTable string) ([]Checkpoint, error) {
+	if checkpointTable == "" {
+		checkpointTable = DefaultCheckpointTable
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT source_name, binlog_file, binlog_pos, last_event_at, updated_at FROM %s ORDER BY source_name", sqlDialect.QuoteQualified(checkpointTable)))
+	if err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %v", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		var c Checkpoint
+		var lastEventAt sql.NullTime
+		if err := rows.Scan(&c.SourceName, &c.Position.File, &c.Position.Pos, &lastEventAt, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描检查点失败: %v", err)
+		}
+		c.LastEventAt = lastEventAt.Time
+		checkpoints = append(checkpoints, c)
+	}
+	return checkpoints, rows.Err()
+}
+
+// saveCheckpoint 保存检查点
+func saveCheckpoint(db types.DB, checkpointTable string, checkpoint *Checkpoint) error {
+	var lastEventAt interface{}
+	if !checkpoint.LastEventAt.IsZero() {
+		lastEventAt = checkpoint.LastEventAt
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (source_name, binlog_file, binlog_pos, last_event_at) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE binlog_file = VALUES(binlog_file), binlog_pos = VALUES(binlog_pos), last_event_at = VALUES(last_event_at)
+	`, sqlDialect.QuoteQualified(checkpointTable))
+
+	if _, err := db.Exec(query, checkpoint.SourceName, checkpoint.Position.File, checkpoint.Position.Pos, lastEventAt); err != nil {
+		return fmt.Errorf("保存检查点失败: %v", err)
+	}
+	return nil
+}
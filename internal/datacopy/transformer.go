@@ -0,0 +1,141 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// RowTransformer 行转换器，DataCopier 对每一行（字段映射之后，按目标列名）调用一次
+//
+// 返回的 rows 替换原行写入目标表，可以返回多行实现拆分，也可以增删列实现数据补充；
+// skip 为 true 时丢弃该行。多个转换器按顺序串联，前一个的输出作为后一个的输入。
+type RowTransformer interface {
+	Transform(ctx context.Context, table string, row map[string]interface{}) (rows []map[string]interface{}, skip bool, err error)
+}
+
+// RowTransformerFunc 函数形式的行转换器
+type RowTransformerFunc func(ctx context.Context, table string, row map[string]interface{}) ([]map[string]interface{}, bool, error)
+
+// Transform 实现 RowTransformer
+func (f RowTransformerFunc) Transform(ctx context.Context, table string, row map[string]interface{}) ([]map[string]interface{}, bool, error) {
+	return f(ctx, table, row)
+}
+
+// RowFilterFunc 行过滤器，返回 false 时跳过该行
+type RowFilterFunc func(ctx context.Context, table string, row map[string]interface{}) (bool, error)
+
+// Transform 实现 RowTransformer
+func (f RowFilterFunc) Transform(ctx context.Context, table string, row map[string]interface{}) ([]map[string]interface{}, bool, error) {
+	keep, err := f(ctx, table, row)
+	if err != nil || !keep {
+		return nil, true, err
+	}
+	return []map[string]interface{}{row}, false, nil
+}
+
+// ForTables 将行转换器限定在指定表上，其它表的行原样通过
+func ForTables(transformer RowTransformer, tables ...string) RowTransformer {
+	tableSet := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		tableSet[table] = true
+	}
+	return RowTransformerFunc(func(ctx context.Context, table string, row map[string]interface{}) ([]map[string]interface{}, bool, error) {
+		if !tableSet[table] {
+			return []map[string]interface{}{row}, false, nil
+		}
+		return transformer.Transform(ctx, table, row)
+	})
+}
+
+// outputRow 待写入目标表的一行
+type outputRow struct {
+	columns []string
+	values  []interface{}
+}
+
+// applyRowTransformers 依次执行行转换器，未配置转换器时直接返回原行
+func (dc *DataCopier) applyRowTransformers(ctx context.Context, tableName string, columns []string, values []interface{}) ([]outputRow, error) {
+	if len(dc.config.Transformers) == 0 {
+		return []outputRow{{columns: columns, values: values}}, nil
+	}
+
+	row := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		row[col] = values[i]
+	}
+
+	current := []map[string]interface{}{row}
+	for _, transformer := range dc.config.Transformers {
+		var next []map[string]interface{}
+		for _, r := range current {
+			produced, skip, err := transformer.Transform(ctx, tableName, r)
+			if err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+			next = append(next, produced...)
+		}
+		if len(next) == 0 {
+			return nil, nil
+		}
+		current = next
+	}
+
+	result := make([]outputRow, 0, len(current))
+	for _, r := range current {
+		outColumns := orderRowColumns(columns, r)
+		outValues := make([]interface{}, len(outColumns))
+		for i, col := range outColumns {
+			outValues[i] = r[col]
+		}
+		result = append(result, outputRow{columns: outColumns, values: outValues})
+	}
+	return result, nil
+}
+
+// orderRowColumns 保持原有列顺序，新增的列按名称排序追加在后
+func orderRowColumns(baseColumns []string, row map[string]interface{}) []string {
+	columns := make([]string, 0, len(row))
+	seen := make(map[string]bool, len(row))
+	for _, col := range baseColumns {
+		if _, exists := row[col]; exists {
+			columns = append(columns, col)
+			seen[col] = true
+		}
+	}
+
+	var extra []string
+	for col := range row {
+		if !seen[col] {
+			extra = append(extra, col)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(columns, extra...)
+}
+
+// sameColumns 判断两组列是否完全一致
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// requireColumn 从行中取出必需的列
+func requireColumn(table string, row map[string]interface{}, column string) (interface{}, error) {
+	value, exists := row[column]
+	if !exists {
+		return nil, fmt.Errorf("表 %s 的行中不存在列 %s", table, column)
+	}
+	return value, nil
+}
@@ -0,0 +1,383 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/checker"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// TableVerification 单表校验结果
+type TableVerification struct {
+	Table      string          `json:"table"`
+	SourceRows int64           `json:"source_rows"`
+	TargetRows int64           `json:"target_rows"`
+	Chunks     int             `json:"chunks"`
+	Mismatches []ChunkMismatch `json:"mismatches,omitempty"`
+	Note       string          `json:"note,omitempty"`    // 校验方式说明，如无主键时按整表校验
+	Skipped    string          `json:"skipped,omitempty"` // 无法校验的原因
+}
+
+// OK 判断表数据是否一致
+func (v TableVerification) OK() bool {
+	return v.Skipped == "" && len(v.Mismatches) == 0
+}
+
+// ChunkMismatch 不一致的主键区间 (After, Through]，为空表示无边界
+type ChunkMismatch struct {
+	After          string `json:"after,omitempty"`
+	Through        string `json:"through,omitempty"`
+	SourceRows     int64  `json:"source_rows"`
+	TargetRows     int64  `json:"target_rows"`
+	SourceChecksum uint64 `json:"source_checksum"`
+	TargetChecksum uint64 `json:"target_checksum"`
+}
+
+// Range 返回区间的可读描述
+func (m ChunkMismatch) Range() string {
+	after, through := m.After, m.Through
+	if after == "" {
+		after = "开始"
+	}
+	if through == "" {
+		through = "结束"
+	}
+	return fmt.Sprintf("(%s, %s]", after, through)
+}
+
+// chunkSummary 一个区间的行数和校验和
+type chunkSummary struct {
+	rows     int64
+	checksum uint64
+}
+
+// add 累加一行，校验和与行顺序无关，避免两端排序规则不同造成误报
+func (s *chunkSummary) add(values []interface{}) {
+	h := crc32.NewIEEE()
+	for _, value := range values {
+		value = normalizeValue(value)
+		if value == nil {
+			h.Write([]byte{'N', 0x1f})
+			continue
+		}
+		h.Write([]byte{'V'})
+		h.Write([]byte(toString(value)))
+		h.Write([]byte{0x1f})
+	}
+	s.rows++
+	s.checksum += uint64(h.Sum32())
+}
+
+// Verify 按主键区间比较源表和目标表的行数与校验和
+//
+// 源数据按复制时相同的条件、字段映射和脱敏规则计算期望值，条件同样作用于目标表，
+// 因此条件中引用的列必须在目标表中存在。包含 NOW()、UUID() 等非确定性表达式
+// 或配置了行转换器时，目标数据无法从源数据推导，校验结果不可信。
+func (dc *DataCopier) Verify(ctx context.Context) ([]TableVerification, error) {
+	ctx, cancel := context.WithTimeout(ctx, dc.config.Timeout)
+	defer cancel()
+
+	if err := dc.prepare(); err != nil {
+		return nil, err
+	}
+
+	results := make([]TableVerification, 0, len(dc.config.Tables))
+	for _, tableName := range dc.config.Tables {
+		result, err := dc.verifyTable(ctx, tableName)
+		if err != nil {
+			return results, fmt.Errorf("校验表 %s 失败: %v", tableName, err)
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// verifyTable 校验单个表
+func (dc *DataCopier) verifyTable(ctx context.Context, tableName string) (*TableVerification, error) {
+	result := &TableVerification{Table: tableName}
+
+	if len(dc.config.Transformers) > 0 {
+		result.Skipped = "配置了行转换器，目标数据无法由源数据推导"
+		return result, nil
+	}
+
+	sourceColumns, err := dc.queryColumns(dc.sourceDB, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("获取源表列信息失败: %v", err)
+	}
+	if err := dc.validateMappingColumns(tableName, sourceColumns); err != nil {
+		return nil, err
+	}
+	targetColumns := dc.mapColumns(tableName, sourceColumns)
+
+	sourceKey, err := dc.primaryKey(dc.sourceDB, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("获取主键失败: %v", err)
+	}
+	targetKey, reason := dc.targetKey(tableName, sourceKey)
+
+	// 无法按主键分区时整表作为一个区间校验
+	if reason != "" {
+		result.Note = reason + "，按整表校验"
+		source, _, err := dc.sourceChunk(tableName, sourceColumns, targetColumns, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		target, err := dc.targetChunk(tableName, targetColumns, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		result.addChunk(nil, nil, source, target)
+		return result, nil
+	}
+
+	var after []interface{}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		source, last, err := dc.sourceChunk(tableName, sourceColumns, targetColumns, sourceKey, after, dc.config.VerifyChunk)
+		if err != nil {
+			return nil, err
+		}
+		if source.rows == 0 {
+			break
+		}
+
+		target, err := dc.targetChunk(tableName, targetColumns, targetKey, after, last)
+		if err != nil {
+			return nil, err
+		}
+		result.addChunk(after, last, source, target)
+
+		after = last
+		if source.rows < int64(dc.config.VerifyChunk) {
+			break
+		}
+	}
+
+	// 最后一个区间之后（源表为空时即整个目标表）多出的目标行
+	target, err := dc.targetChunk(tableName, targetColumns, targetKey, after, nil)
+	if err != nil {
+		return nil, err
+	}
+	if target.rows > 0 {
+		result.addChunk(after, nil, chunkSummary{}, target)
+	}
+
+	return result, nil
+}
+
+// addChunk 记录一个区间的比较结果
+func (v *TableVerification) addChunk(after, through []interface{}, source, target chunkSummary) {
+	v.Chunks++
+	v.SourceRows += source.rows
+	v.TargetRows += target.rows
+
+	if source == target {
+		return
+	}
+	v.Mismatches = append(v.Mismatches, ChunkMismatch{
+		After:          formatKey(after),
+		Through:        formatKey(through),
+		SourceRows:     source.rows,
+		TargetRows:     target.rows,
+		SourceChecksum: source.checksum,
+		TargetChecksum: target.checksum,
+	})
+}
+
+// targetKey 计算目标表中的主键列名，主键被转换或脱敏时无法按区间比较
+func (dc *DataCopier) targetKey(tableName string, sourceKey []string) ([]string, string) {
+	if len(sourceKey) == 0 {
+		return nil, "表没有主键"
+	}
+
+	targetKey := make([]string, len(sourceKey))
+	for i, col := range sourceKey {
+		targetKey[i] = col
+		for _, mapping := range dc.mappings[tableName] {
+			if mapping.SourceField != col {
+				continue
+			}
+			if mapping.expression != nil {
+				return nil, fmt.Sprintf("主键列 %s 配置了转换表达式", col)
+			}
+			targetKey[i] = mapping.TargetField
+		}
+		if dc.masker != nil && dc.masker.rule(tableName, targetKey[i]) != nil {
+			return nil, fmt.Sprintf("主键列 %s 配置了脱敏规则", col)
+		}
+	}
+
+	return targetKey, ""
+}
+
+// sourceChunk 读取源表 after 之后的 limit 行（limit 为0时不限制），返回期望的目标值摘要和最后一行的主键
+func (dc *DataCopier) sourceChunk(tableName string, sourceColumns, targetColumns, key []string, after []interface{}, limit int) (chunkSummary, []interface{}, error) {
+	var summary chunkSummary
+
+	where, args := dc.rangeConditions(tableName, key, after, nil)
+	query := fmt.Sprintf("SELECT * FROM %s%s", sqlDialect.QuoteQualified(tableName), where)
+	if len(key) > 0 {
+		query += " ORDER BY " + sqlDialect.QuoteIdents(key)
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := dc.sourceDB.Query(query, args...)
+	if err != nil {
+		return summary, nil, fmt.Errorf("查询源数据失败: %v", err)
+	}
+	defer rows.Close()
+
+	keyIndexes := make([]int, len(key))
+	for i, col := range key {
+		keyIndexes[i] = indexOf(sourceColumns, col)
+	}
+
+	var last []interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(sourceColumns))
+		valuePtrs := make([]interface{}, len(sourceColumns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return summary, nil, fmt.Errorf("扫描源数据失败: %v", err)
+		}
+
+		last = make([]interface{}, len(keyIndexes))
+		for i, idx := range keyIndexes {
+			last[i] = values[idx]
+		}
+
+		expected, err := dc.transformValues(tableName, sourceColumns, targetColumns, values)
+		if err != nil {
+			return summary, nil, fmt.Errorf("转换数据失败: %v", err)
+		}
+		summary.add(expected)
+	}
+
+	return summary, last, rows.Err()
+}
+
+// targetChunk 读取目标表主键区间 (after, through] 内的数据摘要
+func (dc *DataCopier) targetChunk(tableName string, targetColumns, key []string, after, through []interface{}) (chunkSummary, error) {
+	var summary chunkSummary
+
+	where, args := dc.rangeConditions(tableName, key, after, through)
+	query := fmt.Sprintf("SELECT %s FROM %s%s", sqlDialect.QuoteIdents(targetColumns), sqlDialect.QuoteQualified(tableName), where)
+
+	rows, err := dc.targetDB.Query(query, args...)
+	if err != nil {
+		return summary, fmt.Errorf("查询目标数据失败: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values := make([]interface{}, len(targetColumns))
+		valuePtrs := make([]interface{}, len(targetColumns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return summary, fmt.Errorf("扫描目标数据失败: %v", err)
+		}
+		summary.add(values)
+	}
+
+	return summary, rows.Err()
+}
+
+// rangeConditions 构建复制条件和主键区间的 WHERE 子句
+func (dc *DataCopier) rangeConditions(tableName string, key []string, after, through []interface{}) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if condition := dc.config.Conditions[tableName]; condition != "" {
+		conditions = append(conditions, "("+condition+")")
+	}
+
+	if len(key) > 0 {
+		tuple := "(" + sqlDialect.QuoteIdents(key) + ")"
+		placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(key)), ", ") + ")"
+		if after != nil {
+			conditions = append(conditions, tuple+" > "+placeholders)
+			args = append(args, after...)
+		}
+		if through != nil {
+			conditions = append(conditions, tuple+" <= "+placeholders)
+			args = append(args, through...)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// queryColumns 获取表的列名（与 SELECT * 的顺序一致）
+func (dc *DataCopier) queryColumns(db types.DB, tableName string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", sqlDialect.QuoteQualified(tableName)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
+
+// primaryKey 获取表的主键列
+func (dc *DataCopier) primaryKey(db types.DB, tableName string) ([]string, error) {
+	return checker.PrimaryKeyColumns(db, tableName)
+}
+
+// formatKey 将主键值格式化为可读字符串
+func formatKey(key []interface{}) string {
+	if key == nil {
+		return ""
+	}
+	parts := make([]string, len(key))
+	for i, value := range key {
+		if normalizeValue(value) == nil {
+			parts[i] = "NULL"
+		} else {
+			parts[i] = toString(value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// indexOf 返回列在列表中的位置，不存在时返回 -1
+func indexOf(columns []string, column string) int {
+	for i, col := range columns {
+		if col == column {
+			return i
+		}
+	}
+	return -1
+}
+
+// VerifyBetweenDatabases 校验两个数据库之间复制的数据
+func (cdc *CrossDatabaseCopier) VerifyBetweenDatabases(ctx context.Context, sourceDB, targetDB string, config CopyConfig) ([]TableVerification, error) {
+	sourceConn, err := cdc.dbManager.GetDatabase(sourceDB)
+	if err != nil {
+		return nil, fmt.Errorf("连接源数据库失败: %v", err)
+	}
+
+	targetConn, err := cdc.dbManager.GetDatabase(targetDB)
+	if err != nil {
+		return nil, fmt.Errorf("连接目标数据库失败: %v", err)
+	}
+
+	return NewDataCopier(sourceConn, targetConn, config).Verify(ctx)
+}
@@ -0,0 +1,417 @@
+package datafile
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// columnInfo 目标表列定义，来自 information_schema.COLUMNS
+type columnInfo struct {
+	Name       string
+	DataType   string // 小写，如 int、varchar
+	ColumnType string // 完整类型，如 int(10) unsigned、enum('a','b')
+	Nullable   bool
+	HasDefault bool
+	Extra      string
+	MaxLength  int64 // 字符类型为字符数，二进制类型为字节数，0 表示不限制
+	Unsigned   bool
+	Members    []string // ENUM / SET 的取值
+}
+
+// autoIncrement 是否为自增列
+func (c *columnInfo) autoIncrement() bool {
+	return strings.Contains(strings.ToLower(c.Extra), "auto_increment")
+}
+
+// generated 是否为生成列，生成列不能写入
+func (c *columnInfo) generated() bool {
+	extra := strings.ToUpper(c.Extra)
+	return strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED")
+}
+
+// loadColumns 读取表的列定义，按列顺序返回
+func loadColumns(db types.DB, table string) ([]*columnInfo, error) {
+	rows, err := db.Query(`
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA, CHARACTER_MAXIMUM_LENGTH
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION
+	`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []*columnInfo
+	for rows.Next() {
+		var (
+			info      columnInfo
+			nullable  string
+			def       sql.NullString
+			maxLength sql.NullInt64
+		)
+		if err := rows.Scan(&info.Name, &info.DataType, &info.ColumnType, &nullable, &def, &info.Extra, &maxLength); err != nil {
+			return nil, err
+		}
+		info.DataType = strings.ToLower(info.DataType)
+		info.Nullable = nullable == "YES"
+		info.HasDefault = def.Valid
+		info.MaxLength = maxLength.Int64
+		info.Unsigned = strings.Contains(strings.ToLower(info.ColumnType), "unsigned")
+		if info.DataType == "enum" || info.DataType == "set" {
+			info.Members = parseMembers(info.ColumnType)
+		}
+		columns = append(columns, &info)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("表 %s 不存在", table)
+	}
+	return columns, nil
+}
+
+// parseMembers 解析 enum('a','b') / set('a','b') 的取值
+func parseMembers(columnType string) []string {
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	var members []string
+	body := columnType[start+1 : end]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\'' {
+			continue
+		}
+		var sb strings.Builder
+		for i++; i < len(body); i++ {
+			if body[i] == '\'' {
+				if i+1 < len(body) && body[i+1] == '\'' {
+					sb.WriteByte('\'')
+					i++
+					continue
+				}
+				break
+			}
+			sb.WriteByte(body[i])
+		}
+		members = append(members, sb.String())
+	}
+	return members
+}
+
+// useDefault 表示写入列的默认值（SQL 中写作 DEFAULT）
+type useDefault struct{}
+
+// integerRanges 各整数类型的取值范围
+var integerRanges = map[string][2]int64{
+	"tinyint":   {-1 << 7, 1<<7 - 1},
+	"smallint":  {-1 << 15, 1<<15 - 1},
+	"mediumint": {-1 << 23, 1<<23 - 1},
+	"int":       {-1 << 31, 1<<31 - 1},
+	"integer":   {-1 << 31, 1<<31 - 1},
+	"bigint":    {-1 << 63, 1<<63 - 1},
+}
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+	timePattern    = regexp.MustCompile(`^-?\d{1,3}:\d{1,2}(:\d{1,2}(\.\d{1,6})?)?$`)
+)
+
+// dateTimeLayouts 可识别的日期时间格式
+var dateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// coerce 将文件中的值转换为目标列可接受的值
+//
+// 文本来源（CSV）的值都是字符串，JSON 来源的值可能是 json.Number、bool、对象或数组。
+// hexBinary 为 true 时，二进制列中 0x 开头或 X'...' 形式的字符串按十六进制解码（export-data 的 JSON 和 CSV 写法）。
+func coerce(col *columnInfo, value interface{}, hexBinary bool) (interface{}, error) {
+	if s, ok := value.(string); ok && s == "" && !isStringType(col.DataType) && !isBinaryType(col.DataType) {
+		value = nil
+	}
+
+	if value == nil {
+		switch {
+		case col.Nullable, col.autoIncrement():
+			return nil, nil
+		case col.HasDefault:
+			return useDefault{}, nil
+		}
+		return nil, fmt.Errorf("列不允许为 NULL")
+	}
+
+	switch v := value.(type) {
+	case types.RawSQL:
+		// SQL 表达式（如 NOW()）由数据库计算
+		return v, nil
+	case []byte:
+		return v, nil
+	case bool:
+		if isIntegerType(col.DataType) || col.DataType == "bit" {
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		value = strconv.FormatBool(v)
+	case json.Number:
+		value = v.String()
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		value = v.Format("2006-01-02 15:04:05.999999")
+	case map[string]interface{}, []interface{}:
+		if col.DataType != "json" {
+			return nil, fmt.Errorf("%s 列不能写入对象或数组", col.DataType)
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case string:
+	default:
+		value = fmt.Sprintf("%v", v)
+	}
+
+	return coerceText(col, value.(string), hexBinary)
+}
+
+// coerceText 按列类型校验并转换文本值
+func coerceText(col *columnInfo, text string, hexBinary bool) (interface{}, error) {
+	trimmed := strings.TrimSpace(text)
+
+	switch col.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return parseInteger(col, trimmed)
+
+	case "bit":
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			if b {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+		n, err := strconv.ParseUint(trimmed, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 BIT 值: %s", text)
+		}
+		return n, nil
+
+	case "year":
+		n, err := strconv.Atoi(trimmed)
+		if err != nil || (n != 0 && (n < 1901 || n > 2155)) {
+			return nil, fmt.Errorf("无效的 YEAR 值: %s", text)
+		}
+		return int64(n), nil
+
+	case "decimal", "numeric":
+		if !decimalPattern.MatchString(trimmed) {
+			return nil, fmt.Errorf("无效的数值: %s", text)
+		}
+		if col.Unsigned && strings.HasPrefix(trimmed, "-") {
+			return nil, fmt.Errorf("无符号列不能为负数: %s", text)
+		}
+		return trimmed, nil
+
+	case "float", "double", "real":
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的浮点数: %s", text)
+		}
+		if col.Unsigned && f < 0 {
+			return nil, fmt.Errorf("无符号列不能为负数: %s", text)
+		}
+		return f, nil
+
+	case "date":
+		return parseDateTime(trimmed, true)
+
+	case "datetime", "timestamp":
+		return parseDateTime(trimmed, false)
+
+	case "time":
+		if !timePattern.MatchString(trimmed) {
+			return nil, fmt.Errorf("无效的 TIME 值: %s", text)
+		}
+		return trimmed, nil
+
+	case "json":
+		if !json.Valid([]byte(text)) {
+			return nil, fmt.Errorf("无效的 JSON: %s", truncate(text, 50))
+		}
+		return text, nil
+
+	case "enum":
+		member, ok := findMember(col.Members, text)
+		if !ok {
+			return nil, fmt.Errorf("%s 不是有效的枚举值，可选: %s", text, strings.Join(col.Members, ", "))
+		}
+		return member, nil
+
+	case "set":
+		if text == "" {
+			return text, nil
+		}
+		parts := strings.Split(text, ",")
+		for i, part := range parts {
+			member, ok := findMember(col.Members, part)
+			if !ok {
+				return nil, fmt.Errorf("%s 不是有效的集合值，可选: %s", part, strings.Join(col.Members, ", "))
+			}
+			parts[i] = member
+		}
+		return strings.Join(parts, ","), nil
+	}
+
+	if isBinaryType(col.DataType) {
+		data := []byte(text)
+		if hexBinary {
+			if decoded, ok := decodeHexBinary(text); ok {
+				data = decoded
+			}
+		}
+		if col.MaxLength > 0 && int64(len(data)) > col.MaxLength {
+			return nil, fmt.Errorf("长度 %d 字节超过列长度 %d", len(data), col.MaxLength)
+		}
+		return data, nil
+	}
+
+	if isStringType(col.DataType) && col.MaxLength > 0 {
+		if length := utf8.RuneCountInString(text); int64(length) > col.MaxLength {
+			return nil, fmt.Errorf("长度 %d 超过列长度 %d", length, col.MaxLength)
+		}
+	}
+	return text, nil
+}
+
+// parseInteger 解析整数并检查类型范围
+func parseInteger(col *columnInfo, text string) (interface{}, error) {
+	if col.DataType == "tinyint" {
+		switch strings.ToLower(text) {
+		case "true":
+			return int64(1), nil
+		case "false":
+			return int64(0), nil
+		}
+	}
+
+	if col.Unsigned {
+		n, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的无符号整数: %s", text)
+		}
+		if limit := integerRanges[col.DataType][1]; col.DataType != "bigint" && n > uint64(limit)*2+1 {
+			return nil, fmt.Errorf("%s 超出 %s 的范围", text, col.ColumnType)
+		}
+		return n, nil
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的整数: %s", text)
+	}
+	if r := integerRanges[col.DataType]; n < r[0] || n > r[1] {
+		return nil, fmt.Errorf("%s 超出 %s 的范围", text, col.ColumnType)
+	}
+	return n, nil
+}
+
+// parseDateTime 解析常见的日期时间写法，输出 MySQL 格式；零日期原样保留
+func parseDateTime(text string, dateOnly bool) (interface{}, error) {
+	if strings.HasPrefix(text, "0000-00-00") {
+		return text, nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		t, err := time.Parse(layout, text)
+		if err != nil {
+			continue
+		}
+		if dateOnly {
+			return t.Format("2006-01-02"), nil
+		}
+		if t.Nanosecond() != 0 {
+			return strings.TrimRight(t.Format("2006-01-02 15:04:05.000000"), "0"), nil
+		}
+		return t.Format("2006-01-02 15:04:05"), nil
+	}
+	return nil, fmt.Errorf("无效的日期时间: %s", text)
+}
+
+// findMember 按 MySQL 的规则（不区分大小写）查找 ENUM/SET 取值
+func findMember(members []string, value string) (string, bool) {
+	for _, member := range members {
+		if strings.EqualFold(member, value) {
+			return member, true
+		}
+	}
+	return "", false
+}
+
+func isIntegerType(dataType string) bool {
+	_, ok := integerRanges[dataType]
+	return ok
+}
+
+func isStringType(dataType string) bool {
+	switch dataType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		return true
+	}
+	return false
+}
+
+func isBinaryType(dataType string) bool {
+	switch dataType {
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return true
+	}
+	return false
+}
+
+// truncate 截断过长的文本用于错误信息
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// decodeHexBinary 解码 0x 开头或 X'...' 形式的十六进制文本
+func decodeHexBinary(text string) ([]byte, bool) {
+	var digits string
+	switch {
+	case strings.HasPrefix(text, "0x"):
+		digits = text[2:]
+	case len(text) >= 3 && (text[0] == 'X' || text[0] == 'x') && text[1] == '\'' && text[len(text)-1] == '\'':
+		digits = text[2 : len(text)-1]
+	default:
+		return nil, false
+	}
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
@@ -0,0 +1,88 @@
+package datafile
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestCSVBinaryRoundTrip(t *testing.T) {
+	binary := []byte{0x00, 0xff, '\n', '"', 0xc3, 0x28}
+	cells := []cell{
+		toCell(columnInteger, int64(1)),
+		toCell(columnBinary, binary),
+		toCell(columnBinary, []byte("plain")),
+		toCell(columnText, nil),
+	}
+
+	var buf bytes.Buffer
+	w, err := newRowWriter(FormatCSV, &buf, "files", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.writeHeader([]string{"id", "data", "name", "note"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.writeRow(cells); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := newCSVRecordReader(&buf, CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := reader.next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.next(); err != io.EOF {
+		t.Fatalf("期望只有一条记录，实际: %v", err)
+	}
+
+	blob := &columnInfo{Name: "data", DataType: "blob", Nullable: true}
+	tests := []struct {
+		column string
+		want   interface{}
+	}{
+		{"data", binary},
+		{"name", []byte("plain")},
+		{"note", nil},
+	}
+	for _, tt := range tests {
+		got, err := coerce(blob, rec.values[tt.column], true)
+		if err != nil {
+			t.Fatalf("列 %s 转换失败: %v", tt.column, err)
+		}
+		if want, ok := tt.want.([]byte); ok {
+			if !bytes.Equal(got.([]byte), want) {
+				t.Errorf("列 %s = %q，期望 %q", tt.column, got, want)
+			}
+		} else if got != nil {
+			t.Errorf("列 %s = %#v，期望 NULL", tt.column, got)
+		}
+	}
+}
+
+func TestDecodeHexBinary(t *testing.T) {
+	tests := []struct {
+		text string
+		want []byte
+		ok   bool
+	}{
+		{"0x4142", []byte("AB"), true},
+		{"X'4142'", []byte("AB"), true},
+		{"x''", []byte{}, true},
+		{"X'414'", nil, false},
+		{"0xZZ", nil, false},
+		{"hello", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := decodeHexBinary(tt.text)
+		if ok != tt.ok || !bytes.Equal(got, tt.want) {
+			t.Errorf("decodeHexBinary(%q) = %q, %v，期望 %q, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package datafile
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/checker"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// ExportConfig 导出配置
+type ExportConfig struct {
+	Tables       []string          // 为空时导出库中所有表
+	Format       Format            // 导出格式
+	Conditions   map[string]string // 表名 -> WHERE条件
+	Where        string            // 未单独指定条件的表使用的 WHERE 条件
+	OutputDir    string            // 输出目录，每个表一个文件
+	Gzip         bool              // 是否使用 gzip 压缩
+	SQLBatchSize int               // sql 格式每条 INSERT 的行数，默认500
+}
+
+// TableExportResult 单表导出结果
+type TableExportResult struct {
+	Table string
+	File  string
+	Rows  int64
+}
+
+// ExportProgress 导出进度回调
+type ExportProgress func(table string, rows int64)
+
+// Exporter 表数据导出器，逐行读取并写出，内存占用与表大小无关
+type Exporter struct {
+	db         types.DB
+	config     ExportConfig
+	onProgress ExportProgress
+}
+
+// NewExporter 创建导出器
+func NewExporter(db types.DB, config ExportConfig) *Exporter {
+	if config.Format == "" {
+		config.Format = FormatJSONL
+	}
+	if config.OutputDir == "" {
+		config.OutputDir = "."
+	}
+	return &Exporter{db: db, config: config}
+}
+
+// SetProgressCallback 设置进度回调
+func (e *Exporter) SetProgressCallback(callback ExportProgress) {
+	e.onProgress = callback
+}
+
+// Export 导出所有表，每个表写入 <OutputDir>/<表名>.<格式>[.gz]
+func (e *Exporter) Export(ctx context.Context) ([]TableExportResult, error) {
+	tables := e.config.Tables
+	if len(tables) == 0 {
+		var err error
+		tables, err = e.listTables()
+		if err != nil {
+			return nil, fmt.Errorf("获取表列表失败: %v", err)
+		}
+	}
+
+	if err := os.MkdirAll(e.config.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建输出目录失败: %v", err)
+	}
+
+	var results []TableExportResult
+	for _, table := range tables {
+		path := filepath.Join(e.config.OutputDir, table+e.config.Format.Extension())
+		if e.config.Gzip {
+			path += ".gz"
+		}
+
+		rows, err := e.exportToFile(ctx, table, path)
+		if err != nil {
+			return results, fmt.Errorf("导出表 %s 失败: %v", table, err)
+		}
+		results = append(results, TableExportResult{Table: table, File: path, Rows: rows})
+	}
+
+	return results, nil
+}
+
+// exportToFile 导出单表到文件，失败时删除不完整的文件
+func (e *Exporter) exportToFile(ctx context.Context, table, path string) (rows int64, err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	var w io.Writer = file
+	if e.config.Gzip {
+		gz := gzip.NewWriter(file)
+		defer func() {
+			if closeErr := gz.Close(); err == nil && closeErr != nil {
+				err = closeErr
+			}
+		}()
+		w = gz
+	}
+
+	return e.ExportTable(ctx, table, w)
+}
+
+// ExportTable 将单表数据按配置的格式写入 w
+func (e *Exporter) ExportTable(ctx context.Context, table string, w io.Writer) (int64, error) {
+	query := fmt.Sprintf("SELECT * FROM %s", quoteIdentifier(table))
+	condition, exists := e.config.Conditions[table]
+	if !exists {
+		condition = e.config.Where
+	}
+	if condition != "" {
+		query += " WHERE " + condition
+	}
+
+	// 按主键排序，使导出结果稳定，便于版本管理中比较差异
+	key, err := e.primaryKey(table)
+	if err != nil {
+		return 0, fmt.Errorf("获取主键失败: %v", err)
+	}
+	if len(key) > 0 {
+		quoted := make([]string, len(key))
+		for i, col := range key {
+			quoted[i] = quoteIdentifier(col)
+		}
+		query += " ORDER BY " + strings.Join(quoted, ", ")
+	}
+
+	rows, err := e.db.Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("查询数据失败: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("获取列信息失败: %v", err)
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, fmt.Errorf("获取列类型失败: %v", err)
+	}
+	kinds := make([]columnKind, len(columnTypes))
+	for i, ct := range columnTypes {
+		kinds[i] = classifyColumn(ct)
+	}
+
+	writer, err := newRowWriter(e.config.Format, w, table, e.config.SQLBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.writeHeader(columns); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	values := make([]interface{}, len(columns))
+	valuePtrs := make([]interface{}, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	cells := make([]cell, len(columns))
+
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return count, fmt.Errorf("扫描数据失败: %v", err)
+		}
+		for i, value := range values {
+			cells[i] = toCell(kinds[i], value)
+		}
+		if err := writer.writeRow(cells); err != nil {
+			return count, fmt.Errorf("写出数据失败: %v", err)
+		}
+
+		count++
+		if count%10000 == 0 {
+			if e.onProgress != nil {
+				e.onProgress(table, count)
+			}
+			select {
+			case <-ctx.Done():
+				return count, ctx.Err()
+			default:
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("读取数据失败: %v", err)
+	}
+
+	if err := writer.close(); err != nil {
+		return count, fmt.Errorf("写出数据失败: %v", err)
+	}
+	if e.onProgress != nil {
+		e.onProgress(table, count)
+	}
+
+	return count, nil
+}
+
+// listTables 列出当前库中的所有基础表
+func (e *Exporter) listTables() ([]string, error) {
+	rows, err := e.db.Query(`
+		SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// primaryKey 获取表的主键列
+func (e *Exporter) primaryKey(table string) ([]string, error) {
+	return checker.PrimaryKeyColumns(e.db, table)
+}
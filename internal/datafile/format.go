@@ -0,0 +1,172 @@
+package datafile
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Format 数据文件格式
+type Format string
+
+const (
+	FormatCSV   Format = "csv"   // 首行为列名，NULL 写作 \N
+	FormatJSONL Format = "jsonl" // 每行一个 JSON 对象
+	FormatJSON  Format = "json"  // JSON 数组，可直接用 DataBuilder.InsertFromJSON 导入
+	FormatSQL   Format = "sql"   // INSERT 语句，可用 insert-data 导入
+	FormatYAML  Format = "yaml"  // YAML 列表，可直接用 DataBuilder.InsertFromYAML 导入
+)
+
+// Formats 支持的格式
+var Formats = []Format{FormatCSV, FormatJSONL, FormatJSON, FormatSQL, FormatYAML}
+
+// ParseFormat 解析格式名称
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的格式: %s，支持: csv, jsonl, json, sql, yaml", name)
+}
+
+// Extension 返回格式对应的文件扩展名
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// FormatFromPath 根据文件扩展名判断格式（忽略 .gz 后缀）
+func FormatFromPath(path string) (Format, error) {
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV, nil
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+		return FormatJSONL, nil
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON, nil
+	case strings.HasSuffix(name, ".sql"):
+		return FormatSQL, nil
+	case strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".yml"):
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("无法根据扩展名识别文件格式: %s", path)
+}
+
+// cellKind 导出值的类别，决定各格式中的写法
+type cellKind int
+
+const (
+	cellNull   cellKind = iota
+	cellNumber          // 数字，原样写出
+	cellString          // 文本
+	cellBinary          // 非 UTF-8 的二进制数据
+)
+
+// cell 一个导出值
+type cell struct {
+	kind cellKind
+	text string
+	raw  []byte
+}
+
+// maxSafeInteger JSON/YAML 解析为 float64 时不丢失精度的最大整数
+const maxSafeInteger = 1 << 53
+
+// columnKind 列的类型分类
+type columnKind int
+
+const (
+	columnText columnKind = iota
+	columnInteger
+	columnFloat
+	columnDecimal
+	columnDate
+	columnDateTime
+	columnBinary
+)
+
+// classifyColumn 根据驱动返回的类型名对列分类
+func classifyColumn(ct *sql.ColumnType) columnKind {
+	name := strings.TrimPrefix(ct.DatabaseTypeName(), "UNSIGNED ")
+	switch name {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT", "YEAR":
+		return columnInteger
+	case "FLOAT", "DOUBLE":
+		return columnFloat
+	case "DECIMAL":
+		return columnDecimal
+	case "DATE":
+		return columnDate
+	case "DATETIME", "TIMESTAMP":
+		return columnDateTime
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BIT", "GEOMETRY":
+		return columnBinary
+	}
+	return columnText
+}
+
+// toCell 将驱动扫描出的值转换为导出值
+//
+// 超出 2^53 的整数和 DECIMAL 按文本导出，避免 JSON/YAML 导入时被解析为浮点数丢失精度。
+func toCell(kind columnKind, value interface{}) cell {
+	if value == nil {
+		return cell{kind: cellNull}
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return cell{kind: cellString, text: formatTime(kind, v)}
+	case int64:
+		return integerCell(strconv.FormatInt(v, 10))
+	case uint64:
+		return integerCell(strconv.FormatUint(v, 10))
+	case float64:
+		return cell{kind: cellNumber, text: strconv.FormatFloat(v, 'g', -1, 64)}
+	case float32:
+		return cell{kind: cellNumber, text: strconv.FormatFloat(float64(v), 'g', -1, 32)}
+	case []byte:
+		switch kind {
+		case columnInteger:
+			return integerCell(string(v))
+		case columnFloat:
+			return cell{kind: cellNumber, text: string(v)}
+		case columnBinary:
+			if !utf8.Valid(v) {
+				return cell{kind: cellBinary, raw: v, text: "0x" + hex.EncodeToString(v)}
+			}
+		}
+		return cell{kind: cellString, text: string(v)}
+	}
+
+	return cell{kind: cellString, text: fmt.Sprintf("%v", value)}
+}
+
+// integerCell 整数在安全范围内按数字导出，否则按文本导出
+func integerCell(text string) cell {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil && n > -maxSafeInteger && n < maxSafeInteger {
+		return cell{kind: cellNumber, text: text}
+	}
+	return cell{kind: cellString, text: text}
+}
+
+// formatTime 按列类型格式化时间，零值写作 MySQL 的零日期
+func formatTime(kind columnKind, t time.Time) string {
+	if kind == columnDate {
+		if t.IsZero() {
+			return "0000-00-00"
+		}
+		return t.Format("2006-01-02")
+	}
+	if t.IsZero() {
+		return "0000-00-00 00:00:00"
+	}
+	if t.Nanosecond() != 0 {
+		return strings.TrimRight(t.Format("2006-01-02 15:04:05.000000"), "0")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,719 @@
+package datafile
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// ConflictStrategy 主键/唯一键冲突时的处理方式
+type ConflictStrategy string
+
+const (
+	ConflictError   ConflictStrategy = "error"   // 冲突的行记为错误
+	ConflictIgnore  ConflictStrategy = "ignore"  // 跳过冲突的行（INSERT IGNORE）
+	ConflictUpdate  ConflictStrategy = "update"  // 更新已有的行（ON DUPLICATE KEY UPDATE）
+	ConflictReplace ConflictStrategy = "replace" // 删除已有的行后插入（REPLACE）
+)
+
+// ParseConflictStrategy 解析冲突处理方式
+func ParseConflictStrategy(name string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(strings.ToLower(name)); strategy {
+	case ConflictError, ConflictIgnore, ConflictUpdate, ConflictReplace:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("不支持的冲突处理方式: %s，支持: error, ignore, update, replace", name)
+}
+
+// SkipColumn 在 Columns 映射中表示忽略该字段
+const SkipColumn = "-"
+
+// maxKeptErrors ImportResult 中保留的错误数量，完整的错误通过 SetErrorHandler 获取
+const maxKeptErrors = 100
+
+// ImportConfig 导入配置
+type ImportConfig struct {
+	Table      string            // 目标表，为空时使用文件名（不含扩展名）
+	Format     Format            // 为空时根据扩展名判断
+	Columns    map[string]string // 文件字段 -> 表列，值为 "-" 时忽略该字段
+	CSV        CSVOptions        // CSV 读取选项
+	BatchSize  int               // 每批插入的行数，默认1000
+	OnConflict ConflictStrategy  // 冲突处理方式，默认 error
+	Truncate   bool              // 导入前清空表
+	MaxErrors  int               // 错误行数超过该值时中止，0 表示不限制
+}
+
+// RowError 一行数据的导入错误
+type RowError struct {
+	Table   string
+	Line    int64  // CSV/JSONL 为行号，JSON 数组和 YAML 为记录序号
+	Column  string // 出错的列，整行错误时为空
+	Message string
+	Raw     string // 原始记录
+}
+
+func (e RowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("第 %d 行 列 %s: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("第 %d 行: %s", e.Line, e.Message)
+}
+
+// ImportResult 单表导入结果
+type ImportResult struct {
+	Table    string
+	File     string
+	Rows     int64      // 读取的记录数
+	Imported int64      // 成功写入的记录数
+	Failed   int64      // 出错的记录数
+	Errors   []RowError // 前100条错误
+}
+
+// ImportProgress 导入进度回调
+type ImportProgress func(table string, rows int64)
+
+// Importer 数据文件导入器，逐条读取、按目标列类型转换后分批写入
+type Importer struct {
+	db         types.DB
+	config     ImportConfig
+	onProgress ImportProgress
+	onError    func(RowError)
+}
+
+// NewImporter 创建导入器
+func NewImporter(db types.DB, config ImportConfig) *Importer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.OnConflict == "" {
+		config.OnConflict = ConflictError
+	}
+	return &Importer{db: db, config: config}
+}
+
+// SetProgressCallback 设置进度回调
+func (im *Importer) SetProgressCallback(callback ImportProgress) {
+	im.onProgress = callback
+}
+
+// SetErrorHandler 设置行错误处理函数，每个出错的行调用一次
+func (im *Importer) SetErrorHandler(handler func(RowError)) {
+	im.onError = handler
+}
+
+// TableFromPath 根据文件名得到表名，如 data/users.csv.gz -> users
+func TableFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".gz")
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// ImportFile 导入一个文件
+//
+// JSON 文件顶层为对象、YAML 文件顶层为映射时，按 {表名: [记录...]} 导入多个表，
+// 其他情况导入到 Table（默认为文件名）。
+func (im *Importer) ImportFile(ctx context.Context, path string) ([]*ImportResult, error) {
+	format := im.config.Format
+	if format == "" {
+		var err error
+		if format, err = FormatFromPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("解压文件失败: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	table := im.config.Table
+	if table == "" {
+		table = TableFromPath(path)
+	}
+
+	results, err := im.ImportAll(ctx, table, r, format)
+	for _, result := range results {
+		result.File = path
+	}
+	return results, err
+}
+
+// ImportAll 将 r 中的数据导入，JSON/YAML 为 {表名: 数组} 形式的多表数据时返回每个表的结果
+func (im *Importer) ImportAll(ctx context.Context, table string, r io.Reader, format Format) ([]*ImportResult, error) {
+	switch format {
+	case FormatJSON:
+		return im.importJSON(ctx, r, table)
+	case FormatYAML:
+		return im.importYAML(ctx, r, table)
+	}
+	result, err := im.Import(ctx, table, r, format)
+	if result == nil {
+		return nil, err
+	}
+	return []*ImportResult{result}, err
+}
+
+// Import 将 r 中的数据导入 table，支持 csv、jsonl、json 和 yaml；
+// 多表 JSON/YAML 的各表结果合并为一个，需要分表结果时使用 ImportAll
+func (im *Importer) Import(ctx context.Context, table string, r io.Reader, format Format) (*ImportResult, error) {
+	switch format {
+	case FormatCSV:
+		plan, err := im.plan(table)
+		if err != nil {
+			return nil, err
+		}
+		options := im.config.CSV
+		if options.NoHeader && len(options.Header) == 0 {
+			options.Header = plan.writableColumns()
+		}
+		reader, err := newCSVRecordReader(r, options)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CSV 失败: %v", err)
+		}
+		if err := plan.checkFields(reader.header); err != nil {
+			return nil, err
+		}
+		return im.run(ctx, plan, reader, true)
+
+	case FormatJSONL:
+		plan, err := im.plan(table)
+		if err != nil {
+			return nil, err
+		}
+		return im.run(ctx, plan, &jsonLinesReader{r: bufio.NewReader(r)}, true)
+
+	case FormatJSON, FormatYAML:
+		importFunc := im.importJSON
+		if format == FormatYAML {
+			importFunc = im.importYAML
+		}
+		results, err := importFunc(ctx, r, table)
+		if len(results) == 0 {
+			return nil, err
+		}
+		return mergeResults(results), err
+	}
+	return nil, fmt.Errorf("不支持导入 %s 格式", format)
+}
+
+// mergeResults 合并多个表的导入结果，表名以逗号分隔
+func mergeResults(results []*ImportResult) *ImportResult {
+	if len(results) == 1 {
+		return results[0]
+	}
+	merged := &ImportResult{File: results[0].File}
+	tables := make([]string, len(results))
+	for i, result := range results {
+		tables[i] = result.Table
+		merged.Rows += result.Rows
+		merged.Imported += result.Imported
+		merged.Failed += result.Failed
+		for _, rowErr := range result.Errors {
+			if len(merged.Errors) < maxKeptErrors {
+				merged.Errors = append(merged.Errors, rowErr)
+			}
+		}
+	}
+	merged.Table = strings.Join(tables, ", ")
+	return merged
+}
+
+// ImportRows 导入内存中的记录，按与文件导入相同的规则转换类型
+func (im *Importer) ImportRows(ctx context.Context, table string, rows []map[string]interface{}) (*ImportResult, error) {
+	plan, err := im.plan(table)
+	if err != nil {
+		return nil, err
+	}
+	return im.run(ctx, plan, &sliceReader{rows: rows}, false)
+}
+
+// importJSON 导入 JSON 数组，或 {表名: 数组} 形式的多表数据
+func (im *Importer) importJSON(ctx context.Context, r io.Reader, table string) ([]*ImportResult, error) {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return nil, fmt.Errorf("读取 JSON 失败: %v", err)
+	}
+	decoder := json.NewDecoder(br)
+	decoder.UseNumber()
+
+	if first != '{' {
+		plan, err := im.plan(table)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := newJSONArrayReader(decoder)
+		if err != nil {
+			return nil, err
+		}
+		result, err := im.run(ctx, plan, reader, true)
+		if result == nil {
+			return nil, err
+		}
+		return []*ImportResult{result}, err
+	}
+
+	// 多表：{"users": [...], "roles": [...]}
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("无效的 JSON: %v", err)
+	}
+	var results []*ImportResult
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return results, fmt.Errorf("无效的 JSON: %v", err)
+		}
+		name, _ := token.(string)
+
+		plan, err := im.plan(name)
+		if err != nil {
+			return results, err
+		}
+		reader, err := newJSONArrayReader(decoder)
+		if err != nil {
+			return results, fmt.Errorf("表 %s: %v", name, err)
+		}
+		result, err := im.run(ctx, plan, reader, true)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// importYAML 导入 YAML 列表，或 {表名: 列表} 形式的多表数据；YAML 需整体加载到内存
+func (im *Importer) importYAML(ctx context.Context, r io.Reader, table string) ([]*ImportResult, error) {
+	var document yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&document); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("解析 YAML 失败: %v", err)
+	}
+
+	tables := []string{table}
+	data := map[string][]map[string]interface{}{}
+	root := &document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	if root.Kind == yaml.MappingNode {
+		tables = tables[:0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			name := root.Content[i].Value
+			var rows []map[string]interface{}
+			if err := root.Content[i+1].Decode(&rows); err != nil {
+				return nil, fmt.Errorf("表 %s: 解析 YAML 失败: %v", name, err)
+			}
+			tables = append(tables, name)
+			data[name] = rows
+		}
+	} else {
+		var rows []map[string]interface{}
+		if err := root.Decode(&rows); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %v", err)
+		}
+		data[table] = rows
+	}
+
+	var results []*ImportResult
+	for _, name := range tables {
+		plan, err := im.plan(name)
+		if err != nil {
+			return results, err
+		}
+		result, err := im.run(ctx, plan, &sliceReader{rows: data[name]}, false)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// peekNonSpace 返回第一个非空白字符，不消耗输入
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
+
+// importPlan 单表导入计划：目标列定义和字段映射
+type importPlan struct {
+	table   string
+	columns []*columnInfo
+	byName  map[string]*columnInfo // 小写列名 -> 列
+	mapping map[string]string
+}
+
+// plan 读取目标表结构，生成导入计划
+func (im *Importer) plan(table string) (*importPlan, error) {
+	if table == "" {
+		return nil, fmt.Errorf("未指定目标表")
+	}
+	columns, err := loadColumns(im.db, table)
+	if err != nil {
+		return nil, fmt.Errorf("读取表 %s 结构失败: %v", table, err)
+	}
+
+	plan := &importPlan{
+		table:   table,
+		columns: columns,
+		byName:  make(map[string]*columnInfo, len(columns)),
+		mapping: im.config.Columns,
+	}
+	for _, col := range columns {
+		plan.byName[strings.ToLower(col.Name)] = col
+	}
+	return plan, nil
+}
+
+// writableColumns 可写入的列（排除生成列），按表中顺序
+func (p *importPlan) writableColumns() []string {
+	var names []string
+	for _, col := range p.columns {
+		if !col.generated() {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// resolve 将文件字段映射到目标列，skip 为 true 时忽略该字段
+func (p *importPlan) resolve(field string) (col *columnInfo, skip bool, err error) {
+	name := field
+	if mapped, ok := p.mapping[field]; ok {
+		if mapped == SkipColumn {
+			return nil, true, nil
+		}
+		name = mapped
+	}
+
+	col, ok := p.byName[strings.ToLower(name)]
+	if !ok {
+		return nil, false, fmt.Errorf("表 %s 中不存在列 %s", p.table, name)
+	}
+	if col.generated() {
+		return nil, false, fmt.Errorf("%s 是生成列，不能写入", col.Name)
+	}
+	return col, false, nil
+}
+
+// checkFields 在导入前检查 CSV 标题行，所有字段都必须能映射到目标列
+func (p *importPlan) checkFields(fields []string) error {
+	var unknown []string
+	for _, field := range fields {
+		if _, _, err := p.resolve(field); err != nil {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("表 %s 中没有对应的列: %s（可以用列映射指定目标列，或映射为 - 忽略）",
+			p.table, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// importRow 转换完成、等待写入的行
+type importRow struct {
+	line   int64
+	raw    string
+	values map[string]interface{} // 列名 -> 值
+}
+
+// run 执行单表导入
+func (im *Importer) run(ctx context.Context, plan *importPlan, reader recordReader, hexBinary bool) (*ImportResult, error) {
+	result := &ImportResult{Table: plan.table}
+
+	if im.config.Truncate {
+		if _, err := im.db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", quoteIdentifier(plan.table))); err != nil {
+			return result, fmt.Errorf("清空表 %s 失败: %v", plan.table, err)
+		}
+	}
+
+	var (
+		batch   []importRow
+		columns []string
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := im.writeBatch(plan.table, columns, batch, result)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if rec == nil && err != nil {
+			return result, err
+		}
+		result.Rows++
+
+		if err != nil {
+			if fatal := im.fail(result, RowError{Line: rec.line, Message: err.Error(), Raw: rec.raw}); fatal != nil {
+				return result, fatal
+			}
+			continue
+		}
+
+		row, rowErr := plan.convert(rec, hexBinary)
+		if rowErr != nil {
+			if fatal := im.fail(result, *rowErr); fatal != nil {
+				return result, fatal
+			}
+			continue
+		}
+
+		// 同一批的行必须有相同的列，JSON 记录的字段不一致时先写出当前批
+		rowColumns := sortedColumns(plan, row.values)
+		if len(batch) > 0 && !sameColumns(columns, rowColumns) {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+		columns = rowColumns
+		batch = append(batch, row)
+
+		if len(batch) >= im.config.BatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+			if im.onProgress != nil {
+				im.onProgress(plan.table, result.Rows)
+			}
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			default:
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return result, err
+	}
+	if im.onProgress != nil {
+		im.onProgress(plan.table, result.Rows)
+	}
+	return result, nil
+}
+
+// convert 将记录映射到目标列并转换类型
+func (p *importPlan) convert(rec *record, hexBinary bool) (importRow, *RowError) {
+	row := importRow{line: rec.line, raw: rec.raw, values: make(map[string]interface{}, len(rec.values))}
+
+	for field, value := range rec.values {
+		col, skip, err := p.resolve(field)
+		if skip {
+			continue
+		}
+		if err != nil {
+			return row, &RowError{Table: p.table, Line: rec.line, Column: field, Message: err.Error(), Raw: rec.raw}
+		}
+
+		converted, err := coerce(col, value, hexBinary)
+		if err != nil {
+			return row, &RowError{Table: p.table, Line: rec.line, Column: col.Name, Message: err.Error(), Raw: rec.raw}
+		}
+		row.values[col.Name] = converted
+	}
+
+	// 文件中没有的非空列必须有默认值
+	for _, col := range p.columns {
+		if _, ok := row.values[col.Name]; ok || col.Nullable || col.HasDefault || col.autoIncrement() || col.generated() {
+			continue
+		}
+		return row, &RowError{Table: p.table, Line: rec.line, Column: col.Name, Message: "缺少必填列", Raw: rec.raw}
+	}
+
+	if len(row.values) == 0 {
+		return row, &RowError{Table: p.table, Line: rec.line, Message: "记录没有可写入的列", Raw: rec.raw}
+	}
+	return row, nil
+}
+
+// sortedColumns 按表中的列顺序返回行中的列
+func sortedColumns(plan *importPlan, values map[string]interface{}) []string {
+	columns := make([]string, 0, len(values))
+	for _, col := range plan.columns {
+		if _, ok := values[col.Name]; ok {
+			columns = append(columns, col.Name)
+		}
+	}
+	return columns
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBatch 写入一批行；整批失败时逐行重试，找出出错的行
+func (im *Importer) writeBatch(table string, columns []string, batch []importRow, result *ImportResult) error {
+	if err := im.insert(table, columns, batch); err == nil {
+		result.Imported += int64(len(batch))
+		return nil
+	} else if len(batch) == 1 {
+		return im.fail(result, RowError{Table: table, Line: batch[0].line, Message: err.Error(), Raw: batch[0].raw})
+	}
+
+	for _, row := range batch {
+		if err := im.insert(table, columns, []importRow{row}); err != nil {
+			if fatal := im.fail(result, RowError{Table: table, Line: row.line, Message: err.Error(), Raw: row.raw}); fatal != nil {
+				return fatal
+			}
+			continue
+		}
+		result.Imported++
+	}
+	return nil
+}
+
+// insert 按冲突处理方式生成并执行 INSERT 语句
+func (im *Importer) insert(table string, columns []string, rows []importRow) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdentifier(col)
+	}
+
+	verb := "INSERT INTO"
+	switch im.config.OnConflict {
+	case ConflictIgnore:
+		verb = "INSERT IGNORE INTO"
+	case ConflictReplace:
+		verb = "REPLACE INTO"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s (%s) VALUES ", verb, quoteIdentifier(table), strings.Join(quoted, ", "))
+
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j, col := range columns {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			value := row.values[col]
+			if _, ok := value.(useDefault); ok {
+				sb.WriteString("DEFAULT")
+				continue
+			}
+			if raw, ok := value.(types.RawSQL); ok {
+				sb.WriteString(string(raw))
+				continue
+			}
+			sb.WriteString("?")
+			args = append(args, value)
+		}
+		sb.WriteString(")")
+	}
+
+	if im.config.OnConflict == ConflictUpdate {
+		updates := make([]string, len(quoted))
+		for i, col := range quoted {
+			updates[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+		}
+		sb.WriteString(" ON DUPLICATE KEY UPDATE ")
+		sb.WriteString(strings.Join(updates, ", "))
+	}
+
+	_, err := im.db.Exec(sb.String(), args...)
+	return err
+}
+
+// fail 记录一行错误，超过 MaxErrors 时返回中止错误
+func (im *Importer) fail(result *ImportResult, rowErr RowError) error {
+	rowErr.Table = result.Table
+	result.Failed++
+	if len(result.Errors) < maxKeptErrors {
+		result.Errors = append(result.Errors, rowErr)
+	}
+	if im.onError != nil {
+		im.onError(rowErr)
+	}
+
+	if im.config.MaxErrors > 0 && result.Failed > int64(im.config.MaxErrors) {
+		return fmt.Errorf("表 %s 错误行数超过 %d，导入中止（最后一个错误: %v）", result.Table, im.config.MaxErrors, rowErr)
+	}
+	return nil
+}
+
+// quoteIdentifier 用反引号包裹标识符
+func quoteIdentifier(name string) string {
+	return dialect.MySQL.QuoteIdent(name)
+}
+
+// DataFiles 列出目录中可导入的数据文件，按文件名排序
+func DataFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		format, err := FormatFromPath(entry.Name())
+		if err != nil || format == FormatSQL {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,295 @@
+package datafile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// record 从文件读出的一条记录
+type record struct {
+	line   int64                  // 记录在文件中的起始行号，JSON 数组和 YAML 为记录序号
+	values map[string]interface{} // 字段名 -> 值
+	raw    string                 // 原始内容，用于错误报告
+}
+
+// recordReader 逐条读取记录，读完返回 io.EOF
+type recordReader interface {
+	next() (*record, error)
+}
+
+// CSVOptions CSV 读取选项
+type CSVOptions struct {
+	Delimiter  rune     // 字段分隔符，默认逗号
+	Quote      rune     // 引号字符，默认双引号
+	NullValues []string // 表示 NULL 的文本，默认 \N
+	NoHeader   bool     // 文件没有标题行，按 Header 或表的列顺序对应
+	Header     []string // NoHeader 时使用的列名
+}
+
+// csvRecordReader CSV 记录读取器，支持自定义分隔符和引号，字段内可以包含换行
+type csvRecordReader struct {
+	r       *bufio.Reader
+	options CSVOptions
+	header  []string
+	line    int64
+}
+
+func newCSVRecordReader(r io.Reader, options CSVOptions) (*csvRecordReader, error) {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.Quote == 0 {
+		options.Quote = '"'
+	}
+	if options.NullValues == nil {
+		options.NullValues = []string{NullText}
+	}
+	if options.Delimiter == options.Quote {
+		return nil, fmt.Errorf("分隔符和引号不能相同")
+	}
+
+	reader := &csvRecordReader{r: bufio.NewReader(r), options: options}
+	if options.NoHeader {
+		reader.header = options.Header
+		return reader, nil
+	}
+
+	fields, _, _, err := reader.readFields()
+	if err == io.EOF {
+		return nil, fmt.Errorf("文件为空，缺少标题行")
+	}
+	if err != nil {
+		return nil, err
+	}
+	reader.header = fieldTexts(fields)
+	for i, name := range reader.header {
+		reader.header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+	}
+	return reader, nil
+}
+
+// csvField 一个 CSV 字段，quoted 表示字段带引号（带引号的 \N 不视为 NULL）
+type csvField struct {
+	text   string
+	quoted bool
+}
+
+func fieldTexts(fields []csvField) []string {
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = field.text
+	}
+	return texts
+}
+
+func (c *csvRecordReader) next() (*record, error) {
+	fields, line, raw, err := c.readFields()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &record{line: line, raw: raw, values: make(map[string]interface{}, len(fields))}
+	if len(fields) != len(c.header) {
+		return rec, fmt.Errorf("字段数 %d 与标题列数 %d 不一致", len(fields), len(c.header))
+	}
+	for i, field := range fields {
+		rec.values[c.header[i]] = c.value(field)
+	}
+	return rec, nil
+}
+
+// value 将字段转换为值，匹配 NULL 标记的无引号字段为 nil
+func (c *csvRecordReader) value(field csvField) interface{} {
+	if !field.quoted {
+		for _, null := range c.options.NullValues {
+			if field.text == null {
+				return nil
+			}
+		}
+	}
+	return field.text
+}
+
+// readFields 读取一条记录的所有字段，跳过空行
+func (c *csvRecordReader) readFields() ([]csvField, int64, string, error) {
+	for {
+		fields, line, raw, err := c.readRecord()
+		if err != nil {
+			return nil, line, raw, err
+		}
+		if len(fields) == 1 && !fields[0].quoted && fields[0].text == "" {
+			continue
+		}
+		return fields, line, raw, nil
+	}
+}
+
+// readRecord 读取一条记录，带引号的字段可以跨行，两个连续引号表示一个引号
+func (c *csvRecordReader) readRecord() ([]csvField, int64, string, error) {
+	line, err := c.r.ReadString('\n')
+	if line == "" && err != nil {
+		return nil, c.line + 1, "", err
+	}
+	c.line++
+	start := c.line
+
+	var (
+		raw    strings.Builder
+		fields []csvField
+		field  strings.Builder
+		quoted bool
+		inQ    bool
+	)
+	raw.WriteString(line)
+
+	for {
+		runes := []rune(line)
+		for i := 0; i < len(runes); i++ {
+			r := runes[i]
+			switch {
+			case inQ && r == c.options.Quote:
+				if i+1 < len(runes) && runes[i+1] == c.options.Quote {
+					field.WriteRune(r)
+					i++
+				} else {
+					inQ = false
+				}
+			case inQ:
+				field.WriteRune(r)
+			case r == c.options.Quote && field.Len() == 0 && !quoted:
+				inQ, quoted = true, true
+			case r == c.options.Delimiter:
+				fields = append(fields, csvField{text: field.String(), quoted: quoted})
+				field.Reset()
+				quoted = false
+			case r == '\n' || (r == '\r' && i+1 < len(runes) && runes[i+1] == '\n'):
+				// 记录结束
+			default:
+				field.WriteRune(r)
+			}
+		}
+
+		if !inQ {
+			break
+		}
+
+		// 引号内的换行，继续读取下一行
+		if err != nil {
+			return nil, start, raw.String(), fmt.Errorf("第 %d 行: 引号未闭合", start)
+		}
+		line, err = c.r.ReadString('\n')
+		if line == "" && err != nil {
+			return nil, start, raw.String(), fmt.Errorf("第 %d 行: 引号未闭合", start)
+		}
+		c.line++
+		raw.WriteString(line)
+	}
+
+	fields = append(fields, csvField{text: field.String(), quoted: quoted})
+	return fields, start, strings.TrimRight(raw.String(), "\r\n"), nil
+}
+
+// jsonLinesReader JSON Lines 读取器，每行一个 JSON 对象
+type jsonLinesReader struct {
+	r    *bufio.Reader
+	line int64
+}
+
+func (j *jsonLinesReader) next() (*record, error) {
+	for {
+		data, err := j.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return nil, err
+		}
+		j.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		rec := &record{line: j.line, raw: string(data)}
+		values, decodeErr := decodeObject(data)
+		if decodeErr != nil {
+			return rec, decodeErr
+		}
+		rec.values = values
+		return rec, nil
+	}
+}
+
+// decodeObject 解析一个 JSON 对象，数字保留为 json.Number 以免丢失精度
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("无效的 JSON: %v", err)
+	}
+	if values == nil {
+		return nil, fmt.Errorf("记录必须是 JSON 对象")
+	}
+	return values, nil
+}
+
+// jsonArrayReader 流式读取 JSON 数组中的对象
+type jsonArrayReader struct {
+	decoder *json.Decoder
+	index   int64
+}
+
+// newJSONArrayReader 从当前位置读取一个数组，decoder 需已设置 UseNumber
+func newJSONArrayReader(decoder *json.Decoder) (*jsonArrayReader, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("无效的 JSON: %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("数据必须是 JSON 数组")
+	}
+	return &jsonArrayReader{decoder: decoder}, nil
+}
+
+func (j *jsonArrayReader) next() (*record, error) {
+	if !j.decoder.More() {
+		// 读取结束的 ]
+		if _, err := j.decoder.Token(); err != nil {
+			return nil, fmt.Errorf("无效的 JSON: %v", err)
+		}
+		return nil, io.EOF
+	}
+
+	j.index++
+	var value json.RawMessage
+	if err := j.decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("第 %d 条记录: 无效的 JSON: %v", j.index, err)
+	}
+
+	rec := &record{line: j.index, raw: string(value)}
+	values, err := decodeObject(value)
+	if err != nil {
+		return rec, err
+	}
+	rec.values = values
+	return rec, nil
+}
+
+// sliceReader 读取已加载到内存的记录（YAML）
+type sliceReader struct {
+	rows  []map[string]interface{}
+	index int
+}
+
+func (s *sliceReader) next() (*record, error) {
+	if s.index >= len(s.rows) {
+		return nil, io.EOF
+	}
+	s.index++
+	row := s.rows[s.index-1]
+	return &record{line: int64(s.index), values: row, raw: fmt.Sprintf("%v", row)}, nil
+}
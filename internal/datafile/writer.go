@@ -0,0 +1,283 @@
+package datafile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rowWriter 按格式写出行
+type rowWriter interface {
+	writeHeader(columns []string) error
+	writeRow(cells []cell) error
+	close() error
+}
+
+// newRowWriter 创建指定格式的行写出器
+func newRowWriter(format Format, w io.Writer, table string, sqlBatchSize int) (rowWriter, error) {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatCSV:
+		return &csvWriter{bw: bw, w: csv.NewWriter(bw)}, nil
+	case FormatJSONL:
+		return &jsonWriter{bw: bw, lines: true}, nil
+	case FormatJSON:
+		return &jsonWriter{bw: bw}, nil
+	case FormatSQL:
+		if sqlBatchSize <= 0 {
+			sqlBatchSize = 500
+		}
+		return &sqlWriter{bw: bw, table: table, batchSize: sqlBatchSize}, nil
+	case FormatYAML:
+		return &yamlWriter{bw: bw}, nil
+	}
+	return nil, fmt.Errorf("不支持的格式: %s", format)
+}
+
+// NullText CSV 中表示 NULL 的文本，与 MySQL LOAD DATA 一致
+const NullText = `\N`
+
+// csvWriter CSV 写出器，非 UTF-8 的二进制数据写作 X'十六进制'，导入时解码
+type csvWriter struct {
+	bw *bufio.Writer
+	w  *csv.Writer
+}
+
+func (c *csvWriter) writeHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) writeRow(cells []cell) error {
+	record := make([]string, len(cells))
+	for i, value := range cells {
+		switch value.kind {
+		case cellNull:
+			record[i] = NullText
+		case cellBinary:
+			record[i] = "X'" + hex.EncodeToString(value.raw) + "'"
+		default:
+			record[i] = value.text
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.bw.Flush()
+}
+
+// jsonWriter JSON 数组或 JSON Lines 写出器，按列顺序输出键
+type jsonWriter struct {
+	bw      *bufio.Writer
+	lines   bool
+	columns []string
+	rows    int64
+}
+
+func (j *jsonWriter) writeHeader(columns []string) error {
+	j.columns = columns
+	if !j.lines {
+		_, err := j.bw.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+func (j *jsonWriter) writeRow(cells []cell) error {
+	var sb strings.Builder
+	if !j.lines {
+		if j.rows > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n  ")
+	}
+
+	sb.WriteString("{")
+	for i, value := range cells {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		key, _ := json.Marshal(j.columns[i])
+		sb.Write(key)
+		sb.WriteString(": ")
+		switch value.kind {
+		case cellNull:
+			sb.WriteString("null")
+		case cellNumber:
+			sb.WriteString(value.text)
+		default:
+			text, err := json.Marshal(value.text)
+			if err != nil {
+				return err
+			}
+			sb.Write(text)
+		}
+	}
+	sb.WriteString("}")
+	if j.lines {
+		sb.WriteString("\n")
+	}
+
+	j.rows++
+	_, err := j.bw.WriteString(sb.String())
+	return err
+}
+
+func (j *jsonWriter) close() error {
+	if !j.lines {
+		if j.rows > 0 {
+			j.bw.WriteString("\n")
+		}
+		j.bw.WriteString("]\n")
+	}
+	return j.bw.Flush()
+}
+
+// yamlWriter YAML 列表写出器，按列顺序输出键
+type yamlWriter struct {
+	bw      *bufio.Writer
+	columns []string
+	rows    int64
+}
+
+func (y *yamlWriter) writeHeader(columns []string) error {
+	y.columns = columns
+	return nil
+}
+
+func (y *yamlWriter) writeRow(cells []cell) error {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for i, value := range cells {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: y.columns[i]}
+		var node *yaml.Node
+		switch value.kind {
+		case cellNull:
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		case cellNumber:
+			tag := "!!int"
+			if strings.ContainsAny(value.text, ".eE") {
+				tag = "!!float"
+			}
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.text}
+		default:
+			node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value.text}
+		}
+		mapping.Content = append(mapping.Content, key, node)
+	}
+
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{mapping}})
+	if err != nil {
+		return err
+	}
+	y.rows++
+	_, err = y.bw.Write(out)
+	return err
+}
+
+func (y *yamlWriter) close() error {
+	if y.rows == 0 {
+		y.bw.WriteString("[]\n")
+	}
+	return y.bw.Flush()
+}
+
+// sqlWriter INSERT 语句写出器，每 batchSize 行一条语句
+type sqlWriter struct {
+	bw        *bufio.Writer
+	table     string
+	batchSize int
+	prefix    string
+	pending   int
+}
+
+func (s *sqlWriter) writeHeader(columns []string) error {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdentifier(col)
+	}
+	s.prefix = fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", quoteIdentifier(s.table), strings.Join(quoted, ", "))
+	_, err := fmt.Fprintf(s.bw, "-- 表 %s 的数据\n", s.table)
+	return err
+}
+
+func (s *sqlWriter) writeRow(cells []cell) error {
+	var sb strings.Builder
+	if s.pending == 0 {
+		sb.WriteString(s.prefix)
+	} else {
+		sb.WriteString(",\n")
+	}
+
+	sb.WriteString("(")
+	for i, value := range cells {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		switch value.kind {
+		case cellNull:
+			sb.WriteString("NULL")
+		case cellNumber:
+			sb.WriteString(value.text)
+		case cellBinary:
+			sb.WriteString("X'")
+			sb.WriteString(strings.TrimPrefix(value.text, "0x"))
+			sb.WriteString("'")
+		default:
+			sb.WriteString(QuoteString(value.text))
+		}
+	}
+	sb.WriteString(")")
+
+	s.pending++
+	if s.pending >= s.batchSize {
+		sb.WriteString(";\n")
+		s.pending = 0
+	}
+
+	_, err := s.bw.WriteString(sb.String())
+	return err
+}
+
+func (s *sqlWriter) close() error {
+	if s.pending > 0 {
+		s.bw.WriteString(";\n")
+	}
+	return s.bw.Flush()
+}
+
+// QuoteString 将字符串转义为 MySQL 字符串字面量
+func QuoteString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case 0x1a:
+			sb.WriteString(`\Z`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
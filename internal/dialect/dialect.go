@@ -0,0 +1,149 @@
+// Package dialect 提供生成 SQL 时的标识符引用、字符串转义和标识符校验，
+// 构建器拼接表名、列名、注释和默认值时统一经过这里，避免保留字和引号破坏语句
+package dialect
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect SQL 方言的引用规则
+type Dialect struct {
+	name             string
+	identQuote       byte // 标识符引号
+	backslashEscapes bool // 字符串中的反斜杠是否为转义符
+	maxIdentLength   int  // 标识符最大长度（字符数）
+}
+
+// MySQL MySQL/MariaDB：反引号标识符，字符串支持反斜杠转义
+var MySQL = &Dialect{name: "mysql", identQuote: '`', backslashEscapes: true, maxIdentLength: 64}
+
+// Name 方言名称
+func (d *Dialect) Name() string {
+	return d.name
+}
+
+// QuoteIdent 引用单个标识符，标识符中的引号加倍；已经引用过的标识符原样返回
+func (d *Dialect) QuoteIdent(name string) string {
+	if d.isQuoted(name) {
+		return name
+	}
+	quote := string(d.identQuote)
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// QuoteQualified 引用可能带库名的标识符，如 shop.orders 引用为 `shop`.`orders`
+func (d *Dialect) QuoteQualified(name string) string {
+	parts := d.splitQualified(name)
+	for i, part := range parts {
+		parts[i] = d.QuoteIdent(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// QuoteIdents 引用多个标识符，以逗号分隔
+func (d *Dialect) QuoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdent(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// QuoteString 转义并用单引号包裹字符串字面量
+func (d *Dialect) QuoteString(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value) + 2)
+	sb.WriteByte('\'')
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\'':
+			sb.WriteString("''")
+		case !d.backslashEscapes:
+			sb.WriteByte(c)
+		case c == '\\':
+			sb.WriteString(`\\`)
+		case c == 0:
+			sb.WriteString(`\0`)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == 0x1a:
+			sb.WriteString(`\Z`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// ValidateIdent 校验标识符：不能为空、超长、包含 NUL 或以空格结尾
+func (d *Dialect) ValidateIdent(name string) error {
+	if d.isQuoted(name) {
+		name = d.unquote(name)
+	}
+	switch {
+	case name == "":
+		return fmt.Errorf("标识符不能为空")
+	case !utf8.ValidString(name):
+		return fmt.Errorf("标识符 %q 不是有效的 UTF-8", name)
+	case utf8.RuneCountInString(name) > d.maxIdentLength:
+		return fmt.Errorf("标识符 %s 超过 %d 个字符", name, d.maxIdentLength)
+	case strings.ContainsRune(name, 0):
+		return fmt.Errorf("标识符 %q 包含 NUL 字符", name)
+	case strings.HasSuffix(name, " "):
+		return fmt.Errorf("标识符 %q 不能以空格结尾", name)
+	}
+	return nil
+}
+
+// ValidateQualified 校验可能带库名的标识符的每一部分
+func (d *Dialect) ValidateQualified(name string) error {
+	parts := d.splitQualified(name)
+	if len(parts) > 2 {
+		return fmt.Errorf("标识符 %s 最多包含库名和表名两部分", name)
+	}
+	for _, part := range parts {
+		if err := d.ValidateIdent(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isQuoted 标识符是否已被完整引用（内部的引号均已加倍）
+func (d *Dialect) isQuoted(name string) bool {
+	if len(name) < 2 || name[0] != d.identQuote || name[len(name)-1] != d.identQuote {
+		return false
+	}
+	inner := name[1 : len(name)-1]
+	quote := string(d.identQuote)
+	return !strings.Contains(strings.ReplaceAll(inner, quote+quote, ""), quote)
+}
+
+func (d *Dialect) unquote(name string) string {
+	quote := string(d.identQuote)
+	return strings.ReplaceAll(name[1:len(name)-1], quote+quote, quote)
+}
+
+// splitQualified 按引号外的点拆分标识符
+func (d *Dialect) splitQualified(name string) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case d.identQuote:
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				parts = append(parts, name[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, name[start:])
+}
@@ -0,0 +1,199 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+
+	"github.com/xiezhihuan/db-migrator/internal/builder"
+	"github.com/xiezhihuan/db-migrator/internal/checker"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// MigrationContext 迁移中使用的连接、检查器和构建器，已按目标数据库配置好。
+// 它本身实现了 context.Context，可以直接传给构建器的方法
+type MigrationContext struct {
+	context.Context
+	DB        types.DB                 // 迁移所在事务的连接，干运行时写操作只打印不执行
+	Database  string                   // 目标数据库名
+	ConfigKey string                   // 迁移器使用的数据库名称（配置键或库名），未知时为空
+	Checker   types.Checker            // 目标数据库的存在性检查器
+	Builder   *builder.SQLBuilder      // SQL 构建器
+	Advanced  *builder.AdvancedBuilder // 链式表构建器
+	Logger    *log.Logger              // 带数据库名前缀的日志
+	DryRun    bool                     // 是否为干运行
+	DBManager types.DBManager          // 其他数据库的只读连接（只读会话），不在迁移事务中；未设置时为 nil
+}
+
+// ContextMigration 可选的迁移接口，迁移器会调用 UpWithContext/DownWithContext 代替 Up/Down。
+// 干运行时也会调用，此时 mc.DB 的写操作只打印不执行
+type ContextMigration interface {
+	types.Migration
+	UpWithContext(mc *MigrationContext) error
+	DownWithContext(mc *MigrationContext) error
+}
+
+// BaseContextMigration 嵌入到只实现 UpWithContext/DownWithContext 的迁移中，补齐 Up/Down
+type BaseContextMigration struct{}
+
+// Up 只在迁移没有实现 UpWithContext 时被调用
+func (BaseContextMigration) Up(ctx context.Context, db types.DB) error {
+	return fmt.Errorf("迁移没有实现 UpWithContext")
+}
+
+// Down 只在迁移没有实现 DownWithContext 时被调用
+func (BaseContextMigration) Down(ctx context.Context, db types.DB) error {
+	return fmt.Errorf("迁移没有实现 DownWithContext")
+}
+
+// NewMigrationContext 为 Up/Down 收到的 db 创建迁移上下文，数据库名取连接的当前库
+func NewMigrationContext(ctx context.Context, db types.DB) (*MigrationContext, error) {
+	var database string
+	if err := db.QueryRow("SELECT DATABASE()").Scan(&database); err != nil {
+		return nil, fmt.Errorf("获取当前数据库失败: %v", err)
+	}
+	return newMigrationContext(ctx, db, database), nil
+}
+
+func newMigrationContext(ctx context.Context, db types.DB, database string) *MigrationContext {
+	c := checker.NewMySQLChecker(db, database)
+	return &MigrationContext{
+		Context:  ctx,
+		DB:       db,
+		Database: database,
+		Checker:  c,
+		Builder:  builder.NewSQLBuilder(c, db),
+		Advanced: builder.NewAdvancedBuilder(c, db),
+		Logger:   log.New(log.Writer(), fmt.Sprintf("[%s] ", database), log.Flags()),
+	}
+}
+
+// readOnlyManager 只提供只读连接的数据库管理器。每个数据库固定使用连接池中的一个连接，
+// 并把会话设为 READ ONLY，写入和 DDL 由服务端拒绝；迁移结束后由 release 丢弃这些连接
+type readOnlyManager struct {
+	types.DBManager
+	ctx   context.Context
+	conns map[string]*readOnlyDB
+}
+
+func newReadOnlyManager(ctx context.Context, manager types.DBManager) *readOnlyManager {
+	return &readOnlyManager{DBManager: manager, ctx: ctx, conns: make(map[string]*readOnlyDB)}
+}
+
+// GetDatabase 获取指定数据库的只读连接
+func (m *readOnlyManager) GetDatabase(name string) (types.DB, error) {
+	db, err := m.DBManager.GetDatabase(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.open(db, name)
+}
+
+// GetDefaultDatabase 获取默认数据库的只读连接
+func (m *readOnlyManager) GetDefaultDatabase() (types.DB, string, error) {
+	db, name, err := m.DBManager.GetDefaultDatabase()
+	if err != nil {
+		return nil, name, err
+	}
+	readOnly, err := m.open(db, name)
+	return readOnly, name, err
+}
+
+// CloseAll 不关闭连接，连接由迁移器统一释放
+func (m *readOnlyManager) CloseAll() error {
+	return nil
+}
+
+// open 从连接池取出一个连接并设为只读会话，同一数据库复用同一连接
+func (m *readOnlyManager) open(db types.DB, name string) (*readOnlyDB, error) {
+	if readOnly, exists := m.conns[name]; exists {
+		return readOnly, nil
+	}
+
+	raw, ok := db.(interface{ GetRawDB() *sql.DB })
+	if !ok {
+		return nil, fmt.Errorf("数据库 %s 的连接不支持只读会话", name)
+	}
+	conn, err := raw.GetRawDB().Conn(m.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库 %s 的连接失败: %v", name, err)
+	}
+	if _, err := conn.ExecContext(m.ctx, "SET SESSION TRANSACTION READ ONLY"); err != nil {
+		discardConn(conn)
+		return nil, fmt.Errorf("设置数据库 %s 的只读会话失败: %v", name, err)
+	}
+
+	readOnly := &readOnlyDB{conn: conn, ctx: m.ctx, name: name}
+	m.conns[name] = readOnly
+	return readOnly, nil
+}
+
+// release 丢弃所有只读连接，只读的会话设置不会回到连接池
+func (m *readOnlyManager) release() {
+	for name, readOnly := range m.conns {
+		discardConn(readOnly.conn)
+		delete(m.conns, name)
+	}
+}
+
+// discardConn 关闭连接而不是放回连接池
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
+
+// readOnlyDB 只读会话中的连接，拒绝 Exec 和事务。
+// 所有查询共用一个连接，与迁移事务一样需要在下一次查询前关闭上一次的 Rows
+type readOnlyDB struct {
+	conn *sql.Conn
+	ctx  context.Context
+	name string
+}
+
+func (d *readOnlyDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, fmt.Errorf("迁移中数据库 %s 的连接是只读的", d.name)
+}
+
+func (d *readOnlyDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.conn.QueryContext(d.ctx, query, args...)
+}
+
+func (d *readOnlyDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.conn.QueryRowContext(d.ctx, query, args...)
+}
+
+func (d *readOnlyDB) Begin() (*sql.Tx, error) {
+	return nil, fmt.Errorf("迁移中数据库 %s 的连接是只读的", d.name)
+}
+
+func (d *readOnlyDB) Close() error {
+	return nil
+}
+
+// dryRunDB 干运行时使用的连接，查询照常执行，写操作只打印
+type dryRunDB struct {
+	types.DB
+}
+
+func (d *dryRunDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if len(args) > 0 {
+		log.Printf("干运行模式: %s %v", query, args)
+	} else {
+		log.Printf("干运行模式: %s", query)
+	}
+	return dryRunResult{}, nil
+}
+
+func (d *dryRunDB) Begin() (*sql.Tx, error) {
+	return nil, fmt.Errorf("干运行模式不支持开启事务")
+}
+
+// dryRunResult 干运行时 Exec 的结果
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) { return 0, nil }
+func (dryRunResult) RowsAffected() (int64, error) { return 0, nil }
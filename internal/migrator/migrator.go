@@ -8,9 +8,13 @@ import (
 	"sort"
 	"time"
 
+	"github.com/xiezhihuan/db-migrator/internal/checker"
 	"github.com/xiezhihuan/db-migrator/internal/types"
 )
 
+// BaselineDescription 基线标记记录的描述，版本不大于基线的迁移视为已执行
+const BaselineDescription = "<< baseline >>"
+
 // Migrator 迁移器
 type Migrator struct {
 	db              types.DB
@@ -19,6 +23,8 @@ type Migrator struct {
 	migrations      []types.Migration
 	migrationsTable string
 	lockTable       string
+	configKey       string          // 数据库名称（配置键或库名），传给 MigrationContext
+	dbManager       types.DBManager // 传给 MigrationContext 用于只读查询其他数据库
 }
 
 // NewMigrator 创建迁移器
@@ -43,6 +49,13 @@ func NewMigrator(db types.DB, checker types.Checker, config types.MigratorConfig
 	}
 }
 
+// SetDatabaseManager 设置迁移上下文中的数据库名称和数据库管理器，
+// 实现了 ContextMigration 的迁移可以通过它只读地查询其他数据库
+func (m *Migrator) SetDatabaseManager(configKey string, dbManager types.DBManager) {
+	m.configKey = configKey
+	m.dbManager = dbManager
+}
+
 // RegisterMigration 注册迁移
 func (m *Migrator) RegisterMigration(migration types.Migration) {
 	m.migrations = append(m.migrations, migration)
@@ -89,6 +102,7 @@ func (m *Migrator) Up(ctx context.Context) error {
 	}
 
 	log.Printf("找到 %d 个迁移，已执行 %d 个", len(m.migrations), len(appliedMigrations))
+	baseline := baselineVersion(appliedMigrations)
 
 	// 执行待处理的迁移
 	executed := 0
@@ -97,6 +111,10 @@ func (m *Migrator) Up(ctx context.Context) error {
 			log.Printf("跳过已执行的迁移: %s - %s", migration.Version(), migration.Description())
 			continue
 		}
+		if baseline != "" && migration.Version() <= baseline {
+			log.Printf("跳过基线 %s 之前的迁移: %s - %s", baseline, migration.Version(), migration.Description())
+			continue
+		}
 
 		if err := m.executeMigration(ctx, migration, true); err != nil {
 			return fmt.Errorf("执行迁移 %s 失败: %v", migration.Version(), err)
@@ -178,6 +196,7 @@ func (m *Migrator) Status(ctx context.Context) ([]types.MigrationStatus, error)
 
 	// 排序迁移
 	m.sortMigrations()
+	baseline := baselineVersion(appliedMigrations)
 
 	var statuses []types.MigrationStatus
 	for _, migration := range m.migrations {
@@ -190,6 +209,10 @@ func (m *Migrator) Status(ctx context.Context) ([]types.MigrationStatus, error)
 		if record, applied := appliedMigrations[migration.Version()]; applied {
 			status.Applied = true
 			status.AppliedAt = &record.AppliedAt
+		} else if baseline != "" && migration.Version() <= baseline {
+			record := appliedMigrations[baseline]
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
 		}
 
 		statuses = append(statuses, status)
@@ -198,6 +221,102 @@ func (m *Migrator) Status(ctx context.Context) ([]types.MigrationStatus, error)
 	return statuses, nil
 }
 
+// Baseline 将版本不大于 version 的迁移记录为已执行，但不执行迁移本身，用于接管已有结构的数据库。
+// version 不是已注册的迁移版本时额外写入一条基线标记，之后注册的更早版本的迁移也会被跳过。
+// 返回新记录的迁移数量
+func (m *Migrator) Baseline(ctx context.Context, version string) (int, error) {
+	if version == "" {
+		return 0, fmt.Errorf("基线版本不能为空")
+	}
+
+	if err := m.Init(ctx); err != nil {
+		return 0, err
+	}
+
+	// 获取锁
+	if err := m.acquireLock(ctx); err != nil {
+		return 0, fmt.Errorf("获取迁移锁失败: %v", err)
+	}
+	defer m.releaseLock(ctx)
+
+	m.sortMigrations()
+
+	appliedMigrations, err := m.getAppliedMigrations(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取已执行迁移失败: %v", err)
+	}
+
+	var pending []types.Migration
+	registered := false
+	for _, migration := range m.migrations {
+		if migration.Version() > version {
+			break
+		}
+		if migration.Version() == version {
+			registered = true
+		}
+		if _, applied := appliedMigrations[migration.Version()]; applied {
+			log.Printf("跳过已执行的迁移: %s - %s", migration.Version(), migration.Description())
+			continue
+		}
+		pending = append(pending, migration)
+	}
+	_, markerExists := appliedMigrations[version]
+	needMarker := !registered && !markerExists
+
+	if len(pending) == 0 && !needMarker {
+		log.Printf("版本 %s 及之前的迁移均已记录", version)
+		return 0, nil
+	}
+
+	if m.config.DryRun {
+		for _, migration := range pending {
+			log.Printf("干运行模式: 将记录迁移 %s - %s", migration.Version(), migration.Description())
+		}
+		if needMarker {
+			log.Printf("干运行模式: 将写入基线标记 %s", version)
+		}
+		return len(pending), nil
+	}
+
+	// 所有记录在同一事务中写入
+	tx, err := m.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("开始事务失败: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, migration := range pending {
+		if err := m.recordMigration(tx, migration.Version(), migration.Description(), true, ""); err != nil {
+			return 0, fmt.Errorf("记录迁移 %s 失败: %v", migration.Version(), err)
+		}
+		log.Printf("记录迁移（未执行）: %s - %s", migration.Version(), migration.Description())
+	}
+	if needMarker {
+		if err := m.recordMigration(tx, version, BaselineDescription, true, ""); err != nil {
+			return 0, fmt.Errorf("写入基线标记失败: %v", err)
+		}
+		log.Printf("写入基线标记: %s", version)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("提交事务失败: %v", err)
+	}
+
+	return len(pending), nil
+}
+
+// baselineVersion 已执行记录中最高的基线标记版本，没有基线时返回空
+func baselineVersion(applied map[string]types.MigrationRecord) string {
+	baseline := ""
+	for version, record := range applied {
+		if record.Description == BaselineDescription && version > baseline {
+			baseline = version
+		}
+	}
+	return baseline
+}
+
 // executeMigration 执行单个迁移
 func (m *Migrator) executeMigration(ctx context.Context, migration types.Migration, isUp bool) error {
 	version := migration.Version()
@@ -211,6 +330,10 @@ func (m *Migrator) executeMigration(ctx context.Context, migration types.Migrati
 	log.Printf("%s迁移: %s - %s", action, version, description)
 
 	if m.config.DryRun {
+		if contextMigration, ok := migration.(ContextMigration); ok {
+			log.Printf("干运行模式: 只打印%s中的写操作", action)
+			return m.runContextMigration(ctx, contextMigration, &dryRunDB{m.db}, isUp, true)
+		}
 		log.Printf("干运行模式: 跳过实际%s", action)
 		return nil
 	}
@@ -226,11 +349,14 @@ func (m *Migrator) executeMigration(ctx context.Context, migration types.Migrati
 	startTime := time.Now()
 	var migrationErr error
 
-	// 执行迁移
-	if isUp {
-		migrationErr = migration.Up(ctx, &TxWrapper{tx})
+	// 执行迁移，每个迁移使用独立的元数据缓存，迁移中的 DDL 会清空缓存
+	db := checker.NewCachingDB(&TxWrapper{tx}, checker.NewMetadataCache())
+	if contextMigration, ok := migration.(ContextMigration); ok {
+		migrationErr = m.runContextMigration(ctx, contextMigration, db, isUp, false)
+	} else if isUp {
+		migrationErr = migration.Up(ctx, db)
 	} else {
-		migrationErr = migration.Down(ctx, &TxWrapper{tx})
+		migrationErr = migration.Down(ctx, db)
 	}
 
 	// 更新迁移记录
@@ -462,6 +588,26 @@ func (m *Migrator) sortMigrations() {
 	})
 }
 
+// runContextMigration 创建迁移上下文并执行 UpWithContext/DownWithContext
+func (m *Migrator) runContextMigration(ctx context.Context, migration ContextMigration, db types.DB, isUp, dryRun bool) error {
+	mc, err := NewMigrationContext(ctx, db)
+	if err != nil {
+		return err
+	}
+	mc.ConfigKey = m.configKey
+	mc.DryRun = dryRun
+	if m.dbManager != nil {
+		manager := newReadOnlyManager(ctx, m.dbManager)
+		defer manager.release()
+		mc.DBManager = manager
+	}
+
+	if isUp {
+		return migration.UpWithContext(mc)
+	}
+	return migration.DownWithContext(mc)
+}
+
 // TxWrapper 事务包装器，实现DB接口
 type TxWrapper struct {
 	tx *sql.Tx
@@ -3,6 +3,7 @@ package migrator
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -52,6 +53,7 @@ func (mm *MultiMigrator) GetMigrator(dbName string) (*Migrator, error) {
 
 	// 创建迁移器
 	migrator := NewMigrator(db, checker, mm.config.Migrator)
+	migrator.SetDatabaseManager(dbName, mm.dbManager)
 
 	// 注册所有迁移到这个迁移器
 	for _, migration := range mm.migrations {
@@ -137,6 +139,43 @@ func (mm *MultiMigrator) Down(ctx context.Context, databases []string, steps int
 	return nil
 }
 
+// Baseline 在多个数据库上记录基线，版本不大于 version 的迁移标记为已执行但不执行
+func (mm *MultiMigrator) Baseline(ctx context.Context, databases []string, version string) error {
+	if len(databases) == 0 {
+		// 使用默认数据库
+		_, defaultDB, err := mm.dbManager.GetDefaultDatabase()
+		if err != nil {
+			return err
+		}
+		databases = []string{defaultDB}
+	}
+
+	var errors []string
+	for _, dbName := range databases {
+		fmt.Printf("\n📌 正在记录基线: %s\n", dbName)
+
+		migrator, err := mm.GetMigrator(dbName)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("数据库 %s: %v", dbName, err))
+			continue
+		}
+
+		recorded, err := migrator.Baseline(ctx, version)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("数据库 %s: %v", dbName, err))
+			continue
+		}
+
+		fmt.Printf("✅ 数据库 %s 基线为 %s，新记录 %d 个迁移\n", dbName, version, recorded)
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("部分数据库记录基线失败:\n%s", strings.Join(errors, "\n"))
+	}
+
+	return nil
+}
+
 // Status 获取迁移状态
 func (mm *MultiMigrator) Status(ctx context.Context, databases []string) ([]types.MultiDatabaseStatus, error) {
 	if len(databases) == 0 {
@@ -251,10 +290,11 @@ func (mm *MultiMigrator) shouldApplyToDatabase(migration types.Migration, dbName
 	return dbName == defaultDB
 }
 
-// getMigrationPath 获取迁移文件路径（这里简化处理）
+// getMigrationPath 获取迁移文件路径，只有实现了 Path() 的迁移（如 SQLMigration）才有路径
 func (mm *MultiMigrator) getMigrationPath(migration types.Migration) string {
-	// 这里需要根据实际情况实现
-	// 可以通过反射获取类型信息，或者添加路径接口
+	if withPath, ok := migration.(interface{ Path() string }); ok {
+		return withPath.Path()
+	}
 	return ""
 }
 
@@ -273,10 +313,35 @@ func (mm *MultiMigrator) extractDatabaseFromPath(path string) string {
 	return ""
 }
 
-// LoadMigrationsFromDirectory 从目录加载迁移（支持多数据库目录结构）
-func (mm *MultiMigrator) LoadMigrationsFromDirectory(baseDir string) error {
-	// 这个方法需要根据实际的迁移文件加载逻辑来实现
-	// 这里提供基本框架
-	fmt.Printf("从目录 %s 加载迁移文件...\n", baseDir)
-	return nil
+// LoadMigrationsFromDirectory 从目录加载 SQL 迁移并注册，返回加载的数量。
+// 目录下的文件应用到默认数据库，子目录中的文件应用到与子目录同名的数据库；
+// Go 迁移需要在代码中通过 RegisterMigration 注册
+func (mm *MultiMigrator) LoadMigrationsFromDirectory(baseDir string) (int, error) {
+	entries, err := os.ReadDir(baseDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("读取迁移目录失败: %v", err)
+	}
+
+	dirs := map[string]string{baseDir: ""}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs[filepath.Join(baseDir, entry.Name())] = entry.Name()
+		}
+	}
+
+	count := 0
+	for dir, database := range dirs {
+		migrations, err := LoadSQLMigrations(dir, database)
+		if err != nil {
+			return count, err
+		}
+		for _, migration := range migrations {
+			mm.RegisterMigration(migration)
+		}
+		count += len(migrations)
+	}
+	return count, nil
 }
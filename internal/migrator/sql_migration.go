@@ -0,0 +1,166 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// SQL 迁移文件中分隔 Up 和 Down 部分的标记
+const (
+	SQLUpMarker   = "-- +migrate Up"
+	SQLDownMarker = "-- +migrate Down"
+)
+
+// SQLMigration 由 <版本>_<名称>.sql 文件定义的迁移，
+// 文件中 SQLUpMarker 之后是向上迁移的语句，SQLDownMarker 之后是回滚语句，支持 DELIMITER
+type SQLMigration struct {
+	version     string
+	description string
+	database    string
+	path        string
+	up          string
+	down        string
+	hasDown     bool
+}
+
+// Version 返回迁移版本
+func (m *SQLMigration) Version() string {
+	return m.version
+}
+
+// Description 返回迁移描述
+func (m *SQLMigration) Description() string {
+	return m.description
+}
+
+// Database 返回目标数据库，文件位于迁移目录下的子目录时为子目录名
+func (m *SQLMigration) Database() string {
+	return m.database
+}
+
+// Databases 返回目标数据库列表
+func (m *SQLMigration) Databases() []string {
+	return nil
+}
+
+// Path 返回迁移文件路径
+func (m *SQLMigration) Path() string {
+	return m.path
+}
+
+// Up 执行向上迁移
+func (m *SQLMigration) Up(ctx context.Context, db types.DB) error {
+	return execSQLScript(db, m.up)
+}
+
+// Down 执行向下迁移（回滚）
+func (m *SQLMigration) Down(ctx context.Context, db types.DB) error {
+	if !m.hasDown {
+		return fmt.Errorf("迁移文件 %s 没有 %s 部分，无法回滚", m.path, SQLDownMarker)
+	}
+	return execSQLScript(db, m.down)
+}
+
+// LoadSQLMigration 读取一个 SQL 迁移文件
+func LoadSQLMigration(path, database string) (*SQLMigration, error) {
+	version, name, ok := ParseMigrationFilename(filepath.Base(path))
+	if !ok {
+		return nil, fmt.Errorf("迁移文件名 %s 不符合 <版本>_<名称>.sql 格式", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移文件失败: %v", err)
+	}
+
+	// 不属于某部分的行留空，使语句的行号与文件一致
+	m := &SQLMigration{version: version, description: name, database: database, path: path}
+	lines := strings.Split(string(data), "\n")
+	up := make([]string, len(lines))
+	down := make([]string, len(lines))
+	var current []string
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case SQLUpMarker:
+			current = up
+		case SQLDownMarker:
+			current = down
+			m.hasDown = true
+		default:
+			if current != nil {
+				current[i] = line
+			}
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("迁移文件 %s 缺少 %s 标记", path, SQLUpMarker)
+	}
+	m.up = strings.Join(up, "\n")
+	m.down = strings.Join(down, "\n")
+	return m, nil
+}
+
+// LoadSQLMigrations 读取目录下的 SQL 迁移文件（不含子目录），按版本排序
+func LoadSQLMigrations(dir, database string) ([]*SQLMigration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %v", err)
+	}
+
+	var migrations []*SQLMigration
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		m, err := LoadSQLMigration(filepath.Join(dir, entry.Name()), database)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// ParseMigrationFilename 从 <版本>_<名称>.<扩展名> 中解析版本和名称，版本必须全为数字
+func ParseMigrationFilename(filename string) (version, name string, ok bool) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	sep := strings.IndexByte(base, '_')
+	if sep <= 0 || sep == len(base)-1 {
+		return "", "", false
+	}
+	for _, r := range base[:sep] {
+		if r < '0' || r > '9' {
+			return "", "", false
+		}
+	}
+	return base[:sep], base[sep+1:], true
+}
+
+// execSQLScript 逐条执行脚本中的语句
+func execSQLScript(db types.DB, script string) error {
+	scanner := sqlparser.NewScanner(strings.NewReader(script))
+	for {
+		statement, err := scanner.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("解析SQL失败: %v", err)
+		}
+		if _, err := db.Exec(statement.Text); err != nil {
+			return fmt.Errorf("执行SQL语句失败: %v", statement.Errorf("%v", err))
+		}
+	}
+}
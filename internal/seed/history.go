@@ -0,0 +1,193 @@
+package seed
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/xiezhihuan/db-migrator/internal/builder"
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// DefaultHistoryTable 默认的种子记录表
+const DefaultHistoryTable = "seed_history"
+
+// HistoryRecord 一次种子导入记录
+type HistoryRecord struct {
+	ID        int64
+	SeedSet   string // 种子目录、内置数据包或数据文件
+	FilePath  string // 相对于种子目录的文件路径
+	Database  string
+	Checksum  string // 文件内容的 SHA-256
+	Strategy  builder.DataInsertStrategy
+	RowsRead  int64
+	Imported  int64
+	Failed    int64
+	AppliedAt time.Time
+}
+
+// History 目标库中的种子记录，每次导入追加一条，最新一条代表当前版本
+type History struct {
+	db     types.DB
+	table  string
+	quoted string // 引用后的表名，用于拼接 SQL
+}
+
+// NewHistory 创建种子记录
+func NewHistory(db types.DB, table string) *History {
+	if table == "" {
+		table = DefaultHistoryTable
+	}
+	return &History{db: db, table: table, quoted: dialect.MySQL.QuoteQualified(table)}
+}
+
+// Ensure 创建记录表
+func (h *History) Ensure() error {
+	_, err := h.db.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			seed_set VARCHAR(191) NOT NULL,
+			file_path VARCHAR(255) NOT NULL,
+			database_name VARCHAR(64) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			strategy VARCHAR(20) NOT NULL,
+			rows_read BIGINT NOT NULL DEFAULT 0,
+			rows_imported BIGINT NOT NULL DEFAULT 0,
+			rows_failed BIGINT NOT NULL DEFAULT 0,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			KEY idx_seed_file (seed_set, file_path)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`, h.quoted))
+	if err != nil {
+		return fmt.Errorf("创建种子记录表失败: %v", err)
+	}
+	return nil
+}
+
+// Last 返回种子文件最近一次导入记录，没有记录时返回 nil
+func (h *History) Last(seedSet, filePath string) (*HistoryRecord, error) {
+	row := h.db.QueryRow(fmt.Sprintf(`
+		SELECT id, seed_set, file_path, database_name, checksum, strategy,
+			rows_read, rows_imported, rows_failed, applied_at
+		FROM %s WHERE seed_set = ? AND file_path = ?
+		ORDER BY id DESC LIMIT 1
+	`, h.quoted), seedSet, filePath)
+
+	record, err := scanRecord(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return record, err
+}
+
+// Record 追加一条导入记录
+func (h *History) Record(record HistoryRecord) error {
+	_, err := h.db.Exec(fmt.Sprintf(`
+		INSERT INTO %s (seed_set, file_path, database_name, checksum, strategy, rows_read, rows_imported, rows_failed)
+		VALUES (?, ?, DATABASE(), ?, ?, ?, ?, ?)
+	`, h.quoted), record.SeedSet, record.FilePath, record.Checksum, string(record.Strategy),
+		record.RowsRead, record.Imported, record.Failed)
+	if err != nil {
+		return fmt.Errorf("写入种子记录失败: %v", err)
+	}
+	return nil
+}
+
+// Latest 返回每个种子文件的最新记录，按种子和文件排序；记录表不存在时返回空
+func (h *History) Latest() ([]HistoryRecord, error) {
+	// 带库名的表按指定的库查找
+	schema, name := "", strings.Trim(h.table, "`")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		schema, name = strings.Trim(name[:i], "`"), strings.Trim(name[i+1:], "`")
+	}
+
+	var count int
+	err := h.db.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+	`, schema, name).Scan(&count)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	rows, err := h.db.Query(fmt.Sprintf(`
+		SELECT h.id, h.seed_set, h.file_path, h.database_name, h.checksum, h.strategy,
+			h.rows_read, h.rows_imported, h.rows_failed, h.applied_at
+		FROM %s h
+		JOIN (SELECT MAX(id) AS id FROM %s GROUP BY seed_set, file_path) latest ON latest.id = h.id
+		ORDER BY h.seed_set, h.file_path
+	`, h.quoted, h.quoted))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []HistoryRecord
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// scanRecord 扫描一条记录
+func scanRecord(scanner interface{ Scan(...interface{}) error }) (*HistoryRecord, error) {
+	var (
+		record   HistoryRecord
+		strategy string
+	)
+	err := scanner.Scan(&record.ID, &record.SeedSet, &record.FilePath, &record.Database, &record.Checksum,
+		&strategy, &record.RowsRead, &record.Imported, &record.Failed, &record.AppliedAt)
+	if err != nil {
+		return nil, err
+	}
+	record.Strategy = builder.DataInsertStrategy(strategy)
+	return &record, nil
+}
+
+// Checksum 计算种子文件内容的 SHA-256
+func Checksum(fsys fs.FS, file string) (string, error) {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Reapplicable 内容变化后是否可以重新导入：upsert、replace、truncate 会覆盖已有的行，
+// insert/ignore 只会追加新行，重新导入需要 --force
+func Reapplicable(strategy builder.DataInsertStrategy) bool {
+	switch strategy {
+	case builder.StrategyInsertOrUpdate, builder.StrategyReplace, builder.StrategyTruncateAndInsert:
+		return true
+	}
+	return false
+}
+
+// Decide 根据最近一次导入记录决定是否导入，返回是否导入和不导入的原因
+func Decide(last *HistoryRecord, checksum string, strategy builder.DataInsertStrategy, force bool) (bool, string) {
+	switch {
+	case last == nil || force:
+		return true, ""
+	case last.Checksum == checksum:
+		return false, fmt.Sprintf("已于 %s 导入", last.AppliedAt.Format("2006-01-02 15:04:05"))
+	case !Reapplicable(strategy):
+		return false, fmt.Sprintf("内容已变化，%s 策略不会重新导入，使用 upsert 策略或 --force", strategy)
+	}
+	return true, ""
+}
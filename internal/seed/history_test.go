@@ -0,0 +1,19 @@
+package seed
+
+import "testing"
+
+func TestNewHistoryQuotesTable(t *testing.T) {
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{"", "`seed_history`"},
+		{"order", "`order`"},
+		{"meta.seed_history", "`meta`.`seed_history`"},
+	}
+	for _, tt := range tests {
+		if got := NewHistory(nil, tt.table).quoted; got != tt.want {
+			t.Errorf("NewHistory(%q) 的表名为 %s，期望 %s", tt.table, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xiezhihuan/db-migrator/internal/builder"
+	"github.com/xiezhihuan/db-migrator/internal/datafile"
+)
+
+// ManifestFiles 种子目录中可识别的清单文件名
+var ManifestFiles = []string{"manifest.yaml", "manifest.yml", "manifest.json"}
+
+// Manifest 种子清单，声明表的导入顺序、策略和适用环境
+//
+//	description: 基础数据
+//	strategy: upsert
+//	tables:
+//	  - table: roles
+//	  - table: permissions
+//	    strategy: insert
+//	  - table: demo_users
+//	    file: users.csv
+//	    environments: [dev, test]
+type Manifest struct {
+	Description string                     `yaml:"description,omitempty" json:"description,omitempty"`
+	Strategy    builder.DataInsertStrategy `yaml:"strategy,omitempty" json:"strategy,omitempty"` // 默认策略，默认 insert
+	Tables      []TableSeed                `yaml:"tables" json:"tables"`
+}
+
+// TableSeed 清单中的一个表
+type TableSeed struct {
+	Table        string                     `yaml:"table" json:"table"`
+	File         string                     `yaml:"file,omitempty" json:"file,omitempty"`         // 默认查找 <table>.yaml|yml|json|jsonl|csv|sql
+	Strategy     builder.DataInsertStrategy `yaml:"strategy,omitempty" json:"strategy,omitempty"` // 为空时使用清单的默认策略
+	Environments []string                   `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Columns      map[string]string          `yaml:"columns,omitempty" json:"columns,omitempty"` // 文件字段 -> 表列
+}
+
+// Entry 解析后的种子文件
+type Entry struct {
+	Table        string
+	File         string // 相对于种子目录的路径
+	Format       datafile.Format
+	Strategy     builder.DataInsertStrategy
+	Environments []string
+	Columns      map[string]string
+}
+
+// AppliesTo 判断种子是否适用于指定环境；声明了环境的种子只在这些环境中导入
+func (e Entry) AppliesTo(environment string) bool {
+	if len(e.Environments) == 0 {
+		return true
+	}
+	for _, env := range e.Environments {
+		if strings.EqualFold(env, environment) {
+			return true
+		}
+	}
+	return false
+}
+
+// DirSetName 种子目录在导入记录中的名称。
+//
+// 目录位于项目根目录 root 下时为相对 root 的路径，否则为绝对路径，
+// 因此以相对路径或绝对路径、从不同工作目录指定同一目录时名称相同。
+func DirSetName(dir, root string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(filepath.Clean(dir))
+	}
+	if root != "" {
+		if rootAbs, err := filepath.Abs(root); err == nil {
+			rel, err := filepath.Rel(rootAbs, abs)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(abs)
+}
+
+// Set 一组种子数据：一个种子目录或一个内置数据包
+type Set struct {
+	Name        string
+	Description string
+	FS          fs.FS
+	Entries     []Entry
+}
+
+// seedExtensions 种子文件扩展名，同一个表存在多个文件时按此顺序选择
+var seedExtensions = []string{".yaml", ".yml", ".json", ".jsonl", ".csv", ".sql"}
+
+// LoadSet 读取种子目录：按清单中的顺序排列表，清单未列出的文件按文件名顺序追加
+func LoadSet(name string, fsys fs.FS) (*Set, error) {
+	manifest, err := loadManifest(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Strategy == "" {
+		manifest.Strategy = builder.StrategyInsertOnly
+	}
+	if err := validateStrategy(manifest.Strategy); err != nil {
+		return nil, err
+	}
+
+	files, err := seedFiles(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("读取种子目录失败: %v", err)
+	}
+
+	set := &Set{Name: name, Description: manifest.Description, FS: fsys}
+	used := make(map[string]bool)
+
+	for _, table := range manifest.Tables {
+		if table.Table == "" {
+			return nil, fmt.Errorf("清单中存在未指定 table 的条目")
+		}
+
+		file := table.File
+		if file == "" {
+			file = findSeedFile(files, table.Table)
+			if file == "" {
+				return nil, fmt.Errorf("表 %s 没有对应的种子文件", table.Table)
+			}
+		} else if _, err := fs.Stat(fsys, file); err != nil {
+			return nil, fmt.Errorf("表 %s 的种子文件 %s 不存在", table.Table, file)
+		}
+
+		strategy := table.Strategy
+		if strategy == "" {
+			strategy = manifest.Strategy
+		}
+		if err := validateStrategy(strategy); err != nil {
+			return nil, fmt.Errorf("表 %s: %v", table.Table, err)
+		}
+
+		format, err := datafile.FormatFromPath(file)
+		if err != nil {
+			return nil, err
+		}
+
+		used[file] = true
+		set.Entries = append(set.Entries, Entry{
+			Table:        table.Table,
+			File:         file,
+			Format:       format,
+			Strategy:     strategy,
+			Environments: table.Environments,
+			Columns:      table.Columns,
+		})
+	}
+
+	for _, file := range files {
+		if used[file] {
+			continue
+		}
+		format, _ := datafile.FormatFromPath(file)
+		set.Entries = append(set.Entries, Entry{
+			Table:    datafile.TableFromPath(file),
+			File:     file,
+			Format:   format,
+			Strategy: manifest.Strategy,
+		})
+	}
+
+	return set, nil
+}
+
+// FileSet 由单个数据文件组成的种子，文件名即表名
+func FileSet(name string, fsys fs.FS, file string, strategy builder.DataInsertStrategy) (*Set, error) {
+	format, err := datafile.FormatFromPath(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateStrategy(strategy); err != nil {
+		return nil, err
+	}
+	return &Set{
+		Name: name,
+		FS:   fsys,
+		Entries: []Entry{{
+			Table:    datafile.TableFromPath(file),
+			File:     file,
+			Format:   format,
+			Strategy: strategy,
+		}},
+	}, nil
+}
+
+// loadManifest 读取清单，没有清单时返回空清单
+func loadManifest(fsys fs.FS) (*Manifest, error) {
+	manifest := &Manifest{}
+	for _, name := range ManifestFiles {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(data, manifest)
+		} else {
+			err = yaml.Unmarshal(data, manifest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析清单 %s 失败: %v", name, err)
+		}
+		return manifest, nil
+	}
+	return manifest, nil
+}
+
+// seedFiles 列出种子目录根下的数据文件（不含清单），按文件名排序
+func seedFiles(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || isManifest(entry.Name()) {
+			continue
+		}
+		if _, err := datafile.FormatFromPath(entry.Name()); err != nil {
+			continue
+		}
+		files = append(files, entry.Name())
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func isManifest(name string) bool {
+	for _, manifest := range ManifestFiles {
+		if name == manifest {
+			return true
+		}
+	}
+	return false
+}
+
+// findSeedFile 查找表对应的种子文件
+func findSeedFile(files []string, table string) string {
+	for _, ext := range seedExtensions {
+		for _, file := range files {
+			name := strings.TrimSuffix(file, ".gz")
+			if name == table+ext {
+				return file
+			}
+		}
+	}
+	return ""
+}
+
+// validateStrategy 检查种子支持的插入策略
+func validateStrategy(strategy builder.DataInsertStrategy) error {
+	switch strategy {
+	case builder.StrategyInsertOnly, builder.StrategyInsertOrUpdate, builder.StrategyTruncateAndInsert,
+		builder.StrategyReplace, builder.StrategyIgnore:
+		return nil
+	}
+	return fmt.Errorf("不支持的种子策略: %s，支持: insert, upsert, truncate, replace, ignore", strategy)
+}
+
+// ParseStrategy 解析策略名称，merge/overwrite 作为 upsert/truncate 的别名（与 copy-data 一致）
+func ParseStrategy(name string) (builder.DataInsertStrategy, error) {
+	strategy := builder.DataInsertStrategy(strings.ToLower(name))
+	switch strategy {
+	case "merge":
+		strategy = builder.StrategyInsertOrUpdate
+	case "overwrite":
+		strategy = builder.StrategyTruncateAndInsert
+	}
+	return strategy, validateStrategy(strategy)
+}
+
+// Tables 返回种子涉及的表名
+func (s *Set) Tables() []string {
+	tables := make([]string, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		tables = append(tables, entry.Table)
+	}
+	return tables
+}
+
+// String 描述种子文件
+func (e Entry) String() string {
+	return fmt.Sprintf("%s <- %s [%s]", e.Table, path.Clean(e.File), e.Strategy)
+}
@@ -0,0 +1,34 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSetName(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(filepath.Dir(wd), "shared_seeds")
+
+	tests := []struct {
+		dir  string
+		root string
+		want string
+	}{
+		{"seeds", ".", "seeds"},
+		{"./seeds/", ".", "seeds"},
+		{filepath.Join(wd, "seeds"), ".", "seeds"},
+		{filepath.Join(wd, "seeds", "dev"), wd, "seeds/dev"},
+		{"seeds", "seeds", "."},
+		{"../shared_seeds", ".", filepath.ToSlash(outside)},
+		{outside, "", filepath.ToSlash(outside)},
+	}
+	for _, tt := range tests {
+		if got := DirSetName(tt.dir, tt.root); got != tt.want {
+			t.Errorf("DirSetName(%q, %q) = %q，期望 %q", tt.dir, tt.root, got, tt.want)
+		}
+	}
+}
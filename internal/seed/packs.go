@@ -0,0 +1,42 @@
+package seed
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// packsFS 内置数据包，每个子目录是一个种子目录
+//
+//go:embed packs
+var packsFS embed.FS
+
+// PackPrefix 内置数据包在导入记录中的名称前缀
+const PackPrefix = "pack:"
+
+// PackNames 返回内置数据包名称
+func PackNames() []string {
+	entries, _ := fs.ReadDir(packsFS, "packs")
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pack 加载内置数据包
+func Pack(name string) (*Set, error) {
+	fsys, err := fs.Sub(packsFS, "packs/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, "."); err != nil || strings.ContainsAny(name, "/\\") {
+		return nil, fmt.Errorf("未知的内置数据类型: %s，可用: %s", name, strings.Join(PackNames(), ", "))
+	}
+	return LoadSet(PackPrefix+name, fsys)
+}
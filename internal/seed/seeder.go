@@ -0,0 +1,245 @@
+package seed
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/builder"
+	"github.com/xiezhihuan/db-migrator/internal/datafile"
+	"github.com/xiezhihuan/db-migrator/internal/sqlparser"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// ApplyOptions 导入选项
+type ApplyOptions struct {
+	Environment string                     // 当前环境，声明了 environments 的种子只在匹配时导入
+	Tables      []string                   // 只导入这些表，为空时导入全部
+	Strategy    builder.DataInsertStrategy // 覆盖清单中的策略
+	BatchSize   int                        // 每批插入的行数，默认1000
+	MaxErrors   int                        // 每个表的错误行数超过该值时中止，0 表示不限制
+	Force       bool                       // 忽略导入记录，重新导入所有种子
+}
+
+// Result 单个种子文件的导入结果
+type Result struct {
+	Entry     Entry
+	Skipped   bool   // 因环境、表过滤或已导入而跳过
+	Reason    string // 跳过原因
+	Checksum  string
+	Reapplied bool // 内容变化后重新导入
+	Tables    []*datafile.ImportResult
+}
+
+// Failed 出错的行数
+func (r *Result) Failed() int64 {
+	var failed int64
+	for _, table := range r.Tables {
+		failed += table.Failed
+	}
+	return failed
+}
+
+// Seeder 将种子数据导入数据库
+type Seeder struct {
+	db      types.DB
+	history *History
+	onError func(Entry, datafile.RowError)
+}
+
+// NewSeeder 创建种子导入器
+func NewSeeder(db types.DB) *Seeder {
+	return &Seeder{db: db}
+}
+
+// SetHistory 设置导入记录，设置后每个种子文件在同一数据库中只导入一次
+func (s *Seeder) SetHistory(history *History) {
+	s.history = history
+}
+
+// SetErrorHandler 设置行错误处理函数
+func (s *Seeder) SetErrorHandler(handler func(Entry, datafile.RowError)) {
+	s.onError = handler
+}
+
+// Apply 按顺序导入种子，行错误记录在结果中，文件或表级错误中止导入
+//
+// 设置了导入记录时，已导入且内容未变化的种子跳过；内容变化的种子在可覆盖的策略下重新导入。
+// 有行错误的导入不写记录，下次执行时会重试。
+func (s *Seeder) Apply(ctx context.Context, set *Set, options ApplyOptions) ([]*Result, error) {
+	if s.history != nil {
+		if err := s.history.Ensure(); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []*Result
+	for _, entry := range set.Entries {
+		if options.Strategy != "" {
+			entry.Strategy = options.Strategy
+		}
+
+		result := &Result{Entry: entry}
+		results = append(results, result)
+
+		if len(options.Tables) > 0 && !containsFold(options.Tables, entry.Table) {
+			result.Skipped, result.Reason = true, "不在 --tables 中"
+			continue
+		}
+		if !entry.AppliesTo(options.Environment) {
+			result.Skipped = true
+			result.Reason = fmt.Sprintf("仅适用于环境 %s", strings.Join(entry.Environments, ", "))
+			continue
+		}
+
+		if s.history != nil {
+			checksum, err := Checksum(set.FS, entry.File)
+			if err != nil {
+				return results, fmt.Errorf("读取种子 %s 失败: %v", entry.File, err)
+			}
+			last, err := s.history.Last(set.Name, entry.File)
+			if err != nil {
+				return results, fmt.Errorf("读取种子记录失败: %v", err)
+			}
+
+			apply, reason := Decide(last, checksum, entry.Strategy, options.Force)
+			result.Checksum = checksum
+			result.Reapplied = apply && last != nil
+			if !apply {
+				result.Skipped, result.Reason = true, reason
+				continue
+			}
+		}
+
+		tables, err := s.applyEntry(ctx, set, entry, options)
+		result.Tables = tables
+		if err != nil {
+			return results, fmt.Errorf("导入种子 %s 失败: %v", entry.File, err)
+		}
+
+		if s.history != nil && result.Failed() == 0 {
+			record := HistoryRecord{SeedSet: set.Name, FilePath: entry.File, Checksum: result.Checksum, Strategy: entry.Strategy}
+			for _, table := range tables {
+				record.RowsRead += table.Rows
+				record.Imported += table.Imported
+			}
+			if err := s.history.Record(record); err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// applyEntry 导入一个种子文件
+func (s *Seeder) applyEntry(ctx context.Context, set *Set, entry Entry, options ApplyOptions) ([]*datafile.ImportResult, error) {
+	importConfig, err := ImportConfig(entry.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	importConfig.Table = entry.Table
+	importConfig.Format = entry.Format
+	importConfig.Columns = entry.Columns
+	importConfig.BatchSize = options.BatchSize
+	importConfig.MaxErrors = options.MaxErrors
+
+	importer := datafile.NewImporter(s.db, importConfig)
+	if s.onError != nil {
+		importer.SetErrorHandler(func(rowErr datafile.RowError) {
+			s.onError(entry, rowErr)
+		})
+	}
+
+	file, err := set.FS.Open(entry.File)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(strings.ToLower(entry.File), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("解压文件失败: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if entry.Format == datafile.FormatSQL {
+		return importSQL(ctx, importer, r)
+	}
+
+	results, err := importer.ImportAll(ctx, entry.Table, r, entry.Format)
+	for _, result := range results {
+		result.File = entry.File
+	}
+	return results, err
+}
+
+// importSQL 解析 INSERT 语句后按种子策略导入，语句中的表名优先
+func importSQL(ctx context.Context, importer *datafile.Importer, r io.Reader) ([]*datafile.ImportResult, error) {
+	statements, err := sqlparser.NewInsertParser().ParseInsert(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*datafile.ImportResult
+	for _, stmt := range statements {
+		if len(stmt.Columns) == 0 {
+			return results, fmt.Errorf("第%d行: 种子 SQL 的 INSERT 语句必须指定列名", stmt.LineNumber)
+		}
+
+		rows := make([]map[string]interface{}, 0, len(stmt.Values))
+		for _, values := range stmt.Values {
+			if len(values) != len(stmt.Columns) {
+				return results, fmt.Errorf("第%d行: 值的数量与列数不一致", stmt.LineNumber)
+			}
+			row := make(map[string]interface{}, len(values))
+			for i, col := range stmt.Columns {
+				row[col] = values[i]
+			}
+			rows = append(rows, row)
+		}
+
+		result, err := importer.ImportRows(ctx, stmt.TableName, rows)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// ImportConfig 将插入策略转换为数据文件导入配置
+func ImportConfig(strategy builder.DataInsertStrategy) (datafile.ImportConfig, error) {
+	var importConfig datafile.ImportConfig
+	switch strategy {
+	case builder.StrategyInsertOnly, builder.StrategyIgnore:
+		// 种子可以重复执行，已存在的行跳过
+		importConfig.OnConflict = datafile.ConflictIgnore
+	case builder.StrategyInsertOrUpdate:
+		importConfig.OnConflict = datafile.ConflictUpdate
+	case builder.StrategyReplace:
+		importConfig.OnConflict = datafile.ConflictReplace
+	case builder.StrategyTruncateAndInsert:
+		importConfig.OnConflict = datafile.ConflictError
+		importConfig.Truncate = true
+	default:
+		return importConfig, validateStrategy(strategy)
+	}
+	return importConfig, nil
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,9 @@
 package sqlparser
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -14,14 +13,12 @@ import (
 
 // InsertParser INSERT语句解析器实现
 type InsertParser struct {
-	delimiter string
 	variables map[string]interface{} // 存储MySQL变量
 }
 
 // NewInsertParser 创建新的INSERT解析器
 func NewInsertParser() *InsertParser {
 	return &InsertParser{
-		delimiter: ";",
 		variables: make(map[string]interface{}),
 	}
 }
@@ -34,394 +31,369 @@ func (p *InsertParser) ParseInsertFile(filePath string) ([]types.InsertStatement
 	}
 	defer file.Close()
 
-	var statements []types.InsertStatement
-	var currentStatement strings.Builder
-	var inMultiLineComment bool
-	var inString bool
-	var stringChar rune
-
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
+	return p.ParseInsert(file)
+}
 
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
+// ParseInsert 解析 r 中的INSERT语句，SET @变量 语句的值用于后续语句
+func (p *InsertParser) ParseInsert(r io.Reader) ([]types.InsertStatement, error) {
+	var statements []types.InsertStatement
 
-		// 处理分隔符设置
-		if strings.HasPrefix(strings.TrimSpace(strings.ToUpper(line)), "DELIMITER") {
-			p.delimiter = strings.TrimSpace(line[9:])
-			continue
+	reader := p.NewInsertReader(r, 0)
+	for {
+		stmt, err := reader.Next()
+		if err == io.EOF {
+			break
 		}
-
-		processedLine := p.processLine(line, &inMultiLineComment, &inString, &stringChar)
-		if processedLine == "" {
-			continue
+		if err != nil {
+			return nil, err
 		}
+		statements = append(statements, *stmt)
+	}
 
-		currentStatement.WriteString(processedLine)
-		currentStatement.WriteString(" ")
-
-		// 检查是否遇到分隔符
-		if p.isStatementEnd(processedLine, inString) {
-			statementText := strings.TrimSpace(currentStatement.String())
-			statementText = strings.TrimSuffix(statementText, p.delimiter)
-			statementText = strings.TrimSpace(statementText)
-
-			if statementText != "" {
-				// 尝试解析SET变量语句
-				if p.parseSetStatement(statementText) {
-					currentStatement.Reset()
-					continue
-				}
-
-				// 解析INSERT语句
-				stmt, err := p.parseInsertStatement(statementText, lineNumber)
-				if err != nil {
-					return nil, fmt.Errorf("第%d行解析错误: %v", lineNumber, err)
-				}
-				if stmt != nil {
-					statements = append(statements, *stmt)
-				}
-			}
+	return statements, nil
+}
 
-			currentStatement.Reset()
-		}
-	}
+// InsertReader 流式读取INSERT语句，每次返回同一语句中的一批行，内存占用与批大小相关
+type InsertReader struct {
+	parser     *InsertParser
+	scanner    *Scanner
+	batchSize  int
+	current    *types.InsertStatement // 正在读取值组的语句，Values 为空
+	statements int
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取文件错误: %v", err)
-	}
+// NewInsertReader 创建流式读取器，batchSize <= 0 时每条语句的所有行一次返回
+func (p *InsertParser) NewInsertReader(r io.Reader, batchSize int) *InsertReader {
+	scanner := NewScanner(r)
+	scanner.StreamValues()
+	return &InsertReader{parser: p, scanner: scanner, batchSize: batchSize}
+}
 
-	return statements, nil
+// Statements 已读取的INSERT语句数
+func (r *InsertReader) Statements() int {
+	return r.statements
 }
 
-// processLine 处理单行，移除注释（与DDL解析器相同的逻辑）
-func (p *InsertParser) processLine(line string, inMultiLineComment *bool, inString *bool, stringChar *rune) string {
-	var result strings.Builder
-	runes := []rune(line)
-
-	for i, r := range runes {
-		// 处理多行注释
-		if *inMultiLineComment {
-			if r == '*' && i+1 < len(runes) && runes[i+1] == '/' {
-				*inMultiLineComment = false
-				i++ // 跳过下一个字符
+// Next 返回下一批行，同一条语句的多批行共用语句头（表名、列名、行号），没有更多数据时返回 io.EOF
+func (r *InsertReader) Next() (*types.InsertStatement, error) {
+	for {
+		if r.current != nil {
+			batch, err := r.readRows()
+			if err != nil {
+				return nil, err
 			}
-			continue
+			if batch != nil {
+				return batch, nil
+			}
+			r.current = nil
 		}
 
-		// 处理字符串
-		if *inString {
-			result.WriteRune(r)
-			if r == *stringChar && (i == 0 || runes[i-1] != '\\') {
-				*inString = false
-			}
-			continue
+		statement, err := r.scanner.Next()
+		if err != nil {
+			return nil, err
 		}
 
-		// 检查字符串开始
-		if r == '\'' || r == '"' || r == '`' {
-			*inString = true
-			*stringChar = r
-			result.WriteRune(r)
+		// 尝试解析SET变量语句
+		if r.parser.parseSetStatement(statement) {
 			continue
 		}
 
-		// 检查单行注释
-		if r == '-' && i+1 < len(runes) && runes[i+1] == '-' {
-			break
+		// 只处理INSERT语句
+		if statement.Keyword(0) != "INSERT" {
+			continue // 忽略非INSERT语句
 		}
-
-		// 检查多行注释开始
-		if r == '/' && i+1 < len(runes) && runes[i+1] == '*' {
-			*inMultiLineComment = true
-			i++ // 跳过下一个字符
-			continue
+		if !statement.Streaming {
+			return nil, statement.Errorf("无法找到VALUES子句")
 		}
 
-		result.WriteRune(r)
+		header, err := r.parser.parseInsertHeader(statement)
+		if err != nil {
+			return nil, err
+		}
+		r.current = header
+		r.statements++
 	}
-
-	return strings.TrimSpace(result.String())
 }
 
-// isStatementEnd 检查是否是语句结束
-func (p *InsertParser) isStatementEnd(line string, inString bool) bool {
-	if inString {
-		return false
-	}
-	return strings.HasSuffix(strings.TrimSpace(line), p.delimiter)
-}
+// readRows 读取当前语句的下一批行，语句结束时返回 nil
+func (r *InsertReader) readRows() (*types.InsertStatement, error) {
+	batch := *r.current
+	batch.Values = nil
+	for r.batchSize <= 0 || len(batch.Values) < r.batchSize {
+		row, err := r.scanner.NextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
 
-// parseInsertStatement 解析单个INSERT语句
-func (p *InsertParser) parseInsertStatement(statement string, lineNumber int) (*types.InsertStatement, error) {
-	statement = strings.TrimSpace(statement)
-	if statement == "" {
-		return nil, nil
+		values, err := r.parser.parseRow(row)
+		if err != nil {
+			return nil, err
+		}
+		batch.Values = append(batch.Values, values)
 	}
 
-	upperStatement := strings.ToUpper(statement)
-
-	// 只处理INSERT语句
-	if !strings.HasPrefix(upperStatement, "INSERT") {
-		return nil, nil // 忽略非INSERT语句
+	if len(batch.Values) == 0 {
+		return nil, nil
 	}
-
-	return p.parseInsert(statement, lineNumber)
+	return &batch, nil
 }
 
-// parseInsert 解析INSERT语句
-func (p *InsertParser) parseInsert(statement string, lineNumber int) (*types.InsertStatement, error) {
-	// 匹配 INSERT INTO table_name (columns) VALUES (values)
+// parseInsertHeader 解析 INSERT ... VALUES 之前的部分
+func (p *InsertParser) parseInsertHeader(statement *Statement) (*types.InsertStatement, error) {
 	// 支持多种格式：
 	// 1. INSERT INTO table (col1, col2) VALUES (val1, val2)
 	// 2. INSERT INTO table VALUES (val1, val2)
 	// 3. INSERT IGNORE INTO table ...
 	// 4. INSERT INTO table (col1, col2) VALUES (val1, val2), (val3, val4)
+	c := newCursor(statement)
+	c.accept("INSERT")
+	for c.accept("LOW_PRIORITY") || c.accept("DELAYED") || c.accept("HIGH_PRIORITY") || c.accept("IGNORE") {
+	}
+	c.accept("INTO")
 
 	// 提取表名
-	tableName, err := p.extractTableName(statement)
-	if err != nil {
-		return nil, err
+	tableName, ok := c.name()
+	if !ok {
+		return nil, statement.Errorf("无法解析表名")
+	}
+	if c.accept("PARTITION") {
+		c.skipParens()
 	}
 
 	// 提取列名
-	columns, err := p.extractColumns(statement)
+	columns, err := p.extractColumns(c)
 	if err != nil {
 		return nil, err
 	}
 
-	// 提取值
-	values, err := p.extractValues(statement)
-	if err != nil {
-		return nil, err
+	if !c.accept("VALUES") && !c.accept("VALUE") {
+		return nil, statement.Errorf("无法找到VALUES子句")
 	}
 
 	return &types.InsertStatement{
 		TableName:  tableName,
 		Columns:    columns,
-		Values:     values,
-		Statement:  statement,
-		LineNumber: lineNumber,
+		Statement:  statement.Text,
+		LineNumber: statement.Pos.Line,
 	}, nil
 }
 
-// extractTableName 提取表名
-func (p *InsertParser) extractTableName(statement string) (string, error) {
-	// 匹配 INSERT [IGNORE] INTO table_name
-	re := regexp.MustCompile(`(?i)INSERT\s+(?:IGNORE\s+)?INTO\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 3 {
-		return "", fmt.Errorf("无法解析表名")
-	}
-
-	tableName := matches[2]
-	if matches[1] != "" {
-		tableName = matches[1] + "." + tableName
-	}
-
-	return tableName, nil
-}
-
-// extractColumns 提取列名
-func (p *InsertParser) extractColumns(statement string) ([]string, error) {
-	// 查找 (column1, column2, ...) 部分
-	re := regexp.MustCompile(`(?i)INTO\s+(?:\x60?\w+\x60?\.)?(?:\x60?\w+\x60?)\s*\(\s*([^)]+)\)\s+VALUES`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 2 {
-		// 没有指定列名，返回空列表
-		return []string{}, nil
+// extractColumns 提取列名，没有指定列名时返回空列表
+func (p *InsertParser) extractColumns(c *cursor) ([]string, error) {
+	columns := []string{}
+	if !c.acceptPunct("(") {
+		return columns, nil
 	}
 
-	columnsPart := strings.TrimSpace(matches[1])
-	if columnsPart == "" {
-		return []string{}, nil
-	}
+	for !c.acceptPunct(")") {
+		tok := c.peek()
+		if !tok.IsIdent() {
+			return nil, p.errorAt(c, "无法解析列名")
+		}
+		c.pos++
+		columns = append(columns, tok.Value)
 
-	// 分割列名
-	var columns []string
-	for _, col := range strings.Split(columnsPart, ",") {
-		col = strings.TrimSpace(col)
-		col = strings.Trim(col, "`") // 移除反引号
-		if col != "" {
-			columns = append(columns, col)
+		if !c.acceptPunct(",") && !c.peek().IsPunct(")") {
+			return nil, p.errorAt(c, "列名之间缺少逗号")
 		}
 	}
 
 	return columns, nil
 }
 
-// extractValues 提取值
-func (p *InsertParser) extractValues(statement string) ([][]interface{}, error) {
-	// 查找 VALUES 后的部分
-	re := regexp.MustCompile(`(?i)VALUES\s+(.+)$`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("无法找到VALUES子句")
+// parseRow 解析一个值组（括号内的词法单元）
+func (p *InsertParser) parseRow(row *Statement) ([]interface{}, error) {
+	values := []interface{}{}
+	if len(row.Tokens) == 0 {
+		return values, nil
 	}
 
-	valuesPart := strings.TrimSpace(matches[1])
-	return p.parseValueGroups(valuesPart)
+	c := newCursor(row)
+	for _, part := range splitTopLevel(c) {
+		value, err := p.parseValue(row, part[0], part[1])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	// 末尾的逗号表示缺少最后一个值
+	if row.Tokens[len(row.Tokens)-1].IsPunct(",") {
+		_, err := p.parseValue(row, len(row.Tokens), len(row.Tokens))
+		return nil, err
+	}
+	return values, nil
 }
 
-// parseValueGroups 解析值组
-func (p *InsertParser) parseValueGroups(valuesPart string) ([][]interface{}, error) {
-	var result [][]interface{}
-	var current []interface{}
-	var currentValue strings.Builder
-	var inString bool
-	var stringChar rune
-	var parenDepth int
-	var inValueGroup bool
-
-	runes := []rune(valuesPart)
-
-	for i, r := range runes {
-		if inString {
-			currentValue.WriteRune(r)
-			if r == stringChar && (i == 0 || runes[i-1] != '\\') {
-				inString = false
-			}
-			continue
+// parseValue 解析单个值，tokens[from, to) 为值的词法单元
+//
+// 字面量按MySQL规则转换：字符串处理转义，十进制小数保留原文（DECIMAL 精确值），_binary 为 []byte；
+// 0x41、X'41'、0b01、b'01' 在数值列中是整数、在字符串列中是字节串，与函数调用和表达式一样
+// 返回 types.RawSQL 原样写入SQL，由数据库按目标列类型解释。
+func (p *InsertParser) parseValue(statement *Statement, from, to int) (interface{}, error) {
+	tokens := statement.Tokens[from:to]
+	if len(tokens) == 0 {
+		pos := statement.Pos
+		if from < len(statement.Tokens) {
+			pos = statement.Tokens[from].Pos
 		}
+		return nil, fmt.Errorf("%s: 空值", pos)
+	}
 
-		switch r {
-		case '\'', '"':
-			inString = true
-			stringChar = r
-			currentValue.WriteRune(r)
-
-		case '(':
-			if !inValueGroup {
-				inValueGroup = true
-				parenDepth = 1
-			} else {
-				parenDepth++
-				currentValue.WriteRune(r)
-			}
+	tok := tokens[0]
+	if len(tokens) == 1 {
+		switch tok.Type {
+		case TokenString:
+			// 字符串值（带引号）
+			return tok.Value, nil
 
-		case ')':
-			if inValueGroup {
-				parenDepth--
-				if parenDepth == 0 {
-					// 处理最后一个值
-					if currentValue.Len() > 0 {
-						value, err := p.parseValue(strings.TrimSpace(currentValue.String()))
-						if err != nil {
-							return nil, err
-						}
-						current = append(current, value)
-						currentValue.Reset()
-					}
-
-					result = append(result, current)
-					current = []interface{}{}
-					inValueGroup = false
-				} else {
-					currentValue.WriteRune(r)
+		case TokenNumber:
+			if value, ok := parseNumber(tok.Text); ok {
+				if _, isBytes := value.([]byte); isBytes {
+					return types.RawSQL(tok.Text), nil
 				}
+				return value, nil
 			}
 
-		case ',':
-			if inValueGroup && parenDepth == 1 {
-				// 值分隔符
-				value, err := p.parseValue(strings.TrimSpace(currentValue.String()))
-				if err != nil {
-					return nil, err
-				}
-				current = append(current, value)
-				currentValue.Reset()
-			} else if !inValueGroup {
-				// 值组分隔符，忽略
-			} else {
-				currentValue.WriteRune(r)
+		case TokenWord:
+			switch strings.ToUpper(tok.Text) {
+			case "NULL":
+				return nil, nil
+			case "TRUE":
+				return true, nil
+			case "FALSE":
+				return false, nil
 			}
 
-		default:
-			if inValueGroup {
-				currentValue.WriteRune(r)
+		case TokenVariable:
+			// 处理MySQL变量 @variableName
+			if value, exists := p.variables[strings.ToLower(tok.Value)]; exists {
+				return value, nil
 			}
+			return nil, fmt.Errorf("%s: 未定义的变量: %s", tok.Pos, tok.Text)
 		}
 	}
 
-	return result, nil
-}
-
-// parseValue 解析单个值
-func (p *InsertParser) parseValue(valueStr string) (interface{}, error) {
-	valueStr = strings.TrimSpace(valueStr)
-
-	if valueStr == "" {
-		return nil, fmt.Errorf("空值")
-	}
-
-	// NULL值
-	if strings.ToUpper(valueStr) == "NULL" {
-		return nil, nil
-	}
-
-	// 处理MySQL变量 @variableName
-	if strings.HasPrefix(valueStr, "@") {
-		varName := strings.ToLower(valueStr[1:])
-		if value, exists := p.variables[varName]; exists {
+	// 带符号的数字
+	if len(tokens) == 2 && (tok.IsPunct("-") || tok.IsPunct("+")) && tokens[1].Type == TokenNumber {
+		if value, ok := parseNumber(tok.Text + tokens[1].Text); ok {
 			return value, nil
 		}
-		return nil, fmt.Errorf("未定义的变量: %s", valueStr)
 	}
 
-	// 字符串值（带引号）
-	if (strings.HasPrefix(valueStr, "'") && strings.HasSuffix(valueStr, "'")) ||
-		(strings.HasPrefix(valueStr, "\"") && strings.HasSuffix(valueStr, "\"")) {
-		return valueStr[1 : len(valueStr)-1], nil
+	// X'41'、B'01'、N'abc'、_binary'abc'、_utf8mb4 0x41 等带前缀的字面量
+	if value, ok, err := parseIntroducedLiteral(tokens); ok || err != nil {
+		return value, err
 	}
 
-	// 数字值
-	if intVal, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
-		return intVal, nil
+	// 相邻的字符串字面量连接为一个字符串：'a' 'b' = 'ab'
+	if joined, ok := joinStrings(tokens); ok {
+		return joined, nil
 	}
 
-	if floatVal, err := strconv.ParseFloat(valueStr, 64); err == nil {
-		return floatVal, nil
+	// 其他情况（函数、表达式）原样写入SQL，词法分析保证片段不会越过值组的边界
+	return types.RawSQL(statement.Slice(from, to)), nil
+}
+
+// parseNumber 解析数字字面量：整数为 int64（超出范围时为 uint64），
+// 科学计数法为 float64，十进制小数保留原文，0x/0b 为 []byte（字符串上下文中的值）
+func parseNumber(text string) (interface{}, bool) {
+	unsigned := strings.TrimPrefix(text, "+")
+	if len(unsigned) > 2 && unsigned[0] == '0' {
+		switch unsigned[1] {
+		case 'x', 'X':
+			data, err := decodeHex(unsigned[2:])
+			return data, err == nil
+		case 'b', 'B':
+			data, err := decodeBits(unsigned[2:])
+			return data, err == nil
+		}
 	}
 
-	// 布尔值
-	if strings.ToUpper(valueStr) == "TRUE" {
-		return true, nil
+	if intVal, err := strconv.ParseInt(unsigned, 10, 64); err == nil {
+		return intVal, true
 	}
-	if strings.ToUpper(valueStr) == "FALSE" {
-		return false, nil
+	if uintVal, err := strconv.ParseUint(unsigned, 10, 64); err == nil {
+		return uintVal, true
 	}
+	if strings.ContainsAny(unsigned, "eE") {
+		if floatVal, err := strconv.ParseFloat(unsigned, 64); err == nil {
+			return floatVal, true
+		}
+		return nil, false
+	}
+	if decimalPattern.MatchString(unsigned) {
+		return unsigned, true
+	}
+	return nil, false
+}
 
-	// 其他情况作为字符串处理
-	return valueStr, nil
+// errorAt 生成当前位置的错误
+func (p *InsertParser) errorAt(c *cursor, message string) error {
+	pos := c.stmt.Pos
+	if !c.done() {
+		pos = c.peek().Pos
+	}
+	return fmt.Errorf("%s: %s", pos, message)
 }
 
-// parseSetStatement 解析SET变量语句
-func (p *InsertParser) parseSetStatement(statement string) bool {
-	statement = strings.TrimSpace(statement)
+// parseSetStatement 解析 SET @var = value[, @var2 = value2] 语句
+func (p *InsertParser) parseSetStatement(statement *Statement) bool {
+	c := newCursor(statement)
+	if !c.accept("SET") {
+		return false
+	}
+
+	type assignment struct {
+		name     string
+		from, to int
+	}
+	var assignments []assignment
 
-	// 匹配 SET @variable = value 格式
-	setRegex := regexp.MustCompile(`(?i)^SET\s+@(\w+)\s*=\s*(.+)$`)
-	matches := setRegex.FindStringSubmatch(statement)
+	for !c.done() {
+		tok := c.peek()
+		if tok.Type != TokenVariable || strings.HasPrefix(tok.Text, "@@") {
+			return false
+		}
+		c.pos++
+		if !c.acceptPunct("=") && !c.acceptPunct(":=") {
+			return false
+		}
 
-	if len(matches) == 3 {
-		varName := strings.ToLower(matches[1])
-		valueExpr := strings.TrimSpace(matches[2])
+		// 表达式到下一个顶层逗号为止
+		from, depth := c.pos, 0
+		for ; !c.done(); c.pos++ {
+			t := c.peek()
+			if t.IsPunct("(") {
+				depth++
+			} else if t.IsPunct(")") {
+				depth--
+			} else if t.IsPunct(",") && depth == 0 {
+				break
+			}
+		}
+		assignments = append(assignments, assignment{name: strings.ToLower(tok.Value), from: from, to: c.pos})
+		c.acceptPunct(",")
+	}
 
+	for _, a := range assignments {
 		// 计算表达式值
-		value := p.evaluateExpression(valueExpr)
-		p.variables[varName] = value
-
-		return true
+		value, err := p.parseValue(statement, a.from, a.to)
+		if err != nil {
+			value = types.RawSQL(statement.Slice(a.from, a.to))
+		}
+		if raw, ok := value.(types.RawSQL); ok {
+			value = p.evaluateExpression(string(raw))
+		}
+		p.variables[a.name] = value
 	}
 
-	return false
+	return len(assignments) > 0
 }
 
-// evaluateExpression 计算表达式值
+// evaluateExpression 计算表达式值，无法在本地计算的表达式在每次使用时原样写入SQL
 func (p *InsertParser) evaluateExpression(expr string) interface{} {
 	expr = strings.TrimSpace(expr)
 	upperExpr := strings.ToUpper(expr)
@@ -436,23 +408,7 @@ func (p *InsertParser) evaluateExpression(expr string) interface{} {
 		return time.Now().Format("2006-01-02 15:04:05")
 	}
 
-	// 处理数字
-	if num, err := strconv.ParseInt(expr, 10, 64); err == nil {
-		return num
-	}
-
-	if num, err := strconv.ParseFloat(expr, 64); err == nil {
-		return num
-	}
-
-	// 处理字符串（去掉引号）
-	if (strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'")) ||
-		(strings.HasPrefix(expr, "\"") && strings.HasSuffix(expr, "\"")) {
-		return expr[1 : len(expr)-1]
-	}
-
-	// 默认返回字符串
-	return expr
+	return types.RawSQL(expr)
 }
 
 // GetVariables 获取解析到的变量
@@ -0,0 +1,166 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xiezhihuan/db-migrator/internal/dialect"
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+const sampleDataFile = "../../examples/sql_data/sample_data.sql"
+
+func TestParseSampleData(t *testing.T) {
+	statements, err := NewInsertParser().ParseInsertFile(sampleDataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRows := []struct {
+		table string
+		rows  int
+	}{
+		{"categories", 7}, {"products", 6}, {"users", 4}, {"user_addresses", 4},
+		{"orders", 3}, {"order_items", 5}, {"cart_items", 5}, {"settings", 10},
+		{"products", 10}, {"settings", 1}, {"users", 2}, {"settings", 3},
+	}
+	if len(statements) != len(wantRows) {
+		t.Fatalf("解析出 %d 条语句，期望 %d 条", len(statements), len(wantRows))
+	}
+	for i, want := range wantRows {
+		if statements[i].TableName != want.table || len(statements[i].Values) != want.rows {
+			t.Errorf("第%d条语句为 %s (%d 行)，期望 %s (%d 行)", i+1,
+				statements[i].TableName, len(statements[i].Values), want.table, want.rows)
+		}
+	}
+
+	values := []struct {
+		stmt, row, col int
+		want           interface{}
+	}{
+		{0, 0, 2, "电子产品"},
+		{1, 0, 0, int64(1)},
+		{1, 0, 5, "8999.00"},
+		{10, 0, 3, nil},
+		{10, 1, 2, "password'with'quote"},
+		{11, 0, 1, `{"name": "test", "value": true}`},
+		{11, 1, 1, `C:\Program Files\App`},
+		{11, 2, 1, `He said "Hello World!"`},
+	}
+	for _, v := range values {
+		if got := statements[v.stmt].Values[v.row][v.col]; got != v.want {
+			t.Errorf("第%d条语句第%d行第%d列 = %#v，期望 %#v", v.stmt+1, v.row+1, v.col+1, got, v.want)
+		}
+	}
+}
+
+func TestInsertRoundTrip(t *testing.T) {
+	statements, err := NewInsertParser().ParseInsertFile(sampleDataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	for _, stmt := range statements {
+		sb.WriteString(formatInsert(stmt))
+		sb.WriteString(";\n")
+	}
+	reparsed, err := NewInsertParser().ParseInsert(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("重新解析失败: %v\n%s", err, sb.String())
+	}
+
+	if len(reparsed) != len(statements) {
+		t.Fatalf("重新解析出 %d 条语句，期望 %d 条", len(reparsed), len(statements))
+	}
+	for i := range statements {
+		if reparsed[i].TableName != statements[i].TableName ||
+			!reflect.DeepEqual(reparsed[i].Columns, statements[i].Columns) ||
+			!reflect.DeepEqual(reparsed[i].Values, statements[i].Values) {
+			t.Errorf("第%d条语句往返后不一致:\n%#v\n%#v", i+1, statements[i], reparsed[i])
+		}
+	}
+}
+
+// formatInsert 将解析结果重新生成为 INSERT 语句
+func formatInsert(stmt types.InsertStatement) string {
+	rows := make([]string, len(stmt.Values))
+	for i, row := range stmt.Values {
+		values := make([]string, len(row))
+		for j, value := range row {
+			switch v := value.(type) {
+			case nil:
+				values[j] = "NULL"
+			case string:
+				// DECIMAL 解析为字符串，加引号后解析结果相同
+				values[j] = dialect.MySQL.QuoteString(v)
+			case []byte:
+				values[j] = fmt.Sprintf("_binary X'%x'", v)
+			case types.RawSQL:
+				values[j] = string(v)
+			default:
+				values[j] = fmt.Sprintf("%v", v)
+			}
+		}
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		dialect.MySQL.QuoteIdent(stmt.TableName), dialect.MySQL.QuoteIdents(stmt.Columns), strings.Join(rows, ", "))
+}
+
+func TestParseLiteralValues(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want interface{}
+	}{
+		{"42", int64(42)},
+		{"-42", int64(-42)},
+		{"18446744073709551615", uint64(18446744073709551615)},
+		{"1.50", "1.50"},
+		{"1e3", 1000.0},
+		{"'a\\nb'", "a\nb"},
+		{"'a' 'b'", "ab"},
+		{"N'中文'", "中文"},
+		{"TRUE", true},
+		{"NULL", nil},
+		{"0x41", types.RawSQL("0x41")},
+		{"0b1000001", types.RawSQL("0b1000001")},
+		{"X'41'", types.RawSQL("X'41'")},
+		{"b'01'", types.RawSQL("b'01'")},
+		{"_binary 0x41", []byte("A")},
+		{"_binary'A'", []byte("A")},
+		{"_utf8mb4 0x41", "A"},
+		{"NOW()", types.RawSQL("NOW()")},
+		{"1 + 2", types.RawSQL("1 + 2")},
+	}
+	for _, tt := range tests {
+		statements, err := NewInsertParser().ParseInsert(strings.NewReader("INSERT INTO t (c) VALUES (" + tt.sql + ")"))
+		if err != nil {
+			t.Errorf("%s: 解析失败: %v", tt.sql, err)
+			continue
+		}
+		if got := statements[0].Values[0][0]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %#v，期望 %#v", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalidLiterals(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"X'4G'", "无效的十六进制字面量 X'4G'"},
+		{"b'012'", "无效的位值字面量 b'012'"},
+		{"@missing", "未定义的变量: @missing"},
+		{"1,", "空值"},
+	}
+	for _, tt := range tests {
+		_, err := NewInsertParser().ParseInsert(strings.NewReader("INSERT INTO t VALUES (" + tt.sql + ")"))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s 的错误为 %v，期望包含 %q", tt.sql, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package sqlparser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TokenType 词法单元类型
+type TokenType int
+
+const (
+	TokenEOF         TokenType = iota
+	TokenSpace                 // 空白
+	TokenComment               // -- 、# 和 /* */ 注释
+	TokenWord                  // 关键字或未加引号的标识符
+	TokenQuotedIdent           // `反引号标识符`
+	TokenString                // '字符串' 或 "字符串"
+	TokenNumber                // 数字，包括 0x/0b 前缀
+	TokenVariable              // @var、@'var'、@@session.var
+	TokenPunct                 // 运算符和标点
+	TokenDelimiter             // 当前语句分隔符
+	TokenCondOpen              // 条件注释开始 /*!40101
+	TokenCondClose             // 条件注释结束 */
+)
+
+// Position 源文件中的位置，行和列从1开始，列按字符计算
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("第%d行第%d列", p.Line, p.Column)
+}
+
+// Token 词法单元
+type Token struct {
+	Type   TokenType
+	Text   string // 原始文本
+	Value  string // 字符串和标识符去掉引号、处理转义后的值，变量为去掉 @ 的名称，其他与 Text 相同
+	Pos    Position
+	Offset int // 在所属语句文本中的字节偏移
+}
+
+// IsKeyword 判断是否为指定关键字（不区分大小写）
+func (t Token) IsKeyword(keyword string) bool {
+	return t.Type == TokenWord && strings.EqualFold(t.Text, keyword)
+}
+
+// IsIdent 判断是否可以作为标识符
+func (t Token) IsIdent() bool {
+	return t.Type == TokenWord || t.Type == TokenQuotedIdent
+}
+
+// IsPunct 判断是否为指定标点
+func (t Token) IsPunct(punct string) bool {
+	return t.Type == TokenPunct && t.Text == punct
+}
+
+// multiCharOperators 多字符运算符，长的在前
+var multiCharOperators = []string{"<=>", "->>", "<=", ">=", "<>", "!=", ":=", "||", "&&", "<<", ">>", "->"}
+
+// Lexer MySQL 词法分析器，按 mysql 客户端的规则识别字符串、标识符、注释、条件注释和语句分隔符
+type Lexer struct {
+	r         *bufio.Reader
+	delimiter string
+	line      int
+	column    int
+	condDepth int
+	raw       strings.Builder
+}
+
+// NewLexer 创建词法分析器，默认分隔符为 ;
+func NewLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		r:         bufio.NewReader(r),
+		delimiter: ";",
+		line:      1,
+		column:    1,
+	}
+}
+
+// Delimiter 当前分隔符
+func (l *Lexer) Delimiter() string {
+	return l.delimiter
+}
+
+// SetDelimiter 修改分隔符（DELIMITER 命令）
+func (l *Lexer) SetDelimiter(delimiter string) {
+	l.delimiter = delimiter
+}
+
+// Pos 当前位置
+func (l *Lexer) Pos() Position {
+	return Position{Line: l.line, Column: l.column}
+}
+
+// Next 读取下一个词法单元，输入结束时返回 TokenEOF
+func (l *Lexer) Next() (Token, error) {
+	l.raw.Reset()
+	pos := l.Pos()
+	token := func(tokenType TokenType) (Token, error) {
+		text := l.raw.String()
+		return Token{Type: tokenType, Text: text, Value: text, Pos: pos}, nil
+	}
+
+	c, ok := l.peekByte(0)
+	if !ok {
+		return Token{Type: TokenEOF, Pos: pos}, l.readErr()
+	}
+
+	if l.atDelimiter() {
+		l.skip(len(l.delimiter))
+		return token(TokenDelimiter)
+	}
+
+	next, _ := l.peekByte(1)
+	switch {
+	case isSpace(c):
+		for {
+			c, ok := l.peekByte(0)
+			if !ok || !isSpace(c) || l.atDelimiter() {
+				break
+			}
+			l.skip(1)
+		}
+		return token(TokenSpace)
+
+	case c == '#' || c == '-' && next == '-' && l.dashCommentStart():
+		l.skipLine()
+		return token(TokenComment)
+
+	case c == '/' && next == '*':
+		if third, _ := l.peekByte(2); third == '!' {
+			l.skip(3)
+			for {
+				c, ok := l.peekByte(0)
+				if !ok || !isDigit(c) {
+					break
+				}
+				l.skip(1)
+			}
+			l.condDepth++
+			return token(TokenCondOpen)
+		}
+		l.skip(2)
+		for {
+			c, ok := l.peekByte(0)
+			if !ok {
+				if err := l.readErr(); err != nil {
+					return Token{}, err
+				}
+				return Token{}, fmt.Errorf("%s: 注释未结束", pos)
+			}
+			l.skip(1)
+			if c == '*' {
+				if c, _ := l.peekByte(0); c == '/' {
+					l.skip(1)
+					return token(TokenComment)
+				}
+			}
+		}
+
+	case c == '*' && next == '/' && l.condDepth > 0:
+		l.skip(2)
+		l.condDepth--
+		return token(TokenCondClose)
+
+	case c == '\'' || c == '"':
+		value, err := l.readQuoted(c, true, pos)
+		if err != nil {
+			return Token{}, err
+		}
+		return Token{Type: TokenString, Text: l.raw.String(), Value: value, Pos: pos}, nil
+
+	case c == '`':
+		value, err := l.readQuoted(c, false, pos)
+		if err != nil {
+			return Token{}, err
+		}
+		return Token{Type: TokenQuotedIdent, Text: l.raw.String(), Value: value, Pos: pos}, nil
+
+	case c == '@':
+		return l.readVariable(pos)
+
+	case isDigit(c) || c == '.' && isDigit(next):
+		l.readNumber()
+		// 以数字开头的标识符，如 2fa_codes
+		if c, ok := l.peekByte(0); ok && isWordByte(c) && !l.atDelimiter() && !strings.Contains(l.raw.String(), ".") {
+			l.readWord(false)
+			return token(TokenWord)
+		}
+		return token(TokenNumber)
+
+	case isWordByte(c):
+		l.readWord(false)
+		return token(TokenWord)
+	}
+
+	for _, op := range multiCharOperators {
+		if l.hasPrefix(op) {
+			l.skip(len(op))
+			return token(TokenPunct)
+		}
+	}
+	l.skip(1)
+	return token(TokenPunct)
+}
+
+// ReadLine 读取到行尾的原始文本（不含换行符），用于 DELIMITER 命令
+func (l *Lexer) ReadLine() (string, error) {
+	l.raw.Reset()
+	l.skipLine()
+	return l.raw.String(), l.readErr()
+}
+
+// readQuoted 读取引号包围的字符串或标识符，引号重复表示引号本身；escapes 为 true 时处理反斜杠转义
+func (l *Lexer) readQuoted(quote byte, escapes bool, pos Position) (string, error) {
+	var value strings.Builder
+	l.skip(1)
+	for {
+		c, ok := l.peekByte(0)
+		if !ok {
+			if err := l.readErr(); err != nil {
+				return "", err
+			}
+			if quote == '`' {
+				return "", fmt.Errorf("%s: 标识符未结束", pos)
+			}
+			return "", fmt.Errorf("%s: 字符串未结束", pos)
+		}
+		l.skip(1)
+
+		switch {
+		case c == quote:
+			if next, _ := l.peekByte(0); next == quote {
+				l.skip(1)
+				value.WriteByte(quote)
+				continue
+			}
+			return value.String(), nil
+
+		case c == '\\' && escapes:
+			escaped, ok := l.peekByte(0)
+			if !ok {
+				continue
+			}
+			l.skip(1)
+			value.WriteString(unescape(escaped))
+
+		default:
+			value.WriteByte(c)
+		}
+	}
+}
+
+// unescape MySQL 字符串转义，\% 和 \_ 保留反斜杠（用于 LIKE）
+func unescape(c byte) string {
+	switch c {
+	case '0':
+		return "\x00"
+	case 'b':
+		return "\b"
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case 'Z':
+		return "\x1a"
+	case '%', '_':
+		return "\\" + string(c)
+	}
+	return string(c)
+}
+
+// readVariable 读取 @var、@'var'、@`var` 和 @@[global.|session.]var
+func (l *Lexer) readVariable(pos Position) (Token, error) {
+	l.skip(1)
+	system := false
+	if c, _ := l.peekByte(0); c == '@' {
+		l.skip(1)
+		system = true
+	}
+
+	var name string
+	if c, _ := l.peekByte(0); !system && (c == '\'' || c == '"' || c == '`') {
+		value, err := l.readQuoted(c, c != '`', pos)
+		if err != nil {
+			return Token{}, err
+		}
+		name = value
+	} else {
+		l.readWord(system)
+		name = strings.TrimLeft(l.raw.String(), "@")
+	}
+	return Token{Type: TokenVariable, Text: l.raw.String(), Value: name, Pos: pos}, nil
+}
+
+// readNumber 读取整数、小数、科学计数法和 0x/0b 字面量
+func (l *Lexer) readNumber() {
+	if l.hasPrefix("0x") || l.hasPrefix("0X") || l.hasPrefix("0b") || l.hasPrefix("0B") {
+		l.skip(2)
+		l.readWord(false)
+		return
+	}
+
+	for {
+		c, ok := l.peekByte(0)
+		if !ok || l.atDelimiter() {
+			return
+		}
+		switch {
+		case isDigit(c) || c == '.':
+			l.skip(1)
+		case c == 'e' || c == 'E':
+			next, _ := l.peekByte(1)
+			if isDigit(next) {
+				l.skip(1)
+			} else if sign, _ := l.peekByte(2); (next == '+' || next == '-') && isDigit(sign) {
+				l.skip(2)
+			} else {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readWord 读取标识符字符，dotted 为 true 时允许 . （系统变量）
+func (l *Lexer) readWord(dotted bool) {
+	for {
+		c, ok := l.peekByte(0)
+		if !ok || l.atDelimiter() || !(isWordByte(c) || isDigit(c) || dotted && c == '.') {
+			return
+		}
+		l.skip(1)
+	}
+}
+
+// dashCommentStart -- 后面必须是空白或行尾才是注释
+func (l *Lexer) dashCommentStart() bool {
+	c, ok := l.peekByte(2)
+	return !ok || isSpace(c)
+}
+
+// skipLine 跳过到行尾（不含换行符）
+func (l *Lexer) skipLine() {
+	for {
+		c, ok := l.peekByte(0)
+		if !ok || c == '\n' {
+			return
+		}
+		l.skip(1)
+	}
+}
+
+func (l *Lexer) atDelimiter() bool {
+	return l.delimiter != "" && l.hasPrefix(l.delimiter)
+}
+
+func (l *Lexer) hasPrefix(prefix string) bool {
+	data, _ := l.r.Peek(len(prefix))
+	return string(data) == prefix
+}
+
+func (l *Lexer) peekByte(i int) (byte, bool) {
+	data, _ := l.r.Peek(i + 1)
+	if len(data) <= i {
+		return 0, false
+	}
+	return data[i], true
+}
+
+// skip 读取 n 个字节并更新位置，UTF-8 后续字节不计列
+func (l *Lexer) skip(n int) {
+	for i := 0; i < n; i++ {
+		c, err := l.r.ReadByte()
+		if err != nil {
+			return
+		}
+		l.raw.WriteByte(c)
+		switch {
+		case c == '\n':
+			l.line++
+			l.column = 1
+		case c&0xC0 != 0x80:
+			l.column++
+		}
+	}
+}
+
+// readErr 返回读取时遇到的非 EOF 错误
+func (l *Lexer) readErr() error {
+	if _, err := l.r.Peek(1); err != nil && err != io.EOF {
+		return fmt.Errorf("读取文件错误: %v", err)
+	}
+	return nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordByte 标识符字符，非 ASCII 字符都视为标识符的一部分
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c >= 0x80
+}
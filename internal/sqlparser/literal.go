@@ -0,0 +1,106 @@
+package sqlparser
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// decimalPattern 十进制整数或小数
+var decimalPattern = regexp.MustCompile(`^-?(\d+\.?\d*|\.\d+)$`)
+
+// parseIntroducedLiteral 解析带前缀的字面量，ok 为 false 表示不是这种形式
+//
+//	X'4142' / x'4142'     十六进制字面量，校验后按 types.RawSQL 原样写入，由数据库按列类型解释
+//	B'0101' / b'0101'     位值字面量，同上
+//	N'abc'                国家字符集字符串
+//	_binary 'abc'         二进制字符串，_binary 0x41 同样为 []byte
+//	_utf8mb4 'abc'        其他字符集前缀按字符串处理
+func parseIntroducedLiteral(tokens []Token) (value interface{}, ok bool, err error) {
+	if len(tokens) != 2 || tokens[0].Type != TokenWord {
+		return nil, false, nil
+	}
+	prefix, literal := tokens[0], tokens[1]
+
+	if literal.Type == TokenString {
+		switch strings.ToUpper(prefix.Text) {
+		case "X":
+			if _, err := decodeHex(literal.Value); err != nil {
+				return nil, true, fmt.Errorf("%s: 无效的十六进制字面量 %s%s", prefix.Pos, prefix.Text, literal.Text)
+			}
+			return types.RawSQL(prefix.Text + literal.Text), true, nil
+		case "B":
+			if _, err := decodeBits(literal.Value); err != nil {
+				return nil, true, fmt.Errorf("%s: 无效的位值字面量 %s%s", prefix.Pos, prefix.Text, literal.Text)
+			}
+			return types.RawSQL(prefix.Text + literal.Text), true, nil
+		case "N":
+			return literal.Value, true, nil
+		}
+	}
+
+	if !strings.HasPrefix(prefix.Text, "_") {
+		return nil, false, nil
+	}
+	binary := strings.EqualFold(prefix.Text, "_binary")
+	switch literal.Type {
+	case TokenString:
+		if binary {
+			return []byte(literal.Value), true, nil
+		}
+		return literal.Value, true, nil
+	case TokenNumber:
+		data, ok := parseNumber(literal.Text)
+		bytes, isBytes := data.([]byte)
+		if !ok || !isBytes {
+			return nil, false, nil
+		}
+		if binary {
+			return bytes, true, nil
+		}
+		return string(bytes), true, nil
+	}
+	return nil, false, nil
+}
+
+// joinStrings 相邻的字符串字面量按MySQL规则连接
+func joinStrings(tokens []Token) (string, bool) {
+	var b strings.Builder
+	for _, tok := range tokens {
+		if tok.Type != TokenString {
+			return "", false
+		}
+		b.WriteString(tok.Value)
+	}
+	return b.String(), true
+}
+
+// decodeHex 解码十六进制数字，奇数位时左侧补0
+func decodeHex(digits string) ([]byte, error) {
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	return hex.DecodeString(digits)
+}
+
+// decodeBits 解码二进制位，按字节右对齐，左侧补0
+func decodeBits(digits string) ([]byte, error) {
+	if digits == "" {
+		return []byte{}, nil
+	}
+	data := make([]byte, (len(digits)+7)/8)
+	for i := 0; i < len(digits); i++ {
+		bit := len(digits) - 1 - i // 从最低位开始的位置
+		switch digits[i] {
+		case '1':
+			data[len(data)-1-bit/8] |= 1 << (bit % 8)
+		case '0':
+		default:
+			return nil, fmt.Errorf("无效的二进制位: %c", digits[i])
+		}
+	}
+	return data, nil
+}
@@ -1,26 +1,21 @@
 package sqlparser
 
 import (
-	"bufio"
+	"container/heap"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/xiezhihuan/db-migrator/internal/types"
 )
 
 // Parser SQL解析器实现
-type Parser struct {
-	delimiter string
-}
+type Parser struct{}
 
 // NewParser 创建新的SQL解析器
 func NewParser() *Parser {
-	return &Parser{
-		delimiter: ";",
-	}
+	return &Parser{}
 }
 
 // ParseFile 解析SQL文件
@@ -31,311 +26,236 @@ func (p *Parser) ParseFile(filePath string) ([]types.SQLStatement, error) {
 	}
 	defer file.Close()
 
-	var statements []types.SQLStatement
-	var currentStatement strings.Builder
-	var inMultiLineComment bool
-	var inString bool
-	var stringChar rune
-
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
-
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-
-		// 处理分隔符设置
-		if strings.HasPrefix(strings.TrimSpace(strings.ToUpper(line)), "DELIMITER") {
-			p.delimiter = strings.TrimSpace(line[9:])
-			continue
-		}
-
-		processedLine := p.processLine(line, &inMultiLineComment, &inString, &stringChar)
-		if processedLine == "" {
-			continue
-		}
-
-		currentStatement.WriteString(processedLine)
-		currentStatement.WriteString(" ")
-
-		// 检查是否遇到分隔符
-		if p.isStatementEnd(processedLine, inString) {
-			statementText := strings.TrimSpace(currentStatement.String())
-			statementText = strings.TrimSuffix(statementText, p.delimiter)
-			statementText = strings.TrimSpace(statementText)
-
-			if statementText != "" {
-				stmt, err := p.parseStatement(statementText)
-				if err != nil {
-					return nil, fmt.Errorf("第%d行解析错误: %v", lineNumber, err)
-				}
-				if stmt != nil {
-					statements = append(statements, *stmt)
-				}
-			}
-
-			currentStatement.Reset()
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取文件错误: %v", err)
-	}
-
-	return statements, nil
+	return p.Parse(file)
 }
 
-// processLine 处理单行，移除注释
-func (p *Parser) processLine(line string, inMultiLineComment *bool, inString *bool, stringChar *rune) string {
-	var result strings.Builder
-	runes := []rune(line)
-
-	for i, r := range runes {
-		// 处理多行注释
-		if *inMultiLineComment {
-			if r == '*' && i+1 < len(runes) && runes[i+1] == '/' {
-				*inMultiLineComment = false
-				i++ // 跳过下一个字符
-			}
-			continue
-		}
+// Parse 解析 r 中的所有语句，按文件顺序返回
+func (p *Parser) Parse(r io.Reader) ([]types.SQLStatement, error) {
+	var statements []types.SQLStatement
 
-		// 处理字符串
-		if *inString {
-			result.WriteRune(r)
-			if r == *stringChar && (i == 0 || runes[i-1] != '\\') {
-				*inString = false
-			}
-			continue
+	scanner := NewScanner(r)
+	for {
+		statement, err := scanner.Next()
+		if err == io.EOF {
+			break
 		}
-
-		// 检查字符串开始
-		if r == '\'' || r == '"' || r == '`' {
-			*inString = true
-			*stringChar = r
-			result.WriteRune(r)
-			continue
+		if err != nil {
+			return nil, err
 		}
 
-		// 检查单行注释
-		if r == '-' && i+1 < len(runes) && runes[i+1] == '-' {
-			break
+		stmt, err := p.ParseStatement(statement)
+		if err != nil {
+			return nil, err
 		}
-
-		// 检查多行注释开始
-		if r == '/' && i+1 < len(runes) && runes[i+1] == '*' {
-			*inMultiLineComment = true
-			i++ // 跳过下一个字符
-			continue
+		if stmt != nil {
+			statements = append(statements, *stmt)
 		}
-
-		result.WriteRune(r)
 	}
 
-	return strings.TrimSpace(result.String())
-}
-
-// isStatementEnd 检查是否是语句结束
-func (p *Parser) isStatementEnd(line string, inString bool) bool {
-	if inString {
-		return false
-	}
-	return strings.HasSuffix(strings.TrimSpace(line), p.delimiter)
+	return statements, nil
 }
 
-// parseStatement 解析单个SQL语句
-func (p *Parser) parseStatement(statement string) (*types.SQLStatement, error) {
-	statement = strings.TrimSpace(statement)
-	if statement == "" {
-		return nil, nil
+// ParseStatement 解析单个SQL语句
+func (p *Parser) ParseStatement(statement *Statement) (*types.SQLStatement, error) {
+	if len(statement.Tokens) == 0 {
+		return nil, nil // 只有空的条件注释
+	}
+
+	c := newCursor(statement)
+	var (
+		stmt *types.SQLStatement
+		err  error
+	)
+	switch statement.Keyword(0) {
+	case "CREATE":
+		stmt, err = p.parseCreate(c)
+	case "ALTER":
+		stmt, err = p.parseAlter(c)
+	case "DROP":
+		stmt, err = p.parseDrop(c)
+	case "RENAME":
+		stmt, err = p.parseRename(c)
+	case "INSERT", "REPLACE", "UPDATE", "DELETE", "TRUNCATE":
+		stmt, err = p.parseDML(c)
+	default:
+		stmt = p.parseOther(c)
 	}
-
-	upperStatement := strings.ToUpper(statement)
-
-	// CREATE TABLE
-	if strings.HasPrefix(upperStatement, "CREATE TABLE") {
-		return p.parseCreateTable(statement)
-	}
-
-	// CREATE VIEW
-	if strings.HasPrefix(upperStatement, "CREATE VIEW") ||
-		strings.Contains(upperStatement, "CREATE OR REPLACE VIEW") {
-		return p.parseCreateView(statement)
-	}
-
-	// CREATE PROCEDURE/FUNCTION
-	if strings.HasPrefix(upperStatement, "CREATE PROCEDURE") ||
-		strings.HasPrefix(upperStatement, "CREATE FUNCTION") ||
-		strings.Contains(upperStatement, "CREATE OR REPLACE PROCEDURE") ||
-		strings.Contains(upperStatement, "CREATE OR REPLACE FUNCTION") {
-		return p.parseCreateProcedure(statement)
+	if err != nil {
+		return nil, err
 	}
 
-	// CREATE TRIGGER
-	if strings.HasPrefix(upperStatement, "CREATE TRIGGER") {
-		return p.parseCreateTrigger(statement)
+	stmt.Statement = statement.Text
+	stmt.Line = statement.Pos.Line
+	if stmt.Dependencies == nil {
+		stmt.Dependencies = []string{}
 	}
+	return stmt, nil
+}
 
-	// CREATE INDEX
-	if strings.HasPrefix(upperStatement, "CREATE INDEX") ||
-		strings.HasPrefix(upperStatement, "CREATE UNIQUE INDEX") {
-		return p.parseCreateIndex(statement)
+// parseCreate 解析CREATE语句
+func (p *Parser) parseCreate(c *cursor) (*types.SQLStatement, error) {
+	c.accept("CREATE")
+	c.accept("OR", "REPLACE")
+
+	// 视图和存储程序的可选子句
+	if c.accept("ALGORITHM") {
+		c.acceptPunct("=")
+		c.pos++
+	}
+	c.skipDefiner()
+	if c.accept("SQL", "SECURITY") {
+		c.pos++
+	}
+
+	switch {
+	case c.accept("TABLE"), c.accept("TEMPORARY", "TABLE"):
+		return p.parseCreateTable(c)
+	case c.accept("VIEW"):
+		return p.parseCreateView(c)
+	case c.accept("PROCEDURE"):
+		return p.parseCreateProcedure(c, "PROCEDURE")
+	case c.accept("FUNCTION"), c.accept("AGGREGATE", "FUNCTION"):
+		return p.parseCreateProcedure(c, "FUNCTION")
+	case c.accept("TRIGGER"):
+		return p.parseCreateTrigger(c)
+	case c.accept("INDEX"), c.accept("UNIQUE", "INDEX"), c.accept("FULLTEXT", "INDEX"), c.accept("SPATIAL", "INDEX"):
+		return p.parseCreateIndex(c)
+	case c.accept("DATABASE"), c.accept("SCHEMA"):
+		return p.parseNamed(c, "CREATE_DATABASE", "无法解析数据库名")
+	case c.accept("EVENT"):
+		return p.parseNamed(c, "CREATE_EVENT", "无法解析事件名")
 	}
 
 	// 其他DDL语句
-	if strings.HasPrefix(upperStatement, "CREATE") {
-		return &types.SQLStatement{
-			Type:         "CREATE_OTHER",
-			Name:         "unknown",
-			Statement:    statement,
-			Dependencies: []string{},
-		}, nil
-	}
-
-	return nil, nil // 忽略非CREATE语句
+	return &types.SQLStatement{
+		Type:         "CREATE_OTHER",
+		Name:         "unknown",
+		Dependencies: []string{},
+	}, nil
 }
 
-// parseCreateTable 解析CREATE TABLE语句
-func (p *Parser) parseCreateTable(statement string) (*types.SQLStatement, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("无法解析表名")
+// parseNamed 解析 [IF [NOT] EXISTS] name 形式的语句
+func (p *Parser) parseNamed(c *cursor, stmtType, message string) (*types.SQLStatement, error) {
+	c.accept("IF", "NOT", "EXISTS")
+	c.accept("IF", "EXISTS")
+	name, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("%s", message)
 	}
+	return &types.SQLStatement{Type: stmtType, Name: name}, nil
+}
 
-	tableName := matches[2]
-	if matches[1] != "" {
-		tableName = matches[1] + "." + tableName
+// parseCreateTable 解析CREATE TABLE语句
+func (p *Parser) parseCreateTable(c *cursor) (*types.SQLStatement, error) {
+	c.accept("IF", "NOT", "EXISTS")
+	tableName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析表名")
 	}
 
-	// 解析外键依赖
-	dependencies := p.extractForeignKeyDependencies(statement)
-
 	return &types.SQLStatement{
 		Type:         "CREATE_TABLE",
 		Name:         tableName,
-		Statement:    statement,
-		Dependencies: dependencies,
+		Dependencies: p.extractForeignKeyDependencies(c),
 	}, nil
 }
 
 // parseCreateView 解析CREATE VIEW语句
-func (p *Parser) parseCreateView(statement string) (*types.SQLStatement, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?VIEW\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 3 {
-		return nil, fmt.Errorf("无法解析视图名")
-	}
-
-	viewName := matches[2]
-	if matches[1] != "" {
-		viewName = matches[1] + "." + viewName
+func (p *Parser) parseCreateView(c *cursor) (*types.SQLStatement, error) {
+	viewName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析视图名")
 	}
 
-	// 解析视图依赖的表
-	dependencies := p.extractTableDependencies(statement)
-
 	return &types.SQLStatement{
 		Type:         "CREATE_VIEW",
 		Name:         viewName,
-		Statement:    statement,
-		Dependencies: dependencies,
+		Dependencies: p.extractTableDependencies(c),
 	}, nil
 }
 
 // parseCreateProcedure 解析CREATE PROCEDURE/FUNCTION语句
-func (p *Parser) parseCreateProcedure(statement string) (*types.SQLStatement, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?(PROCEDURE|FUNCTION)\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 4 {
-		return nil, fmt.Errorf("无法解析存储过程/函数名")
-	}
-
-	objType := strings.ToUpper(matches[1])
-	objName := matches[3]
-	if matches[2] != "" {
-		objName = matches[2] + "." + objName
+func (p *Parser) parseCreateProcedure(c *cursor, objType string) (*types.SQLStatement, error) {
+	c.accept("IF", "NOT", "EXISTS")
+	objName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析存储过程/函数名")
 	}
 
 	return &types.SQLStatement{
 		Type:         "CREATE_" + objType,
 		Name:         objName,
-		Statement:    statement,
 		Dependencies: []string{}, // 存储过程依赖比较复杂，暂时不解析
 	}, nil
 }
 
 // parseCreateTrigger 解析CREATE TRIGGER语句
-func (p *Parser) parseCreateTrigger(statement string) (*types.SQLStatement, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+TRIGGER\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)\s+(?:BEFORE|AFTER|INSTEAD\s+OF)\s+(?:INSERT|UPDATE|DELETE)(?:\s+OR\s+(?:INSERT|UPDATE|DELETE))*\s+ON\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 5 {
-		return nil, fmt.Errorf("无法解析触发器信息")
+func (p *Parser) parseCreateTrigger(c *cursor) (*types.SQLStatement, error) {
+	c.accept("IF", "NOT", "EXISTS")
+	triggerName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析触发器名")
 	}
 
-	triggerName := matches[2]
-	if matches[1] != "" {
-		triggerName = matches[1] + "." + triggerName
+	if !c.accept("BEFORE") && !c.accept("AFTER") && !c.accept("INSTEAD", "OF") {
+		return nil, c.stmt.Errorf("无法解析触发器 %s 的触发时机", triggerName)
 	}
-
-	tableName := matches[4]
-	if matches[3] != "" {
-		tableName = matches[3] + "." + tableName
+	for c.accept("INSERT") || c.accept("UPDATE") || c.accept("DELETE") {
+		if !c.accept("OR") {
+			break
+		}
+	}
+	if !c.accept("ON") {
+		return nil, c.stmt.Errorf("无法解析触发器 %s 的事件", triggerName)
+	}
+	tableName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析触发器 %s 的表名", triggerName)
 	}
 
 	return &types.SQLStatement{
 		Type:         "CREATE_TRIGGER",
 		Name:         triggerName,
-		Statement:    statement,
 		Dependencies: []string{tableName}, // 触发器依赖表
 	}, nil
 }
 
 // parseCreateIndex 解析CREATE INDEX语句
-func (p *Parser) parseCreateIndex(statement string) (*types.SQLStatement, error) {
-	re := regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)\s+ON\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindStringSubmatch(statement)
-
-	if len(matches) < 5 {
-		return nil, fmt.Errorf("无法解析索引信息")
+func (p *Parser) parseCreateIndex(c *cursor) (*types.SQLStatement, error) {
+	c.accept("IF", "NOT", "EXISTS")
+	indexName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析索引名")
 	}
-
-	indexName := matches[2]
-	if matches[1] != "" {
-		indexName = matches[1] + "." + indexName
+	if c.accept("USING") {
+		c.pos++
 	}
-
-	tableName := matches[4]
-	if matches[3] != "" {
-		tableName = matches[3] + "." + tableName
+	if !c.accept("ON") {
+		return nil, c.stmt.Errorf("无法解析索引 %s 的表名", indexName)
+	}
+	tableName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析索引 %s 的表名", indexName)
 	}
 
 	return &types.SQLStatement{
 		Type:         "CREATE_INDEX",
 		Name:         indexName,
-		Statement:    statement,
 		Dependencies: []string{tableName}, // 索引依赖表
 	}, nil
 }
 
 // extractForeignKeyDependencies 提取外键依赖
-func (p *Parser) extractForeignKeyDependencies(statement string) []string {
+func (p *Parser) extractForeignKeyDependencies(c *cursor) []string {
 	var dependencies []string
 
 	// 匹配 FOREIGN KEY ... REFERENCES table_name
-	re := regexp.MustCompile(`(?i)REFERENCES\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindAllStringSubmatch(statement, -1)
-
-	for _, match := range matches {
-		if len(match) >= 3 {
-			tableName := match[2]
-			if match[1] != "" {
-				tableName = match[1] + "." + tableName
-			}
+	for !c.done() {
+		if !c.accept("REFERENCES") {
+			c.pos++
+			continue
+		}
+		if tableName, ok := c.name(); ok {
 			dependencies = append(dependencies, tableName)
 		}
 	}
@@ -344,19 +264,16 @@ func (p *Parser) extractForeignKeyDependencies(statement string) []string {
 }
 
 // extractTableDependencies 提取表依赖（用于视图）
-func (p *Parser) extractTableDependencies(statement string) []string {
+func (p *Parser) extractTableDependencies(c *cursor) []string {
 	var dependencies []string
 
-	// 从FROM和JOIN子句中提取表名
-	re := regexp.MustCompile(`(?i)(?:FROM|JOIN)\s+(?:\x60?(\w+)\x60?\.)?(?:\x60?(\w+)\x60?)`)
-	matches := re.FindAllStringSubmatch(statement, -1)
-
-	for _, match := range matches {
-		if len(match) >= 3 {
-			tableName := match[2]
-			if match[1] != "" {
-				tableName = match[1] + "." + tableName
-			}
+	// 从FROM和JOIN子句中提取表名，子查询在后续扫描中处理
+	for !c.done() {
+		if !c.accept("FROM") && !c.accept("JOIN") {
+			c.pos++
+			continue
+		}
+		if tableName, ok := c.name(); ok {
 			dependencies = append(dependencies, tableName)
 		}
 	}
@@ -366,9 +283,24 @@ func (p *Parser) extractTableDependencies(statement string) []string {
 
 // ValidateStatements 验证语句
 func (p *Parser) ValidateStatements(statements []types.SQLStatement) error {
-	// 检查重复定义
+	// 检查重复定义，中间删除或重命名过的对象可以重新创建
 	names := make(map[string]bool)
 	for _, stmt := range statements {
+		switch {
+		case stmt.Drop != nil:
+			for _, name := range stmt.Drop.Names {
+				delete(names, "CREATE_"+stmt.Drop.ObjectType+":"+name)
+			}
+			continue
+		case stmt.Type == "RENAME_TABLE":
+			for _, rename := range stmt.Renames {
+				delete(names, "CREATE_TABLE:"+rename.From)
+			}
+			continue
+		case !strings.HasPrefix(stmt.Type, "CREATE_") || stmt.Type == "CREATE_OTHER":
+			continue
+		}
+
 		key := stmt.Type + ":" + stmt.Name
 		if names[key] {
 			return fmt.Errorf("重复定义: %s %s", stmt.Type, stmt.Name)
@@ -379,78 +311,82 @@ func (p *Parser) ValidateStatements(statements []types.SQLStatement) error {
 	return nil
 }
 
-// SortByDependencies 按依赖关系排序
+// SortByDependencies 按依赖关系排序：保持文件中的顺序，只把依赖后面才创建的对象的语句移到该对象之后。
+// 依赖的对象本身被移后时，依赖它的语句（INSERT、ALTER、CREATE INDEX 等）随之移到它之后
 func (p *Parser) SortByDependencies(statements []types.SQLStatement) ([]types.SQLStatement, error) {
-	// 创建依赖图
-	graph := make(map[string][]string)
-	inDegree := make(map[string]int)
-	stmtMap := make(map[string]types.SQLStatement)
-
-	// 初始化
-	for _, stmt := range statements {
-		key := stmt.Name
-		graph[key] = []string{}
-		inDegree[key] = 0
-		stmtMap[key] = stmt
+	// 每个对象第一次创建的语句
+	creators := make(map[string]int)
+	for i, stmt := range statements {
+		switch stmt.Type {
+		case "CREATE_TABLE", "CREATE_VIEW":
+			if _, exists := creators[stmt.Name]; !exists {
+				creators[stmt.Name] = i
+			}
+		}
 	}
 
-	// 构建依赖关系
-	for _, stmt := range statements {
+	// 创建依赖图，创建语句在前面时也要加边：创建语句可能因自身的依赖被移到后面
+	graph := make([][]int, len(statements))
+	inDegree := make([]int, len(statements))
+	for i, stmt := range statements {
 		for _, dep := range stmt.Dependencies {
-			if _, exists := stmtMap[dep]; exists {
-				graph[dep] = append(graph[dep], stmt.Name)
-				inDegree[stmt.Name]++
+			if j, exists := creators[dep]; exists && j != i {
+				graph[j] = append(graph[j], i)
+				inDegree[i]++
 			}
 		}
 	}
 
-	// 拓扑排序
-	var queue []string
-	for name, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, name)
+	// 拓扑排序，可执行的语句中总是先取文件中靠前的
+	ready := &indexHeap{}
+	for i := range statements {
+		if inDegree[i] == 0 {
+			heap.Push(ready, i)
 		}
 	}
 
-	var result []types.SQLStatement
-	processed := 0
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		result = append(result, stmtMap[current])
-		processed++
-
-		for _, neighbor := range graph[current] {
-			inDegree[neighbor]--
-			if inDegree[neighbor] == 0 {
-				queue = append(queue, neighbor)
+	result := make([]types.SQLStatement, 0, len(statements))
+	done := make([]bool, len(statements))
+	for len(result) < len(statements) {
+		if ready.Len() == 0 {
+			// 循环依赖（如互相引用的外键）按文件顺序执行，由 FOREIGN_KEY_CHECKS 等设置决定能否成功
+			for i := range statements {
+				if !done[i] {
+					inDegree[i] = 0
+					heap.Push(ready, i)
+					break
+				}
 			}
 		}
-	}
 
-	if processed != len(statements) {
-		return nil, fmt.Errorf("检测到循环依赖")
-	}
-
-	// 按类型优先级重新排序（表->视图->存储过程->触发器->索引）
-	typeOrder := map[string]int{
-		"CREATE_TABLE":     1,
-		"CREATE_VIEW":      2,
-		"CREATE_PROCEDURE": 3,
-		"CREATE_FUNCTION":  3,
-		"CREATE_TRIGGER":   4,
-		"CREATE_INDEX":     5,
-		"CREATE_OTHER":     6,
-	}
+		current := heap.Pop(ready).(int)
+		if done[current] {
+			continue
+		}
+		done[current] = true
+		result = append(result, statements[current])
 
-	sort.Slice(result, func(i, j int) bool {
-		if typeOrder[result[i].Type] != typeOrder[result[j].Type] {
-			return typeOrder[result[i].Type] < typeOrder[result[j].Type]
+		for _, next := range graph[current] {
+			inDegree[next]--
+			if inDegree[next] == 0 && !done[next] {
+				heap.Push(ready, next)
+			}
 		}
-		return result[i].Name < result[j].Name
-	})
+	}
 
 	return result, nil
 }
+
+// indexHeap 语句下标的最小堆
+type indexHeap []int
+
+func (h indexHeap) Len() int            { return len(h) }
+func (h indexHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h indexHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *indexHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *indexHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,69 @@
+package sqlparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortByDependencies(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "保持文件顺序",
+			sql: `CREATE TABLE users (id INT PRIMARY KEY);
+				CREATE TABLE orders (id INT, user_id INT, FOREIGN KEY (user_id) REFERENCES users (id));
+				INSERT INTO users VALUES (1);`,
+			want: []string{"users", "orders", "users"},
+		},
+		{
+			name: "外键引用后面的表",
+			sql: `CREATE TABLE orders (id INT, user_id INT, FOREIGN KEY (user_id) REFERENCES users (id));
+				CREATE TABLE users (id INT PRIMARY KEY);`,
+			want: []string{"users", "orders"},
+		},
+		{
+			name: "被移后的表上的语句随之移后",
+			sql: `CREATE TABLE orders (id INT, user_id INT, FOREIGN KEY (user_id) REFERENCES users (id));
+				INSERT INTO orders VALUES (1, 1);
+				CREATE INDEX idx_user ON orders (user_id);
+				ALTER TABLE orders ADD COLUMN note TEXT;
+				CREATE TABLE users (id INT PRIMARY KEY);`,
+			want: []string{"users", "orders", "orders", "idx_user", "orders"},
+		},
+		{
+			name: "视图依赖后面的表",
+			sql: `CREATE VIEW active_users AS SELECT * FROM users;
+				CREATE TABLE users (id INT PRIMARY KEY);`,
+			want: []string{"users", "active_users"},
+		},
+		{
+			name: "循环外键按文件顺序",
+			sql: `CREATE TABLE a (id INT, b_id INT, FOREIGN KEY (b_id) REFERENCES b (id));
+				CREATE TABLE b (id INT, a_id INT, FOREIGN KEY (a_id) REFERENCES a (id));
+				INSERT INTO a VALUES (1, 1);`,
+			want: []string{"a", "b", "a"},
+		},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		statements, err := p.Parse(strings.NewReader(tt.sql))
+		if err != nil {
+			t.Fatalf("%s: 解析失败: %v", tt.name, err)
+		}
+		sorted, err := p.SortByDependencies(statements)
+		if err != nil {
+			t.Fatalf("%s: 排序失败: %v", tt.name, err)
+		}
+		var got []string
+		for _, stmt := range sorted {
+			got = append(got, stmt.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: 顺序为 %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
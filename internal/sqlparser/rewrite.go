@@ -0,0 +1,141 @@
+package sqlparser
+
+import (
+	"strings"
+)
+
+// RewriteOptions 执行 SQL 文件前对语句的改写
+type RewriteOptions struct {
+	StripDefiner bool              // 删除 DEFINER=... 子句，对象的定义者为执行导入的用户
+	Definer      string            // 替换 DEFINER 的用户，如 `app`@`%`，StripDefiner 优先
+	Charsets     map[string]string // 字符集替换，键为小写的原字符集
+	Collations   map[string]string // 排序规则替换，键为小写的原排序规则
+}
+
+// Empty 是否不需要改写
+func (o RewriteOptions) Empty() bool {
+	return !o.StripDefiner && o.Definer == "" && len(o.Charsets) == 0 && len(o.Collations) == 0
+}
+
+// edit 替换词法单元 [from, to) 对应的原文
+type edit struct {
+	from, to int
+	text     string
+}
+
+// Rewrite 按选项改写语句，返回新的语句文本；INSERT/REPLACE 语句中只有数据，不做改写
+func Rewrite(stmt *Statement, options RewriteOptions) string {
+	if options.Empty() || stmt.Keyword(0) == "INSERT" || stmt.Keyword(0) == "REPLACE" {
+		return stmt.Text
+	}
+
+	var edits []edit
+	tokens := stmt.Tokens
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.IsKeyword("DEFINER") && i+1 < len(tokens) && tokens[i+1].IsPunct("="):
+			if !options.StripDefiner && options.Definer == "" {
+				continue
+			}
+			end := definerEnd(tokens, i+2)
+			if options.StripDefiner {
+				edits = append(edits, edit{from: i, to: end})
+			} else {
+				edits = append(edits, edit{from: i + 2, to: end, text: options.Definer})
+			}
+			i = end - 1
+
+		case tok.IsKeyword("CHARSET") || tok.IsKeyword("CHARACTER") && i+1 < len(tokens) && tokens[i+1].IsKeyword("SET"):
+			if tok.IsKeyword("CHARACTER") {
+				i++
+			}
+			i = rewriteValue(tokens, i+1, options.Charsets, &edits)
+
+		case tok.IsKeyword("NAMES") && i > 0 && tokens[i-1].IsKeyword("SET"):
+			i = rewriteValue(tokens, i+1, options.Charsets, &edits)
+
+		case tok.IsKeyword("COLLATE"):
+			i = rewriteValue(tokens, i+1, options.Collations, &edits)
+
+		case tok.Type == TokenWord || tok.Type == TokenVariable:
+			// SET character_set_client = utf8 / SET collation_connection = ...
+			name := strings.ToLower(tok.Value)
+			if dot := strings.LastIndex(name, "."); dot >= 0 {
+				name = name[dot+1:]
+			}
+			if i+1 >= len(tokens) || !tokens[i+1].IsPunct("=") {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(name, "character_set_"):
+				i = rewriteValue(tokens, i+1, options.Charsets, &edits)
+			case strings.HasPrefix(name, "collation_"):
+				i = rewriteValue(tokens, i+1, options.Collations, &edits)
+			}
+		}
+	}
+
+	if len(edits) == 0 {
+		return stmt.Text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		start := tokens[e.from].Offset
+		end := start
+		if e.to > e.from {
+			end = tokens[e.to-1].Offset + len(tokens[e.to-1].Text)
+		}
+		b.WriteString(stmt.Text[last:start])
+		b.WriteString(e.text)
+		last = end
+	}
+	b.WriteString(stmt.Text[last:])
+	return b.String()
+}
+
+// definerEnd 返回 DEFINER 值之后的位置：user[@host] 或 CURRENT_USER[()]
+func definerEnd(tokens []Token, i int) int {
+	if i >= len(tokens) {
+		return i
+	}
+	if tokens[i].IsKeyword("CURRENT_USER") {
+		if i+2 < len(tokens) && tokens[i+1].IsPunct("(") && tokens[i+2].IsPunct(")") {
+			return i + 3
+		}
+		return i + 1
+	}
+	i++
+	if i < len(tokens) && tokens[i].Type == TokenVariable {
+		i++ // @host
+	}
+	return i
+}
+
+// rewriteValue 跳过可选的 = 后替换名称，返回最后处理的位置
+func rewriteValue(tokens []Token, i int, names map[string]string, edits *[]edit) int {
+	if i < len(tokens) && tokens[i].IsPunct("=") {
+		i++
+	}
+	if i >= len(tokens) {
+		return i
+	}
+
+	tok := tokens[i]
+	if tok.Type != TokenWord && tok.Type != TokenString && tok.Type != TokenQuotedIdent {
+		return i
+	}
+	replacement, ok := names[strings.ToLower(tok.Value)]
+	if !ok {
+		return i
+	}
+
+	if tok.Type != TokenWord {
+		quote := tok.Text[:1]
+		replacement = quote + replacement + quote
+	}
+	*edits = append(*edits, edit{from: i, to: i + 1, text: replacement})
+	return i
+}
@@ -0,0 +1,366 @@
+package sqlparser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Statement 按分隔符切分出的一条语句
+type Statement struct {
+	Text      string   // 语句原文，不含前后的注释、空白和分隔符
+	Tokens    []Token  // 语句中的词法单元，不含空白、注释和条件注释标记
+	Pos       Position // 语句起始位置
+	Delimiter string   // 结束语句的分隔符，文件末尾没有分隔符时为空
+	Streaming bool     // INSERT/REPLACE 语句到 VALUES 为止，值组通过 Scanner.NextRow 逐个读取
+}
+
+// Keyword 返回第 i 个词法单元的大写关键字，不是关键字时返回空
+func (s *Statement) Keyword(i int) string {
+	if i < 0 || i >= len(s.Tokens) || s.Tokens[i].Type != TokenWord {
+		return ""
+	}
+	return strings.ToUpper(s.Tokens[i].Text)
+}
+
+// Slice 返回词法单元 [from, to) 对应的原文
+func (s *Statement) Slice(from, to int) string {
+	if from >= to || from >= len(s.Tokens) {
+		return ""
+	}
+	last := s.Tokens[to-1]
+	return s.Text[s.Tokens[from].Offset : last.Offset+len(last.Text)]
+}
+
+// Errorf 生成带语句位置的错误
+func (s *Statement) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", s.Pos, fmt.Sprintf(format, args...))
+}
+
+// Scanner 从输入中逐条读取语句，支持 DELIMITER 命令，内存占用与单条语句大小相关
+type Scanner struct {
+	lexer        *Lexer
+	streamValues bool
+	inValues     bool // 正在读取 INSERT 的值组
+	rows         int  // 当前语句已读取的值组数
+}
+
+// NewScanner 创建语句扫描器
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{lexer: NewLexer(r)}
+}
+
+// StreamValues INSERT/REPLACE ... VALUES 语句在 VALUES 处返回，值组由 NextRow 逐个读取，
+// 单条语句包含大量数据时内存占用与单个值组大小相关
+func (s *Scanner) StreamValues() {
+	s.streamValues = true
+}
+
+// Next 返回下一条语句，没有更多语句时返回 io.EOF
+func (s *Scanner) Next() (*Statement, error) {
+	// 跳过未读取的值组
+	for s.inValues {
+		if _, err := s.NextRow(); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+
+	var (
+		stmt    *Statement
+		text    strings.Builder
+		pending strings.Builder // 最后一个有效词法单元之后的空白和注释
+		depth   int
+	)
+
+	for {
+		tok, err := s.lexer.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.Type {
+		case TokenEOF:
+			if stmt == nil {
+				return nil, io.EOF
+			}
+			stmt.Text = text.String()
+			return stmt, nil
+
+		case TokenDelimiter:
+			if stmt == nil {
+				continue // 空语句
+			}
+			stmt.Text = text.String()
+			stmt.Delimiter = tok.Text
+			return stmt, nil
+
+		case TokenSpace, TokenComment:
+			if stmt != nil {
+				pending.WriteString(tok.Text)
+			}
+			continue
+		}
+
+		if stmt == nil {
+			if tok.IsKeyword("DELIMITER") {
+				if err := s.changeDelimiter(tok.Pos); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			stmt = &Statement{Pos: tok.Pos}
+		}
+
+		text.WriteString(pending.String())
+		pending.Reset()
+		tok.Offset = text.Len()
+		text.WriteString(tok.Text)
+		if tok.Type == TokenCondOpen || tok.Type == TokenCondClose {
+			continue
+		}
+		stmt.Tokens = append(stmt.Tokens, tok)
+
+		switch {
+		case tok.IsPunct("("):
+			depth++
+		case tok.IsPunct(")"):
+			depth--
+		case s.streamValues && depth == 0 && (tok.IsKeyword("VALUES") || tok.IsKeyword("VALUE")) && isDataKeyword(stmt.Keyword(0)):
+			stmt.Text = text.String()
+			stmt.Streaming = true
+			s.inValues = true
+			s.rows = 0
+			return stmt, nil
+		}
+	}
+}
+
+// isDataKeyword 是否为可以流式读取值组的语句
+func isDataKeyword(keyword string) bool {
+	return keyword == "INSERT" || keyword == "REPLACE"
+}
+
+// NextRow 读取当前 INSERT 语句的下一个值组，返回的语句只包含括号内的词法单元；
+// 语句结束时返回 io.EOF。值组之后还有子句（如 ON DUPLICATE KEY UPDATE、AS 别名）时返回错误，
+// 逐行读取的调用方无法执行这些子句
+func (s *Scanner) NextRow() (row *Statement, err error) {
+	if !s.inValues {
+		return nil, io.EOF
+	}
+	defer func() {
+		if err != nil && err != io.EOF {
+			s.inValues = false
+		}
+	}()
+
+	tok, err := s.nextSignificant()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.rows > 0 {
+		if !tok.IsPunct(",") {
+			return nil, s.endValues(tok)
+		}
+		if tok, err = s.nextSignificant(); err != nil {
+			return nil, err
+		}
+	}
+	if tok.IsKeyword("ROW") {
+		if tok, err = s.nextSignificant(); err != nil {
+			return nil, err
+		}
+	}
+	if !tok.IsPunct("(") {
+		if s.rows == 0 || tok.Type == TokenEOF || tok.Type == TokenDelimiter {
+			s.inValues = false
+			return nil, fmt.Errorf("%s: 缺少值组", tok.Pos)
+		}
+		return nil, s.endValues(tok)
+	}
+
+	row = &Statement{Pos: tok.Pos}
+	var (
+		text    strings.Builder
+		pending strings.Builder
+		depth   int
+	)
+	for {
+		tok, err := s.lexer.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.Type {
+		case TokenEOF, TokenDelimiter:
+			s.inValues = false
+			return nil, fmt.Errorf("%s: 值组缺少右括号", row.Pos)
+		case TokenSpace, TokenComment:
+			if text.Len() > 0 {
+				pending.WriteString(tok.Text)
+			}
+			continue
+		case TokenCondOpen, TokenCondClose:
+			continue
+		}
+
+		if tok.IsPunct("(") {
+			depth++
+		} else if tok.IsPunct(")") {
+			if depth == 0 {
+				row.Text = text.String()
+				s.rows++
+				return row, nil
+			}
+			depth--
+		}
+
+		text.WriteString(pending.String())
+		pending.Reset()
+		tok.Offset = text.Len()
+		text.WriteString(tok.Text)
+		row.Tokens = append(row.Tokens, tok)
+	}
+}
+
+// endValues 值组之后不是逗号时结束语句，语句没有结束时跳过剩余部分并返回错误
+func (s *Scanner) endValues(tok Token) error {
+	s.inValues = false
+	if tok.Type == TokenEOF || tok.Type == TokenDelimiter {
+		return io.EOF
+	}
+
+	clause := tok
+	for tok.Type != TokenEOF && tok.Type != TokenDelimiter {
+		var err error
+		if tok, err = s.lexer.Next(); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: 不支持值组之后的 %s 子句，请去掉该子句并改用导入的冲突处理策略", clause.Pos, strings.ToUpper(clause.Text))
+}
+
+// nextSignificant 读取下一个非空白、非注释的词法单元
+func (s *Scanner) nextSignificant() (Token, error) {
+	for {
+		tok, err := s.lexer.Next()
+		if err != nil {
+			return tok, err
+		}
+		switch tok.Type {
+		case TokenSpace, TokenComment, TokenCondOpen, TokenCondClose:
+			continue
+		}
+		return tok, nil
+	}
+}
+
+// changeDelimiter 处理 DELIMITER 命令，新分隔符为同一行的第一个非空白字段
+func (s *Scanner) changeDelimiter(pos Position) error {
+	line, err := s.lexer.ReadLine()
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return fmt.Errorf("%s: DELIMITER 缺少分隔符", pos)
+	}
+	s.lexer.SetDelimiter(fields[0])
+	return nil
+}
+
+// cursor 在语句的词法单元上顺序匹配关键字和名称
+type cursor struct {
+	stmt *Statement
+	pos  int
+}
+
+func newCursor(stmt *Statement) *cursor {
+	return &cursor{stmt: stmt}
+}
+
+func (c *cursor) done() bool {
+	return c.pos >= len(c.stmt.Tokens)
+}
+
+func (c *cursor) peek() Token {
+	if c.done() {
+		return Token{Type: TokenEOF}
+	}
+	return c.stmt.Tokens[c.pos]
+}
+
+// accept 依次匹配关键字，全部匹配时前进并返回 true
+func (c *cursor) accept(keywords ...string) bool {
+	for i, keyword := range keywords {
+		if !strings.EqualFold(c.stmt.Keyword(c.pos+i), keyword) {
+			return false
+		}
+	}
+	c.pos += len(keywords)
+	return true
+}
+
+// acceptPunct 匹配标点
+func (c *cursor) acceptPunct(punct string) bool {
+	if c.peek().IsPunct(punct) {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+// name 读取 [schema.]name 形式的名称
+func (c *cursor) name() (string, bool) {
+	tok := c.peek()
+	if !tok.IsIdent() {
+		return "", false
+	}
+	c.pos++
+	name := tok.Value
+
+	if c.peek().IsPunct(".") {
+		if next := c.stmt.Tokens[c.pos+1:]; len(next) > 0 && next[0].IsIdent() {
+			c.pos += 2
+			name += "." + next[0].Value
+		}
+	}
+	return name, true
+}
+
+// skipDefiner 跳过 DEFINER = user[@host] 子句
+func (c *cursor) skipDefiner() {
+	if !c.accept("DEFINER") || !c.acceptPunct("=") {
+		return
+	}
+	if c.accept("CURRENT_USER") {
+		if c.acceptPunct("(") {
+			c.acceptPunct(")")
+		}
+		return
+	}
+	c.pos++ // 用户名
+	if c.peek().Type == TokenVariable {
+		c.pos++ // @host
+	}
+}
+
+// skipParens 当前为 ( 时跳过到匹配的 )
+func (c *cursor) skipParens() {
+	if !c.peek().IsPunct("(") {
+		return
+	}
+	depth := 0
+	for ; !c.done(); c.pos++ {
+		switch {
+		case c.peek().IsPunct("("):
+			depth++
+		case c.peek().IsPunct(")"):
+			depth--
+			if depth == 0 {
+				c.pos++
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package sqlparser
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// streamRows 以流式模式读取所有语句，返回每条语句的原文和值组原文
+func streamRows(t *testing.T, sql string) ([]string, [][]string, error) {
+	t.Helper()
+	scanner := NewScanner(strings.NewReader(sql))
+	scanner.StreamValues()
+
+	var texts []string
+	var rows [][]string
+	for {
+		stmt, err := scanner.Next()
+		if err == io.EOF {
+			return texts, rows, nil
+		}
+		if err != nil {
+			return texts, rows, err
+		}
+		texts = append(texts, stmt.Text)
+		var values []string
+		for stmt.Streaming {
+			row, err := scanner.NextRow()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return texts, rows, err
+			}
+			values = append(values, row.Text)
+		}
+		rows = append(rows, values)
+	}
+}
+
+func TestScannerStreamValues(t *testing.T) {
+	texts, rows, err := streamRows(t, `
+		INSERT INTO users (id, name) VALUES (1, 'a,b'), (2, ')'),(3, CONCAT('x', '(y'));
+		REPLACE INTO tags VALUES (1, 'go');
+		UPDATE users SET name = 'c' WHERE id = 1;
+		INSERT INTO t VALUES ROW(1), ROW(2)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTexts := []string{
+		"INSERT INTO users (id, name) VALUES",
+		"REPLACE INTO tags VALUES",
+		"UPDATE users SET name = 'c' WHERE id = 1",
+		"INSERT INTO t VALUES",
+	}
+	wantRows := [][]string{
+		{"1, 'a,b'", "2, ')'", "3, CONCAT('x', '(y')"},
+		{"1, 'go'"},
+		nil,
+		{"1", "2"},
+	}
+	if strings.Join(texts, "|") != strings.Join(wantTexts, "|") {
+		t.Errorf("语句为 %q，期望 %q", texts, wantTexts)
+	}
+	for i := range wantRows {
+		if i >= len(rows) || strings.Join(rows[i], "|") != strings.Join(wantRows[i], "|") {
+			t.Errorf("第%d条语句的值组为 %q，期望 %q", i+1, rows, wantRows)
+			break
+		}
+	}
+}
+
+func TestScannerSkipsUnreadRows(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("INSERT INTO a VALUES (1), (2); INSERT INTO b VALUES (3);"))
+	scanner.StreamValues()
+	if _, err := scanner.Next(); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := scanner.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt.Text != "INSERT INTO b VALUES" {
+		t.Fatalf("第二条语句为 %q", stmt.Text)
+	}
+	row, err := scanner.NextRow()
+	if err != nil || row.Text != "3" {
+		t.Fatalf("值组为 %v, %v", row, err)
+	}
+}
+
+func TestScannerRejectsTrailingClauses(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"INSERT INTO t (id, n) VALUES (1, 1) ON DUPLICATE KEY UPDATE n = n + 1;", "第1行第37列: 不支持值组之后的 ON 子句"},
+		{"INSERT INTO t VALUES (1, 1), (2, 2) AS new ON DUPLICATE KEY UPDATE n = new.n;", "第1行第37列: 不支持值组之后的 AS 子句"},
+		{"INSERT INTO t VALUES (1) returning id;", "第1行第26列: 不支持值组之后的 RETURNING 子句"},
+	}
+	for _, tt := range tests {
+		_, _, err := streamRows(t, tt.sql+" SELECT 1;")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s 的错误为 %v，期望包含 %q", tt.sql, err, tt.want)
+		}
+	}
+
+	// 出错后继续读取下一条语句
+	scanner := NewScanner(strings.NewReader("INSERT INTO t VALUES (1) ON DUPLICATE KEY UPDATE id = 1; SELECT 2;"))
+	scanner.StreamValues()
+	if _, err := scanner.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanner.NextRow(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanner.NextRow(); err == nil || err == io.EOF {
+		t.Fatalf("期望返回子句错误，实际 %v", err)
+	}
+	stmt, err := scanner.Next()
+	if err != nil || stmt.Text != "SELECT 2" {
+		t.Fatalf("下一条语句为 %v, %v", stmt, err)
+	}
+}
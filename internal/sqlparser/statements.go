@@ -0,0 +1,312 @@
+package sqlparser
+
+import (
+	"strings"
+
+	"github.com/xiezhihuan/db-migrator/internal/types"
+)
+
+// parseAlter 解析ALTER语句，ALTER TABLE 拆分为子句
+func (p *Parser) parseAlter(c *cursor) (*types.SQLStatement, error) {
+	c.accept("ALTER")
+	c.accept("ONLINE")
+	c.accept("IGNORE")
+
+	if !c.accept("TABLE") {
+		// ALTER VIEW/PROCEDURE/FUNCTION/EVENT/DATABASE
+		c.accept("ALGORITHM")
+		if c.acceptPunct("=") {
+			c.pos++
+		}
+		c.skipDefiner()
+		if c.accept("SQL", "SECURITY") {
+			c.pos++
+		}
+		kind := c.stmt.Keyword(c.pos)
+		if kind == "SCHEMA" {
+			kind = "DATABASE"
+		}
+		c.pos++
+		name, _ := c.name()
+		return &types.SQLStatement{Type: "ALTER_" + kind, Name: name}, nil
+	}
+
+	tableName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析表名")
+	}
+
+	alter := &types.AlterTable{Table: tableName}
+	dependencies := []string{tableName}
+	for _, spec := range splitTopLevel(c) {
+		alterSpec := p.parseAlterSpec(&cursor{stmt: c.stmt, pos: spec[0]}, spec[1])
+		alter.Specs = append(alter.Specs, alterSpec)
+		if alterSpec.References != "" {
+			dependencies = append(dependencies, alterSpec.References)
+		}
+	}
+
+	return &types.SQLStatement{
+		Type:         "ALTER_TABLE",
+		Name:         tableName,
+		Dependencies: dependencies,
+		Alter:        alter,
+	}, nil
+}
+
+// parseAlterSpec 解析ALTER TABLE的一个子句，end 为子句结束位置
+func (p *Parser) parseAlterSpec(c *cursor, end int) types.AlterSpec {
+	spec := types.AlterSpec{Action: "OPTION", Definition: c.stmt.Slice(c.pos, end)}
+
+	// 子句内的 REFERENCES
+	references := func() string {
+		for ; c.pos < end; c.pos++ {
+			if c.accept("REFERENCES") {
+				name, _ := c.name()
+				return name
+			}
+		}
+		return ""
+	}
+	// 可选的名称，后面是 ( 时没有名称
+	optionalName := func() string {
+		if c.peek().IsPunct("(") || c.accept("USING") {
+			return ""
+		}
+		name, _ := c.name()
+		return name
+	}
+
+	switch {
+	case c.accept("ADD"):
+		if c.accept("CONSTRAINT") && !c.peek().IsKeyword("PRIMARY") && !c.peek().IsKeyword("UNIQUE") &&
+			!c.peek().IsKeyword("FOREIGN") && !c.peek().IsKeyword("CHECK") {
+			spec.Name, _ = c.name()
+		}
+		switch {
+		case c.accept("PRIMARY", "KEY"):
+			spec.Action = "ADD_PRIMARY_KEY"
+		case c.accept("FOREIGN", "KEY"):
+			spec.Action = "ADD_FOREIGN_KEY"
+			if name := optionalName(); spec.Name == "" {
+				spec.Name = name
+			}
+			spec.References = references()
+		case c.accept("CHECK"):
+			spec.Action = "ADD_CHECK"
+		case c.accept("UNIQUE"), c.accept("FULLTEXT"), c.accept("SPATIAL"), c.accept("INDEX"), c.accept("KEY"):
+			spec.Action = "ADD_INDEX"
+			if !c.accept("INDEX") {
+				c.accept("KEY")
+			}
+			if name := optionalName(); spec.Name == "" {
+				spec.Name = name
+			}
+		case c.accept("PARTITION"):
+		default:
+			spec.Action = "ADD_COLUMN"
+			c.accept("COLUMN")
+			spec.Name, _ = c.name()
+			spec.References = references()
+		}
+
+	case c.accept("DROP"):
+		switch {
+		case c.accept("PRIMARY", "KEY"):
+			spec.Action = "DROP_PRIMARY_KEY"
+		case c.accept("FOREIGN", "KEY"):
+			spec.Action = "DROP_FOREIGN_KEY"
+			spec.Name, _ = c.name()
+		case c.accept("INDEX"), c.accept("KEY"):
+			spec.Action = "DROP_INDEX"
+			spec.Name, _ = c.name()
+		case c.accept("CONSTRAINT"), c.accept("CHECK"):
+			spec.Action = "DROP_CONSTRAINT"
+			spec.Name, _ = c.name()
+		case c.accept("PARTITION"):
+		default:
+			spec.Action = "DROP_COLUMN"
+			c.accept("COLUMN")
+			spec.Name, _ = c.name()
+		}
+
+	case c.accept("MODIFY"):
+		spec.Action = "MODIFY_COLUMN"
+		c.accept("COLUMN")
+		spec.Name, _ = c.name()
+		spec.References = references()
+
+	case c.accept("CHANGE"):
+		spec.Action = "CHANGE_COLUMN"
+		c.accept("COLUMN")
+		spec.Name, _ = c.name()
+		spec.NewName, _ = c.name()
+		spec.References = references()
+
+	case c.accept("ALTER"):
+		if !c.accept("INDEX") && !c.accept("CHECK") && !c.accept("CONSTRAINT") {
+			spec.Action = "ALTER_COLUMN"
+			c.accept("COLUMN")
+		}
+		spec.Name, _ = c.name()
+
+	case c.accept("RENAME"):
+		switch {
+		case c.accept("COLUMN"):
+			spec.Action = "RENAME_COLUMN"
+		case c.accept("INDEX"), c.accept("KEY"):
+			spec.Action = "RENAME_INDEX"
+		default:
+			spec.Action = "RENAME_TABLE"
+			if !c.accept("TO") {
+				c.accept("AS")
+			}
+			spec.NewName, _ = c.name()
+			return spec
+		}
+		spec.Name, _ = c.name()
+		c.accept("TO")
+		spec.NewName, _ = c.name()
+	}
+
+	return spec
+}
+
+// parseDrop 解析DROP语句
+func (p *Parser) parseDrop(c *cursor) (*types.SQLStatement, error) {
+	c.accept("DROP")
+	c.accept("TEMPORARY")
+
+	drop := &types.DropObject{ObjectType: c.stmt.Keyword(c.pos)}
+	switch drop.ObjectType {
+	case "SCHEMA":
+		drop.ObjectType = "DATABASE"
+	case "UNIQUE", "FULLTEXT", "SPATIAL":
+		c.pos++
+		drop.ObjectType = "INDEX"
+	case "":
+		return nil, c.stmt.Errorf("无法解析DROP的对象类型")
+	}
+	c.pos++
+	drop.IfExists = c.accept("IF", "EXISTS")
+
+	for {
+		name, ok := c.name()
+		if !ok {
+			return nil, c.stmt.Errorf("无法解析DROP %s 的对象名", drop.ObjectType)
+		}
+		drop.Names = append(drop.Names, name)
+		if !c.acceptPunct(",") {
+			break
+		}
+	}
+
+	stmt := &types.SQLStatement{
+		Type: "DROP_" + drop.ObjectType,
+		Name: strings.Join(drop.Names, ", "),
+		Drop: drop,
+	}
+	if drop.ObjectType == "INDEX" && c.accept("ON") {
+		drop.Table, _ = c.name()
+		stmt.Dependencies = []string{drop.Table}
+	}
+	return stmt, nil
+}
+
+// parseRename 解析RENAME TABLE语句
+func (p *Parser) parseRename(c *cursor) (*types.SQLStatement, error) {
+	c.accept("RENAME")
+	if !c.accept("TABLE") && !c.accept("TABLES") {
+		return p.parseOther(&cursor{stmt: c.stmt}), nil // RENAME USER 等
+	}
+
+	stmt := &types.SQLStatement{Type: "RENAME_TABLE"}
+	var names []string
+	for {
+		from, ok := c.name()
+		if !ok || !c.accept("TO") {
+			return nil, c.stmt.Errorf("无法解析RENAME TABLE的表名")
+		}
+		to, ok := c.name()
+		if !ok {
+			return nil, c.stmt.Errorf("无法解析RENAME TABLE的新表名")
+		}
+		stmt.Renames = append(stmt.Renames, types.RenameTable{From: from, To: to})
+		stmt.Dependencies = append(stmt.Dependencies, from)
+		names = append(names, from+" -> "+to)
+		if !c.acceptPunct(",") {
+			break
+		}
+	}
+	stmt.Name = strings.Join(names, ", ")
+	return stmt, nil
+}
+
+// parseDML 解析INSERT/REPLACE/UPDATE/DELETE/TRUNCATE语句，只提取目标表
+func (p *Parser) parseDML(c *cursor) (*types.SQLStatement, error) {
+	stmtType := c.stmt.Keyword(0)
+	c.pos++
+	for c.accept("LOW_PRIORITY") || c.accept("DELAYED") || c.accept("HIGH_PRIORITY") || c.accept("QUICK") || c.accept("IGNORE") {
+	}
+	switch stmtType {
+	case "INSERT", "REPLACE":
+		c.accept("INTO")
+	case "DELETE":
+		c.accept("FROM")
+	case "TRUNCATE":
+		c.accept("TABLE")
+	}
+
+	tableName, ok := c.name()
+	if !ok {
+		return nil, c.stmt.Errorf("无法解析%s语句的表名", stmtType)
+	}
+	return &types.SQLStatement{
+		Type:         stmtType,
+		Name:         tableName,
+		Dependencies: []string{tableName},
+	}, nil
+}
+
+// parseOther 解析SET、USE、LOCK TABLES等其他语句
+func (p *Parser) parseOther(c *cursor) *types.SQLStatement {
+	switch {
+	case c.accept("USE"):
+		name, _ := c.name()
+		return &types.SQLStatement{Type: "USE", Name: name}
+	case c.accept("LOCK", "TABLES"), c.accept("LOCK", "TABLE"):
+		return &types.SQLStatement{Type: "LOCK_TABLES"}
+	case c.accept("UNLOCK", "TABLES"), c.accept("UNLOCK", "TABLE"):
+		return &types.SQLStatement{Type: "UNLOCK_TABLES"}
+	case c.stmt.Keyword(0) == "SET":
+		return &types.SQLStatement{Type: "SET"}
+	}
+
+	name := c.stmt.Keyword(0)
+	if name == "" {
+		name = c.stmt.Tokens[0].Text
+	}
+	return &types.SQLStatement{Type: "OTHER", Name: name}
+}
+
+// splitTopLevel 从当前位置起按顶层逗号拆分，返回每段的 [开始, 结束) 位置
+func splitTopLevel(c *cursor) [][2]int {
+	var parts [][2]int
+	start, depth := c.pos, 0
+	for ; !c.done(); c.pos++ {
+		tok := c.peek()
+		switch {
+		case tok.IsPunct("("):
+			depth++
+		case tok.IsPunct(")"):
+			depth--
+		case tok.IsPunct(",") && depth == 0:
+			parts = append(parts, [2]int{start, c.pos})
+			start = c.pos + 1
+		}
+	}
+	if start < c.pos {
+		parts = append(parts, [2]int{start, c.pos})
+	}
+	return parts
+}
@@ -30,6 +30,9 @@ type InsertStatement struct {
 	LineNumber int             `json:"line_number"` // 源文件行号
 }
 
+// RawSQL 原样写入SQL的表达式，如 NOW()、UUID()、DEFAULT，不作为参数绑定
+type RawSQL string
+
 // DataInsertResult 数据插入结果
 type DataInsertResult struct {
 	DatabaseName         string              `json:"database_name"`
@@ -21,10 +21,52 @@ type DatabaseCreateConfig struct {
 
 // SQLStatement SQL语句结构
 type SQLStatement struct {
-	Type         string   // CREATE_TABLE, CREATE_VIEW, CREATE_PROCEDURE, etc.
-	Name         string   // 对象名称
+	Type         string   // CREATE_TABLE, ALTER_TABLE, DROP_TABLE, RENAME_TABLE, INSERT, SET, USE, OTHER, etc.
+	Name         string   // 对象名称，DML为表名
 	Statement    string   // 完整SQL语句
 	Dependencies []string // 依赖的对象名称
+	Line         int      // 语句在文件中的起始行
+
+	Alter   *AlterTable   // ALTER TABLE 的子句
+	Drop    *DropObject   // DROP 语句的对象
+	Renames []RenameTable // RENAME TABLE 的表名对
+}
+
+// AlterTable ALTER TABLE 语句
+type AlterTable struct {
+	Table string
+	Specs []AlterSpec
+}
+
+// AlterSpec ALTER TABLE 的一个子句
+type AlterSpec struct {
+	Action     string // ADD_COLUMN, ADD_INDEX, ADD_PRIMARY_KEY, ADD_FOREIGN_KEY, ADD_CHECK, DROP_COLUMN, DROP_INDEX, DROP_PRIMARY_KEY, DROP_FOREIGN_KEY, DROP_CONSTRAINT, MODIFY_COLUMN, CHANGE_COLUMN, ALTER_COLUMN, RENAME_COLUMN, RENAME_INDEX, RENAME_TABLE, OPTION
+	Name       string // 列、索引或约束名
+	NewName    string // CHANGE/RENAME 的新名称
+	References string // 外键引用的表
+	Definition string // 子句原文
+}
+
+// DropObject DROP 语句
+type DropObject struct {
+	ObjectType string   // TABLE, VIEW, INDEX, PROCEDURE, FUNCTION, TRIGGER, EVENT, DATABASE
+	Names      []string // 删除的对象
+	Table      string   // DROP INDEX ... ON table
+	IfExists   bool
+}
+
+// RenameTable RENAME TABLE 中的一对表名
+type RenameTable struct {
+	From string
+	To   string
+}
+
+// SkippedStatement 执行SQL文件时跳过的语句
+type SkippedStatement struct {
+	Line   int    `json:"line"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
 }
 
 // SQLParser SQL解析器接口
@@ -36,14 +78,16 @@ type SQLParser interface {
 
 // CreateFromSQLResult 从SQL创建的结果
 type CreateFromSQLResult struct {
-	DatabaseName      string       `json:"database_name"`
-	DatabaseCreated   bool         `json:"database_created"`
-	StatementsTotal   int          `json:"statements_total"`
-	StatementsSuccess int          `json:"statements_success"`
-	StatementsFailed  int          `json:"statements_failed"`
-	ExecutionTime     string       `json:"execution_time"`
-	Errors            []string     `json:"errors,omitempty"`
-	CreatedObjects    []ObjectInfo `json:"created_objects"`
+	DatabaseName      string             `json:"database_name"`
+	DatabaseCreated   bool               `json:"database_created"`
+	StatementsTotal   int                `json:"statements_total"`
+	StatementsSuccess int                `json:"statements_success"`
+	StatementsFailed  int                `json:"statements_failed"`
+	StatementsSkipped int                `json:"statements_skipped"`
+	ExecutionTime     string             `json:"execution_time"`
+	Errors            []string           `json:"errors,omitempty"`
+	CreatedObjects    []ObjectInfo       `json:"created_objects"`
+	Skipped           []SkippedStatement `json:"skipped,omitempty"`
 }
 
 // ObjectInfo 创建的对象信息
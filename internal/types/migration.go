@@ -53,6 +53,23 @@ type Checker interface {
 	FunctionExists(ctx context.Context, functionName string) (bool, error)
 	// ConstraintExists 检查约束是否存在
 	ConstraintExists(ctx context.Context, tableName, constraintName string) (bool, error)
+	// ProcedureExists 检查存储过程是否存在
+	ProcedureExists(ctx context.Context, procedureName string) (bool, error)
+	// TriggerExists 检查触发器是否存在
+	TriggerExists(ctx context.Context, triggerName string) (bool, error)
+	// ViewExists 检查视图是否存在
+	ViewExists(ctx context.Context, viewName string) (bool, error)
+	// DatabaseExists 检查数据库是否存在
+	DatabaseExists(ctx context.Context, databaseName string) (bool, error)
+
+	// DescribeTable 获取表的选项和列，表不存在时返回 nil
+	DescribeTable(ctx context.Context, tableName string) (*TableInfo, error)
+	// ListIndexes 获取表的索引（包括主键）
+	ListIndexes(ctx context.Context, tableName string) ([]IndexInfo, error)
+	// ListForeignKeys 获取表的外键
+	ListForeignKeys(ctx context.Context, tableName string) ([]ForeignKeyInfo, error)
+	// ListTables 获取所有表名（不包括视图）
+	ListTables(ctx context.Context) ([]string, error)
 }
 
 // MigrationRecord 迁移记录
@@ -109,6 +126,7 @@ type DatabaseConfig struct {
 type MigratorConfig struct {
 	MigrationsTable  string   `yaml:"migrations_table"`
 	LockTable        string   `yaml:"lock_table"`
+	SeedTable        string   `yaml:"seed_table,omitempty"` // 种子数据导入记录表
 	AutoBackup       bool     `yaml:"auto_backup"`
 	DryRun           bool     `yaml:"dry_run"`
 	DefaultDatabase  string   `yaml:"default_database,omitempty"`  // 默认操作的数据库
@@ -0,0 +1,57 @@
+package types
+
+import "strings"
+
+// TableInfo 表的选项和列定义
+type TableInfo struct {
+	Name          string
+	Type          string // BASE TABLE 或 VIEW
+	Engine        string
+	Charset       string
+	Collation     string
+	RowFormat     string
+	AutoIncrement int64 // 下一个自增值，没有自增列时为 0
+	Comment       string
+	Columns       []ColumnInfo
+}
+
+// ColumnInfo 列定义
+type ColumnInfo struct {
+	Name       string
+	DataType   string // 基本类型，如 varchar、int
+	ColumnType string // 完整类型，如 varchar(64)、int unsigned
+	Nullable   bool
+	Default    *string // 没有默认值或默认值为 NULL 时为 nil
+	Extra      string  // auto_increment、on update CURRENT_TIMESTAMP、VIRTUAL GENERATED 等
+	Generated  string  // 生成列表达式
+	Comment    string
+}
+
+// Column 按名称查找列，不区分大小写
+func (t *TableInfo) Column(name string) *ColumnInfo {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// IndexInfo 索引定义，主键的名称为 PRIMARY
+type IndexInfo struct {
+	Name     string
+	Columns  []string // 函数索引的表达式部分为空字符串
+	SubParts []int    // 每列的前缀长度，0 表示整列
+	Unique   bool
+	Type     string // BTREE、HASH、FULLTEXT、SPATIAL
+}
+
+// ForeignKeyInfo 外键定义
+type ForeignKeyInfo struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+	OnUpdate   string
+}